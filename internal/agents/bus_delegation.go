@@ -0,0 +1,90 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxDelegationDepth caps how many agent-to-agent hops a single task may
+// take below the agent the orchestrator first sent it to.
+const MaxDelegationDepth = 3
+
+// MetaDelegationChain is the BusMessage.Meta key holding the roles a task
+// has passed through, e.g. "orchestrator>coder>researcher".
+const MetaDelegationChain = "delegation_chain"
+
+var (
+	// ErrNotInHandler is returned by Delegate outside an AgentHandler.
+	ErrNotInHandler = errors.New("bus: Delegate called outside an agent handler")
+	// ErrDelegationDepth is returned when a chain would exceed MaxDelegationDepth.
+	ErrDelegationDepth = errors.New("bus: delegation depth exceeded")
+	// ErrDelegationCycle is returned when a role would delegate back to a
+	// role already in its chain.
+	ErrDelegationCycle = errors.New("bus: circular delegation")
+)
+
+type delegationKey struct{}
+
+// delegation is carried in the context Send passes to a handler.
+type delegation struct {
+	bus   *MultiAgentBus
+	chain []AgentRole
+}
+
+// Delegate lets the agent handling ctx send a sub-task to another role, e.g.
+// a Coder asking the Researcher a question, and wait for its result. ctx must
+// be the context the handler was called with. Delegation is refused when the
+// target is already in the chain or the chain is MaxDelegationDepth deep.
+func Delegate(ctx context.Context, to AgentRole, payload string) (BusMessage, error) {
+	d, ok := ctx.Value(delegationKey{}).(*delegation)
+	if !ok {
+		return BusMessage{}, ErrNotInHandler
+	}
+	chain := formatChain(append(d.chain, to))
+	for _, role := range d.chain {
+		if role == to {
+			return BusMessage{}, fmt.Errorf("%w: %s", ErrDelegationCycle, chain)
+		}
+	}
+	// chain[0] is the original sender and chain[1] the first agent, so the
+	// hops taken so far are len(chain)-2.
+	if len(d.chain)-2 >= MaxDelegationDepth {
+		return BusMessage{}, fmt.Errorf("%w: %s", ErrDelegationDepth, chain)
+	}
+	return d.bus.Send(ctx, BusMessage{
+		Type:    MsgTask,
+		From:    d.chain[len(d.chain)-1],
+		To:      to,
+		Payload: payload,
+	})
+}
+
+// withDelegation returns the handler context for msg and stamps msg.Meta
+// with the delegation chain that led to it.
+func (b *MultiAgentBus) withDelegation(ctx context.Context, msg *BusMessage) context.Context {
+	var chain []AgentRole
+	if d, ok := ctx.Value(delegationKey{}).(*delegation); ok && d.bus == b {
+		chain = append(chain, d.chain...)
+	} else {
+		chain = append(chain, msg.From)
+	}
+	chain = append(chain, msg.To)
+
+	meta := make(map[string]string, len(msg.Meta)+1)
+	for k, v := range msg.Meta {
+		meta[k] = v
+	}
+	meta[MetaDelegationChain] = formatChain(chain)
+	msg.Meta = meta
+	return context.WithValue(ctx, delegationKey{}, &delegation{bus: b, chain: chain})
+}
+
+func formatChain(chain []AgentRole) string {
+	parts := make([]string, len(chain))
+	for i, r := range chain {
+		parts[i] = string(r)
+	}
+	return strings.Join(parts, ">")
+}
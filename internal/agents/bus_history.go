@@ -0,0 +1,146 @@
+package agents
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/rs/zerolog/log"
+)
+
+// HistoryFilter selects bus messages for History. A zero Role matches every
+// role; otherwise a message matches if it was sent from or to Role.
+type HistoryFilter struct {
+	Role  AgentRole
+	Since time.Time
+}
+
+// EnableHistoryDB persists every bus message to the SQLite file at path, so
+// the full conversation between sub-agents can be queried after the in-memory
+// window (the last 200 messages) has moved on.
+func (b *MultiAgentBus) EnableHistoryDB(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("bus: history dir: %w", err)
+	}
+	// Create with 0600 before sql.Open: payloads may contain user data.
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("bus: create history db: %w", err)
+	}
+	f.Close()
+	db, err := sql.Open("sqlite3", path+"?_journal_mode=WAL")
+	if err != nil {
+		return fmt.Errorf("bus: open history db: %w", err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS bus_messages (
+			seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+			id         TEXT NOT NULL,
+			type       TEXT NOT NULL,
+			from_role  TEXT NOT NULL,
+			to_role    TEXT NOT NULL,
+			payload    TEXT NOT NULL,
+			meta       TEXT NOT NULL DEFAULT '{}',
+			reply_to   TEXT NOT NULL DEFAULT '',
+			created_at INTEGER NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_bus_messages_created ON bus_messages(created_at);
+	`)
+	if err != nil {
+		db.Close()
+		return fmt.Errorf("bus: migrate history db: %w", err)
+	}
+	b.histMu.Lock()
+	old := b.db
+	b.db = db
+	b.histMu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// Close closes the history database, if one is enabled.
+func (b *MultiAgentBus) Close() error {
+	b.histMu.Lock()
+	db := b.db
+	b.db = nil
+	b.histMu.Unlock()
+	if db == nil {
+		return nil
+	}
+	return db.Close()
+}
+
+// History returns messages matching filter, oldest first. It reads the
+// history database when enabled and the in-memory window otherwise.
+func (b *MultiAgentBus) History(filter HistoryFilter) ([]BusMessage, error) {
+	b.histMu.Lock()
+	db := b.db
+	if db == nil {
+		var out []BusMessage
+		for _, m := range b.history {
+			if filter.matches(m) {
+				out = append(out, m)
+			}
+		}
+		b.histMu.Unlock()
+		return out, nil
+	}
+	b.histMu.Unlock()
+
+	rows, err := db.Query(`
+		SELECT id, type, from_role, to_role, payload, meta, reply_to, created_at
+		FROM bus_messages
+		WHERE created_at >= ? AND (? = '' OR from_role = ? OR to_role = ?)
+		ORDER BY seq`,
+		filter.Since.UnixNano(), filter.Role, filter.Role, filter.Role)
+	if err != nil {
+		return nil, fmt.Errorf("bus: query history: %w", err)
+	}
+	defer rows.Close()
+	var out []BusMessage
+	for rows.Next() {
+		var m BusMessage
+		var meta string
+		var created int64
+		if err := rows.Scan(&m.ID, &m.Type, &m.From, &m.To, &m.Payload, &meta, &m.ReplyTo, &created); err != nil {
+			return nil, fmt.Errorf("bus: scan history: %w", err)
+		}
+		if meta != "" && meta != "{}" {
+			_ = json.Unmarshal([]byte(meta), &m.Meta)
+		}
+		m.CreatedAt = time.Unix(0, created)
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+func (f HistoryFilter) matches(m BusMessage) bool {
+	if m.CreatedAt.Before(f.Since) {
+		return false
+	}
+	return f.Role == "" || m.From == f.Role || m.To == f.Role
+}
+
+// persist writes msg to the history database. Failures are logged rather
+// than failing the send: the bus must keep working if the disk fills up.
+func (b *MultiAgentBus) persist(db *sql.DB, msg BusMessage) {
+	meta := "{}"
+	if len(msg.Meta) > 0 {
+		if data, err := json.Marshal(msg.Meta); err == nil {
+			meta = string(data)
+		}
+	}
+	_, err := db.Exec(`
+		INSERT INTO bus_messages (id, type, from_role, to_role, payload, meta, reply_to, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		msg.ID, msg.Type, msg.From, msg.To, msg.Payload, meta, msg.ReplyTo, msg.CreatedAt.UnixNano())
+	if err != nil {
+		log.Warn().Err(err).Str("msg", msg.ID).Msg("bus: failed to persist message")
+	}
+}
@@ -0,0 +1,105 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// PrevResultPlaceholder in a PipelineStep payload is replaced by the
+// previous step's result. Without it the result is appended to the payload.
+const PrevResultPlaceholder = "{{prev}}"
+
+// PipelineStep is one stage of a Pipeline.
+type PipelineStep struct {
+	Role    AgentRole
+	Payload string
+}
+
+// Pipeline runs steps in order, feeding each step's result into the next
+// step's payload, and returns the last result. It stops at the first failing
+// step.
+func (b *MultiAgentBus) Pipeline(ctx context.Context, steps []PipelineStep) (BusMessage, error) {
+	if len(steps) == 0 {
+		return BusMessage{}, errors.New("bus: empty pipeline")
+	}
+	var prev BusMessage
+	for i, step := range steps {
+		payload := step.Payload
+		if i > 0 {
+			if strings.Contains(payload, PrevResultPlaceholder) {
+				payload = strings.ReplaceAll(payload, PrevResultPlaceholder, prev.Payload)
+			} else {
+				payload += "\n\nInput from " + string(prev.From) + ":\n" + prev.Payload
+			}
+		}
+		res, err := b.Send(ctx, BusMessage{Type: MsgTask, From: RoleOrchestrator, To: step.Role, Payload: payload})
+		if err != nil {
+			return res, fmt.Errorf("bus: pipeline step %d (%s): %w", i+1, step.Role, err)
+		}
+		prev = res
+	}
+	return prev, nil
+}
+
+// Aggregate fans tasks out to their roles in parallel and merges the results
+// with merge (DefaultMerge when nil) into a single orchestrator result.
+// Failed tasks appear in the merge input as MsgError messages; the returned
+// error joins their errors, so callers can use a partial merge.
+func (b *MultiAgentBus) Aggregate(ctx context.Context, tasks map[AgentRole]string, merge func(map[AgentRole]BusMessage) string) (BusMessage, error) {
+	if merge == nil {
+		merge = DefaultMerge
+	}
+	results := make(map[AgentRole]BusMessage, len(tasks))
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+	)
+	for role, payload := range tasks {
+		wg.Add(1)
+		go func(r AgentRole, p string) {
+			defer wg.Done()
+			res, err := b.Send(ctx, BusMessage{Type: MsgTask, From: RoleOrchestrator, To: r, Payload: p})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				res = BusMessage{Type: MsgError, From: r, Payload: err.Error()}
+				errs = append(errs, fmt.Errorf("%s: %w", r, err))
+			}
+			results[r] = res
+		}(role, payload)
+	}
+	wg.Wait()
+
+	return BusMessage{
+		Type:    MsgResult,
+		From:    RoleOrchestrator,
+		Payload: merge(results),
+	}, errors.Join(errs...)
+}
+
+// DefaultMerge concatenates results under a heading per role, in role
+// order, skipping failed tasks.
+func DefaultMerge(results map[AgentRole]BusMessage) string {
+	roles := make([]string, 0, len(results))
+	for r := range results {
+		roles = append(roles, string(r))
+	}
+	sort.Strings(roles)
+	var sb strings.Builder
+	for _, r := range roles {
+		res := results[AgentRole(r)]
+		if res.Type == MsgError {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "## %s\n%s", r, res.Payload)
+	}
+	return sb.String()
+}
@@ -8,6 +8,7 @@ Detects:
   - Missed follow-ups ("follow up with X" never resolved)
   - Context loss (abrupt topic change mid-task)
   - Repetitive failures (same error mentioned 3+ times)
+  - Stalled goals (no progress in the goal store for StalledGoalDays)
 
 This feature does not exist in any other open-source AI agent.
 Inspired by: r/AI_Agents — 'something that quietly prevents things from unraveling'
@@ -17,14 +18,19 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/Omkar0612/nexus-ai/internal/events"
+	"github.com/Omkar0612/nexus-ai/internal/goals"
 	"github.com/Omkar0612/nexus-ai/internal/memory"
+	"github.com/Omkar0612/nexus-ai/internal/semantic"
+	"github.com/rs/zerolog/log"
 )
 
 // DriftSignal represents a detected work drift pattern
 type DriftSignal struct {
-	Type        string    // stalled_task, missed_followup, context_loss, repetitive_failure
+	Type        string    // stalled_task, missed_followup, context_loss, repetitive_failure, stalled_goal
 	Severity    string    // low, medium, high
 	Description string
 	Suggestion  string
@@ -38,52 +44,252 @@ type DriftDetector struct {
 	userID     string
 	signals    []DriftSignal
 	thresholds DriftThresholds
+	bus        *events.Bus
+	goals      GoalSource
+
+	mu        sync.Mutex           // guards signals and dismissed
+	dismissed map[string]dismissal // keyed by TaskRef
+
+	// Optional embedding-based completion matching; see WithSemanticMatching.
+	sem          *semantic.Store
+	semThreshold float64
+}
+
+// dismissal snoozes a signal until a time or until its severity changes.
+type dismissal struct {
+	until    time.Time
+	severity string // severity when dismissed; "" until the next scan sees it
+}
+
+// DefaultSemanticThreshold is the cosine similarity above which a completion
+// message is taken to close a pending task.
+const DefaultSemanticThreshold = 0.75
+
+// DriftOption configures a DriftDetector.
+type DriftOption func(*DriftDetector)
+
+// WithSemanticMatching pairs completions with pending tasks by embedding
+// similarity, so "shipped the auth rewrite" closes "building the login
+// overhaul". A threshold <= 0 uses DefaultSemanticThreshold. When an
+// embedding call fails, that pair falls back to keyword matching.
+func WithSemanticMatching(s *semantic.Store, threshold float64) DriftOption {
+	return func(d *DriftDetector) {
+		if threshold <= 0 {
+			threshold = DefaultSemanticThreshold
+		}
+		d.sem, d.semThreshold = s, threshold
+	}
 }
 
-// DriftThresholds configures when to fire alerts
+// GoalSource lists stalled goals; *goals.Store implements it.
+type GoalSource interface {
+	Stalled(days int) ([]goals.Goal, error)
+}
+
+// DriftThresholds configures when to fire alerts and which vocabulary
+// counts as a task, a completion, a follow-up or an error. Keywords are
+// matched case-insensitively as substrings. Zero fields take the value from
+// DefaultDriftThresholds, so callers only set what they want to change.
 type DriftThresholds struct {
 	StalledTaskHours   int
 	MissedFollowupDays int
 	GoalDeviationScore float64
+	StalledGoalDays    int
+
+	TaskKeywords     []string // open a pending task, e.g. "working on"
+	DoneKeywords     []string // close a matching pending task, e.g. "shipped"
+	FollowupKeywords []string // promise a follow-up, e.g. "remind me"
+	ErrorKeywords    []string // counted towards repetitive_failure
+
+	// Signals older than MediumAfter are "medium", older than HighAfter "high".
+	MediumAfter time.Duration
+	HighAfter   time.Duration
+}
+
+// DefaultDriftThresholds returns the thresholds and English keyword lists
+// used when none are configured.
+func DefaultDriftThresholds() DriftThresholds {
+	return DriftThresholds{
+		StalledTaskHours:   24,
+		MissedFollowupDays: 2,
+		GoalDeviationScore: 0.6,
+		StalledGoalDays:    7,
+		TaskKeywords:       []string{"working on", "need to", "will", "plan to", "building", "creating"},
+		DoneKeywords:       []string{"done", "finished", "completed", "shipped", "deployed", "fixed"},
+		FollowupKeywords:   []string{"follow up", "remind me", "check back", "circle back", "ping them"},
+		ErrorKeywords:      []string{"error", "failed", "not working", "broken", "issue", "bug"},
+		MediumAfter:        24 * time.Hour,
+		HighAfter:          72 * time.Hour,
+	}
+}
+
+// withDefaults fills zero fields from DefaultDriftThresholds.
+func (t DriftThresholds) withDefaults() DriftThresholds {
+	def := DefaultDriftThresholds()
+	if t.StalledTaskHours <= 0 {
+		t.StalledTaskHours = def.StalledTaskHours
+	}
+	if t.MissedFollowupDays <= 0 {
+		t.MissedFollowupDays = def.MissedFollowupDays
+	}
+	if t.GoalDeviationScore <= 0 {
+		t.GoalDeviationScore = def.GoalDeviationScore
+	}
+	if t.StalledGoalDays <= 0 {
+		t.StalledGoalDays = def.StalledGoalDays
+	}
+	if len(t.TaskKeywords) == 0 {
+		t.TaskKeywords = def.TaskKeywords
+	}
+	if len(t.DoneKeywords) == 0 {
+		t.DoneKeywords = def.DoneKeywords
+	}
+	if len(t.FollowupKeywords) == 0 {
+		t.FollowupKeywords = def.FollowupKeywords
+	}
+	if len(t.ErrorKeywords) == 0 {
+		t.ErrorKeywords = def.ErrorKeywords
+	}
+	for _, kws := range []*[]string{&t.TaskKeywords, &t.DoneKeywords, &t.FollowupKeywords, &t.ErrorKeywords} {
+		lowered := make([]string, len(*kws))
+		for i, kw := range *kws {
+			lowered[i] = strings.ToLower(kw)
+		}
+		*kws = lowered
+	}
+	if t.MediumAfter <= 0 {
+		t.MediumAfter = def.MediumAfter
+	}
+	if t.HighAfter <= 0 {
+		t.HighAfter = def.HighAfter
+	}
+	return t
+}
+
+// WithThresholds replaces the default thresholds and keyword lists; unset
+// fields keep their defaults.
+func WithThresholds(t DriftThresholds) DriftOption {
+	return func(d *DriftDetector) { d.thresholds = t.withDefaults() }
 }
 
 // NewDriftDetector creates a new drift detector
-func NewDriftDetector(mem *memory.Store, userID string) *DriftDetector {
-	return &DriftDetector{
-		mem:    mem,
-		userID: userID,
-		thresholds: DriftThresholds{
-			StalledTaskHours:   24,
-			MissedFollowupDays: 2,
-			GoalDeviationScore: 0.6,
-		},
+func NewDriftDetector(mem *memory.Store, userID string, opts ...DriftOption) *DriftDetector {
+	d := &DriftDetector{
+		mem:        mem,
+		userID:     userID,
+		thresholds: DefaultDriftThresholds(),
+		dismissed:  make(map[string]dismissal),
+	}
+	for _, opt := range opts {
+		opt(d)
 	}
+	return d
+}
+
+// SetEventBus publishes every signal found by Scan to bus as events.KindDriftSignal.
+func (d *DriftDetector) SetEventBus(bus *events.Bus) {
+	d.bus = bus
+}
+
+// SetGoalSource makes Scan report goals idle for StalledGoalDays as
+// stalled_goal signals.
+func (d *DriftDetector) SetGoalSource(src GoalSource) {
+	d.goals = src
 }
 
-// Scan analyses recent memory for drift signals
+// Scan analyses recent memory (and the goal source, if set) for drift signals
 func (d *DriftDetector) Scan(ctx context.Context) ([]DriftSignal, error) {
 	history, err := d.mem.GetEpisodicHistory(d.userID, 100)
 	if err != nil {
 		return nil, err
 	}
 	var signals []DriftSignal
-	signals = append(signals, d.detectStalledTasks(history)...)
+	signals = append(signals, d.detectStalledTasks(ctx, history)...)
 	signals = append(signals, d.detectMissedFollowups(history)...)
 	signals = append(signals, d.detectRepetitiveFailures(history)...)
+	if d.goals != nil {
+		stalled, err := d.detectStalledGoals()
+		if err != nil {
+			return nil, err
+		}
+		signals = append(signals, stalled...)
+	}
+	signals = d.suppressDismissed(signals, time.Now())
+	d.mu.Lock()
 	d.signals = signals
+	d.mu.Unlock()
+	for _, s := range signals {
+		d.bus.Publish(events.Event{
+			Kind:     events.KindDriftSignal,
+			Source:   "drift_detector",
+			Severity: s.Severity,
+			Message:  s.Description,
+			Payload:  s,
+			At:       s.DetectedAt,
+		})
+	}
 	return signals, nil
 }
 
-func (d *DriftDetector) detectStalledTasks(history []memory.Memory) []DriftSignal {
+// Dismiss snoozes the signal for taskRef until the given time. The signal
+// comes back earlier if it escalates, i.e. its age crosses into a new
+// severity, so a dismissed "medium" stalled task is reported again once it
+// turns "high".
+func (d *DriftDetector) Dismiss(taskRef string, until time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var severity string
+	for _, s := range d.signals {
+		if s.TaskRef == taskRef {
+			severity = s.Severity
+			break
+		}
+	}
+	d.dismissed[taskRef] = dismissal{until: until, severity: severity}
+}
+
+// suppressDismissed drops snoozed signals and forgets dismissals that have
+// expired or whose signal changed severity.
+func (d *DriftDetector) suppressDismissed(signals []DriftSignal, now time.Time) []DriftSignal {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for ref, dm := range d.dismissed {
+		if !now.Before(dm.until) {
+			delete(d.dismissed, ref)
+		}
+	}
+	if len(d.dismissed) == 0 {
+		return signals
+	}
+	kept := signals[:0]
+	for _, s := range signals {
+		dm, ok := d.dismissed[s.TaskRef]
+		if ok && s.TaskRef != "" {
+			if dm.severity == "" {
+				dm.severity = s.Severity
+				d.dismissed[s.TaskRef] = dm
+			}
+			if dm.severity == s.Severity {
+				continue
+			}
+		}
+		if ok {
+			delete(d.dismissed, s.TaskRef)
+		}
+		kept = append(kept, s)
+	}
+	return kept
+}
+
+func (d *DriftDetector) detectStalledTasks(ctx context.Context, history []memory.Memory) []DriftSignal {
 	var signals []DriftSignal
-	taskKW := []string{"working on", "need to", "will", "plan to", "building", "creating"}
-	doneKW := []string{"done", "finished", "completed", "shipped", "deployed", "fixed"}
+	match := d.completionMatcher(ctx)
 	pending := make(map[string]time.Time)
 
 	for i := len(history) - 1; i >= 0; i-- {
 		m := history[i]
 		content := strings.ToLower(m.Content)
-		for _, kw := range taskKW {
+		for _, kw := range d.thresholds.TaskKeywords {
 			if strings.Contains(content, kw) {
 				ref := taskRef(m.Content, kw)
 				if ref != "" {
@@ -91,10 +297,10 @@ func (d *DriftDetector) detectStalledTasks(history []memory.Memory) []DriftSigna
 				}
 			}
 		}
-		for _, kw := range doneKW {
+		for _, kw := range d.thresholds.DoneKeywords {
 			if strings.Contains(content, kw) {
 				for ref := range pending {
-					if fuzzyMatch(content, ref) {
+					if match(content, ref) {
 						delete(pending, ref)
 					}
 				}
@@ -107,7 +313,7 @@ func (d *DriftDetector) detectStalledTasks(history []memory.Memory) []DriftSigna
 		if time.Since(start) > threshold {
 			signals = append(signals, DriftSignal{
 				Type:        "stalled_task",
-				Severity:    severityFromAge(time.Since(start)),
+				Severity:    d.thresholds.severity(time.Since(start)),
 				Description: fmt.Sprintf("Task stalled: '%s' (last touched %s ago)", ref, fmtAge(time.Since(start))),
 				Suggestion:  fmt.Sprintf("Resume or close: '%s'", ref),
 				DetectedAt:  time.Now(),
@@ -120,10 +326,9 @@ func (d *DriftDetector) detectStalledTasks(history []memory.Memory) []DriftSigna
 
 func (d *DriftDetector) detectMissedFollowups(history []memory.Memory) []DriftSignal {
 	var signals []DriftSignal
-	kws := []string{"follow up", "remind me", "check back", "circle back", "ping them"}
 	for _, m := range history {
 		content := strings.ToLower(m.Content)
-		for _, kw := range kws {
+		for _, kw := range d.thresholds.FollowupKeywords {
 			if strings.Contains(content, kw) {
 				age := time.Since(m.CreatedAt)
 				if age > time.Duration(d.thresholds.MissedFollowupDays)*24*time.Hour {
@@ -148,11 +353,10 @@ func (d *DriftDetector) detectMissedFollowups(history []memory.Memory) []DriftSi
 
 func (d *DriftDetector) detectRepetitiveFailures(history []memory.Memory) []DriftSignal {
 	var signals []DriftSignal
-	errorKW := []string{"error", "failed", "not working", "broken", "issue", "bug"}
 	counts := make(map[string]int)
 	for _, m := range history {
 		content := strings.ToLower(m.Content)
-		for _, kw := range errorKW {
+		for _, kw := range d.thresholds.ErrorKeywords {
 			if strings.Contains(content, kw) {
 				counts[kw]++
 				if counts[kw] == 3 {
@@ -170,9 +374,32 @@ func (d *DriftDetector) detectRepetitiveFailures(history []memory.Memory) []Drif
 	return signals
 }
 
+func (d *DriftDetector) detectStalledGoals() ([]DriftSignal, error) {
+	stalled, err := d.goals.Stalled(d.thresholds.StalledGoalDays)
+	if err != nil {
+		return nil, fmt.Errorf("drift: stalled goals: %w", err)
+	}
+	var signals []DriftSignal
+	for _, g := range stalled {
+		age := time.Since(g.LastActivity)
+		signals = append(signals, DriftSignal{
+			Type:        "stalled_goal",
+			Severity:    d.thresholds.severity(age),
+			Description: fmt.Sprintf("Goal stalled: '%s' at %.0f%% (no progress in %s)", g.Title, g.Progress*100, fmtAge(age)),
+			Suggestion:  fmt.Sprintf("Take one small step on '%s', or drop it", g.Title),
+			DetectedAt:  time.Now(),
+			TaskRef:     g.ID,
+		})
+	}
+	return signals, nil
+}
+
 // FormatReport generates a human-readable drift report
 func (d *DriftDetector) FormatReport() string {
-	if len(d.signals) == 0 {
+	d.mu.Lock()
+	signals := d.signals
+	d.mu.Unlock()
+	if len(signals) == 0 {
 		return "✅ No drift detected — all work looks on track."
 	}
 	var sb strings.Builder
@@ -181,8 +408,9 @@ func (d *DriftDetector) FormatReport() string {
 		"stalled_task":      "🔴",
 		"missed_followup":   "🟡",
 		"repetitive_failure": "🔴",
+		"stalled_goal":      "🎯",
 	}
-	for _, s := range d.signals {
+	for _, s := range signals {
 		icon := icons[s.Type]
 		if icon == "" {
 			icon = "🔵"
@@ -192,6 +420,41 @@ func (d *DriftDetector) FormatReport() string {
 	return sb.String()
 }
 
+// completionMatcher returns the function that decides whether a completion
+// message closes a pending task: fuzzyMatch, or embedding similarity when a
+// semantic store is configured. Embeddings are cached for the scan.
+func (d *DriftDetector) completionMatcher(ctx context.Context) func(done, ref string) bool {
+	if d.sem == nil {
+		return fuzzyMatch
+	}
+	cache := make(map[string][]float64)
+	embed := func(text string) ([]float64, error) {
+		if v, ok := cache[text]; ok {
+			return v, nil
+		}
+		v, err := d.sem.Embed(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		cache[text] = v
+		return v, nil
+	}
+	return func(done, ref string) bool {
+		if fuzzyMatch(done, ref) {
+			return true
+		}
+		a, err := embed(done)
+		if err == nil {
+			var b []float64
+			if b, err = embed(ref); err == nil {
+				return semantic.Similarity(a, b) >= d.semThreshold
+			}
+		}
+		log.Debug().Err(err).Msg("drift: embedding failed, using keyword match")
+		return false
+	}
+}
+
 func taskRef(content, keyword string) string {
 	idx := strings.Index(strings.ToLower(content), keyword)
 	if idx < 0 {
@@ -213,11 +476,12 @@ func fuzzyMatch(a, b string) bool {
 	return false
 }
 
-func severityFromAge(d time.Duration) string {
-	if d > 72*time.Hour {
+// severity grades a signal by how long it has been outstanding.
+func (t DriftThresholds) severity(d time.Duration) string {
+	if d > t.HighAfter {
 		return "high"
 	}
-	if d > 24*time.Hour {
+	if d > t.MediumAfter {
 		return "medium"
 	}
 	return "low"
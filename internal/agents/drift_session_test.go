@@ -2,10 +2,19 @@ package agents
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/Omkar0612/nexus-ai/internal/goals"
 	"github.com/Omkar0612/nexus-ai/internal/memory"
+	"github.com/Omkar0612/nexus-ai/internal/semantic"
 )
 
 // --- helpers ---
@@ -150,3 +159,192 @@ func TestSessionBrieferRecentSession(t *testing.T) {
 		t.Errorf("expected nil brief for recent session, got %+v", brief)
 	}
 }
+
+type fakeGoalSource []goals.Goal
+
+func (f fakeGoalSource) Stalled(int) ([]goals.Goal, error) { return f, nil }
+
+func TestDriftDetectorStalledGoals(t *testing.T) {
+	store := newMemStoreWithEntries(t, "u1", nil)
+	detector := NewDriftDetector(store, "u1")
+	detector.SetGoalSource(fakeGoalSource{
+		{ID: "g-1", Title: "Ship NEXUS v2", Progress: 0.3, LastActivity: time.Now().Add(-10 * 24 * time.Hour)},
+	})
+
+	signals, err := detector.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(signals) != 1 || signals[0].Type != "stalled_goal" || signals[0].TaskRef != "g-1" || signals[0].Severity != "high" {
+		t.Errorf("expected one high stalled_goal signal, got %+v", signals)
+	}
+}
+
+// conceptEmbedServer embeds text onto one axis per concept, so paraphrases
+// of the same concept are identical vectors and different concepts are
+// orthogonal.
+func conceptEmbedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	concepts := [][]string{{"login", "auth", "sign-in"}, {"invoice", "billing"}}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		vec := make([]float64, len(concepts)+1)
+		vec[len(concepts)] = 1
+		for i, words := range concepts {
+			for _, w := range words {
+				if strings.Contains(strings.ToLower(req["prompt"]), w) {
+					vec[i], vec[len(concepts)] = 1, 0
+				}
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"embedding": vec})
+	}))
+}
+
+func TestDriftDetectorSemanticCompletion(t *testing.T) {
+	srv := conceptEmbedServer(t)
+	defer srv.Close()
+	sem, err := semantic.New(filepath.Join(t.TempDir(), "sem.db"), srv.URL, "test")
+	if err != nil {
+		t.Fatalf("semantic.New: %v", err)
+	}
+	defer sem.Close()
+
+	now := time.Now()
+	history := []memory.Memory{ // newest first, as GetEpisodicHistory returns
+		{Content: "finally shipped the auth rewrite", CreatedAt: now.Add(-time.Hour)},
+		{Content: "working on the invoice export", CreatedAt: now.Add(-47 * time.Hour)},
+		{Content: "building the login overhaul", CreatedAt: now.Add(-48 * time.Hour)},
+	}
+	stalledRefs := func(d *DriftDetector) []string {
+		var refs []string
+		for _, s := range d.detectStalledTasks(context.Background(), history) {
+			refs = append(refs, s.TaskRef)
+		}
+		sort.Strings(refs)
+		return refs
+	}
+
+	keyword := NewDriftDetector(nil, "u1")
+	if got := stalledRefs(keyword); len(got) != 2 {
+		t.Fatalf("keyword matching should miss the paraphrase, got %q", got)
+	}
+	semanticDetector := NewDriftDetector(nil, "u1", WithSemanticMatching(sem, 0))
+	got := stalledRefs(semanticDetector)
+	if len(got) != 1 || !strings.Contains(got[0], "invoice") {
+		t.Errorf("expected only the invoice task to stay stalled, got %q", got)
+	}
+}
+
+func TestDriftDetectorSemanticFallback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	sem, err := semantic.New(filepath.Join(t.TempDir(), "sem.db"), srv.URL, "test")
+	if err != nil {
+		t.Fatalf("semantic.New: %v", err)
+	}
+	defer sem.Close()
+
+	history := []memory.Memory{
+		{Content: "deployed the payment service", CreatedAt: time.Now().Add(-time.Hour)},
+		{Content: "building the payment service", CreatedAt: time.Now().Add(-48 * time.Hour)},
+	}
+	d := NewDriftDetector(nil, "u1", WithSemanticMatching(sem, 0))
+	if signals := d.detectStalledTasks(context.Background(), history); len(signals) != 0 {
+		t.Errorf("keyword fallback should close the task, got %+v", signals)
+	}
+}
+
+func TestDriftDetectorCustomVocabulary(t *testing.T) {
+	now := time.Now()
+	history := []memory.Memory{
+		{Content: "Submitted the survey analysis to the journal", CreatedAt: now.Add(-time.Hour)},
+		{Content: "Drafting the survey analysis chapter", CreatedAt: now.Add(-50 * time.Hour)},
+		{Content: "Drafting the literature review", CreatedAt: now.Add(-50 * time.Hour)},
+	}
+
+	if got := NewDriftDetector(nil, "u1").detectStalledTasks(context.Background(), history); len(got) != 0 {
+		t.Fatalf("default vocabulary should not recognise research tasks, got %+v", got)
+	}
+
+	d := NewDriftDetector(nil, "u1", WithThresholds(DriftThresholds{
+		TaskKeywords: []string{"Drafting"},
+		DoneKeywords: []string{"submitted"},
+		HighAfter:    48 * time.Hour,
+	}))
+	signals := d.detectStalledTasks(context.Background(), history)
+	if len(signals) != 1 || !strings.Contains(signals[0].TaskRef, "literature review") {
+		t.Fatalf("expected only the literature review to be stalled, got %+v", signals)
+	}
+	if signals[0].Severity != "high" {
+		t.Errorf("severity = %q, want high with HighAfter=48h", signals[0].Severity)
+	}
+	if d.thresholds.StalledTaskHours != 24 || len(d.thresholds.ErrorKeywords) == 0 {
+		t.Errorf("unset thresholds should keep defaults: %+v", d.thresholds)
+	}
+}
+
+func TestDriftDetectorDismiss(t *testing.T) {
+	d := NewDriftDetector(nil, "u1")
+	now := time.Now()
+	stalled := func(severity string) []DriftSignal {
+		return []DriftSignal{
+			{Type: "stalled_task", Severity: severity, TaskRef: "working on the parser"},
+			{Type: "stalled_task", Severity: "low", TaskRef: "building the docs"},
+		}
+	}
+	d.signals = stalled("medium")
+	d.Dismiss("working on the parser", now.Add(7*24*time.Hour))
+
+	if got := d.suppressDismissed(stalled("medium"), now); len(got) != 1 || got[0].TaskRef != "building the docs" {
+		t.Fatalf("dismissed signal should be suppressed, got %+v", got)
+	}
+	if got := d.suppressDismissed(stalled("medium"), now.Add(8*24*time.Hour)); len(got) != 2 {
+		t.Errorf("dismissal should expire, got %+v", got)
+	}
+
+	d.Dismiss("working on the parser", now.Add(7*24*time.Hour))
+	if got := d.suppressDismissed(stalled("high"), now); len(got) != 2 {
+		t.Errorf("escalated signal should reappear, got %+v", got)
+	}
+	if got := d.suppressDismissed(stalled("high"), now); len(got) != 2 {
+		t.Errorf("dismissal should be cleared after escalation, got %+v", got)
+	}
+}
+
+func TestDriftDetectorConcurrentScanAndDismiss(t *testing.T) {
+	entries := []struct {
+		content string
+		age     time.Duration
+	}{
+		{"the API is broken again", 3 * time.Hour},
+		{"still broken after the fix", 2 * time.Hour},
+		{"broken for the third time today", 1 * time.Hour},
+	}
+	d := NewDriftDetector(newMemStoreWithEntries(t, "u1", entries), "u1")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if _, err := d.Scan(context.Background()); err != nil {
+					t.Errorf("Scan: %v", err)
+					return
+				}
+				_ = d.FormatReport()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				d.Dismiss("broken", time.Now().Add(time.Hour))
+			}
+		}()
+	}
+	wg.Wait()
+}
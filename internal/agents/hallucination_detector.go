@@ -20,11 +20,15 @@ Zero cost, zero external API calls, works offline.
 */
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/Omkar0612/nexus-ai/internal/types"
 )
 
 // VerificationTag classifies an LLM response's reliability
@@ -44,6 +48,8 @@ type Claim struct {
 	Confidence float64 // 0.0 = very suspicious, 1.0 = likely correct
 	Flagged    bool
 	Reason     string
+
+	kbConflict string // contradiction found in the knowledge base, if any
 }
 
 // HallucinationReport is the full analysis of a response
@@ -64,6 +70,7 @@ type HallucinationReport struct {
 type HallucinationDetector struct {
 	retryThreshold  float64 // score below this triggers retry suggestion
 	memoryContext   []string // recent verified facts from memory
+	knowledge       func(query string) []string // passages from indexed documents
 	urlPattern      *regexp.Regexp
 	datePattern     *regexp.Regexp
 	numberPattern   *regexp.Regexp
@@ -81,7 +88,7 @@ func NewHallucinationDetector(retryThreshold float64) *HallucinationDetector {
 		retryThreshold: retryThreshold,
 		urlPattern:     regexp.MustCompile(`https?://[\w./?=#&%-]+`),
 		datePattern:    regexp.MustCompile(`\b(\d{4}|Jan(?:uary)?|Feb(?:ruary)?|Mar(?:ch)?|Apr(?:il)?|May|Jun(?:e)?|Jul(?:y)?|Aug(?:ust)?|Sep(?:tember)?|Oct(?:ober)?|Nov(?:ember)?|Dec(?:ember)?)\b`),
-		numberPattern:  regexp.MustCompile(`\b\d+(?:[,.]\d+)?(?:\s*(?:million|billion|thousand|%|USD|\$))?\b`),
+		numberPattern:  regexp.MustCompile(`\$?\b\d+(?:[,.]\d+)*(?:\s*(?:million|billion|thousand|bn\b|[MBK]\b|%|USD\b))?`),
 		hyperboleWords: []string{"always", "never", "every", "all", "none", "guaranteed", "certainly", "definitely", "100%", "impossible"},
 		hedgeWords:     []string{"might", "may", "could", "possibly", "perhaps", "approximately", "around", "estimated", "roughly", "I think", "I believe"},
 		certaintyWords: []string{"is", "are", "was", "were", "will", "the exact", "precisely"},
@@ -93,6 +100,14 @@ func (h *HallucinationDetector) LoadMemoryContext(facts []string) {
 	h.memoryContext = facts
 }
 
+// LoadKnowledgeRetriever sets a lookup into the user's indexed documents
+// (e.g. kb.KB.Search or semantic.Store.Search adapted to return passage text).
+// It is queried once per extracted claim with the sentence containing it, and
+// contradicting passages flag the claim and count as report contradictions.
+func (h *HallucinationDetector) LoadKnowledgeRetriever(fn func(query string) []string) {
+	h.knowledge = fn
+}
+
 // Analyse runs hallucination detection on an LLM response
 func (h *HallucinationDetector) Analyse(response string) *HallucinationReport {
 	report := &HallucinationReport{
@@ -113,8 +128,13 @@ func (h *HallucinationDetector) Analyse(response string) *HallucinationReport {
 		totalScore += claims[i].Confidence
 	}
 
-	// Cross-reference against memory
+	// Cross-reference against memory and the knowledge base
 	report.Contradictions = h.findContradictions(response)
+	for _, c := range claims {
+		if c.kbConflict != "" && !containsStr(report.Contradictions, c.kbConflict) {
+			report.Contradictions = append(report.Contradictions, c.kbConflict)
+		}
+	}
 
 	// Calculate overall score
 	if len(claims) > 0 {
@@ -137,6 +157,39 @@ func (h *HallucinationDetector) Analyse(response string) *HallucinationReport {
 	return report
 }
 
+// Completer is the subset of router.Router used by VerifyAndRetry.
+type Completer interface {
+	Complete(ctx context.Context, systemPrompt, userMsg string) (*types.AgentResult, error)
+}
+
+// VerifyAndRetry completes userMsg through r and analyses the response. While
+// the report says ShouldRetry and retries remain, it re-prompts with the
+// report's RetryPromptHint appended to the system prompt. It returns the
+// best-scoring response and its report; a failed retry keeps the best so far.
+func (h *HallucinationDetector) VerifyAndRetry(ctx context.Context, r Completer, systemPrompt, userMsg string, maxRetries int) (string, *HallucinationReport, error) {
+	var best string
+	var bestReport *HallucinationReport
+	system := systemPrompt
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		res, err := r.Complete(ctx, system, userMsg)
+		if err != nil {
+			if bestReport != nil {
+				return best, bestReport, nil
+			}
+			return "", nil, fmt.Errorf("hallucination: complete: %w", err)
+		}
+		report := h.Analyse(res.Content)
+		if bestReport == nil || report.OverallScore > bestReport.OverallScore {
+			best, bestReport = res.Content, report
+		}
+		if !report.ShouldRetry {
+			break
+		}
+		system = strings.TrimSpace(systemPrompt + "\n\n" + report.RetryPromptHint)
+	}
+	return best, bestReport, nil
+}
+
 func (h *HallucinationDetector) extractClaims(text string) []Claim {
 	var claims []Claim
 
@@ -150,6 +203,14 @@ func (h *HallucinationDetector) extractClaims(text string) []Claim {
 		claims = append(claims, Claim{Text: date, ClaimType: "date"})
 	}
 
+	// Extract quantities with a unit (amounts, percentages); bare numbers
+	// are too ambiguous to be worth a claim of their own
+	for _, num := range h.numberPattern.FindAllString(text, -1) {
+		if _, unit, _ := parseQuantity(num); unit != "" {
+			claims = append(claims, Claim{Text: num, ClaimType: "number"})
+		}
+	}
+
 	// Extract sentences with strong assertions
 	sentences := strings.Split(text, ".")
 	for _, s := range sentences {
@@ -205,22 +266,104 @@ func (h *HallucinationDetector) scoreClaim(c *Claim, fullText string) float64 {
 	}
 
 	// Cross-reference memory context
+	sentence := claimSentence(c.Text, fullText)
 	for _, fact := range h.memoryContext {
-		if contradicts(c.Text, fact) {
+		if why := h.contradiction(sentence, fact); why != "" {
 			score -= 0.3
 			c.Flagged = true
-			c.Reason = fmt.Sprintf("contradicts memory: '%s'", truncate(fact, 60))
+			c.Reason = fmt.Sprintf("contradicts memory: '%s' (%s)", truncate(fact, 60), why)
+		}
+	}
+
+	// Cross-reference the user's indexed documents
+	if h.knowledge != nil {
+		for _, passage := range h.knowledge(sentence) {
+			reason := ""
+			switch {
+			case c.ClaimType == "url" && conflictingURL(c.Text, passage, h.urlPattern) != "":
+				reason = fmt.Sprintf("knowledge base cites %s", conflictingURL(c.Text, passage, h.urlPattern))
+			default:
+				if why := h.contradiction(sentence, passage); why != "" {
+					reason = fmt.Sprintf("contradicts knowledge base: '%s' (%s)", truncate(passage, 60), why)
+				}
+			}
+			if reason != "" {
+				score -= 0.3
+				c.Flagged = true
+				c.Reason = reason
+				c.kbConflict = fmt.Sprintf("Knowledge base may contradict: \"%s\"", truncate(passage, 80))
+				break
+			}
 		}
 	}
 
 	return math.Max(0, math.Min(1, score))
 }
 
+// claimSentence returns the sentence of fullText containing claim, or the
+// claim itself if it is not found.
+func claimSentence(claim, fullText string) string {
+	i := strings.Index(fullText, claim)
+	if i < 0 {
+		return claim
+	}
+	start := strings.LastIndexAny(fullText[:i], ".!?\n") + 1
+	end := len(fullText)
+	for j := i + len(claim); j < len(fullText); j++ {
+		// A '.' followed by a non-space is part of a URL or number, not a full stop.
+		if c := fullText[j]; c == '!' || c == '?' || c == '\n' ||
+			(c == '.' && (j+1 == len(fullText) || fullText[j+1] == ' ')) {
+			end = j
+			break
+		}
+	}
+	return strings.TrimSpace(fullText[start:end])
+}
+
+// conflictingURL returns a URL in passage on the same host as claimURL but
+// with a different path, i.e. the documented endpoint the claim likely got wrong.
+func conflictingURL(claimURL, passage string, urlPattern *regexp.Regexp) string {
+	host, path := splitURL(claimURL)
+	found := false
+	var other string
+	for _, u := range urlPattern.FindAllString(passage, -1) {
+		h, p := splitURL(u)
+		if h != host {
+			continue
+		}
+		if p == path {
+			found = true
+		} else if other == "" {
+			other = u
+		}
+	}
+	if found {
+		return ""
+	}
+	return other
+}
+
+func splitURL(u string) (host, path string) {
+	u = strings.TrimRight(u, ".,;")
+	u = u[strings.Index(u, "://")+3:]
+	host, path, _ = strings.Cut(u, "/")
+	return strings.ToLower(host), strings.TrimSuffix(path, "/")
+}
+
+func containsStr(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 func (h *HallucinationDetector) findContradictions(response string) []string {
 	var contradictions []string
 	for _, fact := range h.memoryContext {
-		if contradicts(response, fact) {
-			contradictions = append(contradictions, fmt.Sprintf("Response may contradict: \"%s\"", truncate(fact, 80)))
+		if why := h.contradiction(response, fact); why != "" {
+			contradictions = append(contradictions, fmt.Sprintf("Response may contradict: \"%s\" (%s)", truncate(fact, 80), why))
 		}
 	}
 	return contradictions
@@ -268,8 +411,19 @@ func (r *HallucinationReport) FormatBadge() string {
 	return ""
 }
 
-func contradicts(a, b string) bool {
-	// Simple heuristic: look for shared named entities with conflicting numbers
+// contradiction explains why a and b appear to contradict each other, or
+// returns "" if they do not.
+func (h *HallucinationDetector) contradiction(a, b string) string {
+	if why := oppositeTerms(a, b); why != "" {
+		return why
+	}
+	return h.numericConflict(a, b)
+}
+
+// oppositeTerms flags statements with high word overlap that use opposite
+// signal words such as enabled/disabled.
+func oppositeTerms(a, b string) string {
+	// Simple heuristic: look for shared named entities with conflicting signals
 	wordsA := strings.Fields(strings.ToLower(a))
 	wordsB := strings.Fields(strings.ToLower(b))
 	shared := 0
@@ -284,7 +438,7 @@ func contradicts(a, b string) bool {
 	}
 	// Only flag if high word overlap but contains opposite signals
 	if shared < 3 {
-		return false
+		return ""
 	}
 	oppositePairs := [][2]string{
 		{"increase", "decrease"}, {"up", "down"}, {"higher", "lower"},
@@ -294,8 +448,140 @@ func contradicts(a, b string) bool {
 		hasA := strings.Contains(strings.ToLower(a), pair[0]) && strings.Contains(strings.ToLower(b), pair[1])
 		hasB := strings.Contains(strings.ToLower(a), pair[1]) && strings.Contains(strings.ToLower(b), pair[0])
 		if hasA || hasB {
-			return true
+			return fmt.Sprintf("'%s' vs '%s'", pair[0], pair[1])
 		}
 	}
-	return false
+	return ""
+}
+
+// minSharedContext is how many context words two sentences must share before
+// their numbers are assumed to describe the same quantity.
+const minSharedContext = 2
+
+var sentenceSplit = regexp.MustCompile(`[.!?]\s+|\n+`)
+
+// numericConflict flags a sentence of a that shares context with b but cites
+// a different number of the same unit, e.g. "revenue was $4M" vs "$2M". It
+// returns the conflicting values as "<a> vs <b>".
+func (h *HallucinationDetector) numericConflict(a, b string) string {
+	numsB := h.numberPattern.FindAllString(b, -1)
+	if len(numsB) == 0 {
+		return ""
+	}
+	for _, sentence := range sentenceSplit.Split(a, -1) {
+		numsA := h.numberPattern.FindAllString(sentence, -1)
+		if len(numsA) == 0 || sharedContextWords(sentence, b) < minSharedContext {
+			continue
+		}
+		if x, y := differingQuantity(numsA, numsB); x != "" {
+			return fmt.Sprintf("%s vs %s", x, y)
+		}
+	}
+	return ""
+}
+
+// differingQuantity finds a unit for which each side cites a value the other
+// does not, and returns one such value from each side.
+func differingQuantity(numsA, numsB []string) (string, string) {
+	onlyIn := func(nums, other []string, unit string) string {
+		for _, n := range nums {
+			v, u, ok := parseQuantity(n)
+			if !ok || u != unit {
+				continue
+			}
+			matched := false
+			for _, o := range other {
+				if ov, ou, ok := parseQuantity(o); ok && ou == unit && sameValue(v, ov) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return strings.TrimSpace(n)
+			}
+		}
+		return ""
+	}
+	seen := map[string]bool{}
+	for _, n := range numsA {
+		_, unit, ok := parseQuantity(n)
+		if !ok || seen[unit] {
+			continue
+		}
+		seen[unit] = true
+		if x := onlyIn(numsA, numsB, unit); x != "" {
+			if y := onlyIn(numsB, numsA, unit); y != "" {
+				return x, y
+			}
+		}
+	}
+	return "", ""
+}
+
+// parseQuantity normalises a numberPattern match to a value and unit: "$"
+// for currency, "%" for percentages, "" for plain counts. Magnitude words
+// and suffixes (K, M, million, bn, ...) are applied to the value.
+func parseQuantity(s string) (value float64, unit string, ok bool) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "$") {
+		unit, s = "$", s[1:]
+	}
+	i := 0
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == ',' || s[i] == '.') {
+		i++
+	}
+	digits, suffix := strings.ReplaceAll(s[:i], ",", ""), strings.TrimSpace(s[i:])
+	v, err := strconv.ParseFloat(digits, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	switch suffix {
+	case "K", "thousand":
+		v *= 1e3
+	case "M", "million":
+		v *= 1e6
+	case "B", "bn", "billion":
+		v *= 1e9
+	case "%":
+		unit = "%"
+	case "USD":
+		unit = "$"
+	}
+	return v, unit, true
+}
+
+func sameValue(a, b float64) bool {
+	return math.Abs(a-b) <= 0.005*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// contextStopwords are too common to establish that two sentences describe
+// the same thing.
+var contextStopwords = map[string]bool{
+	"the": true, "was": true, "were": true, "and": true, "for": true, "with": true,
+	"that": true, "this": true, "are": true, "has": true, "had": true, "have": true,
+	"but": true, "not": true, "its": true, "from": true, "our": true, "your": true,
+}
+
+// sharedContextWords counts distinct non-numeric words (3+ letters, not
+// stopwords) that appear in both a and b.
+func sharedContextWords(a, b string) int {
+	words := func(s string) map[string]bool {
+		set := map[string]bool{}
+		for _, w := range strings.Fields(strings.ToLower(s)) {
+			w = strings.Trim(w, ".,;:!?()[]\"'")
+			if len(w) < 3 || contextStopwords[w] || strings.ContainsAny(w, "0123456789$%") {
+				continue
+			}
+			set[w] = true
+		}
+		return set
+	}
+	wa, wb := words(a), words(b)
+	n := 0
+	for w := range wa {
+		if wb[w] {
+			n++
+		}
+	}
+	return n
 }
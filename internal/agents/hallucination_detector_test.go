@@ -1,7 +1,12 @@
 package agents
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/Omkar0612/nexus-ai/internal/types"
 )
 
 func TestHallucinationDetectorVerified(t *testing.T) {
@@ -67,3 +72,126 @@ func TestHallucinationShouldRetry(t *testing.T) {
 		t.Error("retry hint too short")
 	}
 }
+
+func TestHallucinationDetectorKnowledgeBase(t *testing.T) {
+	d := NewHallucinationDetector(0.6)
+	var queries []string
+	d.LoadKnowledgeRetriever(func(query string) []string {
+		queries = append(queries, query)
+		return []string{"List users with GET https://api.example.com/v2/users (v1 was removed)."}
+	})
+	report := d.Analyse("To list users, call https://api.example.com/v1/users with your token.")
+	if len(queries) == 0 || !strings.Contains(queries[0], "To list users") {
+		t.Fatalf("retriever queries = %q, want the claim's sentence", queries)
+	}
+	if report.Tag != TagContradicted {
+		t.Fatalf("tag = %s, want CONTRADICTED", report.Tag)
+	}
+	var flagged bool
+	for _, c := range report.Claims {
+		if c.ClaimType == "url" && c.Flagged && strings.Contains(c.Reason, "/v2/users") {
+			flagged = true
+		}
+	}
+	if !flagged {
+		t.Errorf("url claim not flagged with the documented endpoint: %+v", report.Claims)
+	}
+
+	// A passage citing the same endpoint is not a contradiction.
+	d.LoadKnowledgeRetriever(func(string) []string {
+		return []string{"Users live at https://api.example.com/v1/users and https://api.example.com/v1/teams."}
+	})
+	if report := d.Analyse("To list users, call https://api.example.com/v1/users with your token."); report.Tag == TagContradicted {
+		t.Errorf("matching endpoint reported as contradiction: %+v", report.Contradictions)
+	}
+}
+
+func TestHallucinationDetectorNumericContradiction(t *testing.T) {
+	cases := []struct {
+		name, memory, response, a, b string
+	}{
+		{"dollars", "Q3 revenue for the Dubai office was $2M.", "The Dubai office revenue in Q3 was $4M.", "$4M", "$2M"},
+		{"percent", "Customer churn this quarter dropped to 3% after the redesign.", "After the redesign, customer churn dropped to 7%.", "7%", "3%"},
+		{"dates", "The beta launch is scheduled for March 5, 2026.", "The beta launch is scheduled for March 12, 2026.", "12", "5"},
+	}
+	for _, tc := range cases {
+		d := NewHallucinationDetector(0.6)
+		d.LoadMemoryContext([]string{tc.memory})
+		report := d.Analyse(tc.response)
+		if report.Tag != TagContradicted {
+			t.Errorf("%s: tag = %s, want CONTRADICTED", tc.name, report.Tag)
+			continue
+		}
+		want := tc.a + " vs " + tc.b
+		if !strings.Contains(report.Contradictions[0], want) {
+			t.Errorf("%s: contradiction %q does not cite %q", tc.name, report.Contradictions[0], want)
+		}
+		var flagged bool
+		for _, c := range report.Claims {
+			flagged = flagged || (c.Flagged && strings.Contains(c.Reason, want))
+		}
+		if !flagged {
+			t.Errorf("%s: no claim flagged with %q: %+v", tc.name, want, report.Claims)
+		}
+	}
+}
+
+func TestHallucinationDetectorNumericAgreement(t *testing.T) {
+	d := NewHallucinationDetector(0.6)
+	d.LoadMemoryContext([]string{"Q3 revenue for the Dubai office was $2 million."})
+	if r := d.Analyse("The Dubai office revenue in Q3 was $2M."); r.Tag == TagContradicted {
+		t.Errorf("equal amounts flagged: %v", r.Contradictions)
+	}
+	// Different numbers about unrelated things are not a contradiction.
+	if r := d.Analyse("The team shipped 4 releases and fixed 30% of open bugs."); r.Tag == TagContradicted {
+		t.Errorf("unrelated numbers flagged: %v", r.Contradictions)
+	}
+}
+
+type scriptedCompleter struct {
+	replies []string
+	systems []string
+}
+
+func (s *scriptedCompleter) Complete(ctx context.Context, systemPrompt, userMsg string) (*types.AgentResult, error) {
+	s.systems = append(s.systems, systemPrompt)
+	if len(s.replies) == 0 {
+		return nil, fmt.Errorf("no more replies")
+	}
+	reply := s.replies[0]
+	s.replies = s.replies[1:]
+	return &types.AgentResult{Content: reply}, nil
+}
+
+func TestVerifyAndRetry(t *testing.T) {
+	d := NewHallucinationDetector(0.6)
+	d.LoadMemoryContext([]string{"Q3 revenue for the Dubai office was $2M."})
+	c := &scriptedCompleter{replies: []string{
+		"The Dubai office revenue in Q3 was definitely $4M, guaranteed.",
+		"I believe the Dubai office revenue in Q3 was approximately $2M.",
+		"unused",
+	}}
+	got, report, err := d.VerifyAndRetry(context.Background(), c, "You are helpful.", "What was Q3 revenue?", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "approximately $2M") || report.ShouldRetry {
+		t.Fatalf("got %q (score %.2f)", got, report.OverallScore)
+	}
+	if len(c.systems) != 2 || !strings.Contains(c.systems[1], "Only assert facts") {
+		t.Errorf("retry prompts = %q", c.systems)
+	}
+
+	// When every retry stays bad, the best-scoring response is returned.
+	c = &scriptedCompleter{replies: []string{
+		"It is definitely, always, guaranteed, 100% impossible to fail.",
+		"It always works.",
+	}}
+	got, report, err = d.VerifyAndRetry(context.Background(), c, "sys", "q", 1)
+	if err != nil || len(c.systems) != 2 {
+		t.Fatalf("err=%v calls=%d", err, len(c.systems))
+	}
+	if got != "It always works." {
+		t.Errorf("best = %q (score %.2f)", got, report.OverallScore)
+	}
+}
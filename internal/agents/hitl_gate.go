@@ -18,6 +18,9 @@ NEXUS HITLGate:
      - Timeout:  action is safely cancelled (fail-closed)
   5. Emergency override: block ALL actions until human unlocks
   6. Approval history stored in audit log
+
+Steps 2-4 are the DefaultPolicy; SetPolicy can tighten any risk level or
+block whole action categories.
 */
 
 import (
@@ -26,7 +29,11 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/Omkar0612/nexus-ai/internal/audit"
+	"github.com/Omkar0612/nexus-ai/internal/events"
 	"github.com/rs/zerolog/log"
 )
 
@@ -54,6 +61,83 @@ type ApprovalRequest struct {
 	Meta        map[string]string
 }
 
+// PolicyAction is what the gate does with an action.
+type PolicyAction string
+
+const (
+	PolicyAuto    PolicyAction = "auto"    // execute immediately
+	PolicyAudit   PolicyAction = "audit"   // execute and record it
+	PolicyApprove PolicyAction = "approve" // wait for human approval
+	PolicyBlock   PolicyAction = "block"   // refuse outright
+)
+
+// Policy maps a risk level ("low", "medium", "high") or an action category
+// to a PolicyAction. Any other key is a category: it matches actions that
+// start with it as a whole word, case-insensitively, so "delete" covers
+// "delete database" but "rm" does not cover "rmdir". The longest matching
+// category wins among categories, and an action gets the stricter of its
+// category and risk rules, so a category can tighten but never loosen the
+// treatment of a risk level.
+type Policy map[string]PolicyAction
+
+// DefaultPolicy auto-runs low risk, audits medium and asks a human for high.
+func DefaultPolicy() Policy {
+	return Policy{"low": PolicyAuto, "medium": PolicyAudit, "high": PolicyApprove}
+}
+
+// policyStrictness orders PolicyActions from most to least permissive.
+// Unknown actions rank above block so they still reach Execute's error.
+var policyStrictness = map[PolicyAction]int{
+	PolicyAuto: 0, PolicyAudit: 1, PolicyApprove: 2, PolicyBlock: 3,
+}
+
+func strictness(a PolicyAction) int {
+	if n, ok := policyStrictness[a]; ok {
+		return n
+	}
+	return len(policyStrictness)
+}
+
+// decide returns the action for an action string at the given (lower-case)
+// risk, and false if no rule applies.
+func (p Policy) decide(action, risk string) (PolicyAction, bool) {
+	lower := strings.ToLower(action)
+	best := ""
+	for key := range p {
+		k := strings.ToLower(key)
+		if k == "low" || k == "medium" || k == "high" {
+			continue
+		}
+		if categoryMatch(lower, k) && len(k) > len(best) {
+			best = key
+		}
+	}
+	byRisk, riskOK := p[risk]
+	if best == "" {
+		return byRisk, riskOK
+	}
+	byCategory := p[best]
+	if riskOK && strictness(byRisk) > strictness(byCategory) {
+		return byRisk, true
+	}
+	return byCategory, true
+}
+
+// categoryMatch reports whether action starts with the whole word(s) of
+// category: the prefix must end the action or be followed by a character
+// that is not a letter or digit.
+func categoryMatch(action, category string) bool {
+	rest, ok := strings.CutPrefix(action, category)
+	if !ok || category == "" {
+		return false
+	}
+	if rest == "" {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(rest)
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
 // ActionFunc is a function that performs a NEXUS action
 type ActionFunc func(ctx context.Context) error
 
@@ -66,6 +150,11 @@ type HITLGate struct {
 	timeout     time.Duration
 	locked      bool // emergency lock — blocks all non-low-risk actions
 	onDecision  func(req *ApprovalRequest)
+	bus         *events.Bus
+	feedback    *RiskFeedback
+	policy      Policy
+	audit       *audit.Log
+	auditUser   string
 }
 
 // NewHITLGate creates a new HITL gate
@@ -77,20 +166,57 @@ func NewHITLGate(approvalTimeout time.Duration, notifyFn func(req *ApprovalReque
 		pending: make(map[string]*ApprovalRequest),
 		timeout: approvalTimeout,
 		notify:  notifyFn,
+		policy:  DefaultPolicy(),
 	}
 }
 
+// SetAuditLog records every approval, rejection and timeout, and every
+// action run under PolicyAudit or refused by PolicyBlock, in l as userID.
+func (g *HITLGate) SetAuditLog(l *audit.Log, userID string) {
+	g.audit = l
+	g.auditUser = userID
+}
+
+// SetPolicy replaces the gate's policy. Risk levels missing from p keep
+// their DefaultPolicy action, so a policy of {"medium": "approve"} only
+// tightens medium risk.
+func (g *HITLGate) SetPolicy(p Policy) {
+	merged := DefaultPolicy()
+	for k, v := range p {
+		merged[strings.ToLower(k)] = v
+	}
+	g.mu.Lock()
+	g.policy = merged
+	g.mu.Unlock()
+}
+
 // SetDecisionCallback sets a function called when any approval decision is made
 func (g *HITLGate) SetDecisionCallback(fn func(req *ApprovalRequest)) {
 	g.onDecision = fn
 }
 
+// SetEventBus publishes approval requests (events.KindHITLRequested) and
+// decisions (events.KindHITLDecision) to bus.
+func (g *HITLGate) SetEventBus(bus *events.Bus) {
+	g.bus = bus
+}
+
+// SetRiskFeedback lets consistent approvals of allowlisted action patterns
+// lower their effective risk (see RiskFeedback).
+func (g *HITLGate) SetRiskFeedback(f *RiskFeedback) {
+	g.feedback = f
+}
+
 // Execute runs an action through the HITL gate
 func (g *HITLGate) Execute(ctx context.Context, action, rationale string, risk string, fn ActionFunc) error {
 	g.mu.RLock()
 	locked := g.locked
 	g.mu.RUnlock()
 
+	if effective := g.feedback.EffectiveRisk(action, risk); effective != risk {
+		log.Info().Str("action", action).Str("risk", risk).Str("effective", effective).Msg("HITL: risk lowered by approval history")
+		risk = effective
+	}
 	riskLower := strings.ToLower(risk)
 
 	// Emergency lock blocks everything except low risk
@@ -98,20 +224,45 @@ func (g *HITLGate) Execute(ctx context.Context, action, rationale string, risk s
 		return fmt.Errorf("HITL gate is in emergency lock mode. Unlock with: nexus hitl unlock")
 	}
 
-	switch riskLower {
-	case "low":
-		log.Debug().Str("action", action).Msg("HITL: auto-executing low risk action")
-		return fn(ctx)
+	g.mu.RLock()
+	decision, ok := g.policy.decide(action, riskLower)
+	g.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown risk level: %s", risk)
+	}
 
-	case "medium":
-		log.Info().Str("action", action).Msg("HITL: executing medium risk action (auto-approved with audit)")
+	switch decision {
+	case PolicyAuto:
+		log.Debug().Str("action", action).Str("risk", riskLower).Msg("HITL: auto-executing action")
 		return fn(ctx)
 
-	case "high":
+	case PolicyAudit:
+		log.Info().Str("action", action).Str("risk", riskLower).Msg("HITL: executing action (auto-approved with audit)")
+		start := time.Now()
+		err := fn(ctx)
+		outcome := "executed"
+		if err != nil {
+			outcome = "failed: " + err.Error()
+		}
+		g.recordAudit(audit.AuditEntry{
+			Action: action, Rationale: rationale, Risk: audit.RiskLevel(riskLower),
+			ApprovedBy: "policy", Outcome: outcome, DurationMs: time.Since(start).Milliseconds(),
+		})
+		return err
+
+	case PolicyApprove:
 		return g.requestApproval(ctx, action, rationale, risk, fn)
 
+	case PolicyBlock:
+		log.Warn().Str("action", action).Str("risk", riskLower).Msg("HITL: action blocked by policy")
+		g.recordAudit(audit.AuditEntry{
+			Action: action, Rationale: rationale, Risk: audit.RiskLevel(riskLower),
+			ApprovedBy: "policy", Outcome: "blocked",
+		})
+		return fmt.Errorf("action %q blocked by HITL policy", action)
+
 	default:
-		return fmt.Errorf("unknown risk level: %s", risk)
+		return fmt.Errorf("unknown HITL policy action %q for %s", decision, action)
 	}
 }
 
@@ -127,15 +278,24 @@ func (g *HITLGate) requestApproval(ctx context.Context, action, rationale, risk
 	}
 	g.mu.Lock()
 	g.pending[req.ID] = req
+	snapshot := *req // decide may update req as soon as it is pending
 	g.mu.Unlock()
 
-	log.Warn().Str("id", req.ID).Str("action", action).Msg("HITL: high-risk action awaiting human approval")
+	log.Warn().Str("id", req.ID).Str("action", action).Str("risk", risk).Msg("HITL: action awaiting human approval")
 
 	if g.notify != nil {
-		if err := g.notify(req); err != nil {
+		if err := g.notify(&snapshot); err != nil {
 			log.Error().Err(err).Msg("HITL: failed to send approval notification")
 		}
 	}
+	g.bus.Publish(events.Event{
+		Kind:     events.KindHITLRequested,
+		Source:   "hitl_gate",
+		Severity: "high",
+		Message:  FormatApprovalMessage(&snapshot),
+		Payload:  snapshot,
+		At:       snapshot.RequestedAt,
+	})
 
 	// Wait for decision or timeout
 	ticker := time.NewTicker(500 * time.Millisecond)
@@ -152,14 +312,14 @@ func (g *HITLGate) requestApproval(ctx context.Context, action, rationale, risk
 			return fmt.Errorf("HITL approval timed out after %s — action cancelled for safety", g.timeout)
 		case <-ticker.C:
 			g.mu.RLock()
-			status := req.Status
+			status, by := req.Status, req.DecidedBy
 			g.mu.RUnlock()
 			switch status {
 			case ApprovalApproved:
-				log.Info().Str("id", req.ID).Str("by", req.DecidedBy).Msg("HITL: action approved")
+				log.Info().Str("id", req.ID).Str("by", by).Msg("HITL: action approved")
 				return fn(ctx)
 			case ApprovalRejected:
-				return fmt.Errorf("action rejected by %s", req.DecidedBy)
+				return fmt.Errorf("action rejected by %s", by)
 			}
 		}
 	}
@@ -196,15 +356,53 @@ func (g *HITLGate) recordDecision(req *ApprovalRequest, status ApprovalStatus, b
 	if by != "" {
 		req.DecidedBy = by
 	}
+	if req.DecisionAt.IsZero() {
+		req.DecisionAt = time.Now()
+	}
 	delete(g.pending, req.ID)
 	g.history = append(g.history, *req)
 	if len(g.history) > 100 {
 		g.history = g.history[len(g.history)-100:]
 	}
+	snapshot := *req
 	g.mu.Unlock()
 	if g.onDecision != nil {
 		g.onDecision(req)
 	}
+	g.feedback.Observe(snapshot)
+	meta := map[string]string{"request_id": snapshot.ID}
+	for k, v := range snapshot.Meta {
+		meta[k] = v
+	}
+	g.recordAudit(audit.AuditEntry{
+		Action:     snapshot.Action,
+		Rationale:  snapshot.Rationale,
+		Risk:       audit.RiskLevel(strings.ToLower(snapshot.Risk)),
+		ApprovedBy: snapshot.DecidedBy,
+		Outcome:    string(snapshot.Status),
+		DurationMs: snapshot.DecisionAt.Sub(snapshot.RequestedAt).Milliseconds(),
+		Meta:       meta,
+		CreatedAt:  snapshot.DecisionAt,
+	})
+	g.bus.Publish(events.Event{
+		Kind:     events.KindHITLDecision,
+		Source:   "hitl_gate",
+		Severity: snapshot.Risk,
+		Message:  fmt.Sprintf("HITL %s: %s (by %s)", snapshot.Status, snapshot.Action, snapshot.DecidedBy),
+		Payload:  snapshot,
+	})
+}
+
+// recordAudit writes e to the audit log, if one is set.
+func (g *HITLGate) recordAudit(e audit.AuditEntry) {
+	if g.audit == nil {
+		return
+	}
+	e.UserID = g.auditUser
+	e.Agent = "hitl_gate"
+	if err := g.audit.Record(e); err != nil {
+		log.Error().Err(err).Str("action", e.Action).Msg("HITL: failed to write audit entry")
+	}
 }
 
 // EmergencyLock blocks all non-low-risk actions immediately
@@ -238,9 +436,65 @@ func FormatApprovalMessage(req *ApprovalRequest) string {
 			"**Why:** %s\n"+
 			"**Risk:** %s\n"+
 			"**Expires:** %s\n\n"+
-			"Reply:\n✅ `nexus hitl approve %s`\n❌ `nexus hitl reject %s`",
+			"Tap a button below, or reply:\n✅ `nexus hitl approve %s`\n❌ `nexus hitl reject %s`",
 		req.Action, req.Rationale, req.Risk,
 		req.ExpiresAt.Format("15:04:05"),
 		req.ID, req.ID,
 	)
 }
+
+// Callback data prefixes for the approval keyboard. Telegram limits
+// callback_data to 64 bytes, which "hitl:approve:" plus a request ID fits.
+const (
+	callbackApprove = "hitl:approve:"
+	callbackReject  = "hitl:reject:"
+)
+
+// InlineKeyboardButton is a Telegram inline keyboard button.
+type InlineKeyboardButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
+}
+
+// InlineKeyboardMarkup is the reply_markup for a Telegram message with
+// inline buttons.
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// ApprovalKeyboard returns one-tap approve/reject buttons for req, to be
+// sent as reply_markup alongside FormatApprovalMessage. Taps arrive as
+// callback queries; pass their data to HandleCallback.
+func ApprovalKeyboard(req *ApprovalRequest) InlineKeyboardMarkup {
+	return InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{{
+		{Text: "✅ Approve", CallbackData: callbackApprove + req.ID},
+		{Text: "❌ Reject", CallbackData: callbackReject + req.ID},
+	}}}
+}
+
+// IsApprovalCallback reports whether callback data came from an
+// ApprovalKeyboard button.
+func IsApprovalCallback(data string) bool {
+	return strings.HasPrefix(data, callbackApprove) || strings.HasPrefix(data, callbackReject)
+}
+
+// HandleCallback applies a tap on an ApprovalKeyboard button by decidedBy
+// and returns a short confirmation suitable for answerCallbackQuery.
+func (g *HITLGate) HandleCallback(data, decidedBy string) (string, error) {
+	switch {
+	case strings.HasPrefix(data, callbackApprove):
+		id := strings.TrimPrefix(data, callbackApprove)
+		if err := g.Approve(id, decidedBy); err != nil {
+			return "", err
+		}
+		return "✅ Approved " + id, nil
+	case strings.HasPrefix(data, callbackReject):
+		id := strings.TrimPrefix(data, callbackReject)
+		if err := g.Reject(id, decidedBy); err != nil {
+			return "", err
+		}
+		return "❌ Rejected " + id, nil
+	default:
+		return "", fmt.Errorf("not a HITL approval callback: %q", data)
+	}
+}
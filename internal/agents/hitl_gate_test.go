@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 	"time"
+
+	"github.com/Omkar0612/nexus-ai/internal/audit"
 )
 
 func TestHITLLowRiskAutoExecute(t *testing.T) {
@@ -99,3 +101,118 @@ func TestHITLEmergencyLock(t *testing.T) {
 		t.Errorf("expected success after unlock, got: %v", err)
 	}
 }
+
+func TestHITLApprovalKeyboardCallback(t *testing.T) {
+	gate := NewHITLGate(5*time.Second, nil)
+	sent := make(chan *ApprovalRequest, 1)
+	gate.notify = func(req *ApprovalRequest) error { sent <- req; return nil }
+	done := make(chan error, 1)
+	go func() {
+		done <- gate.Execute(context.Background(), "drop table users", "migration", "high",
+			func(ctx context.Context) error { return nil },
+		)
+	}()
+
+	req := <-sent
+	kb := ApprovalKeyboard(req)
+	if len(kb.InlineKeyboard) != 1 || len(kb.InlineKeyboard[0]) != 2 {
+		t.Fatalf("unexpected keyboard layout: %+v", kb)
+	}
+	reject := kb.InlineKeyboard[0][1].CallbackData
+	if !IsApprovalCallback(reject) || len(reject) > 64 {
+		t.Fatalf("bad callback data %q", reject)
+	}
+	reply, err := gate.HandleCallback(reject, "omkar")
+	if err != nil {
+		t.Fatalf("HandleCallback: %v", err)
+	}
+	if reply == "" {
+		t.Error("expected a confirmation reply")
+	}
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected rejection error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for rejected action")
+	}
+	if _, err := gate.HandleCallback(reject, "omkar"); err == nil {
+		t.Error("second tap on a decided request should fail")
+	}
+	if _, err := gate.HandleCallback("digest:open", "omkar"); err == nil {
+		t.Error("non-HITL callback data should be rejected")
+	}
+}
+
+func TestHITLPolicy(t *testing.T) {
+	gate := NewHITLGate(100*time.Millisecond, nil)
+	gate.SetPolicy(Policy{"medium": PolicyApprove, "delete": PolicyBlock, "delete temp": PolicyAuto, "rm": PolicyBlock})
+	noop := func(ctx context.Context) error { return nil }
+
+	if err := gate.Execute(context.Background(), "read file", "", "low", noop); err != nil {
+		t.Errorf("low risk should keep the default auto policy: %v", err)
+	}
+	if err := gate.Execute(context.Background(), "update config", "", "medium", noop); err == nil {
+		t.Error("medium risk should now wait for approval and time out")
+	}
+	executed := false
+	err := gate.Execute(context.Background(), "Delete old backups", "", "low", func(ctx context.Context) error {
+		executed = true
+		return nil
+	})
+	if err == nil || executed {
+		t.Error("delete category should be blocked regardless of risk")
+	}
+	if err := gate.Execute(context.Background(), "delete temp files", "", "low", noop); err != nil {
+		t.Errorf("longest category match should win: %v", err)
+	}
+	if err := gate.Execute(context.Background(), "delete temp files", "", "high", noop); err == nil {
+		t.Error("a looser category rule must not override high risk approval")
+	}
+	if err := gate.Execute(context.Background(), "rmdir build", "", "low", noop); err != nil {
+		t.Errorf("category \"rm\" should not match \"rmdir\": %v", err)
+	}
+	if err := gate.Execute(context.Background(), "rm -rf build", "", "low", noop); err == nil {
+		t.Error("category \"rm\" should match \"rm -rf\"")
+	}
+	if err := gate.Execute(context.Background(), "x", "", "critical", noop); err == nil {
+		t.Error("unknown risk level with no rule should error")
+	}
+}
+
+func TestHITLAuditLog(t *testing.T) {
+	auditLog, err := audit.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("audit.Open: %v", err)
+	}
+	defer auditLog.Close()
+	gate := NewHITLGate(100*time.Millisecond, nil)
+	gate.SetAuditLog(auditLog, "u1")
+	noop := func(ctx context.Context) error { return nil }
+
+	_ = gate.Execute(context.Background(), "update config", "tuning", "medium", noop)
+	_ = gate.Execute(context.Background(), "wipe disk", "cleanup", "high", noop)
+
+	entries, err := auditLog.Query(audit.AuditQuery{UserID: "u1", Agent: "hitl_gate"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	outcomes := map[string]audit.AuditEntry{}
+	for _, e := range entries {
+		outcomes[e.Outcome] = e
+	}
+	if e, ok := outcomes["executed"]; !ok || e.Action != "update config" || e.ApprovedBy != "policy" {
+		t.Errorf("missing audit-tier entry: %+v", entries)
+	}
+	e, ok := outcomes[string(ApprovalTimeout)]
+	if !ok {
+		t.Fatalf("missing timeout entry: %+v", entries)
+	}
+	if e.Action != "wipe disk" || e.Rationale != "cleanup" || e.Risk != audit.RiskHigh || e.ApprovedBy != "auto" {
+		t.Errorf("timeout entry = %+v", e)
+	}
+	if e.Meta["request_id"] == "" {
+		t.Error("timeout entry should carry the request ID")
+	}
+}
@@ -24,6 +24,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/Omkar0612/nexus-ai/internal/events"
 )
 
 // LoopEvent records a detected loop
@@ -56,6 +58,7 @@ type LoopDetector struct {
 	windowSize   int                    // only look at last N calls
 	tokenPerCall int                    // estimated tokens per call
 	onLoop       func(LoopEvent)
+	bus          *events.Bus
 }
 
 // NewLoopDetector creates a loop detector with sensible defaults
@@ -79,6 +82,11 @@ func (d *LoopDetector) SetLoopCallback(fn func(LoopEvent)) {
 	d.onLoop = fn
 }
 
+// SetEventBus publishes every detected loop to bus as events.KindLoopDetected.
+func (d *LoopDetector) SetEventBus(bus *events.Bus) {
+	d.bus = bus
+}
+
 // Record registers a tool call and returns (isLoop, event)
 func (d *LoopDetector) Record(tool, input string) (bool, *LoopEvent) {
 	d.mu.Lock()
@@ -130,6 +138,14 @@ func (d *LoopDetector) Record(tool, input string) (bool, *LoopEvent) {
 		if d.onLoop != nil {
 			d.onLoop(event)
 		}
+		d.bus.Publish(events.Event{
+			Kind:     events.KindLoopDetected,
+			Source:   "loop_detector",
+			Severity: "high",
+			Message:  event.Format(),
+			Payload:  event,
+			At:       now,
+		})
 		return true, &event
 	}
 	return false, nil
@@ -2,6 +2,8 @@ package agents
 
 import (
 	"testing"
+
+	"github.com/Omkar0612/nexus-ai/internal/events"
 )
 
 func TestLoopDetectorNoLoop(t *testing.T) {
@@ -64,3 +66,22 @@ func TestLoopDetectorReset(t *testing.T) {
 		t.Error("should not loop after reset")
 	}
 }
+
+func TestLoopDetectorPublishesEvent(t *testing.T) {
+	bus := events.NewBus(4)
+	ch := bus.Subscribe(events.KindLoopDetected)
+	d := NewLoopDetector(2, 20)
+	d.SetEventBus(bus)
+
+	d.Record("web-search", "same query")
+	d.Record("web-search", "same query")
+
+	select {
+	case e := <-ch:
+		if _, ok := e.Payload.(LoopEvent); !ok {
+			t.Errorf("expected LoopEvent payload, got %T", e.Payload)
+		}
+	default:
+		t.Fatal("expected loop event on the bus")
+	}
+}
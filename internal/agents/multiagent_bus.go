@@ -12,17 +12,20 @@ NEXUS MultiAgentBus:
   2. Central message bus — agents pass structured messages, not raw strings
   3. Role enforcement — agents can't do work outside their role
   4. Task router — auto-routes tasks to the best-fit agent
-  5. Result aggregator — merges outputs from parallel agents
+  5. Result aggregator — Pipeline chains agents, Aggregate merges parallel outputs
   6. Timeout protection — stalled sub-agents are cancelled automatically
   7. Loop detection integration — bus detects circular message chains
+  8. Optional SQLite history — every message queryable after the fact (audit trail)
 */
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 
 	"github.com/rs/zerolog/log"
 )
@@ -61,7 +64,8 @@ type BusMessage struct {
 	ReplyTo   string // ID of message this is replying to
 }
 
-// AgentHandler is a function that processes a task message
+// AgentHandler is a function that processes a task message. A handler may
+// hand part of its work to another role with Delegate(ctx, ...).
 type AgentHandler func(ctx context.Context, msg BusMessage) (BusMessage, error)
 
 // SubAgent is a registered agent on the bus
@@ -89,6 +93,7 @@ type MultiAgentBus struct {
 	agents   map[AgentRole]*SubAgent
 	mu       sync.RWMutex
 	history  []BusMessage
+	db       *sql.DB // optional history sink, see EnableHistoryDB
 	histMu   sync.Mutex
 	stats    BusStats
 	timeout  time.Duration
@@ -154,6 +159,7 @@ func (b *MultiAgentBus) Send(ctx context.Context, msg BusMessage) (BusMessage, e
 	agent.TaskCount++
 	agent.mu.Unlock()
 
+	ctx = b.withDelegation(ctx, &msg)
 	b.record(msg)
 
 	ctx, cancel := context.WithTimeout(ctx, b.timeout)
@@ -175,9 +181,20 @@ func (b *MultiAgentBus) Send(ctx context.Context, msg BusMessage) (BusMessage, e
 	b.histMu.Unlock()
 
 	if err != nil {
+		failed := BusMessage{
+			ID: fmt.Sprintf("msg-%d", time.Now().UnixNano()), Type: MsgError,
+			From: msg.To, To: msg.From, Payload: err.Error(), ReplyTo: msg.ID, CreatedAt: time.Now(),
+		}
+		b.record(failed)
 		return BusMessage{Type: MsgError, From: msg.To, Payload: err.Error()}, err
 	}
+	if result.ID == "" {
+		result.ID = fmt.Sprintf("msg-%d", time.Now().UnixNano())
+	}
 	result.From = msg.To
+	if result.To == "" {
+		result.To = msg.From
+	}
 	result.ReplyTo = msg.ID
 	result.CreatedAt = time.Now()
 	b.record(result)
@@ -216,14 +233,61 @@ func (b *MultiAgentBus) Broadcast(ctx context.Context, payload string) map[Agent
 	return results
 }
 
-// Route auto-routes a task to the best-fit agent based on keywords
+// Route auto-routes a task to the best-fit agent: the registered agent whose
+// Capabilities best match the task, or by keyword inference when none match.
 func (b *MultiAgentBus) Route(ctx context.Context, task string) (BusMessage, error) {
-	role := b.inferRole(task)
+	role, ok := b.matchCapabilities(task)
+	if !ok {
+		role = b.inferRole(task)
+	}
 	return b.Send(ctx, BusMessage{
 		Type: MsgTask, From: RoleOrchestrator, To: role, Payload: task,
 	})
 }
 
+// minCapabilityMatches is how many of an agent's declared capabilities a
+// task must mention before capability routing overrides keyword inference.
+const minCapabilityMatches = 1
+
+// matchCapabilities scores every registered agent by how many of its
+// Capabilities appear in the task as whole words (or phrases), ignoring a
+// trailing plural "s". Ties go to the agent with fewer capabilities — the
+// more specialised one — then to the role name, so routing is stable.
+func (b *MultiAgentBus) matchCapabilities(task string) (AgentRole, bool) {
+	words := strings.FieldsFunc(strings.ToLower(task), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	text := " " + strings.Join(words, " ") + " "
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var (
+		best      AgentRole
+		bestScore int
+		bestCaps  int
+	)
+	for role, agent := range b.agents {
+		score := 0
+		for _, c := range agent.Capabilities {
+			phrase := strings.Join(strings.Fields(strings.ToLower(c)), " ")
+			if phrase == "" {
+				continue
+			}
+			if strings.Contains(text, " "+phrase+" ") || strings.Contains(text, " "+phrase+"s ") {
+				score++
+			}
+		}
+		if score < minCapabilityMatches {
+			continue
+		}
+		n := len(agent.Capabilities)
+		if score > bestScore || score == bestScore && (n < bestCaps || n == bestCaps && role < best) {
+			best, bestScore, bestCaps = role, score, n
+		}
+	}
+	return best, bestScore > 0
+}
+
 // inferRole determines the best agent role for a task using keyword matching.
 // ORDERING IS CRITICAL: check multi-word / more-specific phrases first so they
 // are not swallowed by shorter single-word matches in later cases.
@@ -287,11 +351,15 @@ func (b *MultiAgentBus) ListAgents() string {
 
 func (b *MultiAgentBus) record(msg BusMessage) {
 	b.histMu.Lock()
-	defer b.histMu.Unlock()
 	b.history = append(b.history, msg)
 	if len(b.history) > 200 {
 		b.history = b.history[len(b.history)-200:]
 	}
+	db := b.db
+	b.histMu.Unlock()
+	if db != nil {
+		b.persist(db, msg)
+	}
 }
 
 func containsAny(s string, keywords ...string) bool {
@@ -2,6 +2,11 @@ package agents
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -92,3 +97,183 @@ func TestBusStats(t *testing.T) {
 		t.Error("expected at least 1 task in stats")
 	}
 }
+
+func TestBusHistoryDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bus.db")
+	bus := NewBus(5 * time.Second)
+	if err := bus.EnableHistoryDB(path); err != nil {
+		t.Fatal(err)
+	}
+	echo := func(ctx context.Context, msg BusMessage) (BusMessage, error) {
+		return BusMessage{Type: MsgResult, Payload: "done: " + msg.Payload, Meta: map[string]string{"tokens": "12"}}, nil
+	}
+	_ = bus.Register(&SubAgent{Role: RoleCoder, Handler: echo})
+	_ = bus.Register(&SubAgent{Role: RoleWriter, Handler: func(ctx context.Context, msg BusMessage) (BusMessage, error) {
+		return BusMessage{}, fmt.Errorf("writer crashed")
+	}})
+
+	before := time.Now()
+	task, _ := bus.Send(context.Background(), BusMessage{Type: MsgTask, From: RoleOrchestrator, To: RoleCoder, Payload: "refactor"})
+	_, _ = bus.Send(context.Background(), BusMessage{Type: MsgTask, From: RoleOrchestrator, To: RoleWriter, Payload: "draft"})
+	bus.Close()
+
+	// A fresh bus on the same file sees the full trail.
+	bus = NewBus(5 * time.Second)
+	if err := bus.EnableHistoryDB(path); err != nil {
+		t.Fatal(err)
+	}
+	defer bus.Close()
+	all, err := bus.History(HistoryFilter{Since: before})
+	if err != nil || len(all) != 4 {
+		t.Fatalf("History = %d messages, %v", len(all), err)
+	}
+	coder, _ := bus.History(HistoryFilter{Role: RoleCoder})
+	if len(coder) != 2 || coder[1].ReplyTo != coder[0].ID || coder[1].ID != task.ID || coder[1].Meta["tokens"] != "12" {
+		t.Errorf("coder history = %+v", coder)
+	}
+	writer, _ := bus.History(HistoryFilter{Role: RoleWriter})
+	if len(writer) != 2 || writer[1].Type != MsgError || writer[1].Payload != "writer crashed" {
+		t.Errorf("writer history = %+v", writer)
+	}
+	if later, _ := bus.History(HistoryFilter{Since: time.Now().Add(time.Minute)}); len(later) != 0 {
+		t.Errorf("Since filter returned %d messages", len(later))
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0o600 {
+		t.Errorf("history db mode = %v, %v", fi.Mode(), err)
+	}
+}
+
+func TestBusDelegation(t *testing.T) {
+	bus := NewBus(5 * time.Second)
+	var researcherChain string
+	_ = bus.Register(&SubAgent{Role: RoleResearcher, Handler: func(ctx context.Context, msg BusMessage) (BusMessage, error) {
+		researcherChain = msg.Meta[MetaDelegationChain]
+		if msg.Payload == "loop" {
+			// Delegating back up the chain is refused.
+			_, err := Delegate(ctx, RoleCoder, "loop")
+			return BusMessage{Type: MsgResult, Payload: fmt.Sprint(err)}, nil
+		}
+		return BusMessage{Type: MsgResult, Payload: "Go 1.24 added generic type aliases"}, nil
+	}})
+	_ = bus.Register(&SubAgent{Role: RoleCoder, Handler: func(ctx context.Context, msg BusMessage) (BusMessage, error) {
+		answer, err := Delegate(ctx, RoleResearcher, msg.Payload)
+		if err != nil {
+			return BusMessage{}, err
+		}
+		return BusMessage{Type: MsgResult, Payload: "coded using: " + answer.Payload}, nil
+	}})
+
+	res, err := bus.Send(context.Background(), BusMessage{Type: MsgTask, From: RoleOrchestrator, To: RoleCoder, Payload: "what changed in Go 1.24?"})
+	if err != nil || res.Payload != "coded using: Go 1.24 added generic type aliases" {
+		t.Fatalf("Send = %q, %v", res.Payload, err)
+	}
+	if researcherChain != "orchestrator>coder>researcher" {
+		t.Errorf("chain = %q", researcherChain)
+	}
+
+	res, _ = bus.Send(context.Background(), BusMessage{Type: MsgTask, From: RoleOrchestrator, To: RoleCoder, Payload: "loop"})
+	if !strings.Contains(res.Payload, "circular delegation") {
+		t.Errorf("cycle not refused: %q", res.Payload)
+	}
+	if _, err := Delegate(context.Background(), RoleCoder, "x"); !errors.Is(err, ErrNotInHandler) {
+		t.Errorf("Delegate outside handler: %v", err)
+	}
+}
+
+func TestBusDelegationDepth(t *testing.T) {
+	bus := NewBus(5 * time.Second)
+	roles := []AgentRole{RoleCoder, RoleResearcher, RoleAnalyst, RoleWriter, RoleReviewer}
+	var depthErr error
+	for i, role := range roles {
+		next := AgentRole("")
+		if i+1 < len(roles) {
+			next = roles[i+1]
+		}
+		_ = bus.Register(&SubAgent{Role: role, Handler: func(ctx context.Context, msg BusMessage) (BusMessage, error) {
+			if next == "" {
+				return BusMessage{Type: MsgResult, Payload: "leaf"}, nil
+			}
+			res, err := Delegate(ctx, next, msg.Payload)
+			if errors.Is(err, ErrDelegationDepth) {
+				depthErr = err
+				return BusMessage{Type: MsgResult, Payload: "stopped"}, nil
+			}
+			return res, err
+		}})
+	}
+	res, err := bus.Send(context.Background(), BusMessage{Type: MsgTask, From: RoleOrchestrator, To: RoleCoder, Payload: "deep"})
+	if err != nil || res.Payload != "stopped" || depthErr == nil {
+		t.Fatalf("res=%q err=%v depthErr=%v", res.Payload, err, depthErr)
+	}
+	if !strings.Contains(depthErr.Error(), "orchestrator>coder>researcher>analyst>writer>reviewer") {
+		t.Errorf("depth error = %v", depthErr)
+	}
+}
+
+func TestBusPipelineAndAggregate(t *testing.T) {
+	bus := NewBus(5 * time.Second)
+	upper := func(prefix string) AgentHandler {
+		return func(ctx context.Context, msg BusMessage) (BusMessage, error) {
+			if msg.Payload == "fail" {
+				return BusMessage{}, fmt.Errorf("%s failed", prefix)
+			}
+			return BusMessage{Type: MsgResult, Payload: prefix + "(" + msg.Payload + ")"}, nil
+		}
+	}
+	_ = bus.Register(&SubAgent{Role: RoleResearcher, Handler: upper("research")})
+	_ = bus.Register(&SubAgent{Role: RoleWriter, Handler: upper("write")})
+	_ = bus.Register(&SubAgent{Role: RoleReviewer, Handler: upper("review")})
+
+	res, err := bus.Pipeline(context.Background(), []PipelineStep{
+		{Role: RoleResearcher, Payload: "topic"},
+		{Role: RoleWriter, Payload: "draft from {{prev}}"},
+		{Role: RoleReviewer, Payload: "check"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "review(check\n\nInput from writer:\nwrite(draft from research(topic)))"; res.Payload != want {
+		t.Errorf("pipeline = %q, want %q", res.Payload, want)
+	}
+	if _, err := bus.Pipeline(context.Background(), []PipelineStep{{Role: RoleWriter, Payload: "fail"}, {Role: RoleReviewer}}); err == nil || !strings.Contains(err.Error(), "step 1 (writer)") {
+		t.Errorf("pipeline error = %v", err)
+	}
+
+	agg, err := bus.Aggregate(context.Background(), map[AgentRole]string{
+		RoleResearcher: "facts", RoleWriter: "intro", RoleReviewer: "fail",
+	}, nil)
+	if err == nil || !strings.Contains(err.Error(), "review failed") {
+		t.Errorf("aggregate error = %v", err)
+	}
+	if agg.Payload != "## researcher\nresearch(facts)\n\n## writer\nwrite(intro)" {
+		t.Errorf("aggregate = %q", agg.Payload)
+	}
+	custom, _ := bus.Aggregate(context.Background(), map[AgentRole]string{RoleWriter: "a"}, func(r map[AgentRole]BusMessage) string {
+		return strings.ToUpper(r[RoleWriter].Payload)
+	})
+	if custom.Payload != "WRITE(A)" {
+		t.Errorf("custom merge = %q", custom.Payload)
+	}
+}
+
+func TestBusRouteByCapabilities(t *testing.T) {
+	bus := NewBus(5 * time.Second)
+	handled := func(ctx context.Context, msg BusMessage) (BusMessage, error) {
+		return BusMessage{Type: MsgResult}, nil
+	}
+	_ = bus.Register(&SubAgent{Role: "dba", Handler: handled, Capabilities: []string{"sql", "database", "query plan"}})
+	_ = bus.Register(&SubAgent{Role: RoleWriter, Handler: handled, Capabilities: []string{"blog", "copy", "docs"}})
+	_ = bus.Register(&SubAgent{Role: RoleCoder, Handler: handled})
+
+	for task, want := range map[string]AgentRole{
+		"Why is this database query slow? Check the query plan.": "dba",
+		"List all SQL tables":          "dba",
+		"Write two blogs about SQLite": RoleWriter, // "blogs" matches "blog", "SQLite" is not "sql"
+		"Refactor the parser":          RoleCoder,  // no capability match: keyword inference
+	} {
+		res, err := bus.Route(context.Background(), task)
+		if err != nil || res.From != want {
+			t.Errorf("Route(%q) → %s (%v), want %s", task, res.From, err, want)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package agents
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Omkar0612/nexus-ai/internal/audit"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+RiskFeedback — approval-fatigue reduction for the HITL gate.
+
+Every human decision on a high-risk action is tallied per action pattern
+(lower-cased, digits collapsed). Once a pattern has been approved
+Threshold times in a row it becomes a *candidate* for demotion — but it is
+only actually demoted (high → medium) if it matches an entry on the explicit
+Allowlist. Nothing is ever silently auto-demoted.
+
+Both the demotion and its revocation (any rejection) are written to the
+audit log so every policy change has a trail.
+*/
+
+var digitRunRe = regexp.MustCompile(`[0-9]+`)
+
+// RiskFeedbackConfig configures a RiskFeedback.
+type RiskFeedbackConfig struct {
+	// Threshold is the number of consecutive approvals required (default 5).
+	Threshold int
+	// Allowlist holds path.Match-style glob patterns over normalised actions
+	// (e.g. "deploy to staging*") that may be demoted.
+	Allowlist []string
+	// AuditLog, if set, records every demotion and revocation.
+	AuditLog *audit.Log
+	// UserID is recorded on audit entries.
+	UserID string
+}
+
+// PatternStats is the tally for one action pattern.
+type PatternStats struct {
+	Pattern              string
+	ConsecutiveApprovals int
+	TotalApprovals       int
+	Rejections           int
+	Demoted              bool
+	LastDecision         time.Time
+}
+
+// RiskFeedback learns from HITL decisions to lower the effective risk of
+// routine, explicitly allowlisted actions.
+type RiskFeedback struct {
+	mu    sync.Mutex
+	cfg   RiskFeedbackConfig
+	stats map[string]*PatternStats
+}
+
+// NewRiskFeedback creates a feedback tracker.
+func NewRiskFeedback(cfg RiskFeedbackConfig) *RiskFeedback {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = 5
+	}
+	return &RiskFeedback{cfg: cfg, stats: make(map[string]*PatternStats)}
+}
+
+// Allow adds a glob pattern to the allowlist.
+func (f *RiskFeedback) Allow(pattern string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cfg.Allowlist = append(f.cfg.Allowlist, strings.ToLower(pattern))
+}
+
+// EffectiveRisk returns the risk to enforce for action: "medium" for a
+// demoted high-risk pattern, otherwise risk unchanged.
+func (f *RiskFeedback) EffectiveRisk(action, risk string) string {
+	if f == nil || !strings.EqualFold(risk, "high") {
+		return risk
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok := f.stats[actionPattern(action)]; ok && s.Demoted {
+		return "medium"
+	}
+	return risk
+}
+
+// Observe records a human decision on a high-risk request.
+func (f *RiskFeedback) Observe(req ApprovalRequest) {
+	if f == nil || !strings.EqualFold(req.Risk, "high") {
+		return
+	}
+	pattern := actionPattern(req.Action)
+
+	f.mu.Lock()
+	s, ok := f.stats[pattern]
+	if !ok {
+		s = &PatternStats{Pattern: pattern}
+		f.stats[pattern] = s
+	}
+	s.LastDecision = time.Now()
+	var change string
+	switch req.Status {
+	case ApprovalApproved:
+		s.ConsecutiveApprovals++
+		s.TotalApprovals++
+		if !s.Demoted && s.ConsecutiveApprovals >= f.cfg.Threshold && f.allowed(pattern) {
+			s.Demoted = true
+			change = "demoted"
+		}
+	case ApprovalRejected:
+		s.Rejections++
+		s.ConsecutiveApprovals = 0
+		if s.Demoted {
+			s.Demoted = false
+			change = "revoked"
+		}
+	default:
+		// Timeouts carry no signal about the action itself.
+	}
+	snapshot := *s
+	f.mu.Unlock()
+
+	if change != "" {
+		f.recordChange(change, snapshot, req)
+	}
+}
+
+// Revoke restores full approval for a demoted pattern. Demoted actions no
+// longer reach a human, so this is the manual counterpart of a rejection.
+func (f *RiskFeedback) Revoke(action, by string) {
+	pattern := actionPattern(action)
+	f.mu.Lock()
+	s, ok := f.stats[pattern]
+	if !ok || !s.Demoted {
+		f.mu.Unlock()
+		return
+	}
+	s.Demoted = false
+	s.ConsecutiveApprovals = 0
+	snapshot := *s
+	f.mu.Unlock()
+	f.recordChange("revoked", snapshot, ApprovalRequest{Action: action, DecidedBy: by})
+}
+
+// Stats returns the tally for every observed pattern.
+func (f *RiskFeedback) Stats() []PatternStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]PatternStats, 0, len(f.stats))
+	for _, s := range f.stats {
+		out = append(out, *s)
+	}
+	return out
+}
+
+// Candidates returns patterns that met the approval threshold but are not
+// allowlisted, so the user can decide whether to add them.
+func (f *RiskFeedback) Candidates() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []string
+	for p, s := range f.stats {
+		if !s.Demoted && s.ConsecutiveApprovals >= f.cfg.Threshold {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// allowed reports whether pattern matches the allowlist. Caller must hold f.mu.
+func (f *RiskFeedback) allowed(pattern string) bool {
+	for _, glob := range f.cfg.Allowlist {
+		if ok, _ := path.Match(strings.ToLower(glob), pattern); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *RiskFeedback) recordChange(change string, s PatternStats, req ApprovalRequest) {
+	rationale := fmt.Sprintf("%d consecutive approvals (threshold %d); pattern is allowlisted", s.ConsecutiveApprovals, f.cfg.Threshold)
+	if change == "revoked" {
+		rationale = fmt.Sprintf("demotion revoked by %s", req.DecidedBy)
+	}
+	log.Info().Str("pattern", s.Pattern).Str("change", change).Msg("HITL: risk policy changed")
+	if f.cfg.AuditLog == nil {
+		return
+	}
+	effective := "high"
+	if s.Demoted {
+		effective = "medium"
+	}
+	err := f.cfg.AuditLog.Record(audit.AuditEntry{
+		UserID:     f.cfg.UserID,
+		Agent:      "hitl_gate",
+		Action:     "risk_" + change + ": " + s.Pattern,
+		Rationale:  rationale,
+		Outcome:    change,
+		Risk:       audit.RiskHigh,
+		ApprovedBy: req.DecidedBy,
+		Meta: map[string]string{
+			"pattern":        s.Pattern,
+			"effective_risk": effective,
+			"request_id":     req.ID,
+		},
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("HITL: failed to audit risk policy change")
+	}
+}
+
+// actionPattern normalises an action so "deploy build 412" and
+// "deploy build 413" share a pattern.
+func actionPattern(action string) string {
+	p := digitRunRe.ReplaceAllString(strings.ToLower(action), "#")
+	return strings.Join(strings.Fields(p), " ")
+}
@@ -0,0 +1,79 @@
+package agents
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Omkar0612/nexus-ai/internal/audit"
+)
+
+func approved(action string) ApprovalRequest {
+	return ApprovalRequest{ID: "hitl-test", Action: action, Risk: "high", Status: ApprovalApproved, DecidedBy: "omkar"}
+}
+
+func TestRiskFeedbackRequiresAllowlist(t *testing.T) {
+	fb := NewRiskFeedback(RiskFeedbackConfig{Threshold: 2})
+	for i := 0; i < 3; i++ {
+		fb.Observe(approved("deploy build 41" + string(rune('0'+i))))
+	}
+	if got := fb.EffectiveRisk("deploy build 999", "high"); got != "high" {
+		t.Errorf("non-allowlisted pattern demoted to %s", got)
+	}
+	if c := fb.Candidates(); len(c) != 1 || c[0] != "deploy build #" {
+		t.Errorf("expected candidate 'deploy build #', got %v", c)
+	}
+}
+
+func TestRiskFeedbackDemotesAndAudits(t *testing.T) {
+	log, err := audit.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	fb := NewRiskFeedback(RiskFeedbackConfig{Threshold: 2, Allowlist: []string{"deploy to staging*"}, AuditLog: log, UserID: "u1"})
+	fb.Observe(approved("deploy to staging 1"))
+	if fb.EffectiveRisk("deploy to staging 3", "high") != "high" {
+		t.Fatal("demoted before threshold")
+	}
+	fb.Observe(approved("deploy to staging 2"))
+	if got := fb.EffectiveRisk("deploy to staging 3", "high"); got != "medium" {
+		t.Fatalf("expected demotion to medium, got %s", got)
+	}
+
+	fb.Revoke("deploy to staging 3", "omkar")
+	if got := fb.EffectiveRisk("deploy to staging 3", "high"); got != "high" {
+		t.Errorf("expected revocation to restore high, got %s", got)
+	}
+
+	entries, err := log.Query(audit.AuditQuery{UserID: "u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected demotion + revocation audit entries, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if e.Agent != "hitl_gate" || !strings.HasPrefix(e.Action, "risk_") {
+			t.Errorf("unexpected audit entry: %+v", e)
+		}
+	}
+}
+
+func TestHITLGateUsesRiskFeedback(t *testing.T) {
+	gate := NewHITLGate(100*time.Millisecond, nil)
+	fb := NewRiskFeedback(RiskFeedbackConfig{Threshold: 1, Allowlist: []string{"rotate logs"}})
+	fb.Observe(approved("rotate logs"))
+	gate.SetRiskFeedback(fb)
+
+	executed := false
+	err := gate.Execute(context.Background(), "rotate logs", "nightly", "high", func(ctx context.Context) error {
+		executed = true
+		return nil
+	})
+	if err != nil || !executed {
+		t.Fatalf("expected demoted action to run without approval, err=%v executed=%v", err, executed)
+	}
+}
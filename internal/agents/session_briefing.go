@@ -35,11 +35,18 @@ type SessionBriefer struct {
 	mem      *memory.Store
 	userID   string
 	lastSeen time.Time
+	drift    *DriftDetector
 }
 
 // NewSessionBriefer creates a new session briefer
 func NewSessionBriefer(mem *memory.Store, userID string) *SessionBriefer {
-	return &SessionBriefer{mem: mem, userID: userID, lastSeen: time.Now()}
+	return &SessionBriefer{mem: mem, userID: userID, lastSeen: time.Now(), drift: NewDriftDetector(mem, userID)}
+}
+
+// Dismiss snoozes a drift signal so later briefs leave it out; see
+// DriftDetector.Dismiss.
+func (s *SessionBriefer) Dismiss(taskRef string, until time.Time) {
+	s.drift.Dismiss(taskRef, until)
 }
 
 // ShouldBrief returns true if user has been away long enough to warrant a briefing
@@ -71,8 +78,7 @@ func (s *SessionBriefer) GenerateBrief() (*SessionBrief, error) {
 	brief.ResumeContext = sb.String()
 
 	// Run drift detection
-	detector := NewDriftDetector(s.mem, s.userID)
-	signals, _ := detector.Scan(nil)
+	signals, _ := s.drift.Scan(nil)
 	brief.DriftSignals = signals
 	for _, sig := range signals {
 		if sig.Severity == "high" {
@@ -9,16 +9,21 @@ Security:
 */
 
 import (
+	"bytes"
 	"crypto/rand"
 	"database/sql"
+	"encoding/csv"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Omkar0612/nexus-ai/internal/events"
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -50,7 +55,8 @@ type AuditEntry struct {
 	CreatedAt    time.Time         `json:"created_at"`
 }
 
-// AuditQuery defines filters for querying the audit log.
+// AuditQuery defines filters for querying the audit log. SearchStr matches
+// action, rationale and outcome as a substring.
 type AuditQuery struct {
 	UserID    string
 	Agent     string
@@ -149,7 +155,41 @@ func (l *Log) Record(entry AuditEntry) error {
 }
 
 // Query returns audit entries matching the given filters.
+// Without an explicit Limit it returns at most 50 entries; use QueryStream
+// for exports that may cover the whole log.
 func (l *Log) Query(q AuditQuery) ([]AuditEntry, error) {
+	if q.Limit <= 0 {
+		q.Limit = 50
+	}
+	return l.collect(q)
+}
+
+// QueryStream iterates entries matching q row by row, invoking fn for each,
+// so arbitrarily large result sets are processed with bounded memory.
+// A zero Limit means no limit. Iteration stops at the first error returned by fn,
+// and that error is returned to the caller.
+func (l *Log) QueryStream(q AuditQuery, fn func(AuditEntry) error) error {
+	query, args := buildQuery(q)
+	rows, err := l.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// buildQuery renders q as a SELECT over audit_log, newest first.
+func buildQuery(q AuditQuery) (string, []interface{}) {
 	where := []string{"1=1"}
 	args := []interface{}{}
 
@@ -174,49 +214,73 @@ func (l *Log) Query(q AuditQuery) ([]AuditEntry, error) {
 		args = append(args, q.Until.UTC().Format(sqliteTimeFormat))
 	}
 	if q.SearchStr != "" {
-		where = append(where, "(action LIKE ? OR rationale LIKE ?)")
-		args = append(args, "%"+q.SearchStr+"%", "%"+q.SearchStr+"%")
-	}
-	limit := 50
-	if q.Limit > 0 {
-		limit = q.Limit
+		like := "%" + q.SearchStr + "%"
+		where = append(where, "(action LIKE ? OR rationale LIKE ? OR outcome LIKE ?)")
+		args = append(args, like, like, like)
 	}
 	query := fmt.Sprintf(
 		`SELECT id,user_id,agent,action,rationale,context_used,alternatives,outcome,risk,approved_by,duration_ms,meta,created_at
-		 FROM audit_log WHERE %s ORDER BY created_at DESC LIMIT %d`,
-		strings.Join(where, " AND "), limit,
+		 FROM audit_log WHERE %s ORDER BY created_at DESC, id`,
+		strings.Join(where, " AND "),
 	)
-	rows, err := l.db.Query(query, args...)
-	if err != nil {
-		return nil, err
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", q.Limit)
 	}
-	defer rows.Close()
+	return query, args
+}
 
-	var entries []AuditEntry
-	for rows.Next() {
-		var e AuditEntry
-		var altsJSON, metaJSON, risk, createdAtStr string
-		if err := rows.Scan(
-			&e.ID, &e.UserID, &e.Agent, &e.Action, &e.Rationale,
-			&e.ContextUsed, &altsJSON, &e.Outcome, &risk,
-			&e.ApprovedBy, &e.DurationMs, &metaJSON, &createdAtStr,
-		); err != nil {
-			return nil, err
-		}
-		e.Risk = RiskLevel(risk)
-		_ = json.Unmarshal([]byte(altsJSON), &e.Alternatives)
-		_ = json.Unmarshal([]byte(metaJSON), &e.Meta)
-		if t, err := time.ParseInLocation(sqliteTimeFormat, createdAtStr, time.UTC); err == nil {
-			e.CreatedAt = t
+// scanEntry decodes the current row into an AuditEntry.
+func scanEntry(rows *sql.Rows) (AuditEntry, error) {
+	var e AuditEntry
+	var altsJSON, metaJSON, risk, createdAtStr string
+	if err := rows.Scan(
+		&e.ID, &e.UserID, &e.Agent, &e.Action, &e.Rationale,
+		&e.ContextUsed, &altsJSON, &e.Outcome, &risk,
+		&e.ApprovedBy, &e.DurationMs, &metaJSON, &createdAtStr,
+	); err != nil {
+		return e, err
+	}
+	e.Risk = RiskLevel(risk)
+	_ = json.Unmarshal([]byte(altsJSON), &e.Alternatives)
+	_ = json.Unmarshal([]byte(metaJSON), &e.Meta)
+	if t, err := time.ParseInLocation(sqliteTimeFormat, createdAtStr, time.UTC); err == nil {
+		e.CreatedAt = t
+	}
+	return e, nil
+}
+
+// RecordEvents writes every event from ch to the audit log until ch is closed.
+// Run it in its own goroutine on a bus subscription.
+func (l *Log) RecordEvents(userID string, ch <-chan events.Event) {
+	for e := range ch {
+		risk := RiskLevel(e.Severity)
+		if risk != RiskLow && risk != RiskMedium && risk != RiskHigh {
+			risk = RiskLow
 		}
-		entries = append(entries, e)
+		_ = l.Record(AuditEntry{
+			UserID:     userID,
+			Agent:      e.Source,
+			Action:     string(e.Kind),
+			Outcome:    e.Message,
+			Risk:       risk,
+			ApprovedBy: "auto",
+			CreatedAt:  e.At,
+		})
 	}
-	return entries, rows.Err()
 }
 
 // FormatReport renders audit entries as a human-readable report.
 func FormatReport(entries []AuditEntry) string {
+	return formatReport(entries, false)
+}
+
+// formatReport renders the FormatReport layout; plain drops the emoji and
+// uses Markdown list nesting, for ExportMarkdown.
+func formatReport(entries []AuditEntry, plain bool) string {
 	if len(entries) == 0 {
+		if plain {
+			return "# NEXUS Audit Log\n\nNo audit entries found for this query.\n"
+		}
 		return "📋 No audit entries found for this query."
 	}
 	riskIcons := map[RiskLevel]string{
@@ -225,8 +289,30 @@ func FormatReport(entries []AuditEntry) string {
 		RiskHigh:   "🔴",
 	}
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("📋 **NEXUS Audit Log** (%d entries)\n\n", len(entries)))
+	if plain {
+		sb.WriteString(fmt.Sprintf("# NEXUS Audit Log (%d entries)\n\n", len(entries)))
+	} else {
+		sb.WriteString(fmt.Sprintf("📋 **NEXUS Audit Log** (%d entries)\n\n", len(entries)))
+	}
 	for _, e := range entries {
+		if plain {
+			risk := string(e.Risk)
+			if risk == "" {
+				risk = "unknown"
+			}
+			sb.WriteString(fmt.Sprintf("- **[%s] %s: %s** → %s\n", risk, e.Agent, e.Action, e.Outcome))
+			if e.Rationale != "" {
+				sb.WriteString(fmt.Sprintf("  - Why: %s\n", e.Rationale))
+			}
+			if len(e.Alternatives) > 0 {
+				sb.WriteString(fmt.Sprintf("  - Considered: %s\n", strings.Join(e.Alternatives, ", ")))
+			}
+			if e.ApprovedBy != "auto" && e.ApprovedBy != "" {
+				sb.WriteString(fmt.Sprintf("  - Approved by: %s\n", e.ApprovedBy))
+			}
+			sb.WriteString(fmt.Sprintf("  - %s (%dms)\n", e.CreatedAt.UTC().Format("2006-01-02 15:04:05 UTC"), e.DurationMs))
+			continue
+		}
 		icon := riskIcons[e.Risk]
 		if icon == "" {
 			icon = "⚪"
@@ -247,14 +333,86 @@ func FormatReport(entries []AuditEntry) string {
 }
 
 // ExportJSON returns all entries as a JSON byte slice (for compliance export).
+// Like ExportCSV, a zero Limit exports every matching entry.
 func (l *Log) ExportJSON(q AuditQuery) ([]byte, error) {
-	entries, err := l.Query(q)
+	entries, err := l.collect(q)
 	if err != nil {
 		return nil, err
 	}
 	return json.MarshalIndent(entries, "", "  ")
 }
 
+// csvColumns is the fixed column order of ExportCSV.
+var csvColumns = []string{
+	"id", "created_at", "user_id", "agent", "action", "rationale", "context_used",
+	"alternatives", "outcome", "risk", "approved_by", "duration_ms", "meta",
+}
+
+// ExportCSV returns entries matching q as CSV with a header row, for
+// spreadsheets. Alternatives are joined with "; " and Meta is flattened to
+// sorted "key=value" pairs, so repeated exports diff cleanly. Unlike Query,
+// a zero Limit exports every matching entry.
+func (l *Log) ExportCSV(q AuditQuery) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvColumns); err != nil {
+		return nil, err
+	}
+	err := l.QueryStream(q, func(e AuditEntry) error {
+		meta := make([]string, 0, len(e.Meta))
+		for k, v := range e.Meta {
+			meta = append(meta, k+"="+v)
+		}
+		sort.Strings(meta)
+		row := []string{
+			e.ID, e.CreatedAt.UTC().Format(time.RFC3339Nano), e.UserID, e.Agent, e.Action,
+			e.Rationale, e.ContextUsed, strings.Join(e.Alternatives, "; "), e.Outcome,
+			string(e.Risk), e.ApprovedBy, strconv.FormatInt(e.DurationMs, 10), strings.Join(meta, "; "),
+		}
+		for i := range row {
+			row[i] = csvSafe(row[i])
+		}
+		return w.Write(row)
+	})
+	if err != nil {
+		return nil, err
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// csvSafe defuses spreadsheet formula injection: agent output is untrusted,
+// and a cell starting with = + - @ would be evaluated when the export is
+// opened in Excel or Sheets.
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// ExportMarkdown returns entries matching q in the FormatReport layout
+// without emoji, for compliance documents. Like ExportCSV, a zero Limit
+// exports every matching entry.
+func (l *Log) ExportMarkdown(q AuditQuery) ([]byte, error) {
+	entries, err := l.collect(q)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(formatReport(entries, true)), nil
+}
+
+// collect gathers the entries matching q via QueryStream, so unlike Query a
+// zero Limit means no limit.
+func (l *Log) collect(q AuditQuery) ([]AuditEntry, error) {
+	var entries []AuditEntry
+	err := l.QueryStream(q, func(e AuditEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	return entries, err
+}
+
 // ClassifyRisk auto-classifies an action's risk level.
 func ClassifyRisk(action string) RiskLevel {
 	action = strings.ToLower(action)
@@ -273,5 +431,60 @@ func ClassifyRisk(action string) RiskLevel {
 	return RiskLow
 }
 
+// maxSinceSpan bounds ParseSince so absurd specs cannot overflow time.Duration.
+const maxSinceSpan = 100 * 365 * 24 * time.Hour
+
+// ParseSince converts a relative spec such as "24h", "90m", "7d" or "2w" into
+// the absolute time that far in the past, for AuditQuery.Since. Absolute
+// dates ("2026-01-31") and RFC 3339 timestamps are accepted too.
+func ParseSince(spec string) (time.Time, error) {
+	return parseSince(spec, time.Now())
+}
+
+func parseSince(spec string, now time.Time) (time.Time, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return time.Time{}, fmt.Errorf("audit: empty time range")
+	}
+	// Absolute forms first: RFC 3339 needs its upper-case T and Z.
+	if t, err := time.Parse(time.RFC3339, spec); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", spec, now.Location()); err == nil {
+		return t, nil
+	}
+	spec = strings.ToLower(spec)
+
+	var span time.Duration
+	switch unit := spec[len(spec)-1]; unit {
+	case 'd', 'w':
+		n, err := strconv.Atoi(spec[:len(spec)-1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("audit: invalid time range %q (want e.g. 24h, 7d, 2w)", spec)
+		}
+		day := 24 * time.Hour
+		if unit == 'w' {
+			day *= 7
+		}
+		if n > 0 && time.Duration(n) > maxSinceSpan/day {
+			return time.Time{}, fmt.Errorf("audit: time range %q too large", spec)
+		}
+		span = time.Duration(n) * day
+	default:
+		d, err := time.ParseDuration(spec)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("audit: invalid time range %q (want e.g. 24h, 7d, 2w)", spec)
+		}
+		span = d
+	}
+	if span <= 0 {
+		return time.Time{}, fmt.Errorf("audit: time range %q must be positive", spec)
+	}
+	if span > maxSinceSpan {
+		return time.Time{}, fmt.Errorf("audit: time range %q too large", spec)
+	}
+	return now.Add(-span), nil
+}
+
 // Close shuts down the audit log.
 func (l *Log) Close() error { return l.db.Close() }
@@ -1,6 +1,12 @@
 package audit
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 )
@@ -111,3 +117,167 @@ func TestExportJSON(t *testing.T) {
 		t.Error("expected non-empty JSON export")
 	}
 }
+
+func TestExportsWithoutLimitIncludeEverything(t *testing.T) {
+	l, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer l.Close()
+
+	for i := 0; i < 60; i++ {
+		_ = l.Record(AuditEntry{UserID: "u1", Agent: "a", Action: fmt.Sprintf("step %d", i), Risk: RiskLow, ApprovedBy: "auto"})
+	}
+
+	md, err := l.ExportMarkdown(AuditQuery{UserID: "u1"})
+	if err != nil {
+		t.Fatalf("ExportMarkdown: %v", err)
+	}
+	if !strings.Contains(string(md), "(60 entries)") {
+		t.Errorf("markdown export should include all 60 entries: %.80s", md)
+	}
+	data, err := l.ExportJSON(AuditQuery{UserID: "u1"})
+	if err != nil {
+		t.Fatalf("ExportJSON: %v", err)
+	}
+	var entries []AuditEntry
+	if err := json.Unmarshal(data, &entries); err != nil || len(entries) != 60 {
+		t.Errorf("JSON export should include all 60 entries, got %d (err=%v)", len(entries), err)
+	}
+	if md, _ := l.ExportMarkdown(AuditQuery{UserID: "u1", Limit: 5}); !strings.Contains(string(md), "(5 entries)") {
+		t.Errorf("an explicit Limit should still apply: %.80s", md)
+	}
+}
+
+func TestQueryStream(t *testing.T) {
+	l, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer l.Close()
+
+	for i := 0; i < 60; i++ {
+		_ = l.Record(AuditEntry{UserID: "u1", Agent: "a", Action: fmt.Sprintf("step %d", i), Risk: RiskLow, ApprovedBy: "auto"})
+	}
+
+	count := 0
+	err = l.QueryStream(AuditQuery{UserID: "u1"}, func(e AuditEntry) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+	if count != 60 {
+		t.Errorf("expected all 60 entries without a limit, got %d", count)
+	}
+
+	stop := errors.New("stop")
+	count = 0
+	err = l.QueryStream(AuditQuery{UserID: "u1"}, func(e AuditEntry) error {
+		count++
+		if count == 5 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || count != 5 {
+		t.Errorf("expected stop after 5 entries, got %d entries, err=%v", count, err)
+	}
+}
+
+func TestAuditSearchOutcome(t *testing.T) {
+	l, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer l.Close()
+	_ = l.Record(AuditEntry{UserID: "u1", Agent: "email", Action: "send reply", Outcome: "SMTP timeout after 30s"})
+	_ = l.Record(AuditEntry{UserID: "u1", Agent: "email", Action: "send digest", Outcome: "delivered"})
+
+	got, err := l.Query(AuditQuery{UserID: "u1", SearchStr: "timeout"})
+	if err != nil || len(got) != 1 || got[0].Action != "send reply" {
+		t.Errorf("outcome search = %+v, %v", got, err)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	for spec, want := range map[string]time.Duration{
+		"24h":   24 * time.Hour,
+		"90m":   90 * time.Minute,
+		"1h30m": 90 * time.Minute,
+		"7d":    7 * 24 * time.Hour,
+		"30d":   30 * 24 * time.Hour,
+		"2w":    14 * 24 * time.Hour,
+		" 7D ":  7 * 24 * time.Hour,
+	} {
+		got, err := parseSince(spec, now)
+		if err != nil || now.Sub(got) != want {
+			t.Errorf("parseSince(%q) = %v, %v; want now-%v", spec, got, err, want)
+		}
+	}
+	if got, err := parseSince("2026-01-31", now); err != nil || !got.Equal(time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("absolute date = %v, %v", got, err)
+	}
+	if got, err := parseSince(" 2026-01-02T15:04:05Z ", now); err != nil || !got.Equal(time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)) {
+		t.Errorf("RFC 3339 timestamp = %v, %v", got, err)
+	}
+	if got, _ := parseSince("7D", now); !got.Equal(now.Add(-7 * 24 * time.Hour)) {
+		t.Errorf("upper-case unit = %v", got)
+	}
+	for _, bad := range []string{"", "7", "d", "0d", "-3d", "1.5d", "7y", "abc", "999999999999w", "1000000h"} {
+		if _, err := parseSince(bad, now); err == nil {
+			t.Errorf("parseSince(%q): expected error", bad)
+		}
+	}
+}
+
+func TestExportCSVAndMarkdown(t *testing.T) {
+	l, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer l.Close()
+	at := time.Date(2026, 2, 1, 9, 30, 0, 0, time.UTC)
+	_ = l.Record(AuditEntry{
+		ID: "aud-1", UserID: "u1", Agent: "email", Action: "send reply", Rationale: "user asked, twice",
+		Alternatives: []string{"draft only", "ask first"}, Outcome: "=HYPERLINK(\"http://evil\")",
+		Risk: RiskHigh, ApprovedBy: "alice", DurationMs: 42,
+		Meta: map[string]string{"to": "bob@example.com", "model": "llama3"}, CreatedAt: at,
+	})
+
+	data, err := l.ExportCSV(AuditQuery{UserID: "u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("csv rows = %v, %v", rows, err)
+	}
+	if strings.Join(rows[0], ",") != "id,created_at,user_id,agent,action,rationale,context_used,alternatives,outcome,risk,approved_by,duration_ms,meta" {
+		t.Errorf("header = %v", rows[0])
+	}
+	r := rows[1]
+	if r[0] != "aud-1" || r[1] != "2026-02-01T09:30:00Z" || r[5] != "user asked, twice" || r[7] != "draft only; ask first" {
+		t.Errorf("row = %q", r)
+	}
+	if r[12] != "model=llama3; to=bob@example.com" {
+		t.Errorf("meta = %q, want sorted pairs", r[12])
+	}
+	if !strings.HasPrefix(r[8], "'=") {
+		t.Errorf("formula not defused: %q", r[8])
+	}
+
+	md, err := l.ExportMarkdown(AuditQuery{UserID: "u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# NEXUS Audit Log (1 entries)\n\n" +
+		"- **[high] email: send reply** → =HYPERLINK(\"http://evil\")\n" +
+		"  - Why: user asked, twice\n  - Considered: draft only, ask first\n  - Approved by: alice\n" +
+		"  - 2026-02-01 09:30:00 UTC (42ms)\n"
+	if string(md) != want {
+		t.Errorf("markdown =\n%s\nwant\n%s", md, want)
+	}
+}
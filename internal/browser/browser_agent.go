@@ -13,16 +13,21 @@ NEXUS BrowserAgent:
   7. Loop detection — won't revisit same URL > 3 times
   8. Depth limiter — won't follow links deeper than N hops
   9. Content summarisation via NEXUS LLM router
+  10. Polite crawling — robots.txt rules and Crawl-delay honoured per host
 
 Security:
   - Only http:// and https:// schemes are permitted (file://, gopher://, etc. blocked)
-  - Private/loopback/link-local IPv4+IPv6 ranges blocked (SSRF)
+  - Private/loopback/link-local IPv4+IPv6 ranges blocked (SSRF), checked on
+    every resolved address and again after redirects
   - Cloud metadata endpoints blocked (AWS 169.254.169.254, GCP, Azure, Alibaba)
   - AllowedHosts allowlist for strict production deployments
 */
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net"
 	"net/url"
 	"strings"
 	"sync"
@@ -39,13 +44,16 @@ type BrowseAction struct {
 
 // PageContent is extracted content from a page.
 type PageContent struct {
-	URL       string
-	Title     string
-	Text      string
-	Links     []string
-	Tables    [][]string
-	MetaDesc  string
-	FetchedAt time.Time
+	URL      string
+	Title    string
+	Text     string
+	Links    []string
+	Tables   [][]string
+	MetaDesc string
+	// Structured holds JSON-LD and OpenGraph metadata under the
+	// Structured* keys, nil if the page has none.
+	Structured map[string]any
+	FetchedAt  time.Time
 }
 
 // BrowseResult is the result of a multi-step browser task.
@@ -69,16 +77,25 @@ type BrowserConfig struct {
 	Timeout       time.Duration
 	ScreenshotDir string
 	UserAgent     string
+	SessionDir    string // where named sessions persist; default ~/.nexus/browser/sessions
+	RespectRobots bool   // obey robots.txt rules and Crawl-delay for UserAgent
+	// Chrome drives a local Chrome/Chromium (see ChromePathEnv) instead of
+	// the simulation driver. It needs a build with -tags chromedp.
+	Chrome bool
+	// ChromeNoSandbox starts Chrome with --no-sandbox. Only set it where the
+	// sandbox cannot run, e.g. a container running as root.
+	ChromeNoSandbox bool
 }
 
 // DefaultConfig returns safe browser defaults with SSRF protection enabled.
 func DefaultConfig() BrowserConfig {
 	return BrowserConfig{
-		Headless:  true,
-		MaxDepth:  3,
-		MaxVisits: 3,
-		Timeout:   30 * time.Second,
-		UserAgent: "NEXUS-Agent/1.7 (autonomous; +https://github.com/Omkar0612/nexus-ai)",
+		Headless:      true,
+		MaxDepth:      3,
+		MaxVisits:     3,
+		Timeout:       30 * time.Second,
+		RespectRobots: true,
+		UserAgent:     "NEXUS-Agent/1.7 (autonomous; +https://github.com/Omkar0612/nexus-ai)",
 		BlockedHosts: []string{
 			// IPv4 private/loopback
 			"localhost",
@@ -105,24 +122,51 @@ func DefaultConfig() BrowserConfig {
 
 // BrowserAgent performs autonomous web browsing.
 type BrowserAgent struct {
-	cfg     BrowserConfig
-	visited map[string]int // URL -> visit count
-	mu      sync.Mutex
-	depth   int
+	cfg      BrowserConfig
+	visited  map[string]int // URL -> visit count
+	sessions map[string]*Session
+	driver   Driver
+	lookup   func(ctx context.Context, host string) ([]net.IPAddr, error)
+	robots   map[string]*robotsRules // scheme://host → parsed robots.txt
+	// fetchRobots returns the status and body of a robots.txt URL.
+	fetchRobots func(ctx context.Context, robotsURL string) (int, string, error)
+	mu          sync.Mutex
+	depth       int
 }
 
-// New creates a BrowserAgent.
+// New creates a BrowserAgent. It uses the simulation driver unless
+// cfg.Chrome is set.
 func New(cfg BrowserConfig) *BrowserAgent {
-	return &BrowserAgent{
-		cfg:     cfg,
-		visited: make(map[string]int),
+	b := &BrowserAgent{
+		cfg:      cfg,
+		visited:  make(map[string]int),
+		sessions: make(map[string]*Session),
+		driver:   defaultDriver(cfg),
+		lookup:   net.DefaultResolver.LookupIPAddr,
+		robots:   make(map[string]*robotsRules),
 	}
+	b.fetchRobots = b.httpFetchRobots
+	return b
+}
+
+// SetDriver replaces the driver used to execute browse actions.
+func (b *BrowserAgent) SetDriver(d Driver) {
+	b.driver = d
+}
+
+// Close releases the driver's resources, e.g. the Chrome process.
+func (b *BrowserAgent) Close() error {
+	if c, ok := b.driver.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
 }
 
 // IsAllowed checks if a URL is safe to navigate to.
 // Blocks:
 //   - Non-http(s) schemes (file://, ftp://, gopher://, javascript://, etc.)
-//   - Private/loopback IPv4 and IPv6 ranges
+//   - Private/loopback IPv4 and IPv6 ranges, including hostnames that
+//     resolve to them
 //   - Cloud IMDS metadata endpoints
 //   - URLs exceeding the loop-visit limit
 func (b *BrowserAgent) IsAllowed(rawURL string) (bool, string) {
@@ -130,7 +174,22 @@ func (b *BrowserAgent) IsAllowed(rawURL string) (bool, string) {
 	if err != nil {
 		return false, "invalid URL"
 	}
+	if ok, reason := b.checkURL(parsed); !ok {
+		return false, reason
+	}
 
+	// Loop protection.
+	b.mu.Lock()
+	count := b.visited[rawURL]
+	b.mu.Unlock()
+	if count >= b.cfg.MaxVisits {
+		return false, fmt.Sprintf("URL visited %d times (limit: %d)", count, b.cfg.MaxVisits)
+	}
+	return true, ""
+}
+
+// checkURL applies the scheme, blocklist, DNS and allowlist policy to parsed.
+func (b *BrowserAgent) checkURL(parsed *url.URL) (bool, string) {
 	// 1. Scheme allowlist — only http and https permitted.
 	scheme := strings.ToLower(parsed.Scheme)
 	if scheme != "http" && scheme != "https" {
@@ -150,12 +209,9 @@ func (b *BrowserAgent) IsAllowed(rawURL string) (bool, string) {
 		}
 	}
 
-	// 3. Loop protection.
-	b.mu.Lock()
-	count := b.visited[rawURL]
-	b.mu.Unlock()
-	if count >= b.cfg.MaxVisits {
-		return false, fmt.Sprintf("URL visited %d times (limit: %d)", count, b.cfg.MaxVisits)
+	// 3. Every address the host resolves to must be public.
+	if ok, reason := b.checkResolved(host); !ok {
+		return false, reason
 	}
 
 	// 4. Allowlist check (only enforced when list is non-empty).
@@ -208,31 +264,75 @@ func (b *BrowserAgent) PlanTask(task string) []BrowseAction {
 	return actions
 }
 
-// Run executes a planned sequence of browse actions (simulation / dry-run mode).
-// In production this dispatches to chromedp.
+// Run executes a planned sequence of browse actions in a fresh, in-memory
+// session: cookies set by one action are sent by the next, then discarded.
 func (b *BrowserAgent) Run(task string, actions []BrowseAction) *BrowseResult {
+	return b.run(newSession("", ""), actions)
+}
+
+// RunSession is like Run but uses the named persistent session, so cookies
+// (e.g. a login) survive across invocations until ClearSession is called.
+func (b *BrowserAgent) RunSession(session, task string, actions []BrowseAction) *BrowseResult {
+	sess, err := b.Session(session)
+	if err != nil {
+		return &BrowseResult{Actions: actions, StartedAt: time.Now(), Error: err.Error()}
+	}
+	result := b.run(sess, actions)
+	if err := sess.save(); err != nil && result.Error == "" {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (b *BrowserAgent) run(sess *Session, actions []BrowseAction) *BrowseResult {
 	start := time.Now()
 	result := &BrowseResult{
 		TaskID:    fmt.Sprintf("browse-%d", start.UnixNano()),
 		Actions:   actions,
 		StartedAt: start,
 	}
+	fail := func(msg string) *BrowseResult {
+		result.Error = msg
+		result.Success = false
+		result.Duration = time.Since(start)
+		return result
+	}
 
 	for _, action := range actions {
 		if action.Type == "navigate" {
 			ok, reason := b.IsAllowed(action.Target)
 			if !ok {
-				result.Error = fmt.Sprintf("blocked: %s — %s", action.Target, reason)
-				result.Success = false
-				result.Duration = time.Since(start)
-				return result
+				return fail(fmt.Sprintf("blocked: %s — %s", action.Target, reason))
 			}
+			if ok, reason := b.IsAllowedByRobots(action.Target); !ok {
+				return fail(fmt.Sprintf("blocked: %s — %s", action.Target, reason))
+			}
+			b.waitCrawlDelay(action.Target)
 			b.RecordVisit(action.Target)
-			result.Pages = append(result.Pages, PageContent{
-				URL:       action.Target,
-				FetchedAt: time.Now(),
-				Text:      fmt.Sprintf("[chromedp would fetch: %s]", action.Target),
-			})
+		}
+		timeout := action.Timeout
+		if timeout == 0 {
+			timeout = b.cfg.Timeout
+		}
+		ctx, cancel := context.Background(), context.CancelFunc(func() {})
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		page, err := b.driver.Execute(ctx, action, sess)
+		cancel()
+		if err != nil {
+			return fail(fmt.Sprintf("%s %s: %v", action.Type, action.Target, err))
+		}
+		// A redirect may have landed somewhere the original URL check never saw.
+		if page != nil && action.Type == "navigate" {
+			if final, moved := redirectedFrom(action.Target, page.URL); moved {
+				if ok, reason := b.checkURL(final); !ok {
+					return fail(fmt.Sprintf("blocked: %s redirected to %s — %s", action.Target, page.URL, reason))
+				}
+			}
+		}
+		if page != nil {
+			result.Pages = append(result.Pages, *page)
 		}
 	}
 
@@ -271,6 +371,15 @@ func SummariseContent(page PageContent, maxChars int) string {
 	if page.MetaDesc != "" {
 		sb.WriteString(fmt.Sprintf("Description: %s\n", page.MetaDesc))
 	}
+	for _, f := range []struct{ label, key string }{
+		{"Headline", StructuredTitle},
+		{"Author", StructuredAuthor},
+		{"Published", StructuredPublished},
+	} {
+		if v, _ := page.Structured[f.key].(string); v != "" && v != page.Title {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", f.label, v))
+		}
+	}
 	text := page.Text
 	if len(text) > maxChars {
 		text = text[:maxChars] + "..."
@@ -1,7 +1,16 @@
 package browser
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestBrowserIsAllowedValid(t *testing.T) {
@@ -72,3 +81,399 @@ func TestExtractLinks(t *testing.T) {
 		t.Errorf("expected 2 links, got %d", len(links))
 	}
 }
+
+func TestExtractTables(t *testing.T) {
+	page := `<html><body>
+<table>
+  <thead><tr><th>Plan</th><th colspan="2">Price</th></tr></thead>
+  <tbody>
+    <tr><td rowspan="2">Pro</td><td>$10</td><td>monthly</td></tr>
+    <tr><td>$100</td><td>yearly<br>save 17%</td></tr>
+    <tr><td>Free</td><td colspan="2">$0 <script>track()</script></td></tr>
+    <tr><td>Team</td></tr>
+  </tbody>
+</table>
+<table><tr><td>outer <table><tr><td>inner</td></tr></table></td></tr></table>
+</body></html>`
+	want := [][]string{
+		{"Plan", "Price", "Price"},
+		{"Pro", "$10", "monthly"},
+		{"Pro", "$100", "yearly save 17%"},
+		{"Free", "$0", "$0"},
+		{"Team", "", ""},
+		{"outer inner"},
+		{"inner"},
+	}
+	got := ExtractTables(page)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractTables =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestExtractTablesRowspanPastShortRow(t *testing.T) {
+	page := `<table>
+<tr><td>a</td><td>b</td><td rowspan="2">c</td></tr>
+<tr><td>d</td></tr>
+</table>`
+	want := [][]string{{"a", "b", "c"}, {"d", "", "c"}}
+	if got := ExtractTables(page); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractTables = %q, want %q", got, want)
+	}
+}
+
+func TestParseExtractLaysOutTableHTML(t *testing.T) {
+	page, err := parseExtract(`{"url":"https://x.example/","tableHTML":["<table><tr><th colspan=\"2\">Tier</th></tr><tr><td>A</td><td>B</td></tr></table>"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"Tier", "Tier"}, {"A", "B"}}; !reflect.DeepEqual(page.Tables, want) {
+		t.Errorf("Tables = %q, want %q", page.Tables, want)
+	}
+}
+
+func TestExtractStructured(t *testing.T) {
+	page := `<html><head>
+<meta property="og:title" content="Tea prices rise">
+<meta property="og:image" content="https://news.example.com/tea.jpg">
+<meta property="article:published_time" content="2026-01-01T00:00:00Z">
+<script type="application/ld+json">{"@context":"https://schema.org","@graph":[
+  {"@type":"WebSite","name":"News"},
+  {"@type":"NewsArticle","headline":"Tea prices rise 20%","datePublished":"2026-01-02",
+   "author":[{"@type":"Person","name":"Ada"},"Grace"]}]}</script>
+<script type="application/ld+json">{not json</script>
+<script type="application/ld+json">` + `{"pad":"` + strings.Repeat("x", maxJSONLDBytes) + `"}` + `</script>
+</head><body>text</body></html>`
+
+	got := ExtractStructured(page)
+	if got[StructuredTitle] != "Tea prices rise 20%" || got[StructuredAuthor] != "Ada, Grace" || got[StructuredPublished] != "2026-01-02" {
+		t.Errorf("article fields = %v / %v / %v", got[StructuredTitle], got[StructuredAuthor], got[StructuredPublished])
+	}
+	if ld, _ := got[StructuredJSONLD].([]any); len(ld) != 1 {
+		t.Errorf("expected only the valid, size-limited JSON-LD block, got %d", len(ld))
+	}
+	if og, _ := got[StructuredOpenGraph].(map[string]string); og["og:image"] != "https://news.example.com/tea.jpg" {
+		t.Errorf("opengraph = %v", got[StructuredOpenGraph])
+	}
+	if ExtractStructured("<p>plain</p>") != nil {
+		t.Error("a page without metadata should give nil")
+	}
+}
+
+func TestStructuredFallsBackToOpenGraph(t *testing.T) {
+	page, err := parseExtract(`{"url":"https://x.example/a","title":"A","og":{"og:title":"Launch day","article:author":"Lin","article:published_time":"2026-03-04"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Structured[StructuredTitle] != "Launch day" || page.Structured[StructuredAuthor] != "Lin" {
+		t.Errorf("Structured = %v", page.Structured)
+	}
+	summary := SummariseContent(*page, 100)
+	for _, want := range []string{"Headline: Launch day", "Author: Lin", "Published: 2026-03-04"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary missing %q:\n%s", want, summary)
+		}
+	}
+}
+
+// loginDriver sets a session cookie on /login and records cookies sent elsewhere.
+type loginDriver struct {
+	sent []*http.Cookie
+}
+
+func (d *loginDriver) Execute(_ context.Context, a BrowseAction, sess *Session) (*PageContent, error) {
+	if a.Type != "navigate" {
+		return nil, nil
+	}
+	u, _ := url.Parse(a.Target)
+	if strings.HasSuffix(u.Path, "/login") {
+		sess.SetCookies(u, []*http.Cookie{{Name: "sid", Value: "s3cret-token", Path: "/"}})
+	} else {
+		d.sent = sess.Cookies(u)
+	}
+	return &PageContent{URL: a.Target}, nil
+}
+
+func TestBrowserSessionAcrossActions(t *testing.T) {
+	b := New(DefaultConfig())
+	d := &loginDriver{}
+	b.SetDriver(d)
+
+	res := b.Run("login then scrape", []BrowseAction{
+		{Type: "navigate", Target: "https://app.example.com/login"},
+		{Type: "navigate", Target: "https://app.example.com/dashboard"},
+	})
+	if !res.Success {
+		t.Fatalf("Run failed: %s", res.Error)
+	}
+	if len(d.sent) != 1 || d.sent[0].Value != "s3cret-token" {
+		t.Errorf("expected auth cookie on dashboard request, got %v", d.sent)
+	}
+
+	// The anonymous session must not leak into a later Run.
+	b.Run("scrape", []BrowseAction{{Type: "navigate", Target: "https://app.example.com/other"}})
+	if len(d.sent) != 0 {
+		t.Errorf("expected no cookies in a fresh Run, got %v", d.sent)
+	}
+}
+
+func TestBrowserNamedSessionPersists(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SessionDir = t.TempDir()
+
+	b1 := New(cfg)
+	b1.SetDriver(&loginDriver{})
+	if res := b1.RunSession("work", "login", []BrowseAction{{Type: "navigate", Target: "https://app.example.com/login"}}); !res.Success {
+		t.Fatalf("RunSession: %s", res.Error)
+	}
+
+	b2 := New(cfg)
+	d := &loginDriver{}
+	b2.SetDriver(d)
+	b2.RunSession("work", "scrape", []BrowseAction{{Type: "navigate", Target: "https://app.example.com/dashboard"}})
+	if len(d.sent) != 1 {
+		t.Fatalf("expected persisted cookie in new agent, got %v", d.sent)
+	}
+
+	sess, _ := b2.Session("work")
+	if s := fmt.Sprintf("%v %#v", sess, sess); strings.Contains(s, "s3cret-token") {
+		t.Errorf("cookie value leaked via formatting: %s", s)
+	}
+
+	if err := b2.ClearSession("work"); err != nil {
+		t.Fatalf("ClearSession: %v", err)
+	}
+	b2.RunSession("work", "scrape", []BrowseAction{{Type: "navigate", Target: "https://app.example.com/dashboard"}})
+	if len(d.sent) != 0 {
+		t.Errorf("expected no cookies after ClearSession, got %v", d.sent)
+	}
+}
+
+// fakeDNS maps hostnames to fixed addresses.
+func fakeDNS(records map[string]string) func(context.Context, string) ([]net.IPAddr, error) {
+	return func(_ context.Context, host string) ([]net.IPAddr, error) {
+		ip, ok := records[host]
+		if !ok {
+			return nil, fmt.Errorf("no such host %s", host)
+		}
+		return []net.IPAddr{{IP: net.ParseIP(ip)}}, nil
+	}
+}
+
+func TestBrowserBlocksHostsResolvingInternally(t *testing.T) {
+	b := New(DefaultConfig())
+	b.lookup = fakeDNS(map[string]string{
+		"evil.example.com":  "169.254.169.254",
+		"intra.example.com": "10.1.2.3",
+		"v6.example.com":    "fd00::1",
+		"cgnat.example.com": "100.100.100.200",
+		"good.example.com":  "93.184.216.34",
+	})
+	for _, u := range []string{
+		"http://evil.example.com/latest/meta-data/",
+		"https://intra.example.com/",
+		"https://v6.example.com/",
+		"https://cgnat.example.com/",
+		"http://[::1]:8080/",
+		"http://[::ffff:127.0.0.1]/",
+	} {
+		if ok, _ := b.IsAllowed(u); ok {
+			t.Errorf("%s: expected blocked", u)
+		}
+	}
+	if ok, reason := b.IsAllowed("https://good.example.com/"); !ok {
+		t.Errorf("public host blocked: %s", reason)
+	}
+}
+
+// redirectDriver reports that every navigation ended up at final.
+type redirectDriver struct{ final string }
+
+func (d redirectDriver) Execute(_ context.Context, a BrowseAction, _ *Session) (*PageContent, error) {
+	return &PageContent{URL: d.final, Text: "secret"}, nil
+}
+
+func TestBrowserRecheckAfterRedirect(t *testing.T) {
+	b := New(DefaultConfig())
+	b.lookup = fakeDNS(map[string]string{"good.example.com": "93.184.216.34"})
+	b.SetDriver(redirectDriver{final: "http://169.254.169.254/latest/meta-data/"})
+	res := b.Run("fetch", []BrowseAction{{Type: "navigate", Target: "https://good.example.com/r"}})
+	if res.Success || len(res.Pages) != 0 {
+		t.Fatalf("redirect to metadata endpoint not blocked: %+v", res)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://169.254.169.254/", nil)
+	if err := b.CheckRedirect(req, nil); err == nil {
+		t.Error("CheckRedirect allowed metadata endpoint")
+	}
+	dial := b.SafeDialContext(&net.Dialer{})
+	if _, err := dial(context.Background(), "tcp", "127.0.0.1:80"); err == nil || !strings.Contains(err.Error(), "refusing") {
+		t.Errorf("SafeDialContext dialled loopback: %v", err)
+	}
+}
+
+func TestDefaultDriverIsSimulation(t *testing.T) {
+	// Even with a browser binary available, Chrome is opt-in.
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ChromePathEnv, exe)
+	if _, ok := New(DefaultConfig()).driver.(simulationDriver); !ok {
+		t.Error("expected simulation driver unless BrowserConfig.Chrome is set")
+	}
+	cfg := DefaultConfig()
+	cfg.Chrome = true
+	if _, ok := New(cfg).driver.(simulationDriver); ok {
+		t.Error("BrowserConfig.Chrome should select the Chrome driver")
+	}
+}
+
+func TestParseRobots(t *testing.T) {
+	body := `
+# comment
+User-agent: *
+Disallow: /private
+Crawl-delay: 5
+
+User-agent: Googlebot
+User-agent: nexus-agent
+Disallow: /admin
+Allow: /admin/public$
+Disallow: /*.pdf$
+Crawl-delay: 0.5
+`
+	r := parseRobots(body, robotsAgent(DefaultConfig().UserAgent))
+	for path, want := range map[string]bool{
+		"/":                 true,
+		"/private":          true, // our own group replaces the * group
+		"/admin/users":      false,
+		"/admin/public":     true,
+		"/admin/public/x":   false,
+		"/docs/report.pdf":  false,
+		"/docs/report.pdfx": true,
+	} {
+		if got := r.allowed(path); got != want {
+			t.Errorf("allowed(%q) = %v, want %v", path, got, want)
+		}
+	}
+	if r.crawlDelay != 500*time.Millisecond {
+		t.Errorf("crawlDelay = %v", r.crawlDelay)
+	}
+	if r := parseRobots(body, "otherbot"); r.allowed("/private/x") || r.crawlDelay != 5*time.Second {
+		t.Errorf("* group not applied: %+v", r)
+	}
+}
+
+func TestBrowserRespectsRobots(t *testing.T) {
+	b := New(DefaultConfig())
+	fetches := 0
+	b.fetchRobots = func(_ context.Context, u string) (int, string, error) {
+		fetches++
+		if u != "https://shop.example.com/robots.txt" {
+			t.Errorf("fetched %s", u)
+		}
+		return http.StatusOK, "User-agent: *\nDisallow: /checkout\nCrawl-delay: 0.05\n", nil
+	}
+	if ok, reason := b.IsAllowedByRobots("https://shop.example.com/checkout?step=1"); ok || !strings.Contains(reason, "robots.txt") {
+		t.Errorf("checkout allowed: %s", reason)
+	}
+	res := b.Run("browse", []BrowseAction{
+		{Type: "navigate", Target: "https://shop.example.com/a"},
+		{Type: "navigate", Target: "https://shop.example.com/b"},
+	})
+	if !res.Success {
+		t.Fatalf("Run: %s", res.Error)
+	}
+	if fetches != 1 {
+		t.Errorf("robots.txt fetched %d times, want cached after the first", fetches)
+	}
+	if res.Duration < 50*time.Millisecond {
+		t.Errorf("Crawl-delay not enforced: run took %v", res.Duration)
+	}
+	if res := b.Run("buy", []BrowseAction{{Type: "navigate", Target: "https://shop.example.com/checkout"}}); res.Success {
+		t.Error("navigation to disallowed path succeeded")
+	}
+
+	// Server errors mean "assume disallow"; a missing robots.txt allows all.
+	b.fetchRobots = func(_ context.Context, u string) (int, string, error) {
+		if strings.Contains(u, "down.example.com") {
+			return http.StatusServiceUnavailable, "", nil
+		}
+		return http.StatusNotFound, "", nil
+	}
+	if ok, _ := b.IsAllowedByRobots("https://down.example.com/"); ok {
+		t.Error("5xx robots.txt should disallow")
+	}
+	if ok, _ := b.IsAllowedByRobots("https://norobots.example.com/x"); !ok {
+		t.Error("404 robots.txt should allow")
+	}
+}
+
+// siteDriver serves a fixed link graph.
+type siteDriver map[string][]string
+
+func (d siteDriver) Execute(_ context.Context, a BrowseAction, _ *Session) (*PageContent, error) {
+	links, ok := d[a.Target]
+	if !ok {
+		return nil, fmt.Errorf("404")
+	}
+	return &PageContent{URL: a.Target, Links: links}, nil
+}
+
+func TestBrowserCrawl(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.RespectRobots = false
+	b := New(cfg)
+	b.lookup = fakeDNS(map[string]string{"docs.example.com": "93.184.216.34", "other.example.com": "93.184.216.35"})
+	b.SetDriver(siteDriver{
+		"https://docs.example.com/":      {"https://docs.example.com/a", "https://docs.example.com/a#intro", "https://other.example.com/", "https://docs.example.com/missing"},
+		"https://docs.example.com/a":     {"https://docs.example.com/a/b", "https://docs.example.com/"},
+		"https://docs.example.com/a/b":   {"https://docs.example.com/a/b/c"},
+		"https://docs.example.com/a/b/c": nil,
+		"https://other.example.com/":     nil,
+	})
+
+	res := b.Crawl("https://docs.example.com/", 2)
+	if !res.Success {
+		t.Fatalf("Crawl: %s", res.Error)
+	}
+	var got []string
+	for _, p := range res.Pages {
+		got = append(got, p.URL)
+	}
+	want := "https://docs.example.com/ https://docs.example.com/a https://docs.example.com/a/b"
+	if strings.Join(got, " ") != want {
+		t.Errorf("crawled %v, want %s (same origin, depth 2, no duplicates)", got, want)
+	}
+
+	if res := b.Crawl("http://127.0.0.1/", 1); res.Success {
+		t.Error("crawl of a blocked start URL succeeded")
+	}
+}
+
+func TestSessionRejectsPublicSuffixAndMatchesPathSegments(t *testing.T) {
+	sess := newSession("", "")
+	u, _ := url.Parse("https://app.example.com/admin/login")
+	sess.SetCookies(u, []*http.Cookie{
+		{Name: "wide", Value: "1", Domain: "com", Path: "/"},
+		{Name: "admin", Value: "2", Path: "/admin"},
+	})
+
+	other, _ := url.Parse("https://evil.com/")
+	if c := sess.Cookies(other); len(c) != 0 {
+		t.Errorf("cookie scoped to a public suffix leaked to %s: %v", other, c)
+	}
+	prefix, _ := url.Parse("https://app.example.com/administrator")
+	for _, c := range sess.Cookies(prefix) {
+		if c.Name == "admin" {
+			t.Errorf("/admin cookie sent to %s", prefix)
+		}
+	}
+	admin, _ := url.Parse("https://app.example.com/admin/users")
+	if c := sess.Cookies(admin); len(c) != 1 || c[0].Name != "admin" {
+		t.Errorf("expected /admin cookie on %s, got %v", admin, c)
+	}
+	if sess.Len() != 1 {
+		t.Errorf("rejected cookie recorded: Len = %d, want 1", sess.Len())
+	}
+}
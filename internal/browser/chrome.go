@@ -0,0 +1,244 @@
+//go:build chromedp
+
+package browser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// chromeCandidates are the binary names tried on $PATH, in order.
+var chromeCandidates = []string{
+	"google-chrome", "google-chrome-stable", "chromium", "chromium-browser", "chrome",
+}
+
+// findChrome locates a Chrome or Chromium binary: $NEXUS_CHROME_PATH first,
+// then the usual names on $PATH, then the standard macOS install location.
+func findChrome() (string, bool) {
+	if p := os.Getenv(ChromePathEnv); p != "" {
+		if _, err := os.Stat(p); err == nil {
+			return p, true
+		}
+		return "", false
+	}
+	for _, name := range chromeCandidates {
+		if p, err := exec.LookPath(name); err == nil {
+			return p, true
+		}
+	}
+	if runtime.GOOS == "darwin" {
+		p := "/Applications/Google Chrome.app/Contents/MacOS/Google Chrome"
+		if _, err := os.Stat(p); err == nil {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+func newChromeDriver(cfg BrowserConfig) Driver {
+	return &chromeDriver{cfg: cfg}
+}
+
+// chromeDriver drives a local Chrome through chromedp. One browser process
+// and tab is started lazily and reused by every action, so a click acts on
+// the page the previous navigate loaded. Close kills the process.
+type chromeDriver struct {
+	cfg BrowserConfig
+
+	mu          sync.Mutex
+	tab         context.Context // chromedp tab context; nil until started
+	cancelTab   context.CancelFunc
+	cancelAlloc context.CancelFunc
+}
+
+func (d *chromeDriver) Execute(ctx context.Context, action BrowseAction, sess *Session) (*PageContent, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.tab == nil {
+		if err := d.start(); err != nil {
+			return nil, err
+		}
+	}
+	// Actions run on a child of the tab context so that ctx's deadline
+	// applies without cancelling (and so closing) the tab itself.
+	actx, cancel := context.WithCancel(d.tab)
+	defer cancel()
+	defer context.AfterFunc(ctx, cancel)()
+
+	switch action.Type {
+	case "navigate":
+		return d.navigate(actx, action.Target, sess)
+	case "extract":
+		return d.extract(actx, action.Target)
+	case "click":
+		return nil, d.run(actx, chromedp.Evaluate(clickScript(action.Target), nil), waitReady())
+	case "fill":
+		return nil, d.run(actx, chromedp.Evaluate(fillScript(action.Target, action.Value), nil))
+	case "wait":
+		if action.Target == "" {
+			return nil, d.run(actx, waitReady())
+		}
+		return nil, d.run(actx, chromedp.WaitReady(action.Target, chromedp.ByQuery))
+	case "screenshot":
+		return nil, d.screenshot(actx, action.Target == "full-page")
+	default:
+		return nil, fmt.Errorf("browser: unknown action %q", action.Type)
+	}
+}
+
+// Close shuts down the browser process and removes its profile directory.
+func (d *chromeDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.tab != nil {
+		d.cancelTab()
+		d.cancelAlloc()
+		d.tab = nil
+	}
+	return nil
+}
+
+// start launches Chrome with a throwaway profile and opens its tab. The
+// sandbox stays on unless BrowserConfig.ChromeNoSandbox is set.
+func (d *chromeDriver) start() error {
+	bin, ok := findChrome()
+	if !ok {
+		return fmt.Errorf("browser: no Chrome or Chromium found — install one or set %s", ChromePathEnv)
+	}
+	opts := []chromedp.ExecAllocatorOption{
+		chromedp.ExecPath(bin),
+		chromedp.NoFirstRun,
+		chromedp.NoDefaultBrowserCheck,
+		chromedp.Flag("disable-background-networking", true),
+		chromedp.Flag("disable-extensions", true),
+		chromedp.Flag("disable-sync", true),
+	}
+	if d.cfg.Headless {
+		opts = append(opts, chromedp.Headless, chromedp.DisableGPU)
+	}
+	if d.cfg.UserAgent != "" {
+		opts = append(opts, chromedp.UserAgent(d.cfg.UserAgent))
+	}
+	if d.cfg.ChromeNoSandbox {
+		opts = append(opts, chromedp.NoSandbox)
+	}
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(context.Background(), opts...)
+	tab, cancelTab := chromedp.NewContext(allocCtx)
+	if err := chromedp.Run(tab, network.Enable()); err != nil {
+		cancelTab()
+		cancelAlloc()
+		return fmt.Errorf("browser: start chrome: %w", err)
+	}
+	d.tab, d.cancelTab, d.cancelAlloc = tab, cancelTab, cancelAlloc
+	return nil
+}
+
+// run executes actions, wrapping failures in the package's error prefix.
+func (d *chromeDriver) run(ctx context.Context, actions ...chromedp.Action) error {
+	if err := chromedp.Run(ctx, actions...); err != nil {
+		return fmt.Errorf("browser: chrome: %w", err)
+	}
+	return nil
+}
+
+// waitReady waits until the current document has finished loading.
+func waitReady() chromedp.Action {
+	return chromedp.Poll(`document.readyState === "complete"`, nil, chromedp.WithPollingInterval(100*time.Millisecond))
+}
+
+func (d *chromeDriver) navigate(ctx context.Context, target string, sess *Session) (*PageContent, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("browser: %w", err)
+	}
+	var actions []chromedp.Action
+	for _, c := range sess.Cookies(u) {
+		actions = append(actions, network.SetCookie(c.Name, c.Value).WithURL(target))
+	}
+	actions = append(actions, chromedp.Navigate(target), waitReady())
+	if err := d.run(ctx, actions...); err != nil {
+		return nil, err
+	}
+	page, err := d.extract(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := d.syncCookies(ctx, page.URL, sess); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// syncCookies copies the browser's cookies for pageURL back into sess.
+func (d *chromeDriver) syncCookies(ctx context.Context, pageURL string, sess *Session) error {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	var got []*network.Cookie
+	err = d.run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		got, err = network.GetCookies().WithURLs([]string{pageURL}).Do(ctx)
+		return err
+	}))
+	if err != nil {
+		return err
+	}
+	cookies := make([]*http.Cookie, 0, len(got))
+	for _, c := range got {
+		hc := &http.Cookie{Name: c.Name, Value: c.Value, Path: c.Path, Secure: c.Secure, HttpOnly: c.HTTPOnly}
+		if strings.HasPrefix(c.Domain, ".") { // host-only cookies carry no leading dot
+			hc.Domain = c.Domain
+		}
+		if !c.Session && c.Expires > 0 {
+			hc.Expires = time.Unix(int64(c.Expires), 0)
+		}
+		cookies = append(cookies, hc)
+	}
+	sess.SetCookies(u, cookies)
+	return nil
+}
+
+func (d *chromeDriver) extract(ctx context.Context, selector string) (*PageContent, error) {
+	var raw string
+	if err := d.run(ctx, chromedp.Evaluate(fmt.Sprintf(extractScript, jsString(selector)), &raw)); err != nil {
+		return nil, err
+	}
+	return parseExtract(raw)
+}
+
+// screenshot saves a PNG of the current page to cfg.ScreenshotDir.
+func (d *chromeDriver) screenshot(ctx context.Context, fullPage bool) error {
+	var png []byte
+	capture := chromedp.CaptureScreenshot(&png)
+	if fullPage {
+		capture = chromedp.FullScreenshot(&png, 100)
+	}
+	if err := d.run(ctx, capture); err != nil {
+		return err
+	}
+	dir := d.cfg.ScreenshotDir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("browser: screenshot dir: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("screenshot-%d.png", time.Now().UnixNano()))
+	if err := os.WriteFile(path, png, 0o600); err != nil {
+		return fmt.Errorf("browser: screenshot: %w", err)
+	}
+	return nil
+}
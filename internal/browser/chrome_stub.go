@@ -0,0 +1,21 @@
+//go:build !chromedp
+
+package browser
+
+import (
+	"context"
+	"errors"
+)
+
+// errNoChrome is returned by every action when BrowserConfig.Chrome is set
+// in a build without the Chrome driver.
+var errNoChrome = errors.New("browser: Chrome driver not built in — rebuild with -tags chromedp, or leave BrowserConfig.Chrome unset to simulate")
+
+// noChromeDriver stands in for the Chrome driver in default builds.
+type noChromeDriver struct{}
+
+func newChromeDriver(BrowserConfig) Driver { return noChromeDriver{} }
+
+func (noChromeDriver) Execute(context.Context, BrowseAction, *Session) (*PageContent, error) {
+	return nil, errNoChrome
+}
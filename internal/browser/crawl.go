@@ -0,0 +1,100 @@
+package browser
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxCrawlPages caps a single Crawl so a site with endless generated links
+// (calendars, faceted search) cannot run forever.
+const maxCrawlPages = 200
+
+// Crawl fetches startURL and follows same-origin links breadth-first, up to
+// maxDepth hops away (BrowserConfig.MaxDepth when maxDepth <= 0). Every page
+// goes through the same checks as Run — SSRF, robots.txt, MaxVisits loop
+// protection — and links that fail them are skipped rather than aborting the
+// crawl. Cookies are shared across the whole crawl.
+func (b *BrowserAgent) Crawl(startURL string, maxDepth int) *BrowseResult {
+	if maxDepth <= 0 {
+		maxDepth = b.cfg.MaxDepth
+	}
+	start := time.Now()
+	result := &BrowseResult{
+		TaskID:    fmt.Sprintf("crawl-%d", start.UnixNano()),
+		StartedAt: start,
+	}
+	origin, err := url.Parse(startURL)
+	if err != nil {
+		result.Error = err.Error()
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	type item struct {
+		url   string
+		depth int
+	}
+	sess := newSession("", "")
+	queue := []item{{url: stripFragment(startURL)}}
+	seen := map[string]bool{queue[0].url: true}
+
+	for len(queue) > 0 && len(result.Pages) < maxCrawlPages {
+		it := queue[0]
+		queue = queue[1:]
+
+		action := BrowseAction{Type: "navigate", Target: it.url, Timeout: b.cfg.Timeout}
+		res := b.run(sess, []BrowseAction{action})
+		result.Actions = append(result.Actions, action)
+		if !res.Success {
+			if it.depth == 0 {
+				result.Error = res.Error
+				result.Duration = time.Since(start)
+				return result
+			}
+			log.Debug().Str("url", it.url).Str("reason", res.Error).Msg("crawl: skipping link")
+			continue
+		}
+		result.Pages = append(result.Pages, res.Pages...)
+		if it.depth >= maxDepth {
+			continue
+		}
+		for _, page := range res.Pages {
+			links := page.Links
+			if len(links) == 0 {
+				links = ExtractLinks(page.Text)
+			}
+			for _, link := range links {
+				link = stripFragment(link)
+				if seen[link] || !sameOrigin(origin, link) {
+					continue
+				}
+				seen[link] = true
+				queue = append(queue, item{url: link, depth: it.depth + 1})
+			}
+		}
+	}
+
+	result.Success = true
+	result.Duration = time.Since(start)
+	return result
+}
+
+// sameOrigin reports whether link has the same scheme and host as origin.
+func sameOrigin(origin *url.URL, link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && u.Scheme == origin.Scheme && u.Host == origin.Host
+}
+
+// stripFragment drops the #fragment so in-page anchors count as one page.
+func stripFragment(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String()
+}
@@ -0,0 +1,42 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Driver executes browse actions against a real or simulated browser.
+// Implementations must send and store cookies through sess so that state
+// carries across the actions of a task sequence.
+// Execute returns the page content for navigate/extract actions and nil otherwise.
+type Driver interface {
+	Execute(ctx context.Context, action BrowseAction, sess *Session) (*PageContent, error)
+}
+
+// ChromePathEnv overrides Chrome/Chromium discovery with an explicit binary.
+const ChromePathEnv = "NEXUS_CHROME_PATH"
+
+// defaultDriver returns the simulation driver unless cfg.Chrome opts in to
+// driving a real browser, which needs a build with -tags chromedp.
+func defaultDriver(cfg BrowserConfig) Driver {
+	if cfg.Chrome {
+		return newChromeDriver(cfg)
+	}
+	return simulationDriver{}
+}
+
+// simulationDriver is the dry-run driver: it records navigations without
+// fetching anything. It is the default; see BrowserConfig.Chrome.
+type simulationDriver struct{}
+
+func (simulationDriver) Execute(_ context.Context, action BrowseAction, _ *Session) (*PageContent, error) {
+	if action.Type != "navigate" {
+		return nil, nil
+	}
+	return &PageContent{
+		URL:       action.Target,
+		FetchedAt: time.Now(),
+		Text:      fmt.Sprintf("[simulated fetch: %s]", action.Target),
+	}, nil
+}
@@ -0,0 +1,107 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The scripts below run inside the page driven by the Chrome driver. They
+// live outside the chromedp-tagged chrome.go so parseExtract is tested in
+// the default build.
+
+// pageExtract is the JSON shape returned by extractScript.
+type pageExtract struct {
+	URL       string            `json:"url"`
+	Title     string            `json:"title"`
+	Text      string            `json:"text"`
+	Links     []string          `json:"links"`
+	Tables    [][]string        `json:"tables"` // pre-split rows, used when tableHTML is absent
+	TableHTML []string          `json:"tableHTML"`
+	MetaDesc  string            `json:"meta"`
+	JSONLD    []string          `json:"jsonld"`
+	OpenGraph map[string]string `json:"og"`
+}
+
+// extractScript collects the PageContent fields in one round trip. Links
+// are resolved to absolute URLs and limited to http(s), as in ExtractLinks;
+// outermost tables are returned as HTML and laid out by ExtractTables.
+// JSON-LD blocks over maxJSONLDBytes are dropped in the page.
+const extractScript = `((sel) => {
+  const root = (sel && document.querySelector(sel)) || document.body || document.documentElement;
+  const links = Array.from(document.querySelectorAll("a[href]"))
+    .map(a => a.href)
+    .filter(h => h.startsWith("http://") || h.startsWith("https://"));
+  const tableHTML = Array.from(document.querySelectorAll("table:not(table table)"))
+    .map(t => t.outerHTML);
+  const meta = document.querySelector('meta[name="description"]');
+  const jsonld = Array.from(document.querySelectorAll('script[type="application/ld+json"]'))
+    .map(s => s.textContent)
+    .filter(t => t.length <= 65536);
+  const og = {};
+  document.querySelectorAll('meta[property^="og:"],meta[property^="article:"]').forEach(m => {
+    const p = m.getAttribute("property");
+    if (!(p in og)) og[p] = m.content;
+  });
+  return JSON.stringify({
+    url: location.href,
+    title: document.title,
+    text: root ? root.innerText : "",
+    links: links,
+    tableHTML: tableHTML,
+    meta: meta ? meta.content : "",
+    jsonld: jsonld,
+    og: og
+  });
+})(%s)`
+
+// parseExtract decodes the JSON produced by extractScript.
+func parseExtract(raw string) (*PageContent, error) {
+	var ex pageExtract
+	if err := json.Unmarshal([]byte(raw), &ex); err != nil {
+		return nil, fmt.Errorf("browser: extract: %w", err)
+	}
+	tables := ex.Tables
+	if len(ex.TableHTML) > 0 {
+		tables = ExtractTables(strings.Join(ex.TableHTML, "\n"))
+	}
+	return &PageContent{
+		URL:        ex.URL,
+		Title:      ex.Title,
+		Text:       ex.Text,
+		Links:      ex.Links,
+		Tables:     tables,
+		MetaDesc:   ex.MetaDesc,
+		Structured: structuredData(ex.JSONLD, ex.OpenGraph),
+		FetchedAt:  time.Now(),
+	}, nil
+}
+
+// clickScript clicks the first element matching selector.
+func clickScript(selector string) string {
+	return fmt.Sprintf(`(() => {
+  const el = document.querySelector(%s);
+  if (!el) throw new Error("no element matches " + %[1]s);
+  el.click();
+})()`, jsString(selector))
+}
+
+// fillScript sets the value of the first element matching selector and
+// fires the input and change events a typing user would.
+func fillScript(selector, value string) string {
+	return fmt.Sprintf(`(() => {
+  const el = document.querySelector(%s);
+  if (!el) throw new Error("no element matches " + %[1]s);
+  el.focus();
+  el.value = %s;
+  el.dispatchEvent(new Event("input", {bubbles: true}));
+  el.dispatchEvent(new Event("change", {bubbles: true}));
+})()`, jsString(selector), jsString(value))
+}
+
+// jsString quotes s as a JavaScript string literal.
+func jsString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
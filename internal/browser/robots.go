@@ -0,0 +1,285 @@
+package browser
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// robotsTTL is how long parsed rules are cached (RFC 9309 §2.4 caps it at 24h).
+	robotsTTL = 24 * time.Hour
+	// robotsErrorTTL caches "server error → disallow all" for a shorter time.
+	robotsErrorTTL = 10 * time.Minute
+	// robotsMaxBytes is the parse limit RFC 9309 §2.5 requires us to honour.
+	robotsMaxBytes = 500 << 10
+	robotsTimeout  = 10 * time.Second
+	// maxCrawlDelay bounds how long a hostile Crawl-delay can stall a task.
+	maxCrawlDelay = 30 * time.Second
+)
+
+// robotsRule is one Allow or Disallow line of the matching group.
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// robotsRules is the parsed robots.txt of one scheme://host for our agent.
+type robotsRules struct {
+	rules      []robotsRule
+	disallow   bool // server error: everything is off limits
+	crawlDelay time.Duration
+	expires    time.Time
+	lastVisit  time.Time
+}
+
+// robotsAgent returns the product token robots.txt groups are matched
+// against: "NEXUS-Agent/1.7 (...)" → "nexus-agent".
+func robotsAgent(userAgent string) string {
+	token := userAgent
+	if i := strings.IndexAny(token, "/ "); i >= 0 {
+		token = token[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(token))
+}
+
+// parseRobots extracts the rules of the groups addressed to agent, falling
+// back to the "*" group when none names it.
+func parseRobots(body, agent string) *robotsRules {
+	var (
+		own, star   robotsRules
+		ownSeen     bool
+		groupAgents []string
+		inRules     bool // a rule line ended the run of user-agent lines
+		matchOwn    bool
+		matchStar   bool
+	)
+	sc := bufio.NewScanner(strings.NewReader(body))
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			if inRules {
+				groupAgents, inRules = nil, false
+			}
+			groupAgents = append(groupAgents, strings.ToLower(value))
+			matchOwn, matchStar = false, false
+			for _, a := range groupAgents {
+				if a == "*" {
+					matchStar = true
+				} else if a != "" && strings.Contains(agent, a) {
+					matchOwn = true
+				}
+			}
+			ownSeen = ownSeen || matchOwn
+			continue
+		}
+		if len(groupAgents) == 0 {
+			continue // rule outside any group
+		}
+		inRules = true
+		var target *robotsRules
+		switch {
+		case matchOwn:
+			target = &own
+		case matchStar:
+			target = &star
+		default:
+			continue
+		}
+		switch key {
+		case "allow", "disallow":
+			if value == "" {
+				continue // "Disallow:" with no path allows everything
+			}
+			target.rules = append(target.rules, robotsRule{allow: key == "allow", pattern: value})
+		case "crawl-delay":
+			if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+				target.crawlDelay = time.Duration(secs * float64(time.Second))
+			}
+		}
+	}
+	if ownSeen {
+		return &own
+	}
+	return &star
+}
+
+// allowed applies RFC 9309 matching: the longest matching pattern wins and
+// Allow wins a tie. path includes the query string.
+func (r *robotsRules) allowed(path string) bool {
+	if r.disallow {
+		return false
+	}
+	if path == "" {
+		path = "/"
+	}
+	best, allow := -1, true
+	for _, rule := range r.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > best || n == best && rule.allow {
+			best, allow = n, rule.allow
+		}
+	}
+	return allow
+}
+
+// robotsMatch matches path against a robots pattern supporting the "*"
+// wildcard and a trailing "$" end anchor.
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	parts := strings.Split(strings.TrimSuffix(pattern, "$"), "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	if len(parts) == 1 {
+		return !anchored || rest == ""
+	}
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(rest, part)
+		}
+		j := strings.Index(rest, part)
+		if j < 0 {
+			return false
+		}
+		rest = rest[j+len(part):]
+	}
+	return true
+}
+
+// IsAllowedByRobots checks rawURL against the site's robots.txt for the
+// configured UserAgent. Rules are fetched once per scheme://host and cached.
+// Always true when RespectRobots is off.
+func (b *BrowserAgent) IsAllowedByRobots(rawURL string) (bool, string) {
+	if !b.cfg.RespectRobots {
+		return true, ""
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false, fmt.Sprintf("invalid URL: %v", err)
+	}
+	rules := b.robotsFor(u)
+	path := u.EscapedPath()
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	if !rules.allowed(path) {
+		if rules.disallow {
+			return false, "robots.txt unavailable (server error); assuming disallow"
+		}
+		return false, fmt.Sprintf("disallowed by %s://%s/robots.txt", u.Scheme, u.Host)
+	}
+	return true, ""
+}
+
+// robotsFor returns the cached rules for u's origin, fetching them if needed.
+func (b *BrowserAgent) robotsFor(u *url.URL) *robotsRules {
+	origin := u.Scheme + "://" + u.Host
+	b.mu.Lock()
+	rules, ok := b.robots[origin]
+	b.mu.Unlock()
+	if ok && time.Now().Before(rules.expires) {
+		return rules
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), robotsTimeout)
+	defer cancel()
+	status, body, err := b.fetchRobots(ctx, origin+"/robots.txt")
+	switch {
+	case err != nil:
+		// Unreachable host: the navigation itself will fail the same way, so
+		// there is nothing to protect. Retry on the next visit.
+		return &robotsRules{}
+	case status >= 500:
+		rules = &robotsRules{disallow: true, expires: time.Now().Add(robotsErrorTTL)}
+	case status >= 400:
+		rules = &robotsRules{expires: time.Now().Add(robotsTTL)} // no robots.txt: all allowed
+	default:
+		rules = parseRobots(body, robotsAgent(b.cfg.UserAgent))
+		rules.expires = time.Now().Add(robotsTTL)
+	}
+	b.mu.Lock()
+	if prev, ok := b.robots[origin]; ok {
+		rules.lastVisit = prev.lastVisit
+	}
+	b.robots[origin] = rules
+	b.mu.Unlock()
+	return rules
+}
+
+// waitCrawlDelay sleeps until the host's Crawl-delay has passed since the
+// previous navigation to it, then records this one.
+func (b *BrowserAgent) waitCrawlDelay(rawURL string) {
+	if !b.cfg.RespectRobots {
+		return
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return
+	}
+	origin := u.Scheme + "://" + u.Host
+	b.mu.Lock()
+	rules, ok := b.robots[origin]
+	if !ok {
+		b.mu.Unlock()
+		return
+	}
+	delay := rules.crawlDelay
+	if delay > maxCrawlDelay {
+		delay = maxCrawlDelay
+	}
+	wait := time.Until(rules.lastVisit.Add(delay))
+	if wait < 0 {
+		wait = 0
+	}
+	// Reserve the slot before sleeping so concurrent runs queue up behind us.
+	rules.lastVisit = time.Now().Add(wait)
+	b.mu.Unlock()
+	time.Sleep(wait)
+}
+
+// httpFetchRobots is the default robots.txt fetcher. It goes through the
+// same SSRF guards as page fetches.
+func (b *BrowserAgent) httpFetchRobots(ctx context.Context, robotsURL string) (int, string, error) {
+	client := &http.Client{
+		Transport:     &http.Transport{DialContext: b.SafeDialContext(&net.Dialer{Timeout: robotsTimeout})},
+		CheckRedirect: b.CheckRedirect,
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	if b.cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", b.cfg.UserAgent)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, robotsMaxBytes))
+	if err != nil {
+		return 0, "", err
+	}
+	return resp.StatusCode, string(data), nil
+}
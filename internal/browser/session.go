@@ -0,0 +1,254 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// sessionNameRe restricts session names to safe file names.
+var sessionNameRe = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// storedCookie is the on-disk form of a cookie, together with the URL that
+// set it so it can be replayed into a fresh jar. Values are only ever written
+// to the 0600 session file — never to logs (see Session.String).
+type storedCookie struct {
+	URL      string    `json:"url"`
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain,omitempty"`
+	Path     string    `json:"path,omitempty"`
+	Expires  time.Time `json:"expires,omitempty"`
+	Secure   bool      `json:"secure,omitempty"`
+	HTTPOnly bool      `json:"http_only,omitempty"`
+}
+
+// Session is a named cookie jar shared by every action of a task sequence,
+// so "log in, then scrape the dashboard" keeps its auth cookie. Named sessions
+// are persisted to disk and reused across Run invocations; the anonymous
+// session used by Run lives only for one call.
+//
+// Cookie matching is delegated to net/http/cookiejar with the public suffix
+// list, so a site cannot set cookies for "com" or "co.uk". Session records
+// every cookie the jar accepted and replays them on load.
+//
+// Session implements http.CookieJar.
+type Session struct {
+	Name    string
+	path    string // empty = in-memory only
+	mu      sync.Mutex
+	jar     *cookiejar.Jar
+	cookies map[string]storedCookie // domain|path|name → cookie
+}
+
+func newSession(name, path string) *Session {
+	// cookiejar.New never returns a non-nil error.
+	jar, _ := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	return &Session{Name: name, path: path, jar: jar, cookies: make(map[string]storedCookie)}
+}
+
+// loadSession reads a persisted session, returning an empty one if absent.
+func loadSession(name, path string) (*Session, error) {
+	s := newSession(name, path)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("browser: read session %q: %w", name, err)
+	}
+	var list []storedCookie
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("browser: corrupt session %q: %w", name, err)
+	}
+	for _, c := range list {
+		u, err := url.Parse(c.URL)
+		if err != nil {
+			continue
+		}
+		s.SetCookies(u, []*http.Cookie{{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Expires:  c.Expires,
+			Secure:   c.Secure,
+			HttpOnly: c.HTTPOnly,
+		}})
+	}
+	return s, nil
+}
+
+// SetCookies implements http.CookieJar.
+func (s *Session) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jar.SetCookies(u, cookies)
+
+	host := strings.ToLower(u.Hostname())
+	origin := (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String()
+	now := time.Now()
+	for _, c := range cookies {
+		sc := storedCookie{
+			URL:      origin,
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HttpOnly,
+		}
+		switch {
+		case c.MaxAge < 0:
+			sc.Expires = now.Add(-time.Second)
+		case c.MaxAge > 0:
+			sc.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		case !c.Expires.IsZero():
+			sc.Expires = c.Expires
+		}
+		domain := strings.TrimPrefix(strings.ToLower(c.Domain), ".")
+		if domain == "" {
+			domain = host
+		}
+		cookiePath := c.Path
+		if cookiePath == "" || !strings.HasPrefix(cookiePath, "/") {
+			cookiePath = defaultCookiePath(u.Path)
+		}
+		key := domain + "|" + cookiePath + "|" + c.Name
+		if !sc.Expires.IsZero() && !sc.Expires.After(now) {
+			delete(s.cookies, key)
+			continue
+		}
+		// Only persist what the jar kept, so a rejected cookie (say, one
+		// scoped to a public suffix) is not replayed on the next load.
+		if !s.jarHas(host, cookiePath, c.Name, c.Value) {
+			continue
+		}
+		s.cookies[key] = sc
+	}
+}
+
+// jarHas reports whether the jar would send the cookie name=value to a
+// secure request for host and path.
+func (s *Session) jarHas(host, path, name, value string) bool {
+	for _, c := range s.jar.Cookies(&url.URL{Scheme: "https", Host: host, Path: path}) {
+		if c.Name == name && c.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Cookies implements http.CookieJar.
+func (s *Session) Cookies(u *url.URL) []*http.Cookie {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jar.Cookies(u)
+}
+
+// Len returns the number of cookies recorded in the session.
+func (s *Session) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.cookies)
+}
+
+// String describes the session without revealing any cookie value.
+func (s *Session) String() string {
+	return fmt.Sprintf("browser.Session{name=%q cookies=%d}", s.Name, s.Len())
+}
+
+// GoString prevents cookie leakage via %#v.
+func (s *Session) GoString() string { return s.String() }
+
+// save persists the session (no-op for in-memory sessions).
+func (s *Session) save() error {
+	if s.path == "" {
+		return nil
+	}
+	s.mu.Lock()
+	list := make([]storedCookie, 0, len(s.cookies))
+	now := time.Now()
+	for _, c := range s.cookies {
+		// Session cookies (no expiry) are kept too: the whole point is to
+		// survive across Run invocations of the same logical session.
+		if c.Expires.IsZero() || c.Expires.After(now) {
+			list = append(list, c)
+		}
+	}
+	s.mu.Unlock()
+
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("browser: session mkdir: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("browser: write session %q: %w", s.Name, err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Session returns the named persistent session, loading it from
+// BrowserConfig.SessionDir on first use.
+func (b *BrowserAgent) Session(name string) (*Session, error) {
+	if !sessionNameRe.MatchString(name) {
+		return nil, fmt.Errorf("browser: invalid session name %q", name)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s, ok := b.sessions[name]; ok {
+		return s, nil
+	}
+	s, err := loadSession(name, b.sessionPath(name))
+	if err != nil {
+		return nil, err
+	}
+	b.sessions[name] = s
+	return s, nil
+}
+
+// ClearSession drops all cookies of the named session, in memory and on disk.
+func (b *BrowserAgent) ClearSession(name string) error {
+	if !sessionNameRe.MatchString(name) {
+		return fmt.Errorf("browser: invalid session name %q", name)
+	}
+	b.mu.Lock()
+	delete(b.sessions, name)
+	b.mu.Unlock()
+	if err := os.Remove(b.sessionPath(name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("browser: clear session %q: %w", name, err)
+	}
+	return nil
+}
+
+func (b *BrowserAgent) sessionPath(name string) string {
+	dir := b.cfg.SessionDir
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".nexus", "browser", "sessions")
+	}
+	return filepath.Join(dir, name+".json")
+}
+
+// defaultCookiePath implements the RFC 6265 default-path algorithm.
+func defaultCookiePath(p string) string {
+	i := strings.LastIndex(p, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return p[:i]
+}
@@ -0,0 +1,122 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// resolveTimeout bounds the DNS lookup IsAllowed performs per host.
+const resolveTimeout = 5 * time.Second
+
+// maxRedirects mirrors net/http's default redirect limit.
+const maxRedirects = 10
+
+// cgnat is 100.64.0.0/10 (RFC 6598), which also holds Alibaba Cloud's
+// 100.100.100.200 metadata endpoint. net.IP.IsPrivate does not cover it.
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// blockedIP explains why ip must not be fetched, or returns "" if it is a
+// public unicast address.
+func blockedIP(ip net.IP) string {
+	switch {
+	case ip.IsLoopback():
+		return "loopback address"
+	case ip.IsPrivate():
+		return "private address"
+	case ip.IsLinkLocalUnicast(), ip.IsLinkLocalMulticast():
+		return "link-local address"
+	case ip.IsUnspecified():
+		return "unspecified address"
+	case ip.IsMulticast(), ip.IsInterfaceLocalMulticast():
+		return "multicast address"
+	case cgnat.Contains(ip):
+		return "shared (CGNAT) address"
+	case ip.To4() != nil && ip.To4()[0] == 0:
+		return "this-network address"
+	}
+	return ""
+}
+
+// checkResolved resolves host and rejects it if any address it maps to is
+// internal, so a public name pointing at 169.254.169.254 is caught. Hosts
+// that fail to resolve are let through: they cannot be fetched either, and
+// SafeDialContext repeats the check against the address actually dialled.
+func (b *BrowserAgent) checkResolved(host string) (bool, string) {
+	if ip := net.ParseIP(host); ip != nil {
+		if why := blockedIP(ip); why != "" {
+			return false, fmt.Sprintf("host blocked: %s is a %s", host, why)
+		}
+		return true, ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	addrs, err := b.lookup(ctx, host)
+	if err != nil {
+		return true, ""
+	}
+	for _, a := range addrs {
+		if why := blockedIP(a.IP); why != "" {
+			return false, fmt.Sprintf("host blocked: %s resolves to %s (%s)", host, a.IP, why)
+		}
+	}
+	return true, ""
+}
+
+// CheckRedirect is an http.Client.CheckRedirect hook that applies the
+// navigation policy (scheme, blocklists, DNS check, allowlist) to every
+// redirect target.
+func (b *BrowserAgent) CheckRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("browser: stopped after %d redirects", maxRedirects)
+	}
+	if ok, reason := b.checkURL(req.URL); !ok {
+		return fmt.Errorf("browser: redirect to %s blocked — %s", req.URL, reason)
+	}
+	return nil
+}
+
+// SafeDialContext wraps dialer so connections are only made to public
+// addresses. It resolves the host itself and dials the vetted IP, closing
+// the DNS-rebinding gap between IsAllowed and the actual connection. Drivers
+// fetching over net/http should install it as the Transport's DialContext.
+func (b *BrowserAgent) SafeDialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		var addrs []net.IPAddr
+		if ip := net.ParseIP(host); ip != nil {
+			addrs = []net.IPAddr{{IP: ip}}
+		} else if addrs, err = b.lookup(ctx, host); err != nil {
+			return nil, err
+		}
+		for _, a := range addrs {
+			if why := blockedIP(a.IP); why != "" {
+				return nil, fmt.Errorf("browser: refusing to dial %s (%s): %s", host, a.IP, why)
+			}
+		}
+		if len(addrs) == 0 {
+			return nil, errors.New("browser: no addresses for " + host)
+		}
+		return dialer.DialContext(ctx, network, net.JoinHostPort(addrs[0].IP.String(), port))
+	}
+}
+
+// redirectedFrom reports whether the driver ended up on a different URL than
+// it was sent to.
+func redirectedFrom(target, final string) (*url.URL, bool) {
+	if final == "" || final == target {
+		return nil, false
+	}
+	u, err := url.Parse(final)
+	if err != nil {
+		return nil, false
+	}
+	return u, true
+}
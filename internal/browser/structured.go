@@ -0,0 +1,196 @@
+package browser
+
+import (
+	"encoding/json"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Limits on the JSON-LD parsed from one page. Larger blocks are skipped
+// unparsed so a hostile page cannot make the agent decode megabytes.
+const (
+	maxJSONLDBytes  = 64 << 10
+	maxJSONLDBlocks = 8
+)
+
+// Keys of PageContent.Structured.
+const (
+	StructuredJSONLD    = "jsonld"    // []any, one entry per parsed JSON-LD block
+	StructuredOpenGraph = "opengraph" // map[string]string of og:* and article:* tags
+	StructuredTitle     = "title"     // article headline
+	StructuredAuthor    = "author"    // article author(s), comma-separated
+	StructuredPublished = "published" // article publication date as given
+)
+
+// ExtractStructured collects the JSON-LD blocks and OpenGraph tags of page
+// HTML into the shape of PageContent.Structured. It returns nil if the page
+// has neither.
+func ExtractStructured(page string) map[string]any {
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return nil
+	}
+	var blocks []string
+	og := make(map[string]string)
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Script:
+				if isJSONLD(htmlAttr(n, "type")) && n.FirstChild != nil {
+					blocks = append(blocks, n.FirstChild.Data)
+				}
+			case atom.Meta:
+				if p := htmlAttr(n, "property"); isOpenGraph(p) {
+					if _, seen := og[p]; !seen {
+						og[p] = htmlAttr(n, "content")
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return structuredData(blocks, og)
+}
+
+func htmlAttr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func isJSONLD(typ string) bool {
+	typ, _, _ = strings.Cut(typ, ";")
+	return strings.EqualFold(strings.TrimSpace(typ), "application/ld+json")
+}
+
+func isOpenGraph(property string) bool {
+	return strings.HasPrefix(property, "og:") || strings.HasPrefix(property, "article:")
+}
+
+// structuredData parses the JSON-LD blocks and combines them with the
+// OpenGraph tags, adding the article title, author and publication date
+// when either source has them (JSON-LD first). Nil if there is nothing.
+func structuredData(blocks []string, og map[string]string) map[string]any {
+	var ld []any
+	for _, b := range blocks {
+		if len(ld) == maxJSONLDBlocks {
+			break
+		}
+		if len(b) > maxJSONLDBytes {
+			continue
+		}
+		var v any
+		if err := json.Unmarshal([]byte(b), &v); err != nil {
+			continue
+		}
+		ld = append(ld, v)
+	}
+	if len(ld) == 0 && len(og) == 0 {
+		return nil
+	}
+
+	out := make(map[string]any)
+	if len(ld) > 0 {
+		out[StructuredJSONLD] = ld
+	}
+	if len(og) > 0 {
+		out[StructuredOpenGraph] = og
+	}
+	var title, author, published string
+	if a := findArticle(ld); a != nil {
+		title = firstString(a["headline"], a["name"])
+		author = personNames(a["author"])
+		published = firstString(a["datePublished"], a["dateCreated"])
+	}
+	if title == "" {
+		title = og["og:title"]
+	}
+	if author == "" {
+		author = og["article:author"]
+	}
+	if published == "" {
+		published = og["article:published_time"]
+	}
+	for k, v := range map[string]string{StructuredTitle: title, StructuredAuthor: author, StructuredPublished: published} {
+		if v != "" {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// findArticle returns the first JSON-LD node typed as an article or blog
+// post, looking inside arrays and @graph containers.
+func findArticle(nodes []any) map[string]any {
+	for _, n := range nodes {
+		switch v := n.(type) {
+		case []any:
+			if a := findArticle(v); a != nil {
+				return a
+			}
+		case map[string]any:
+			if isArticleType(v["@type"]) {
+				return v
+			}
+			if g, ok := v["@graph"].([]any); ok {
+				if a := findArticle(g); a != nil {
+					return a
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func isArticleType(t any) bool {
+	switch v := t.(type) {
+	case string:
+		return strings.HasSuffix(v, "Article") || v == "BlogPosting" || v == "SocialMediaPosting"
+	case []any:
+		for _, x := range v {
+			if isArticleType(x) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// personNames renders a schema.org author: a name, a Person or
+// Organization object, or a list of either.
+func personNames(v any) string {
+	switch a := v.(type) {
+	case string:
+		return a
+	case map[string]any:
+		return firstString(a["name"])
+	case []any:
+		var names []string
+		for _, x := range a {
+			if n := personNames(x); n != "" {
+				names = append(names, n)
+			}
+		}
+		return strings.Join(names, ", ")
+	}
+	return ""
+}
+
+// firstString returns the first of vs that is a non-empty string.
+func firstString(vs ...any) string {
+	for _, v := range vs {
+		if s, ok := v.(string); ok && strings.TrimSpace(s) != "" {
+			return strings.TrimSpace(s)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,180 @@
+package browser
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HTML limits on spans; larger values are clamped as browsers do.
+const (
+	maxColspan = 1000
+	maxRowspan = 65534
+)
+
+// ExtractTables parses every <table> in page HTML into rows of cell text,
+// in document order, each table's rows following the previous table's. The
+// header row stays the first row of its table. A cell spanning several
+// columns or rows is repeated in each position it covers, and short rows
+// are padded with empty cells, so every row of a table has the same width.
+// A table nested in a cell is extracted after its enclosing table.
+func ExtractTables(page string) [][]string {
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return nil
+	}
+	var rows [][]string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Table {
+			rows = append(rows, tableGrid(n)...)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return rows
+}
+
+// spanned is a cell still covering rows below the one it started in.
+type spanned struct {
+	text string
+	left int
+}
+
+// tableGrid lays out the rows of one table, expanding colspan and rowspan.
+func tableGrid(table *html.Node) [][]string {
+	var grid [][]string
+	carry := make(map[int]spanned) // column → cell spanning down into it
+	width := 0
+	for _, tr := range tableRows(table) {
+		var row []string
+		col := 0
+		fillCarried := func() {
+			for {
+				s, ok := carry[col]
+				if !ok {
+					return
+				}
+				row = append(row, s.text)
+				if s.left--; s.left == 0 {
+					delete(carry, col)
+				} else {
+					carry[col] = s
+				}
+				col++
+			}
+		}
+		for c := tr.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || (c.DataAtom != atom.Td && c.DataAtom != atom.Th) {
+				continue
+			}
+			fillCarried()
+			text := cellText(c)
+			colspan := spanAttr(c, "colspan", 1, maxColspan)
+			rowspan := spanAttr(c, "rowspan", 0, maxRowspan)
+			if rowspan == 0 {
+				rowspan = maxRowspan // spans to the end of the table
+			}
+			for i := 0; i < colspan; i++ {
+				row = append(row, text)
+				if rowspan > 1 {
+					carry[col] = spanned{text: text, left: rowspan - 1}
+				}
+				col++
+			}
+		}
+		fillCarried()
+		// Cells spanning from above into columns past this row's last cell.
+		for len(carry) > 0 {
+			next := -1
+			for c := range carry {
+				if c >= col && (next < 0 || c < next) {
+					next = c
+				}
+			}
+			if next < 0 {
+				break
+			}
+			for col < next {
+				row = append(row, "")
+				col++
+			}
+			fillCarried()
+		}
+		width = max(width, len(row))
+		grid = append(grid, row)
+	}
+	for i, row := range grid {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		grid[i] = row
+	}
+	return grid
+}
+
+// tableRows returns the rows belonging to table itself (directly or via
+// thead/tbody/tfoot), not those of nested tables.
+func tableRows(table *html.Node) []*html.Node {
+	var rows []*html.Node
+	for c := table.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		switch c.DataAtom {
+		case atom.Tr:
+			rows = append(rows, c)
+		case atom.Thead, atom.Tbody, atom.Tfoot:
+			for r := c.FirstChild; r != nil; r = r.NextSibling {
+				if r.Type == html.ElementNode && r.DataAtom == atom.Tr {
+					rows = append(rows, r)
+				}
+			}
+		}
+	}
+	return rows
+}
+
+// spanAttr reads a colspan/rowspan attribute, clamped to [lo, hi]; a missing
+// or invalid value is 1.
+func spanAttr(n *html.Node, name string, lo, hi int) int {
+	for _, a := range n.Attr {
+		if a.Key != name {
+			continue
+		}
+		v, err := strconv.Atoi(strings.TrimSpace(a.Val))
+		if err != nil || v < lo {
+			return 1
+		}
+		return min(v, hi)
+	}
+	return 1
+}
+
+// cellText returns the visible text of a cell with whitespace collapsed.
+func cellText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			sb.WriteString(n.Data)
+		case n.Type == html.ElementNode && (n.DataAtom == atom.Script || n.DataAtom == atom.Style):
+			return
+		case n.Type == html.ElementNode && n.DataAtom == atom.Br:
+			sb.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if n.Type == html.ElementNode && (n.DataAtom == atom.P || n.DataAtom == atom.Div || n.DataAtom == atom.Li) {
+			sb.WriteString(" ")
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Omkar0612/nexus-ai/internal/audit"
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the agent decision audit log",
+	Long: `Query the audit log of agent decisions stored in ~/.nexus/audit.db.
+
+Examples:
+  nexus audit show --last 7d
+  nexus audit show --last 24h --risk high
+  nexus audit show --search timeout --agent email --format json
+  nexus audit show --last 30d --limit 0 --format csv > audit.csv`,
+}
+
+var auditShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show recent audit entries",
+	RunE:  runAuditShow,
+}
+
+func init() {
+	auditShowCmd.Flags().String("last", "", "Only entries from this far back (e.g. 24h, 7d, 2w) or since a date (2026-01-31)")
+	auditShowCmd.Flags().String("agent", "", "Filter by agent")
+	auditShowCmd.Flags().String("risk", "", "Filter by risk: low | medium | high")
+	auditShowCmd.Flags().String("search", "", "Free-text match on action, rationale and outcome")
+	auditShowCmd.Flags().Int("limit", 50, "Maximum entries to show (0 = all)")
+	auditShowCmd.Flags().String("format", "text", "Output format: text | json | csv | markdown")
+	auditShowCmd.Flags().String("data-dir", "", "NEXUS data directory (default: ~/.nexus)")
+	auditCmd.AddCommand(auditShowCmd)
+}
+
+func runAuditShow(cmd *cobra.Command, _ []string) error {
+	last, _ := cmd.Flags().GetString("last")
+	agent, _ := cmd.Flags().GetString("agent")
+	risk, _ := cmd.Flags().GetString("risk")
+	search, _ := cmd.Flags().GetString("search")
+	limit, _ := cmd.Flags().GetInt("limit")
+	format, _ := cmd.Flags().GetString("format")
+	dataDir, _ := cmd.Flags().GetString("data-dir")
+	user, _ := cmd.Flags().GetString("user")
+
+	q := audit.AuditQuery{
+		UserID:    user,
+		Agent:     agent,
+		Risk:      audit.RiskLevel(risk),
+		SearchStr: search,
+		Limit:     limit,
+	}
+	switch q.Risk {
+	case "", audit.RiskLow, audit.RiskMedium, audit.RiskHigh:
+	default:
+		return fmt.Errorf("unknown risk %q — choose: low, medium, high", risk)
+	}
+	if last != "" {
+		since, err := audit.ParseSince(last)
+		if err != nil {
+			return err
+		}
+		q.Since = since
+	}
+
+	l, err := audit.Open(dataDir)
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	defer l.Close()
+
+	var export func(audit.AuditQuery) ([]byte, error)
+	switch format {
+	case "text":
+	case "json":
+		export = l.ExportJSON
+	case "csv":
+		export = l.ExportCSV
+	case "markdown", "md":
+		export = l.ExportMarkdown
+	default:
+		return fmt.Errorf("unknown format %q — choose: text, json, csv, markdown", format)
+	}
+	if export != nil {
+		data, err := export(q)
+		if err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+		if format == "json" {
+			data = append(data, '\n')
+		}
+		_, err = cmd.OutOrStdout().Write(data)
+		return err
+	}
+	var entries []audit.AuditEntry
+	err = l.QueryStream(q, func(e audit.AuditEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), strings.TrimRight(audit.FormatReport(entries), "\n"))
+	return nil
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -52,34 +53,34 @@ func newCalAgent() *calendar.Agent {
 	return calendar.New(time.Local, calendar.NewGoogle(token, calID))
 }
 
-func runCalToday(_ *cobra.Command, _ []string) error {
+func runCalToday(cmd *cobra.Command, _ []string) error {
 	events, err := newCalAgent().Today(context.Background())
 	if err != nil {
 		return fmt.Errorf("calendar today: %w", err)
 	}
-	printEvents(events, "Today")
+	printEvents(cmd.OutOrStdout(), events, "Today")
 	return nil
 }
 
-func runCalTomorrow(_ *cobra.Command, _ []string) error {
+func runCalTomorrow(cmd *cobra.Command, _ []string) error {
 	events, err := newCalAgent().Tomorrow(context.Background())
 	if err != nil {
 		return fmt.Errorf("calendar tomorrow: %w", err)
 	}
-	printEvents(events, "Tomorrow")
+	printEvents(cmd.OutOrStdout(), events, "Tomorrow")
 	return nil
 }
 
-func runCalWeek(_ *cobra.Command, _ []string) error {
+func runCalWeek(cmd *cobra.Command, _ []string) error {
 	events, err := newCalAgent().Week(context.Background())
 	if err != nil {
 		return fmt.Errorf("calendar week: %w", err)
 	}
-	printEvents(events, "This Week")
+	printEvents(cmd.OutOrStdout(), events, "This Week")
 	return nil
 }
 
-func runCalConflicts(_ *cobra.Command, _ []string) error {
+func runCalConflicts(cmd *cobra.Command, _ []string) error {
 	a := newCalAgent()
 	events, err := a.Week(context.Background())
 	if err != nil {
@@ -87,12 +88,12 @@ func runCalConflicts(_ *cobra.Command, _ []string) error {
 	}
 	conflicts := a.DetectConflicts(events)
 	if len(conflicts) == 0 {
-		fmt.Println("\033[32m✅ No scheduling conflicts this week.\033[0m")
+		fmt.Fprintln(cmd.OutOrStdout(), "\033[32m✅ No scheduling conflicts this week.\033[0m")
 		return nil
 	}
-	fmt.Printf("\n\033[33m⚠️  %d conflict(s) detected:\033[0m\n\n", len(conflicts))
+	fmt.Fprintf(cmd.OutOrStdout(), "\n\033[33m⚠️  %d conflict(s) detected:\033[0m\n\n", len(conflicts))
 	for i, c := range conflicts {
-		fmt.Printf("  %d. \033[31m%s\033[0m overlaps with \033[31m%s\033[0m (%s)\n",
+		fmt.Fprintf(cmd.OutOrStdout(), "  %d. \033[31m%s\033[0m overlaps with \033[31m%s\033[0m (%s)\n",
 			i+1, c.EventA.Title, c.EventB.Title, c.Overlap)
 	}
 	return nil
@@ -105,45 +106,45 @@ func runCalFree(cmd *cobra.Command, _ []string) error {
 	if err != nil {
 		return fmt.Errorf("calendar free: %w", err)
 	}
-	fmt.Printf("\n\033[32m✅ Next free slot (%s):\033[0m %s\n", duration, slot.Format("Mon 02 Jan 15:04"))
+	fmt.Fprintf(cmd.OutOrStdout(), "\n\033[32m✅ Next free slot (%s):\033[0m %s\n", duration, slot.Format("Mon 02 Jan 15:04"))
 	return nil
 }
 
-func runCalDigest(_ *cobra.Command, _ []string) error {
+func runCalDigest(cmd *cobra.Command, _ []string) error {
 	a := newCalAgent()
 	events, err := a.Today(context.Background())
 	if err != nil {
 		return fmt.Errorf("calendar digest: %w", err)
 	}
 	lines := calendar.DigestLines(events, time.Local)
-	fmt.Println("\n\033[35m📅 Today's Digest\033[0m")
+	fmt.Fprintln(cmd.OutOrStdout(), "\n\033[35m📅 Today's Digest\033[0m")
 	if len(lines) == 0 {
-		fmt.Println("  No events today.")
+		fmt.Fprintln(cmd.OutOrStdout(), "  No events today.")
 		return nil
 	}
 	for _, l := range lines {
-		fmt.Println(" ", l)
+		fmt.Fprintln(cmd.OutOrStdout(), " ", l)
 	}
 	return nil
 }
 
-func printEvents(events []calendar.Event, label string) {
-	fmt.Printf("\n\033[35m📅 %s\033[0m\n", label)
+func printEvents(w io.Writer, events []calendar.Event, label string) {
+	fmt.Fprintf(w, "\n\033[35m📅 %s\033[0m\n", label)
 	if len(events) == 0 {
-		fmt.Println("  No events.")
+		fmt.Fprintln(w, "  No events.")
 		return
 	}
 	for _, e := range events {
 		if e.AllDay {
-			fmt.Printf("  📅  %s (all day)\n", e.Title)
+			fmt.Fprintf(w, "  📅  %s (all day)\n", e.Title)
 		} else {
-			fmt.Printf("  🕐  %s — %s  %s\n", e.Start.Format("15:04"), e.End.Format("15:04"), e.Title)
+			fmt.Fprintf(w, "  🕐  %s — %s  %s\n", e.Start.Format("15:04"), e.End.Format("15:04"), e.Title)
 		}
 		if e.Location != "" {
-			fmt.Printf("       📍 %s\n", e.Location)
+			fmt.Fprintf(w, "       📍 %s\n", e.Location)
 		}
 		if len(e.Attendees) > 0 {
-			fmt.Printf("       👥 %s\n", strings.Join(e.Attendees, ", "))
+			fmt.Fprintf(w, "       👥 %s\n", strings.Join(e.Attendees, ", "))
 		}
 	}
 }
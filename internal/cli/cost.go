@@ -0,0 +1,252 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/Omkar0612/nexus-ai/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+var costCmd = &cobra.Command{
+	Use:   "cost",
+	Short: "LLM spend and budget limits",
+	Long: `Show LLM token spend recorded in ~/.nexus/costs.db and the state of
+the daily and monthly budgets.
+
+Limits come from --daily-limit / --monthly-limit, falling back to the
+NEXUS_DAILY_BUDGET and NEXUS_MONTHLY_BUDGET environment variables (USD,
+0 = no limit).
+
+Subcommands:
+  today     Today's spend by provider and model
+  month     Spend per calendar month
+  status    Budget usage against the limits
+  reset     Lift the auto-pause after a budget breach
+  suggest   Suggest a cheaper model`,
+}
+
+func init() {
+	costCmd.PersistentFlags().Float64("daily-limit", 0, "Daily budget in USD (default $NEXUS_DAILY_BUDGET)")
+	costCmd.PersistentFlags().Float64("monthly-limit", 0, "Monthly budget in USD (default $NEXUS_MONTHLY_BUDGET)")
+	costCmd.PersistentFlags().String("data-dir", "", "NEXUS data directory (default: ~/.nexus)")
+}
+
+// budgetLimit returns the value of flag if set, otherwise the env variable.
+func budgetLimit(cmd *cobra.Command, flag, env string) (float64, error) {
+	if cmd.Flags().Changed(flag) {
+		v, _ := cmd.Flags().GetFloat64(flag)
+		return v, nil
+	}
+	s := os.Getenv(env)
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("cost: %s: %w", env, err)
+	}
+	return v, nil
+}
+
+// openCostTracker opens the cost database with the configured limits.
+func openCostTracker(cmd *cobra.Command) (*telemetry.CostTracker, error) {
+	daily, err := budgetLimit(cmd, "daily-limit", "NEXUS_DAILY_BUDGET")
+	if err != nil {
+		return nil, err
+	}
+	monthly, err := budgetLimit(cmd, "monthly-limit", "NEXUS_MONTHLY_BUDGET")
+	if err != nil {
+		return nil, err
+	}
+	if daily < 0 || monthly < 0 {
+		return nil, fmt.Errorf("cost: budget limits must not be negative")
+	}
+	dataDir, _ := cmd.Flags().GetString("data-dir")
+	ct, err := telemetry.New(dataDir, daily, monthly)
+	if err != nil {
+		return nil, fmt.Errorf("cost: %w", err)
+	}
+	return ct, nil
+}
+
+// -- today --
+
+var costTodayCmd = &cobra.Command{
+	Use:   "today",
+	Short: "Show today's spend by provider and model",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		user, _ := cmd.Flags().GetString("user")
+		ct, err := openCostTracker(cmd)
+		if err != nil {
+			return err
+		}
+		defer ct.Close()
+		report, err := ct.DailyReport(user)
+		if err != nil {
+			return fmt.Errorf("cost: %w", err)
+		}
+		fmt.Fprint(cmd.OutOrStdout(), report)
+		return nil
+	},
+}
+
+// -- month --
+
+var costMonthCmd = &cobra.Command{
+	Use:   "month",
+	Short: "Show spend per calendar month",
+	Example: `  nexus cost month
+  nexus cost month --months 12`,
+	Args: cobra.NoArgs,
+	RunE: runCostMonth,
+}
+
+func init() {
+	costMonthCmd.Flags().Int("months", 6, "Number of months to show, including the current one")
+}
+
+func runCostMonth(cmd *cobra.Command, _ []string) error {
+	user, _ := cmd.Flags().GetString("user")
+	months, _ := cmd.Flags().GetInt("months")
+	ct, err := openCostTracker(cmd)
+	if err != nil {
+		return err
+	}
+	defer ct.Close()
+	w := cmd.OutOrStdout()
+	points, err := ct.MonthlyTrend(user, months)
+	if err != nil {
+		return fmt.Errorf("cost: %w", err)
+	}
+	var total float64
+	for _, p := range points {
+		fmt.Fprintf(w, "  %s  $%.5f\n", p.Label, p.Value)
+		total += p.Value
+	}
+	fmt.Fprintf(w, "\nTotal: $%.5f over %d months\n", total, len(points))
+
+	status, err := ct.GetStatus(user)
+	if err != nil {
+		return fmt.Errorf("cost: %w", err)
+	}
+	if status.MonthlyLimit > 0 {
+		fmt.Fprintf(w, "This month: $%.5f / $%.2f (%.0f%%)\n", status.MonthlySpent, status.MonthlyLimit, status.MonthlyPct)
+	}
+	return nil
+}
+
+// -- status --
+
+var costStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show budget usage against the daily and monthly limits",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		user, _ := cmd.Flags().GetString("user")
+		ct, err := openCostTracker(cmd)
+		if err != nil {
+			return err
+		}
+		defer ct.Close()
+		status, err := ct.GetStatus(user)
+		if err != nil {
+			return fmt.Errorf("cost: %w", err)
+		}
+		printBudgetStatus(cmd.OutOrStdout(), status)
+		return nil
+	},
+}
+
+func printBudgetStatus(w io.Writer, s *telemetry.BudgetStatus) {
+	line := func(label string, spent, limit, pct float64) {
+		if limit > 0 {
+			fmt.Fprintf(w, "  %-8s $%.5f / $%.2f (%.0f%%)\n", label, spent, limit, pct)
+		} else {
+			fmt.Fprintf(w, "  %-8s $%.5f (no limit)\n", label, spent)
+		}
+	}
+	line("Daily", s.DailySpent, s.DailyLimit, s.DailyPct)
+	line("Monthly", s.MonthlySpent, s.MonthlyLimit, s.MonthlyPct)
+	switch {
+	case s.BudgetBreached:
+		fmt.Fprintln(w, "\n🚨 Budget breached — LLM calls are paused until `nexus cost reset`.")
+	case s.NearLimit:
+		fmt.Fprintln(w, "\n⚠️  Over 80% of a budget limit.")
+	default:
+		fmt.Fprintln(w, "\n✅ Within budget.")
+	}
+}
+
+// -- reset --
+
+var costResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Lift the auto-pause after a budget breach",
+	Long: `Lift the auto-pause that stops LLM calls once a budget limit is
+breached, including for a running daemon. Recorded spending is not erased,
+so the next call over the limit pauses calls again; raise the limit to
+keep going.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		user, _ := cmd.Flags().GetString("user")
+		ct, err := openCostTracker(cmd)
+		if err != nil {
+			return err
+		}
+		defer ct.Close()
+		if err := ct.ResetBudget(user); err != nil {
+			return fmt.Errorf("cost: %w", err)
+		}
+		status, err := ct.GetStatus(user)
+		if err != nil {
+			return fmt.Errorf("cost: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "⏯  Budget pause lifted.")
+		if status.BudgetBreached {
+			fmt.Fprintln(cmd.OutOrStdout(), "Spending is still over a limit — the next LLM call will pause again.")
+		}
+		return nil
+	},
+}
+
+// -- suggest --
+
+var costSuggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Suggest a cheaper model than the one configured",
+	Example: `  nexus cost suggest
+  nexus cost suggest --provider openai --model gpt-4o`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg := llmConfigFromEnv()
+		provider, _ := cmd.Flags().GetString("provider")
+		model, _ := cmd.Flags().GetString("model")
+		if provider == "" {
+			provider = cfg.Provider
+		}
+		if model == "" {
+			model = cfg.Model
+		}
+		if tip := telemetry.SuggestCheaperModel(provider, model); tip != "" {
+			fmt.Fprintln(cmd.OutOrStdout(), tip)
+			return nil
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s/%s is already free or among the cheapest options.\n", provider, model)
+		return nil
+	},
+}
+
+func init() {
+	costSuggestCmd.Flags().String("provider", "", "LLM provider (default $NEXUS_LLM_PROVIDER)")
+	costSuggestCmd.Flags().String("model", "", "Model name (default $NEXUS_LLM_MODEL)")
+
+	costCmd.AddCommand(costTodayCmd)
+	costCmd.AddCommand(costMonthCmd)
+	costCmd.AddCommand(costStatusCmd)
+	costCmd.AddCommand(costResetCmd)
+	costCmd.AddCommand(costSuggestCmd)
+}
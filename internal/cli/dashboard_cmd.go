@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Omkar0612/nexus-ai/internal/dashboard"
+	"github.com/Omkar0612/nexus-ai/internal/goals"
+	"github.com/spf13/cobra"
+)
+
+var dashboardCmd = &cobra.Command{
+	Use:   "dashboard",
+	Short: "Serve the analytics dashboard",
+	Long: `Serve the analytics dashboard and its JSON, SSE and Prometheus endpoints.
+
+The dashboard listens on 127.0.0.1 only unless --bind says otherwise. Set
+NEXUS_DASHBOARD_TOKEN to require that token (bearer or basic-auth password).
+
+Examples:
+  nexus dashboard
+  NEXUS_DASHBOARD_TOKEN=s3cret nexus dashboard --bind 0.0.0.0 --port 8080`,
+	RunE: runDashboard,
+}
+
+func init() {
+	dashboardCmd.Flags().Int("port", 8080, "Port to listen on")
+	dashboardCmd.Flags().String("bind", "127.0.0.1", "Interface to listen on (0.0.0.0 for all)")
+}
+
+func runDashboard(cmd *cobra.Command, _ []string) error {
+	port, _ := cmd.Flags().GetInt("port")
+	bind, _ := cmd.Flags().GetString("bind")
+	store, err := dashboard.OpenMetricStore("", 30*24*time.Hour, 0)
+	if err != nil {
+		return fmt.Errorf("dashboard: %w", err)
+	}
+	defer store.Close()
+	goalStore, err := goals.Open("")
+	if err != nil {
+		return fmt.Errorf("dashboard: %w", err)
+	}
+	defer goalStore.Close()
+	return dashboard.New(port,
+		dashboard.WithBind(bind),
+		dashboard.WithMetricStore(store),
+		dashboard.WithGoals(goalStore),
+	).Serve()
+}
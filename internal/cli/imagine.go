@@ -18,7 +18,9 @@ var imagineCmd = &cobra.Command{
 Examples:
   nexus imagine "a futuristic Dubai skyline at sunset"
   nexus imagine --backend together "minimalist logo, purple gradient"
-  nexus imagine --output ./cover.png --width 1024 --height 768 "epic mountain landscape"`,
+  nexus imagine --output ./cover.png --width 1024 --height 768 "epic mountain landscape"
+  nexus imagine --input ./cover.png --strength 0.5 "make the sky bluer"
+  nexus imagine --input ./cover.png --mask ./sky-mask.png "a sky full of stars"`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runImagine,
 }
@@ -30,6 +32,9 @@ func init() {
 	imagineCmd.Flags().Int("height", 512, "Image height in pixels")
 	imagineCmd.Flags().Int("steps", 20, "Diffusion steps (more = better quality, slower)")
 	imagineCmd.Flags().String("negative", "", "Negative prompt")
+	imagineCmd.Flags().String("input", "", "Source image for img2img (Stable Diffusion only)")
+	imagineCmd.Flags().String("mask", "", "Inpaint mask for --input: white = repaint, black = keep")
+	imagineCmd.Flags().Float64("strength", 0.75, "img2img/inpaint denoising strength (0-1)")
 	imagineCmd.Flags().String("sd-url", "http://127.0.0.1:7860", "Stable Diffusion API URL")
 	imagineCmd.Flags().String("api-key", "", "API key for Together/Replicate backends")
 	imagineCmd.Flags().String("model", "black-forest-labs/FLUX.1-schnell-Free", "Model name (Together/Replicate)")
@@ -44,6 +49,9 @@ func runImagine(cmd *cobra.Command, args []string) error {
 	height, _ := cmd.Flags().GetInt("height")
 	steps, _ := cmd.Flags().GetInt("steps")
 	negative, _ := cmd.Flags().GetString("negative")
+	input, _ := cmd.Flags().GetString("input")
+	mask, _ := cmd.Flags().GetString("mask")
+	strength, _ := cmd.Flags().GetFloat64("strength")
 	sdURL, _ := cmd.Flags().GetString("sd-url")
 	apiKey, _ := cmd.Flags().GetString("api-key")
 	model, _ := cmd.Flags().GetString("model")
@@ -73,6 +81,14 @@ func runImagine(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("unknown backend %q — choose: stablediffusion, together, replicate", backend)
 	}
 
+	mode := imagegen.ModeTxt2Img
+	switch {
+	case mask != "":
+		mode = imagegen.ModeInpaint
+	case input != "":
+		mode = imagegen.ModeImg2Img
+	}
+
 	agent := imagegen.New(opts...)
 	log.Debug().Str("backend", backend).Str("prompt", prompt).Msg("Generating image...")
 	result, err := agent.Generate(cmd.Context(), imagegen.Request{
@@ -81,7 +97,12 @@ func runImagine(cmd *cobra.Command, args []string) error {
 		Width:          width,
 		Height:         height,
 		Steps:          steps,
+		Mode:           mode,
+		InputImagePath: input,
+		MaskPath:       mask,
+		Strength:       strength,
 		OutputPath:     output,
+		DownloadResult: true,
 	})
 	if err != nil {
 		return fmt.Errorf("imagine: %w", err)
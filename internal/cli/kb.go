@@ -0,0 +1,235 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Omkar0612/nexus-ai/internal/kb"
+	"github.com/spf13/cobra"
+)
+
+// kbIndexTimeout bounds how long a command waits for the initial index.
+const kbIndexTimeout = 2 * time.Minute
+
+var kbCmd = &cobra.Command{
+	Use:   "kb",
+	Short: "Knowledge base — search your own files (local RAG)",
+	Long: `Search and manage the local knowledge base. Files in the KB directory
+(default ~/.nexus/kb) are indexed with TF-IDF; no embedding API is needed.
+
+Subcommands:
+  search    Rank chunks matching a query
+  add       Copy a file into the knowledge base
+  stats     Show what is indexed
+  reindex   Rebuild the index and report unreadable files`,
+}
+
+func init() {
+	kbCmd.PersistentFlags().String("dir", "", "Knowledge base directory (default ~/.nexus/kb)")
+}
+
+// openKB opens the knowledge base and waits for the initial index.
+func openKB(cmd *cobra.Command) (*kb.KnowledgeBase, error) {
+	dir, _ := cmd.Flags().GetString("dir")
+	base, err := kb.New(dir)
+	if err != nil {
+		return nil, fmt.Errorf("kb: %w", err)
+	}
+	if !base.WaitReady(kbIndexTimeout) {
+		return nil, fmt.Errorf("kb: indexing still running after %s (%s)", kbIndexTimeout, base.IndexingState())
+	}
+	return base, nil
+}
+
+// -- search --
+
+var kbSearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search the knowledge base",
+	Example: `  nexus kb search "vault key rotation" --top 5
+  nexus kb search "deploy checklist" --tags ops,runbook`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runKBSearch,
+}
+
+func init() {
+	kbSearchCmd.Flags().Int("top", 5, "Number of results")
+	kbSearchCmd.Flags().StringSlice("tags", nil, "Only search documents with one of these tags")
+}
+
+func runKBSearch(cmd *cobra.Command, args []string) error {
+	top, _ := cmd.Flags().GetInt("top")
+	tags, _ := cmd.Flags().GetStringSlice("tags")
+	base, err := openKB(cmd)
+	if err != nil {
+		return err
+	}
+	query := strings.Join(args, " ")
+	results := base.SearchTagged(query, tags, top)
+	if len(results) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "No matches for %q.\n", query)
+		return nil
+	}
+	for i, r := range results {
+		fmt.Fprintf(cmd.OutOrStdout(), "\n\033[35m%d. %s\033[0m  (score %.3f)\n", i+1, r.DocTitle, r.Score)
+		fmt.Fprintf(cmd.OutOrStdout(), "   %s\n", r.DocPath)
+		fmt.Fprintf(cmd.OutOrStdout(), "   %s\n", snippet(r.Chunk.Text, query, 200))
+	}
+	return nil
+}
+
+// snippet returns about width characters of text around the first query
+// word it contains, on one line.
+func snippet(text, query string, width int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) <= width {
+		return text
+	}
+	start := 0
+	lower := strings.ToLower(text)
+	for _, w := range strings.Fields(strings.ToLower(query)) {
+		if i := strings.Index(lower, w); i >= 0 {
+			start = i - width/4
+			break
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	if start+width > len(text) {
+		start = len(text) - width
+	}
+	// Avoid splitting a UTF-8 sequence.
+	for start > 0 && text[start]&0xC0 == 0x80 {
+		start--
+	}
+	end := start + width
+	for end < len(text) && text[end]&0xC0 == 0x80 {
+		end++
+	}
+	out := text[start:end]
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(text) {
+		out += "…"
+	}
+	return out
+}
+
+// -- add --
+
+var kbAddCmd = &cobra.Command{
+	Use:   "add <file>",
+	Short: "Copy a file into the knowledge base",
+	Example: `  nexus kb add ~/notes/runbook.md --tags ops,runbook
+  nexus kb add spec.pdf`,
+	Args: cobra.ExactArgs(1),
+	RunE: runKBAdd,
+}
+
+func init() {
+	kbAddCmd.Flags().StringSlice("tags", nil, "Tags for scoped search (written to <file>.tags)")
+	kbAddCmd.Flags().Bool("force", false, "Overwrite a file of the same name")
+}
+
+func runKBAdd(cmd *cobra.Command, args []string) error {
+	tags, _ := cmd.Flags().GetStringSlice("tags")
+	force, _ := cmd.Flags().GetBool("force")
+	base, err := openKB(cmd)
+	if err != nil {
+		return err
+	}
+	dir, _ := cmd.Flags().GetString("dir")
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".nexus", "kb")
+	}
+	dst := filepath.Join(dir, filepath.Base(args[0]))
+	if _, err := os.Stat(dst); err == nil && !force {
+		return fmt.Errorf("kb add: %s already exists (use --force to overwrite)", dst)
+	}
+	if err := copyFile(args[0], dst); err != nil {
+		return fmt.Errorf("kb add: %w", err)
+	}
+	if len(tags) > 0 {
+		if err := os.WriteFile(dst+".tags", []byte(strings.Join(tags, ", ")+"\n"), 0o600); err != nil {
+			return fmt.Errorf("kb add: write tags: %w", err)
+		}
+	}
+	if err := base.IndexFile(dst); err != nil {
+		os.Remove(dst)
+		os.Remove(dst + ".tags")
+		return fmt.Errorf("kb add: %s cannot be indexed: %w", args[0], err)
+	}
+	fmt.Printf("\033[32m✅ Added:\033[0m %s\n", dst)
+	if len(tags) > 0 {
+		fmt.Printf("   Tags : %s\n", strings.Join(tags, ", "))
+	}
+	fmt.Println(base.Stats())
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// -- stats --
+
+var kbStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show knowledge base statistics",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		base, err := openKB(cmd)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), base.Stats())
+		return nil
+	},
+}
+
+// -- reindex --
+
+var kbReindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild the index and report files that could not be read",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		base, err := openKB(cmd)
+		if err != nil {
+			return err
+		}
+		st := base.IndexingState()
+		fmt.Printf("\033[32m✅ Reindexed\033[0m %d files in %s\n",
+			st.Done, st.FinishedAt.Sub(st.StartedAt).Round(time.Millisecond))
+		if st.Errors > 0 {
+			fmt.Printf("   \033[33m⚠️  %d file(s) skipped — see messages above\033[0m\n", st.Errors)
+		}
+		fmt.Println(base.Stats())
+		return nil
+	},
+}
+
+func init() {
+	kbCmd.AddCommand(kbSearchCmd)
+	kbCmd.AddCommand(kbAddCmd)
+	kbCmd.AddCommand(kbStatsCmd)
+	kbCmd.AddCommand(kbReindexCmd)
+}
@@ -57,9 +57,10 @@ func runMusic(cmd *cobra.Command, args []string) error {
 
 	log.Debug().Str("backend", backend).Str("prompt", prompt).Dur("duration", duration).Msg("Generating music...")
 	result, err := music.New(opts...).Generate(cmd.Context(), music.Request{
-		Prompt:     prompt,
-		Duration:   duration,
-		OutputPath: out,
+		Prompt:         prompt,
+		Duration:       duration,
+		OutputPath:     out,
+		DownloadResult: true,
 	})
 	if err != nil {
 		return fmt.Errorf("music: %w", err)
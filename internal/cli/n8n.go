@@ -0,0 +1,295 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Omkar0612/nexus-ai/internal/n8n"
+	"github.com/Omkar0612/nexus-ai/internal/router"
+	"github.com/spf13/cobra"
+)
+
+var n8nCmd = &cobra.Command{
+	Use:   "n8n",
+	Short: "Workflow compiler — natural language to n8n workflows",
+	Long: `Compile plain-English automations into n8n workflows, export them for
+import into n8n, or run them locally.
+
+Subcommands:
+  compile   Compile a description into a workflow and save it
+  list      List saved workflows
+  export    Print a saved workflow as importable n8n JSON
+  run       Execute a saved workflow locally`,
+}
+
+// -- compile --
+
+var n8nCompileCmd = &cobra.Command{
+	Use:   "compile <description>",
+	Short: "Compile a natural-language description into a workflow",
+	Example: `  nexus n8n compile "Every morning, fetch GitHub issues and post a summary to Slack"
+  nexus n8n compile "When a webhook fires, call our API" --out workflow.json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runN8NCompile,
+}
+
+func init() {
+	n8nCompileCmd.Flags().String("out", "", "Save workflow JSON to file")
+}
+
+func runN8NCompile(cmd *cobra.Command, args []string) error {
+	out, _ := cmd.Flags().GetString("out")
+	store, err := n8n.OpenStore("")
+	if err != nil {
+		return err
+	}
+	compiler := n8n.NewCompiler(routerLLM{router.New(llmConfigFromEnv())})
+	wf, err := compiler.Compile(cmd.Context(), strings.Join(args, " "))
+	if err != nil {
+		return fmt.Errorf("n8n compile: %w", err)
+	}
+	id, err := store.Save(wf)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(wf, "", "  ")
+	if err != nil {
+		return fmt.Errorf("n8n compile: %w", err)
+	}
+	if err := writeOutput(string(data), out); err != nil {
+		return err
+	}
+	fmt.Printf("\n\033[32m✅ Workflow saved:\033[0m %s (%d nodes)\n", id, len(wf.Nodes))
+	fmt.Printf("   Export : nexus n8n export %s --format n8n\n", id)
+	fmt.Printf("   Run    : nexus n8n run %s\n", id)
+	return nil
+}
+
+// -- list --
+
+var n8nListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved workflows",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		store, err := n8n.OpenStore("")
+		if err != nil {
+			return err
+		}
+		list, err := store.List()
+		if err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No saved workflows. Create one with: nexus n8n compile \"<description>\"")
+			return nil
+		}
+		for _, w := range list {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %-32s %-30s %2d nodes  %s\n", w.ID, w.Name, w.Nodes, w.SavedAt.Format("2006-01-02 15:04"))
+		}
+		return nil
+	},
+}
+
+// -- export --
+
+var n8nExportCmd = &cobra.Command{
+	Use:   "export <id>",
+	Short: "Print a saved workflow as JSON",
+	Example: `  nexus n8n export daily-digest-3f9a --format n8n > daily-digest.json
+  nexus n8n export daily-digest-3f9a --format n8n --out daily-digest.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runN8NExport,
+}
+
+func init() {
+	n8nExportCmd.Flags().String("format", "n8n", "Format: n8n (importable) | nexus (as compiled)")
+	n8nExportCmd.Flags().String("out", "", "Save output to file")
+}
+
+func runN8NExport(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	out, _ := cmd.Flags().GetString("out")
+	var text string
+	switch format {
+	case "n8n":
+		var err error
+		if text, err = n8n.ExportN8N(args[0]); err != nil {
+			return fmt.Errorf("n8n export: %w", err)
+		}
+	case "nexus":
+		store, err := n8n.OpenStore("")
+		if err != nil {
+			return err
+		}
+		wf, err := store.Load(args[0])
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(wf, "", "  ")
+		if err != nil {
+			return fmt.Errorf("n8n export: %w", err)
+		}
+		text = string(data)
+	default:
+		return fmt.Errorf("n8n export: unknown format %q (use n8n or nexus)", format)
+	}
+	return writeOutput(text, out)
+}
+
+// -- run --
+
+var n8nRunCmd = &cobra.Command{
+	Use:   "run <id>",
+	Short: "Execute a saved workflow locally",
+	Long: `Execute a saved workflow with the local executor. Trigger nodes pass
+--data through, HTTP Request nodes make real requests, and IF / Merge nodes
+are evaluated by the executor.`,
+	Example: `  nexus n8n run daily-digest-3f9a
+  nexus n8n run alert-on-error-0c1d --data '{"value": 3}'`,
+	Args: cobra.ExactArgs(1),
+	RunE: runN8NRun,
+}
+
+func init() {
+	n8nRunCmd.Flags().String("data", "", "Execution data as a JSON object")
+	n8nRunCmd.Flags().Duration("timeout", 5*time.Minute, "Overall execution timeout")
+}
+
+func runN8NRun(cmd *cobra.Command, args []string) error {
+	dataStr, _ := cmd.Flags().GetString("data")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	store, err := n8n.OpenStore("")
+	if err != nil {
+		return err
+	}
+	wf, err := store.Load(args[0])
+	if err != nil {
+		return err
+	}
+	data := map[string]interface{}{}
+	if dataStr != "" {
+		if err := json.Unmarshal([]byte(dataStr), &data); err != nil {
+			return fmt.Errorf("n8n run: --data must be a JSON object: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+	exec, runErr := newN8NExecutor().Execute(ctx, wf, data)
+
+	fmt.Printf("\n\033[35m⚙️  %s\033[0m\n", exec.WorkflowName)
+	for _, name := range exec.Order {
+		r := exec.NodeResults[name]
+		icon := "✅"
+		switch r.Status {
+		case n8n.StatusFailed:
+			icon = "❌"
+		case n8n.StatusSkipped:
+			icon = "⏭️ "
+		}
+		fmt.Printf("  %s %-30s %s\n", icon, name, r.Duration.Round(time.Millisecond))
+		if r.Error != "" {
+			fmt.Printf("       %s\n", r.Error)
+		}
+	}
+	if runErr != nil {
+		return fmt.Errorf("n8n run: %w", runErr)
+	}
+	fmt.Printf("\n\033[32m✅ Completed\033[0m in %s\n", exec.FinishedAt.Sub(exec.StartedAt).Round(time.Millisecond))
+	return nil
+}
+
+// newN8NExecutor returns an executor with handlers for the node types the
+// compiler emits most: triggers and HTTP requests.
+func newN8NExecutor() *n8n.Executor {
+	e := n8n.NewExecutor()
+	passThrough := func(_ context.Context, _ n8n.Node, input, data map[string]interface{}) (map[string]interface{}, error) {
+		out := make(map[string]interface{}, len(data)+len(input))
+		for k, v := range data {
+			out[k] = v
+		}
+		for k, v := range input {
+			out[k] = v
+		}
+		return out, nil
+	}
+	for _, t := range []string{
+		"n8n-nodes-base.manualTrigger", "n8n-nodes-base.scheduleTrigger",
+		"n8n-nodes-base.cron", "n8n-nodes-base.webhook", "n8n-nodes-base.noOp",
+	} {
+		e.RegisterHandler(t, passThrough)
+	}
+	e.RegisterHandler("n8n-nodes-base.httpRequest", httpRequestNode)
+	return e
+}
+
+// httpRequestNode performs an n8n HTTP Request node ("url", "method" and
+// optional "body" parameters).
+func httpRequestNode(ctx context.Context, node n8n.Node, _, _ map[string]interface{}) (map[string]interface{}, error) {
+	url, _ := node.Parameters["url"].(string)
+	if url == "" {
+		return nil, fmt.Errorf("missing url parameter")
+	}
+	method, _ := node.Parameters["method"].(string)
+	if method == "" {
+		method = http.MethodGet
+	}
+	var body io.Reader
+	if b, ok := node.Parameters["body"]; ok {
+		data, err := json.Marshal(b)
+		if err != nil {
+			return nil, err
+		}
+		body = strings.NewReader(string(data))
+	}
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), url, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]interface{}{"statusCode": resp.StatusCode}
+	var parsed interface{}
+	if json.Unmarshal(raw, &parsed) == nil {
+		out["body"] = parsed
+	} else {
+		out["body"] = string(raw)
+	}
+	if resp.StatusCode >= 400 {
+		return out, fmt.Errorf("HTTP %s", resp.Status)
+	}
+	return out, nil
+}
+
+// routerLLM adapts the router to the Generate-style LLMClient interfaces.
+type routerLLM struct{ r *router.Router }
+
+func (l routerLLM) Generate(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	res, err := l.r.Complete(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return "", err
+	}
+	return res.Content, nil
+}
+
+func init() {
+	n8nCmd.AddCommand(n8nCompileCmd)
+	n8nCmd.AddCommand(n8nListCmd)
+	n8nCmd.AddCommand(n8nExportCmd)
+	n8nCmd.AddCommand(n8nRunCmd)
+}
@@ -26,6 +26,10 @@ Creative Studio (v1.7):
 Agents:
   nexus calendar   — Calendar agent (today, week, conflicts, free slots)
   nexus skills     — Plugin registry (list, run)
+  nexus audit      — Agent decision audit log (show --last 7d)
+  nexus n8n        — Compile, export and run n8n workflows
+  nexus kb         — Knowledge base search over your own files
+  nexus cost       — LLM spend, budget status and cheaper-model tips
 
 Run 'nexus <command> --help' for details on each command.`,
 }
@@ -38,6 +42,10 @@ func Execute() {
 	}
 }
 
+// Root returns the nexus command tree, e.g. for the Telegram companion to
+// dispatch chat messages as CLI commands.
+func Root() *cobra.Command { return rootCmd }
+
 func init() {
 	// Core
 	rootCmd.AddCommand(startCmd)
@@ -52,6 +60,11 @@ func init() {
 	// Agents
 	rootCmd.AddCommand(calendarCmd)
 	rootCmd.AddCommand(skillsCmd)
+	rootCmd.AddCommand(auditCmd)
+	rootCmd.AddCommand(dashboardCmd)
+	rootCmd.AddCommand(n8nCmd)
+	rootCmd.AddCommand(kbCmd)
+	rootCmd.AddCommand(costCmd)
 
 	// Global flags
 	rootCmd.PersistentFlags().StringP("config", "c", "", "Config file (default: ~/.nexus/nexus.toml)")
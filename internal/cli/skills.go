@@ -57,16 +57,16 @@ func init() {
 	))
 }
 
-func runSkillsList(_ *cobra.Command, _ []string) error {
+func runSkillsList(cmd *cobra.Command, _ []string) error {
 	entries := globalRegistry.List()
 	if len(entries) == 0 {
-		fmt.Println("No skills registered. See CONTRIBUTING.md to build your own.")
+		fmt.Fprintln(cmd.OutOrStdout(), "No skills registered. See CONTRIBUTING.md to build your own.")
 		return nil
 	}
-	fmt.Println("\n\033[35mRegistered Skills\033[0m")
-	fmt.Println(strings.Repeat("-", 50))
+	fmt.Fprintln(cmd.OutOrStdout(), "\n\033[35mRegistered Skills\033[0m")
+	fmt.Fprintln(cmd.OutOrStdout(), strings.Repeat("-", 50))
 	for _, entry := range entries {
-		fmt.Println(" ", entry)
+		fmt.Fprintln(cmd.OutOrStdout(), " ", entry)
 	}
 	return nil
 }
@@ -18,7 +18,9 @@ var speakCmd = &cobra.Command{
 Examples:
   nexus speak "Good morning, your briefing is ready"
   nexus speak --backend coqui --out briefing.wav "3 tasks today"
-  nexus speak --backend elevenlabs "Meeting in 10 minutes"`,
+  nexus speak --backend elevenlabs "Meeting in 10 minutes"
+  nexus speak --backend elevenlabs --stream --out chapter.mp3 "$(cat chapter.txt)"
+  nexus speak --speed 0.9 --ssml '<speak>Chapter one.<break time="1s"/>It begins.</speak>'`,
 	Args:  cobra.MinimumNArgs(1),
 	RunE:  runSpeak,
 }
@@ -27,8 +29,17 @@ func init() {
 	speakCmd.Flags().String("backend", "system", "Backend: system | coqui | elevenlabs")
 	speakCmd.Flags().String("out", "", "Output audio file path (WAV/MP3)")
 	speakCmd.Flags().String("voice", "", "Voice ID or name (provider-specific)")
+	speakCmd.Flags().Float64("speed", 1.0, "Speaking rate multiplier (0.5–2.0)")
+	speakCmd.Flags().Float64("pitch", 1.0, "Pitch multiplier (0.5–2.0, system TTS only)")
+	speakCmd.Flags().Bool("ssml", false, "Treat the text as SSML markup")
+	speakCmd.Flags().Bool("stream", false, "Start playback while audio is still being synthesized (needs mpv or ffplay)")
 	speakCmd.Flags().String("coqui-url", "http://localhost:5002", "Coqui TTS server URL")
 	speakCmd.Flags().String("api-key", "", "API key for ElevenLabs")
+	speakCmd.Flags().String("cache-dir", "", "Audio cache directory (default: ~/.nexus/tts-cache)")
+	speakCmd.Flags().Int64("cache-max-mb", 100, "Audio cache size cap in MB")
+	speakCmd.Flags().Bool("no-cache", false, "Always re-synthesize, bypassing the audio cache")
+	speakCmd.Flags().Int("char-budget", tts.DefaultElevenLabsChars, "ElevenLabs monthly character budget (0 = unmetered)")
+	speakCmd.Flags().Bool("budget-fallback", false, "Use system TTS instead of failing once the character budget is spent")
 }
 
 func runSpeak(cmd *cobra.Command, args []string) error {
@@ -37,8 +48,17 @@ func runSpeak(cmd *cobra.Command, args []string) error {
 	backend, _ := cmd.Flags().GetString("backend")
 	out, _ := cmd.Flags().GetString("out")
 	voice, _ := cmd.Flags().GetString("voice")
+	speed, _ := cmd.Flags().GetFloat64("speed")
+	pitch, _ := cmd.Flags().GetFloat64("pitch")
+	ssml, _ := cmd.Flags().GetBool("ssml")
+	stream, _ := cmd.Flags().GetBool("stream")
 	coquiURL, _ := cmd.Flags().GetString("coqui-url")
 	apiKey, _ := cmd.Flags().GetString("api-key")
+	cacheDir, _ := cmd.Flags().GetString("cache-dir")
+	cacheMaxMB, _ := cmd.Flags().GetInt64("cache-max-mb")
+	noCache, _ := cmd.Flags().GetBool("no-cache")
+	charBudget, _ := cmd.Flags().GetInt("char-budget")
+	budgetFallback, _ := cmd.Flags().GetBool("budget-fallback")
 
 	if apiKey == "" {
 		apiKey = os.Getenv("NEXUS_ELEVENLABS_KEY")
@@ -55,19 +75,42 @@ func runSpeak(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("elevenlabs backend requires --api-key or NEXUS_ELEVENLABS_KEY env var")
 		}
 		opts = append(opts, tts.WithElevenLabs(apiKey, voice))
+		if charBudget > 0 {
+			budget, err := tts.NewCharBudget("", charBudget)
+			if err != nil {
+				return fmt.Errorf("speak: %w", err)
+			}
+			defer budget.Close()
+			opts = append(opts, tts.WithCharBudget(budget, budgetFallback))
+		}
 	default:
 		return fmt.Errorf("unknown backend %q — choose: system, coqui, elevenlabs", backend)
 	}
 
+	if !noCache && backend != "system" {
+		cache, err := tts.NewCache(cacheDir, cacheMaxMB<<20)
+		if err != nil {
+			return fmt.Errorf("speak: %w", err)
+		}
+		opts = append(opts, tts.WithCache(cache))
+	}
+
 	agent := tts.New(opts...)
 	req := tts.Request{
 		Text:       text,
 		Voice:      voice,
 		OutputPath: out,
+		Speed:      speed,
+		Pitch:      pitch,
+		SSML:       ssml,
 	}
 
 	log.Info().Str("backend", backend).Str("text", text).Msg("Speaking...")
-	result, err := agent.Speak(cmd.Context(), req)
+	speak := agent.Speak
+	if stream {
+		speak = agent.SpeakStream
+	}
+	result, err := speak(cmd.Context(), req)
 	if err != nil {
 		return fmt.Errorf("speak: %w", err)
 	}
@@ -78,5 +121,11 @@ func runSpeak(cmd *cobra.Command, args []string) error {
 		fmt.Printf("\n\033[32m✅ Spoken via %s TTS\033[0m\n", result.Backend)
 	}
 	fmt.Printf("   Latency : %s\n", result.Latency)
+	if result.Cached {
+		fmt.Printf("   Cache   : hit (no re-synthesis)\n")
+	}
+	if remaining := agent.RemainingChars(); remaining >= 0 {
+		fmt.Printf("   Budget  : %d chars left this month\n", remaining)
+	}
 	return nil
 }
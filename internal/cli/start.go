@@ -5,9 +5,14 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/Omkar0612/nexus-ai/internal/agents"
+	"github.com/Omkar0612/nexus-ai/internal/audit"
+	"github.com/Omkar0612/nexus-ai/internal/events"
+	"github.com/Omkar0612/nexus-ai/internal/memory"
 	"github.com/Omkar0612/nexus-ai/internal/router"
 	"github.com/Omkar0612/nexus-ai/internal/types"
 	"github.com/Omkar0612/nexus-ai/internal/webui"
@@ -34,6 +39,7 @@ func init() {
 	startCmd.Flags().String("webui-addr", ":7070", "Web UI listen address (e.g. :7070)")
 	startCmd.Flags().BoolP("no-tui", "n", false, "Disable terminal UI")
 	startCmd.Flags().Bool("no-webui", false, "Disable web UI")
+	startCmd.Flags().Bool("reset-identity", false, "Discard the persisted mesh peer identity and generate a new one")
 }
 
 func runStart(cmd *cobra.Command, _ []string) error {
@@ -49,6 +55,7 @@ func runStart(cmd *cobra.Command, _ []string) error {
 	host, _ := cmd.Flags().GetString("host")
 	webuiAddr, _ := cmd.Flags().GetString("webui-addr")
 	noWebUI, _ := cmd.Flags().GetBool("no-webui")
+	resetIdentity, _ := cmd.Flags().GetBool("reset-identity")
 
 	fmt.Printf("\n\033[35m  NEXUS AI v1.8 — Autonomous OS\033[0m\n")
 	fmt.Printf("  Gateway : %s:%d\n", host, port)
@@ -58,37 +65,62 @@ func runStart(cmd *cobra.Command, _ []string) error {
 	fmt.Printf("  Skills  : run 'nexus skills list' to see all plugins\n")
 	fmt.Println()
 
-	// 1. Initialize LLM Base Router (v1.7)
-	llmCfg := types.LLMConfig{
-		Provider:   getEnvOrDefault("NEXUS_LLM_PROVIDER", "ollama"),
-		Model:      getEnvOrDefault("NEXUS_LLM_MODEL", "llama3.2"),
-		BaseURL:    getEnvOrDefault("NEXUS_LLM_BASE_URL", "http://localhost:11434/v1"),
-		APIKey:     os.Getenv("NEXUS_LLM_API_KEY"),
-		TimeoutSec: 120,
+	// 1. Initialize LLM Base Router (v1.7), metered against the budget
+	// limits of `nexus cost` (NEXUS_DAILY_BUDGET / NEXUS_MONTHLY_BUDGET)
+	r := router.New(llmConfigFromEnv())
+	user, _ := cmd.Flags().GetString("user")
+	costs, err := openCostTracker(cmd)
+	if err != nil {
+		return err
 	}
-	r := router.New(llmCfg)
+	defer costs.Close()
+	r.SetBudgetGate(func() (bool, string) { return costs.Gate(user) })
+	r.SetUsageRecorder(func(provider, model string, in, out int) {
+		if _, err := costs.Record(user, provider, model, "router", "", in, out); err != nil {
+			log.Warn().Err(err).Msg("failed to record LLM usage")
+		}
+	})
 	
 	// Create context for daemon lifecycle
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Event bus: budget and drift events are published once and every sink
+	// (currently the audit log) subscribes on its own.
+	auditLog, err := audit.Open("")
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	defer auditLog.Close()
+	bus := events.NewBus(0)
+	defer bus.Close()
+	go auditLog.RecordEvents(user, bus.Subscribe(events.KindAll))
+	costs.SetEventBus(bus)
+	startDriftScans(ctx, bus, user)
+
 	// 2. Initialize Token Stock Market (Dynamic Routing)
 	market := routing.NewMarket(60 * time.Second)
 	market.Start(ctx)
 	defer market.Stop()
 
 	// 3. Initialize Hive-Mind Mesh P2P
+	identity, err := mesh.LoadOrCreateIdentity("", resetIdentity)
+	if err != nil {
+		return err
+	}
 	localNode := &mesh.Node{
-		ID:      fmt.Sprintf("nexus-%s", os.Getenv("USER")),
+		ID:      identity.PeerID,
 		Address: fmt.Sprintf("%s:%d", host, port),
 		Profile: mesh.HardwareProfile{
-			HasGPU: os.Getenv("NEXUS_HAS_GPU") == "true",
+			HasGPU:       os.Getenv("NEXUS_HAS_GPU") == "true",
+			Capabilities: mesh.ParseCapabilities(os.Getenv("NEXUS_MESH_CAPABILITIES")),
 		},
 	}
 	meshNet := mesh.NewNetwork(localNode, nil) // Transport client injection pending
 	discovery := mesh.NewDiscovery(meshNet, localNode)
 	
-	// Start mDNS broadcast (errors logged but non-fatal if offline)
+	// Start mDNS broadcast (errors logged but non-fatal if offline or if
+	// NEXUS_MESH_SECRET is unset)
 	if err := discovery.Start(ctx, port); err != nil {
 		log.Warn().Err(err).Msg("Mesh discovery disabled (mDNS failed)")
 	} else {
@@ -116,6 +148,54 @@ func runStart(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
+// driftScanInterval is how often the daemon scans memory for work drift.
+const driftScanInterval = time.Hour
+
+// startDriftScans runs the drift detector over user's memory every
+// driftScanInterval until ctx is cancelled, publishing signals to bus.
+// A memory store that cannot be opened disables the scans.
+func startDriftScans(ctx context.Context, bus *events.Bus, user string) {
+	mem, err := memory.New("")
+	if err != nil {
+		log.Warn().Err(err).Msg("Drift detection disabled (memory store unavailable)")
+		return
+	}
+	drift := agents.NewDriftDetector(mem, user)
+	drift.SetEventBus(bus)
+	go func() {
+		defer mem.Close()
+		t := time.NewTicker(driftScanInterval)
+		defer t.Stop()
+		for {
+			if _, err := drift.Scan(ctx); err != nil {
+				log.Warn().Err(err).Msg("drift scan failed")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+}
+
+// llmConfigFromEnv builds the LLM router config shared by all commands.
+// Supported env vars: NEXUS_LLM_PROVIDER, NEXUS_LLM_MODEL, NEXUS_LLM_BASE_URL,
+// NEXUS_LLM_API_KEY and NEXUS_LLM_FALLBACKS (e.g. "groq,ollama:llama3.2");
+// a fallback's API key is read from NEXUS_<PROVIDER>_API_KEY.
+func llmConfigFromEnv() types.LLMConfig {
+	return types.LLMConfig{
+		Provider:   getEnvOrDefault("NEXUS_LLM_PROVIDER", "ollama"),
+		Model:      getEnvOrDefault("NEXUS_LLM_MODEL", "llama3.2"),
+		BaseURL:    getEnvOrDefault("NEXUS_LLM_BASE_URL", "http://localhost:11434/v1"),
+		APIKey:     os.Getenv("NEXUS_LLM_API_KEY"),
+		TimeoutSec: 120,
+		Fallbacks: router.ParseFallbacks(os.Getenv("NEXUS_LLM_FALLBACKS"), func(provider string) string {
+			return os.Getenv("NEXUS_" + strings.ToUpper(provider) + "_API_KEY")
+		}),
+	}
+}
+
 func getEnvOrDefault(key, def string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -7,7 +7,6 @@ import (
 	"strings"
 
 	"github.com/Omkar0612/nexus-ai/internal/router"
-	"github.com/Omkar0612/nexus-ai/internal/types"
 	"github.com/Omkar0612/nexus-ai/internal/writing"
 	"github.com/spf13/cobra"
 )
@@ -18,6 +17,7 @@ var writeCmd = &cobra.Command{
 	Long: `AI-powered writing tools backed by your local LLM. Zero additional cost.
 
 Subcommands:
+  outline     Turn a topic into a structured outline
   draft       Generate a new piece of writing
   rewrite     Rewrite text in a different style
   summarise   Condense text to a target word count
@@ -26,6 +26,40 @@ Subcommands:
   translate   Translate text to another language`,
 }
 
+// -- outline --
+
+var writeOutlineCmd = &cobra.Command{
+	Use:   "outline",
+	Short: "Turn a topic into a structured bullet outline",
+	Example: `  nexus write outline --topic "Local-first AI agents" --sections 6 --depth 3
+  nexus write outline --topic "Q3 roadmap" --out outline.md && nexus write expand --file outline.md`,
+	RunE: runWriteOutline,
+}
+
+func init() {
+	writeOutlineCmd.Flags().String("topic", "", "Topic to outline (required)")
+	writeOutlineCmd.Flags().Int("sections", writing.DefaultOutlineSections, "Number of top-level sections")
+	writeOutlineCmd.Flags().Int("depth", 2, "Heading depth: bullet levels per section (1-4)")
+	writeOutlineCmd.Flags().String("out", "", "Save output to file")
+	_ = writeOutlineCmd.MarkFlagRequired("topic")
+}
+
+func runWriteOutline(cmd *cobra.Command, _ []string) error {
+	topic, _ := cmd.Flags().GetString("topic")
+	sections, _ := cmd.Flags().GetInt("sections")
+	depth, _ := cmd.Flags().GetInt("depth")
+	out, _ := cmd.Flags().GetString("out")
+	a, err := newWritingAgent()
+	if err != nil {
+		return err
+	}
+	result, err := a.OutlineSections(cmd.Context(), topic, depth, sections)
+	if err != nil {
+		return fmt.Errorf("write outline: %w", err)
+	}
+	return writeOutput(result, out)
+}
+
 // -- draft --
 
 var writeDraftCmd = &cobra.Command{
@@ -240,17 +274,10 @@ func runWriteTranslate(cmd *cobra.Command, _ []string) error {
 
 // -- shared helpers --
 
-// newWritingAgent builds a writing agent from environment variables.
-// Supported env vars: NEXUS_LLM_PROVIDER, NEXUS_LLM_MODEL, NEXUS_LLM_BASE_URL, NEXUS_LLM_API_KEY.
+// newWritingAgent builds a writing agent from environment variables
+// (see llmConfigFromEnv), including the NEXUS_LLM_FALLBACKS chain.
 func newWritingAgent() (*writing.Agent, error) {
-	r := router.New(types.LLMConfig{
-		Provider:   getEnvOrDefault("NEXUS_LLM_PROVIDER", "ollama"),
-		Model:      getEnvOrDefault("NEXUS_LLM_MODEL", "llama3.2"),
-		BaseURL:    getEnvOrDefault("NEXUS_LLM_BASE_URL", "http://localhost:11434/v1"),
-		APIKey:     os.Getenv("NEXUS_LLM_API_KEY"),
-		TimeoutSec: 120,
-	})
-	return writing.New(r), nil
+	return writing.New(router.New(llmConfigFromEnv())), nil
 }
 
 // readInput reads text from a file or stdin.
@@ -284,6 +311,7 @@ func writeOutput(text, path string) error {
 }
 
 func init() {
+	writeCmd.AddCommand(writeOutlineCmd)
 	writeCmd.AddCommand(writeDraftCmd)
 	writeCmd.AddCommand(writeRewriteCmd)
 	writeCmd.AddCommand(writeSummariseCmd)
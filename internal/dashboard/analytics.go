@@ -14,18 +14,33 @@ web dashboard, exposing:
   6. Scheduler status — job history, next runs
   7. KB stats — document count, search hit rates
 
-HTTP handlers return JSON. Plug in any frontend (React, HTMX, Grafana).
+HTTP handlers return JSON under /api/*, and /api/stream pushes every new
+metric point as Server-Sent Events. /metrics exposes the same data in the
+Prometheus text format for scraping.
+
+The server binds 127.0.0.1 by default. Set NEXUS_DASHBOARD_TOKEN (or
+WithAuthToken) to require the token as a bearer token or basic-auth
+password on every route except /health. A small bundled UI is served at /
+(swap it with WithStaticFS, or plug in any frontend via WithCORS).
 No external analytics service needed. All data is local.
 */
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io/fs"
+	"net"
 	"net/http"
+	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/Omkar0612/nexus-ai/internal/events"
+	"github.com/Omkar0612/nexus-ai/internal/goals"
 )
 
 // MetricPoint is a single time-series data point
@@ -64,11 +79,18 @@ type DashboardSnapshot struct {
 	CustomMetrics  map[string]interface{} `json:"custom_metrics,omitempty"`
 }
 
-// MetricStore is an in-memory time-series store for dashboard metrics
+// MetricStore is an in-memory time-series store for dashboard metrics,
+// optionally persisted to SQLite (see OpenMetricStore)
 type MetricStore struct {
 	mu      sync.RWMutex
 	series  map[string][]MetricPoint
 	maxAge  time.Duration
+
+	// Set by OpenMetricStore; pending is guarded by mu.
+	db      *sql.DB
+	pending []storedPoint
+	stop    chan struct{}
+	done    chan struct{}
 }
 
 // NewMetricStore creates a MetricStore with a retention window
@@ -82,13 +104,17 @@ func NewMetricStore(retention time.Duration) *MetricStore {
 	}
 }
 
-// Record adds a metric data point
-func (m *MetricStore) Record(name string, value float64, label string) {
+// Record adds a metric data point and returns it
+func (m *MetricStore) Record(name string, value float64, label string) MetricPoint {
 	pt := MetricPoint{Timestamp: time.Now(), Value: value, Label: label}
 	m.mu.Lock()
 	m.series[name] = append(m.series[name], pt)
+	if m.db != nil {
+		m.pending = append(m.pending, storedPoint{name, pt})
+	}
 	m.mu.Unlock()
 	m.prune(name)
+	return pt
 }
 
 func (m *MetricStore) prune(name string) {
@@ -134,14 +160,28 @@ type Analytics struct {
 	mu      sync.RWMutex
 	mux     *http.ServeMux
 	port    int
+	cors    CORSConfig
+	static  fs.FS
+	stream  *streamHub
+	budget  float64 // monthly USD budget for BudgetPct; 0 = none
+	bind    string
+	token   string
+	goals   *goals.Store
 }
 
 // New creates an Analytics instance
-func New(port int) *Analytics {
+func New(port int, opts ...Option) *Analytics {
 	a := &Analytics{
-		store: NewMetricStore(30 * 24 * time.Hour),
-		port:  port,
-		mux:   http.NewServeMux(),
+		store:  NewMetricStore(30 * 24 * time.Hour),
+		port:   port,
+		mux:    http.NewServeMux(),
+		static: bundledStatic(),
+		stream: newStreamHub(),
+		bind:   "127.0.0.1",
+		token:  os.Getenv(TokenEnv),
+	}
+	for _, opt := range opts {
+		opt(a)
 	}
 	a.registerRoutes()
 	return a
@@ -151,19 +191,36 @@ func (a *Analytics) registerRoutes() {
 	a.mux.HandleFunc("/api/snapshot", a.handleSnapshot)
 	a.mux.HandleFunc("/api/metrics/", a.handleMetricSeries)
 	a.mux.HandleFunc("/api/agents", a.handleAgents)
+	a.mux.HandleFunc("/api/stream", a.handleStream)
 	a.mux.HandleFunc("/health", a.handleHealth)
+	a.mux.HandleFunc("/metrics", a.handlePrometheus)
+	if a.static != nil {
+		a.mux.Handle("/", spaHandler(a.static))
+	}
 }
 
 // Serve starts the analytics HTTP server
 func (a *Analytics) Serve() error {
-	addr := fmt.Sprintf(":%d", a.port)
-	fmt.Printf("📊 NEXUS Analytics dashboard: http://localhost%s\n", addr)
-	return http.ListenAndServe(addr, a.mux)
+	addr := net.JoinHostPort(a.bind, strconv.Itoa(a.port))
+	fmt.Printf("📊 NEXUS Analytics dashboard: http://%s\n", addr)
+	if a.token == "" && a.bind != "127.0.0.1" && a.bind != "localhost" {
+		fmt.Printf("⚠️  Dashboard is reachable on %s without authentication — set %s\n", a.bind, TokenEnv)
+	}
+	return http.ListenAndServe(addr, a.Handler())
 }
 
-// Record proxies to the metric store
+// Record proxies to the metric store and pushes the point to stream clients
 func (a *Analytics) Record(metric string, value float64, label string) {
-	a.store.Record(metric, value, label)
+	pt := a.store.Record(metric, value, label)
+	a.stream.publish(MetricUpdate{Name: metric, Point: pt})
+}
+
+// ConsumeEvents counts every event from ch as metric "events.<kind>" labelled
+// by source, until ch is closed. Run it in its own goroutine on a bus subscription.
+func (a *Analytics) ConsumeEvents(ch <-chan events.Event) {
+	for e := range ch {
+		a.Record("events."+string(e.Kind), 1, e.Source)
+	}
 }
 
 // UpdateAgentStats replaces the agent stats list
@@ -174,6 +231,12 @@ func (a *Analytics) UpdateAgentStats(stats []AgentStat) {
 }
 
 func (a *Analytics) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a.snapshot())
+}
+
+// snapshot builds the current DashboardSnapshot
+func (a *Analytics) snapshot() DashboardSnapshot {
 	a.mu.RLock()
 	agents := make([]AgentStat, len(a.agents))
 	copy(agents, a.agents)
@@ -192,9 +255,22 @@ func (a *Analytics) handleSnapshot(w http.ResponseWriter, r *http.Request) {
 		tasks += ag.TotalTasks
 	}
 	snapshot.TotalAgentTasks = tasks
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(snapshot)
+	if a.budget > 0 {
+		snapshot.BudgetPct = snapshot.CostMonth / a.budget * 100
+	}
+	if a.goals != nil {
+		if all, err := a.goals.List(); err == nil {
+			for _, g := range all {
+				if !g.Done() {
+					snapshot.ActiveGoals++
+				}
+			}
+		}
+		if stalled, err := a.goals.Stalled(7); err == nil {
+			snapshot.StalledGoals = len(stalled)
+		}
+	}
+	return snapshot
 }
 
 func (a *Analytics) handleMetricSeries(w http.ResponseWriter, r *http.Request) {
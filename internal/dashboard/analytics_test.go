@@ -1,10 +1,17 @@
 package dashboard
 
 import (
+	"bufio"
+	"context"
+	"database/sql"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/Omkar0612/nexus-ai/internal/goals"
 )
 
 func TestMetricStoreRecordAndGet(t *testing.T) {
@@ -64,3 +71,195 @@ func TestAnalyticsHealthEndpoint(t *testing.T) {
 		t.Errorf("expected 200, got %d", w.Code)
 	}
 }
+
+func TestAnalyticsServesBundledUI(t *testing.T) {
+	h := New(9879).Handler()
+	for _, path := range []string{"/", "/goals/123"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "NEXUS Dashboard") {
+			t.Errorf("%s: expected index.html, got %d", path, w.Code)
+		}
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown API path, got %d", w.Code)
+	}
+}
+
+func TestAnalyticsCORS(t *testing.T) {
+	h := New(9880, WithCORS(CORSConfig{AllowedOrigins: []string{"http://localhost:5173"}})).Handler()
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/snapshot", nil)
+	req.Header.Set("Origin", "http://localhost:5173")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "http://localhost:5173" {
+		t.Errorf("preflight: code=%d headers=%v", w.Code, w.Header())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/snapshot", nil)
+	req.Header.Set("Origin", "http://evil.example")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("disallowed origin got CORS header %q", got)
+	}
+}
+
+func TestAnalyticsStream(t *testing.T) {
+	a := New(9881)
+	srv := httptest.NewServer(a.Handler())
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/stream", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /api/stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+	lines := bufio.NewScanner(resp.Body)
+	next := func() string {
+		for lines.Scan() {
+			if l := lines.Text(); strings.HasPrefix(l, "event: ") {
+				return strings.TrimPrefix(l, "event: ")
+			}
+		}
+		return ""
+	}
+	if ev := next(); ev != "snapshot" {
+		t.Fatalf("expected initial snapshot event, got %q", ev)
+	}
+	a.Record("cost_usd", 0.02, "groq")
+	if ev := next(); ev != "metric" {
+		t.Fatalf("expected metric event, got %q", ev)
+	}
+	lines.Scan()
+	if !strings.Contains(lines.Text(), `"name":"cost_usd"`) {
+		t.Errorf("unexpected metric payload: %s", lines.Text())
+	}
+
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for a.stream.subscribers() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := a.stream.subscribers(); n != 0 {
+		t.Errorf("expected subscriber cleanup on disconnect, %d left", n)
+	}
+}
+
+func TestAnalyticsPrometheusMetrics(t *testing.T) {
+	a := New(9882, WithMonthlyBudget(1))
+	a.Record("cost_usd", 0.25, "groq")
+	a.Record("cost_usd", 0.25, "groq")
+	a.Record("events.loop", 1, `web "search"`)
+	a.UpdateAgentStats([]AgentStat{{Name: "Researcher", Role: "researcher", TotalTasks: 10, Failures: 2, SuccessRate: 0.8}})
+
+	w := httptest.NewRecorder()
+	a.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("code=%d content-type=%q", w.Code, w.Header().Get("Content-Type"))
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		"# TYPE nexus_agent_tasks_total counter",
+		`nexus_agent_tasks_total{agent="Researcher",role="researcher"} 10`,
+		`nexus_agent_failures_total{agent="Researcher",role="researcher"} 2`,
+		`nexus_cost_by_model_usd{model="groq"} 0.5`,
+		"nexus_budget_pct 50",
+		`nexus_metric_points{series="events.loop",label="web \"search\""} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+}
+
+func TestAnalyticsAuthToken(t *testing.T) {
+	h := New(9883, WithAuthToken("s3cret")).Handler()
+	do := func(path string, setAuth func(*http.Request)) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if setAuth != nil {
+			setAuth(req)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := do("/api/snapshot", nil); code != http.StatusUnauthorized {
+		t.Errorf("no token: expected 401, got %d", code)
+	}
+	if code := do("/api/snapshot", func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") }); code != http.StatusUnauthorized {
+		t.Errorf("wrong token: expected 401, got %d", code)
+	}
+	if code := do("/api/snapshot", func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cret") }); code != http.StatusOK {
+		t.Errorf("bearer token: expected 200, got %d", code)
+	}
+	if code := do("/metrics", func(r *http.Request) { r.SetBasicAuth("nexus", "s3cret") }); code != http.StatusOK {
+		t.Errorf("basic auth: expected 200, got %d", code)
+	}
+	if code := do("/health", nil); code != http.StatusOK {
+		t.Errorf("health must stay open, got %d", code)
+	}
+}
+
+func TestMetricStorePersistsAcrossRestarts(t *testing.T) {
+	dir := t.TempDir()
+	m, err := OpenMetricStore(dir, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("OpenMetricStore: %v", err)
+	}
+	m.Record("cost_usd", 0.25, "groq")
+	m.Record("latency_ms", 120, "researcher")
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// A point older than the retention window must not be loaded.
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "metrics.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`INSERT INTO metric_points (ts, name, value, label) VALUES (?, 'cost_usd', 9, 'old')`,
+		time.Now().Add(-2*time.Hour).UnixNano())
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err = OpenMetricStore(dir, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer m.Close()
+	cost := m.Get("cost_usd")
+	if len(cost) != 1 || cost[0].Value != 0.25 || cost[0].Label != "groq" {
+		t.Errorf("expected reloaded cost point, got %+v", cost)
+	}
+	if len(m.Get("latency_ms")) != 1 {
+		t.Error("expected reloaded latency point")
+	}
+}
+
+func TestAnalyticsGoalCounts(t *testing.T) {
+	store, err := goals.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("goals.Open: %v", err)
+	}
+	defer store.Close()
+	_, _ = store.Add("Ship NEXUS v2")
+	done, _ := store.Add("Write launch post")
+	_ = store.UpdateProgress(done.ID, 1)
+
+	s := New(9884, WithGoals(store)).snapshot()
+	if s.ActiveGoals != 1 || s.StalledGoals != 0 {
+		t.Errorf("expected 1 active and 0 stalled goals, got %d/%d", s.ActiveGoals, s.StalledGoals)
+	}
+}
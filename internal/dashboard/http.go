@@ -0,0 +1,176 @@
+package dashboard
+
+import (
+	"crypto/subtle"
+	"embed"
+	"io/fs"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/Omkar0612/nexus-ai/internal/goals"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// Option configures an Analytics instance.
+type Option func(*Analytics)
+
+// CORSConfig controls cross-origin access to the API, e.g. for a frontend
+// dev server on another port. An empty AllowedOrigins disables CORS.
+type CORSConfig struct {
+	AllowedOrigins []string // exact origins, or "*" for any
+	AllowedMethods []string // default GET, OPTIONS
+	AllowedHeaders []string // default Content-Type, Authorization
+	MaxAge         int      // preflight cache seconds (default 600)
+}
+
+// WithCORS enables CORS headers for the given config.
+func WithCORS(cfg CORSConfig) Option {
+	return func(a *Analytics) { a.cors = cfg }
+}
+
+// WithMonthlyBudget sets the monthly USD budget that BudgetPct (and the
+// nexus_budget_pct metric) is measured against.
+func WithMonthlyBudget(usd float64) Option {
+	return func(a *Analytics) { a.budget = usd }
+}
+
+// WithMetricStore replaces the default in-memory store, e.g. with one from
+// OpenMetricStore so charts survive restarts.
+func WithMetricStore(m *MetricStore) Option {
+	return func(a *Analytics) { a.store = m }
+}
+
+// WithGoals fills ActiveGoals and StalledGoals (idle 7+ days) from store.
+func WithGoals(store *goals.Store) Option {
+	return func(a *Analytics) { a.goals = store }
+}
+
+// WithStaticFS serves fsys at / instead of the bundled dashboard.
+// Pass nil to serve the API only.
+func WithStaticFS(fsys fs.FS) Option {
+	return func(a *Analytics) { a.static = fsys }
+}
+
+// bundledStatic returns the embedded dashboard assets.
+func bundledStatic() fs.FS {
+	sub, _ := fs.Sub(staticFiles, "static")
+	return sub
+}
+
+// TokenEnv names the environment variable New reads the dashboard access
+// token from.
+const TokenEnv = "NEXUS_DASHBOARD_TOKEN"
+
+// WithAuthToken requires token on every request except /health, as either
+// "Authorization: Bearer <token>" or a basic-auth password (any username,
+// so browsers can log in through their prompt). Overrides NEXUS_DASHBOARD_TOKEN;
+// an empty token disables the check.
+func WithAuthToken(token string) Option {
+	return func(a *Analytics) { a.token = token }
+}
+
+// WithBind sets the interface Serve listens on (default 127.0.0.1).
+// Use "0.0.0.0" or "" to listen on all interfaces.
+func WithBind(host string) Option {
+	return func(a *Analytics) { a.bind = host }
+}
+
+// Handler returns the full HTTP handler: API routes, static UI and middleware.
+func (a *Analytics) Handler() http.Handler {
+	return a.withCORS(a.withAuth(a.mux))
+}
+
+// withAuth rejects requests without the configured token with 401.
+func (a *Analytics) withAuth(next http.Handler) http.Handler {
+	if a.token == "" {
+		return next
+	}
+	want := []byte(a.token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		var got string
+		if _, pass, ok := r.BasicAuth(); ok {
+			got = pass
+		} else if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+			got = strings.TrimPrefix(h, "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(got), want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="NEXUS Dashboard"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withCORS wraps next with the configured CORS policy.
+func (a *Analytics) withCORS(next http.Handler) http.Handler {
+	cfg := a.cors
+	if len(cfg.AllowedOrigins) == 0 {
+		return next
+	}
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodOptions}
+	}
+	headers := cfg.AllowedHeaders
+	if len(headers) == 0 {
+		headers = []string{"Content-Type", "Authorization"}
+	}
+	maxAge := cfg.MaxAge
+	if maxAge <= 0 {
+		maxAge = 600
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" && originAllowed(cfg.AllowedOrigins, origin) {
+			h := w.Header()
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Add("Vary", "Origin")
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+				h.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+				h.Set("Access-Control-Max-Age", strconv.Itoa(maxAge))
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func originAllowed(allowed []string, origin string) bool {
+	for _, o := range allowed {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// spaHandler serves static files, falling back to index.html for unknown
+// paths so client-side routes work. Unknown /api/ paths still 404.
+func spaHandler(fsys fs.FS) http.Handler {
+	files := http.FileServer(http.FS(fsys))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			http.NotFound(w, r)
+			return
+		}
+		name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+		if name != "" {
+			if _, err := fs.Stat(fsys, name); err != nil {
+				r = r.Clone(r.Context())
+				r.URL.Path = "/"
+			}
+		}
+		files.ServeHTTP(w, r)
+	})
+}
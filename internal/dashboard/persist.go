@@ -0,0 +1,171 @@
+package dashboard
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultFlushInterval is how often a persistent MetricStore writes new
+// points to disk.
+const DefaultFlushInterval = 10 * time.Second
+
+// storedPoint is a recorded point waiting to be flushed.
+type storedPoint struct {
+	name string
+	pt   MetricPoint
+}
+
+// OpenMetricStore opens a MetricStore backed by an append-only SQLite table
+// in dataDir/metrics.db (default ~/.nexus). Points within the retention
+// window are loaded into memory, reads stay in memory, and new points are
+// flushed every flushEvery (default DefaultFlushInterval) and on Close.
+func OpenMetricStore(dataDir string, retention, flushEvery time.Duration) (*MetricStore, error) {
+	if dataDir == "" {
+		home, _ := os.UserHomeDir()
+		dataDir = filepath.Join(home, ".nexus")
+	}
+	if flushEvery <= 0 {
+		flushEvery = DefaultFlushInterval
+	}
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		return nil, fmt.Errorf("dashboard: mkdir: %w", err)
+	}
+	dbPath := filepath.Join(dataDir, "metrics.db")
+	// Create with 0600 before sql.Open — prevents world-readable window.
+	f, err := os.OpenFile(dbPath, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("dashboard: create metrics db: %w", err)
+	}
+	f.Close()
+	db, err := sql.Open("sqlite3", dbPath+"?_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS metric_points (
+			ts    INTEGER NOT NULL,
+			name  TEXT NOT NULL,
+			value REAL NOT NULL,
+			label TEXT NOT NULL DEFAULT ''
+		);
+		CREATE INDEX IF NOT EXISTS idx_metric_points_ts ON metric_points(ts);
+	`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("dashboard: migrate metrics db: %w", err)
+	}
+
+	m := NewMetricStore(retention)
+	if err := m.load(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	m.db = db
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	go m.flushLoop(flushEvery)
+	return m, nil
+}
+
+// load reads every point within the retention window, oldest first.
+func (m *MetricStore) load(db *sql.DB) error {
+	cutoff := time.Now().Add(-m.maxAge)
+	rows, err := db.Query(`SELECT ts, name, value, label FROM metric_points WHERE ts > ? ORDER BY ts`,
+		cutoff.UnixNano())
+	if err != nil {
+		return fmt.Errorf("dashboard: load metrics: %w", err)
+	}
+	defer rows.Close()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for rows.Next() {
+		var ts int64
+		var name string
+		var pt MetricPoint
+		if err := rows.Scan(&ts, &name, &pt.Value, &pt.Label); err != nil {
+			return fmt.Errorf("dashboard: load metrics: %w", err)
+		}
+		pt.Timestamp = time.Unix(0, ts)
+		m.series[name] = append(m.series[name], pt)
+	}
+	return rows.Err()
+}
+
+func (m *MetricStore) flushLoop(every time.Duration) {
+	defer close(m.done)
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.Flush(); err != nil {
+				log.Warn().Err(err).Msg("dashboard: metric flush failed")
+			}
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// Flush writes points recorded since the last flush and drops stored points
+// older than the retention window. It is a no-op for in-memory stores.
+func (m *MetricStore) Flush() error {
+	if m.db == nil {
+		return nil
+	}
+	m.mu.Lock()
+	pending := m.pending
+	m.pending = nil
+	m.mu.Unlock()
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		m.requeue(pending)
+		return fmt.Errorf("dashboard: flush: %w", err)
+	}
+	for _, p := range pending {
+		if _, err := tx.Exec(`INSERT INTO metric_points (ts, name, value, label) VALUES (?,?,?,?)`,
+			p.pt.Timestamp.UnixNano(), p.name, p.pt.Value, p.pt.Label); err != nil {
+			tx.Rollback()
+			m.requeue(pending)
+			return fmt.Errorf("dashboard: flush: %w", err)
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM metric_points WHERE ts <= ?`, time.Now().Add(-m.maxAge).UnixNano()); err != nil {
+		tx.Rollback()
+		m.requeue(pending)
+		return fmt.Errorf("dashboard: flush: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		m.requeue(pending)
+		return fmt.Errorf("dashboard: flush: %w", err)
+	}
+	return nil
+}
+
+// requeue puts points from a failed flush back in front of newer ones.
+func (m *MetricStore) requeue(points []storedPoint) {
+	m.mu.Lock()
+	m.pending = append(points, m.pending...)
+	m.mu.Unlock()
+}
+
+// Close flushes outstanding points and closes the database. It is a no-op
+// for in-memory stores.
+func (m *MetricStore) Close() error {
+	if m.db == nil {
+		return nil
+	}
+	close(m.stop)
+	<-m.done
+	err := m.Flush()
+	if cerr := m.db.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,131 @@
+package dashboard
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// handlePrometheus is GET /metrics in the Prometheus text exposition format
+// (version 0.0.4), so NEXUS can be scraped alongside existing infrastructure.
+func (a *Analytics) handlePrometheus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	a.writePrometheus(w)
+}
+
+func (a *Analytics) writePrometheus(w io.Writer) {
+	s := a.snapshot()
+	p := promWriter{w: w}
+
+	p.family("nexus_cost_usd", "gauge", "LLM spend in USD over a trailing window.")
+	p.sample("nexus_cost_usd", s.CostToday, "window", "24h")
+	p.sample("nexus_cost_usd", s.CostMonth, "window", "30d")
+
+	p.family("nexus_cost_by_model_usd", "gauge", "LLM spend in USD per model within the retention window.")
+	byModel := make(map[string]float64)
+	for _, pt := range s.CostSeries {
+		byModel[pt.Label] += pt.Value
+	}
+	for _, model := range sortedKeys(byModel) {
+		p.sample("nexus_cost_by_model_usd", byModel[model], "model", model)
+	}
+
+	p.family("nexus_budget_pct", "gauge", "Monthly spend as a percentage of the configured budget.")
+	p.sample("nexus_budget_pct", s.BudgetPct)
+
+	agents := s.Agents
+	sort.Slice(agents, func(i, j int) bool { return agents[i].Name < agents[j].Name })
+	p.family("nexus_agent_tasks_total", "counter", "Tasks run by each agent.")
+	for _, ag := range agents {
+		p.sample("nexus_agent_tasks_total", float64(ag.TotalTasks), "agent", ag.Name, "role", ag.Role)
+	}
+	p.family("nexus_agent_failures_total", "counter", "Failed tasks per agent.")
+	for _, ag := range agents {
+		p.sample("nexus_agent_failures_total", float64(ag.Failures), "agent", ag.Name, "role", ag.Role)
+	}
+	p.family("nexus_agent_success_ratio", "gauge", "Share of each agent's tasks that succeeded (0-1).")
+	for _, ag := range agents {
+		p.sample("nexus_agent_success_ratio", ag.SuccessRate, "agent", ag.Name, "role", ag.Role)
+	}
+	p.family("nexus_agent_latency_seconds", "gauge", "Average task latency per agent.")
+	for _, ag := range agents {
+		p.sample("nexus_agent_latency_seconds", ag.AvgLatency.Seconds(), "agent", ag.Name, "role", ag.Role)
+	}
+
+	p.family("nexus_metric_sum", "gauge", "Sum of each recorded metric series within the retention window.")
+	sums, counts := a.store.totals()
+	for _, k := range sortedSeriesKeys(sums) {
+		p.sample("nexus_metric_sum", sums[k], "series", k.name, "label", k.label)
+	}
+	p.family("nexus_metric_points", "gauge", "Data points held for each recorded metric series.")
+	for _, k := range sortedSeriesKeys(sums) {
+		p.sample("nexus_metric_points", float64(counts[k]), "series", k.name, "label", k.label)
+	}
+}
+
+// seriesKey identifies one labelled slice of a metric series.
+type seriesKey struct{ name, label string }
+
+// totals sums every series per label, with point counts.
+func (m *MetricStore) totals() (map[seriesKey]float64, map[seriesKey]int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sums := make(map[seriesKey]float64)
+	counts := make(map[seriesKey]int)
+	for name, points := range m.series {
+		for _, pt := range points {
+			k := seriesKey{name, pt.Label}
+			sums[k] += pt.Value
+			counts[k]++
+		}
+	}
+	return sums, counts
+}
+
+func sortedSeriesKeys(m map[seriesKey]float64) []seriesKey {
+	keys := make([]seriesKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].name != keys[j].name {
+			return keys[i].name < keys[j].name
+		}
+		return keys[i].label < keys[j].label
+	})
+	return keys
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// promWriter emits Prometheus text format lines.
+type promWriter struct{ w io.Writer }
+
+func (p promWriter) family(name, typ, help string) {
+	fmt.Fprintf(p.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// sample writes one sample; labels are name/value pairs.
+func (p promWriter) sample(name string, value float64, labels ...string) {
+	if len(labels) == 0 {
+		fmt.Fprintf(p.w, "%s %g\n", name, value)
+		return
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, labels[i], labelEscaper.Replace(labels[i+1])))
+	}
+	fmt.Fprintf(p.w, "%s{%s} %g\n", name, strings.Join(pairs, ","), value)
+}
+
+// labelEscaper applies the only escapes the text format allows in label values.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,40 @@
+// Minimal NEXUS dashboard: renders the headline numbers from /api/snapshot,
+// refreshed live over /api/stream (falls back to polling without SSE).
+async function refresh() {
+  const res = await fetch("/api/snapshot");
+  if (!res.ok) return;
+  render(await res.json());
+}
+
+function render(s) {
+  document.getElementById("cost-today").textContent = "$" + s.cost_today_usd.toFixed(4);
+  document.getElementById("cost-month").textContent = "$" + s.cost_month_usd.toFixed(4);
+  document.getElementById("tasks").textContent = s.total_agent_tasks;
+  document.getElementById("updated").textContent = "updated " + new Date(s.generated_at).toLocaleTimeString();
+
+  const body = document.querySelector("#agents tbody");
+  body.replaceChildren(...(s.agents || []).map(a => {
+    const tr = document.createElement("tr");
+    for (const v of [a.name, a.role, a.total_tasks, a.failures, (a.success_rate * 100).toFixed(0) + "%"]) {
+      const td = document.createElement("td");
+      td.textContent = v;
+      tr.appendChild(td);
+    }
+    return tr;
+  }));
+}
+// Metric events arrive per data point; coalesce bursts into one refresh.
+let pending = null;
+function scheduleRefresh() {
+  if (pending) return;
+  pending = setTimeout(() => { pending = null; refresh(); }, 500);
+}
+
+if (window.EventSource) {
+  const es = new EventSource("/api/stream");
+  es.addEventListener("snapshot", e => render(JSON.parse(e.data)));
+  es.addEventListener("metric", scheduleRefresh);
+} else {
+  refresh();
+  setInterval(refresh, 10000);
+}
@@ -0,0 +1,95 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// MetricUpdate is pushed to /api/stream clients for every recorded point.
+type MetricUpdate struct {
+	Name  string      `json:"name"`
+	Point MetricPoint `json:"point"`
+}
+
+// streamKeepAlive is how often an idle stream sends a comment line, so
+// proxies don't time the connection out.
+const streamKeepAlive = 30 * time.Second
+
+// streamHub fans recorded metric points out to connected SSE clients.
+type streamHub struct {
+	mu      sync.RWMutex
+	clients map[chan MetricUpdate]struct{}
+}
+
+func newStreamHub() *streamHub {
+	return &streamHub{clients: make(map[chan MetricUpdate]struct{})}
+}
+
+// publish sends u to every client without blocking; slow clients miss it
+// and can resync from /api/snapshot.
+func (h *streamHub) publish(u MetricUpdate) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.clients {
+		select {
+		case ch <- u:
+		default:
+		}
+	}
+}
+
+func (h *streamHub) subscribe() chan MetricUpdate {
+	ch := make(chan MetricUpdate, 32)
+	h.mu.Lock()
+	h.clients[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *streamHub) unsubscribe(ch chan MetricUpdate) {
+	h.mu.Lock()
+	delete(h.clients, ch)
+	h.mu.Unlock()
+}
+
+// subscribers returns the number of connected stream clients.
+func (h *streamHub) subscribers() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// handleStream is GET /api/stream. It sends a full "snapshot" event on
+// connect, then a "metric" event (a MetricUpdate) for every Record.
+func (a *Analytics) handleStream(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch := a.stream.subscribe()
+	defer a.stream.unsubscribe(ch)
+
+	rc := http.NewResponseController(w)
+	b, _ := json.Marshal(a.snapshot())
+	fmt.Fprintf(w, "event: snapshot\ndata: %s\n\n", b)
+	rc.Flush()
+
+	ticker := time.NewTicker(streamKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case u := <-ch:
+			b, _ := json.Marshal(u)
+			fmt.Fprintf(w, "event: metric\ndata: %s\n\n", b)
+			rc.Flush()
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			rc.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
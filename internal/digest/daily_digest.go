@@ -27,8 +27,11 @@ Scheduled by SmartScheduler at configurable time (default: 09:00 local).
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/Omkar0612/nexus-ai/internal/goals"
 )
 
 // FeedItem is a single data point from any source
@@ -219,15 +222,28 @@ func HighRiskAuditFeed(actions []string) DigestFeed {
 	}
 }
 
-// GoalsFeed surfaces goals not worked on in 7+ days
-func GoalsFeed(stalledGoals []string) DigestFeed {
+// GoalSource lists stalled goals; *goals.Store implements it
+type GoalSource interface {
+	Stalled(days int) ([]goals.Goal, error)
+}
+
+// GoalsFeed surfaces unfinished goals from store with no activity in the
+// last days days (default 7)
+func GoalsFeed(store GoalSource, days int) DigestFeed {
+	if days <= 0 {
+		days = 7
+	}
 	return func() []FeedItem {
+		stalled, err := store.Stalled(days)
+		if err != nil {
+			return []FeedItem{{Source: "goals", Title: "Goals", Body: "could not load goals: " + err.Error(), Priority: 1, Emoji: "⚠️"}}
+		}
 		var items []FeedItem
-		for _, g := range stalledGoals {
+		for _, g := range stalled {
 			items = append(items, FeedItem{
 				Source:   "goals",
 				Title:    "Stalled Goal",
-				Body:     g + " (no activity in 7+ days)",
+				Body:     fmt.Sprintf("%s — %.0f%% done, no activity in %d days", g.Title, g.Progress*100, int(time.Since(g.LastActivity).Hours()/24)),
 				Priority: 2,
 				Emoji:    "🎯",
 			})
@@ -235,3 +251,38 @@ func GoalsFeed(stalledGoals []string) DigestFeed {
 		return items
 	}
 }
+
+// SecretExpiryFeed reminds the user to rotate vault secrets (name → expiry)
+// before they break automations
+func SecretExpiryFeed(expiring map[string]time.Time) DigestFeed {
+	return func() []FeedItem {
+		names := make([]string, 0, len(expiring))
+		for name := range expiring {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool { return expiring[names[i]].Before(expiring[names[j]]) })
+		var items []FeedItem
+		for _, name := range names {
+			left := time.Until(expiring[name])
+			body, priority, emoji := "", 2, "🔑"
+			switch days := int(left.Hours() / 24); {
+			case left <= 0:
+				body, priority, emoji = name+" has expired — rotate it now", 3, "🚨"
+			case days == 0:
+				body, priority = name+" expires today", 3
+			case days == 1:
+				body = name + " expires in 1 day"
+			default:
+				body = fmt.Sprintf("%s expires in %d days", name, days)
+			}
+			items = append(items, FeedItem{
+				Source:   "vault",
+				Title:    "Secret Expiring",
+				Body:     body,
+				Priority: priority,
+				Emoji:    emoji,
+			})
+		}
+		return items
+	}
+}
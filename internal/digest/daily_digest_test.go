@@ -1,7 +1,11 @@
 package digest
 
 import (
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/Omkar0612/nexus-ai/internal/goals"
 )
 
 func TestDigestBuildEmpty(t *testing.T) {
@@ -21,7 +25,7 @@ func TestDigestBuildWithFeeds(t *testing.T) {
 	d.AddFeed("drift", DriftFeed([]string{"Launch page stalled for 3 days", "Follow up with designer not done"}))
 	d.AddFeed("cost", CostFeed(0.85, 1.00))
 	d.AddFeed("audit", HighRiskAuditFeed([]string{"delete old backups"}))
-	d.AddFeed("goals", GoalsFeed([]string{"Ship NEXUS v2"}))
+	d.AddFeed("goals", GoalsFeed(fakeGoals{{ID: "g-1", Title: "Ship NEXUS v2", LastActivity: time.Now().Add(-8 * 24 * time.Hour)}}, 7))
 
 	report := d.Build()
 	if report.TotalItems < 4 {
@@ -65,3 +69,34 @@ func TestDigestCostFeedHighAlert(t *testing.T) {
 		t.Errorf("expected priority 3 for 95%% spend, got %d", items[0].Priority)
 	}
 }
+
+func TestDigestSecretExpiryFeed(t *testing.T) {
+	feed := SecretExpiryFeed(map[string]time.Time{
+		"GROQ_API_KEY": time.Now().Add(3*24*time.Hour + time.Hour),
+		"OLD_TOKEN":    time.Now().Add(-time.Hour),
+	})
+	items := feed()
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	if items[0].Priority != 3 || !strings.Contains(items[0].Body, "OLD_TOKEN has expired") {
+		t.Errorf("expired secret first with high priority, got %+v", items[0])
+	}
+	if items[1].Body != "GROQ_API_KEY expires in 3 days" {
+		t.Errorf("unexpected body %q", items[1].Body)
+	}
+}
+
+// fakeGoals is a GoalSource returning fixed stalled goals.
+type fakeGoals []goals.Goal
+
+func (f fakeGoals) Stalled(int) ([]goals.Goal, error) { return f, nil }
+
+func TestGoalsFeed(t *testing.T) {
+	items := GoalsFeed(fakeGoals{
+		{ID: "g-1", Title: "Ship NEXUS v2", Progress: 0.4, LastActivity: time.Now().Add(-10 * 24 * time.Hour)},
+	}, 7)()
+	if len(items) != 1 || !strings.Contains(items[0].Body, "Ship NEXUS v2 — 40% done, no activity in 10 days") {
+		t.Errorf("unexpected goal items: %+v", items)
+	}
+}
@@ -6,13 +6,17 @@ EmailAgent — full IMAP/SMTP email operations for NEXUS.
 Security:
   - SMTP header injection prevented: \r and \n stripped from From/To/Subject
   - Recipient addresses validated (must contain '@', no newlines)
-  - Password masked in fmt/log output via SecretString type
+  - Password and OAuth2 token masked in fmt/log output via SecretString type
+  - XOAUTH2 refused over unencrypted connections (except localhost)
+  - IMAP credentials likewise only sent over TLS (except localhost); the
+    mailbox is opened read-only so fetching never marks mail as seen
   - Sensitive field redaction before any LLM processing
 */
 
 import (
 	"crypto/tls"
 	"fmt"
+	"net/mail"
 	"net/smtp"
 	"strings"
 	"sync"
@@ -49,6 +53,8 @@ const (
 // Email represents a single email message.
 type Email struct {
 	ID          string
+	MessageID   string   // RFC 5322 Message-ID, used to thread replies
+	References  []string // Message-IDs of earlier messages in the thread
 	From        string
 	To          []string
 	CC          []string
@@ -74,15 +80,21 @@ type AutoRule struct {
 
 // EmailConfig holds IMAP/SMTP connection settings.
 // Password is a SecretString — it will never appear in logs.
+//
+// For Gmail / Microsoft 365 set OAuth2Token (or TokenSource, which is
+// consulted on every connection and takes precedence) instead of Password;
+// XOAUTH2 is then used for authentication.
 type EmailConfig struct {
-	IMAPHost  string
-	IMAPPort  int
-	SMTPHost  string
-	SMTPPort  int
-	Username  string
-	Password  SecretString // masked in fmt/log output
-	TLS       bool
-	Simulated bool
+	IMAPHost    string
+	IMAPPort    int
+	SMTPHost    string
+	SMTPPort    int
+	Username    string
+	Password    SecretString // masked in fmt/log output
+	OAuth2Token SecretString // OAuth2 access token, masked in fmt/log output
+	TokenSource TokenSource  // optional token refresh callback
+	TLS         bool
+	Simulated   bool
 }
 
 // EmailAgent manages email operations for NEXUS.
@@ -205,39 +217,110 @@ func validateRecipient(addr string) error {
 
 // Send sends an email via SMTP (or records it in simulation mode).
 func (e *EmailAgent) Send(from string, to []string, subject, body string) error {
+	_, err := e.send(&Email{From: from, To: to, Subject: subject, Body: body})
+	return err
+}
+
+// Reply answers original, threading it via In-Reply-To and References and
+// quoting the original body below the reply. The reply goes to the original
+// sender from the configured Username; on success original is marked Replied.
+func (e *EmailAgent) Reply(original *Email, body string) error {
+	to := original.From
+	if addr, err := mail.ParseAddress(original.From); err == nil {
+		to = addr.Address
+	}
+	subject := original.Subject
+	if !strings.HasPrefix(strings.ToLower(subject), "re:") {
+		subject = "Re: " + subject
+	}
+	var refs []string
+	if original.MessageID != "" {
+		refs = append(append(refs, original.References...), original.MessageID)
+	}
+	if _, err := e.send(&Email{
+		From:       e.cfg.Username,
+		To:         []string{to},
+		Subject:    subject,
+		Body:       body + "\n\n" + quoteOriginal(original),
+		References: refs,
+	}); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	original.Replied = true
+	e.mu.Unlock()
+	return nil
+}
+
+// quoteOriginal renders the "On <date>, <sender> wrote:" block.
+func quoteOriginal(em *Email) string {
+	var sb strings.Builder
+	if em.ReceivedAt.IsZero() {
+		fmt.Fprintf(&sb, "%s wrote:\n", em.From)
+	} else {
+		fmt.Fprintf(&sb, "On %s, %s wrote:\n", em.ReceivedAt.Format("Mon, 2 Jan 2006 at 15:04"), em.From)
+	}
+	for _, line := range strings.Split(strings.TrimRight(em.Body, "\n"), "\n") {
+		sb.WriteString("> " + line + "\n")
+	}
+	return sb.String()
+}
+
+// send sanitises and delivers msg, recording it in e.sent. The In-Reply-To
+// and References headers come from msg.References, whose last entry is the
+// message being answered.
+func (e *EmailAgent) send(msg *Email) (*Email, error) {
 	// Sanitise header fields to prevent SMTP injection.
-	from = sanitiseHeader(from)
-	subject = sanitiseHeader(subject)
+	from := sanitiseHeader(msg.From)
+	subject := sanitiseHeader(msg.Subject)
+	body := msg.Body
+	to := msg.To
+	refs := make([]string, 0, len(msg.References))
+	for _, id := range msg.References {
+		if id = sanitiseHeader(id); id != "" {
+			refs = append(refs, id)
+		}
+	}
 	sanitised := make([]string, 0, len(to))
 	for _, addr := range to {
 		if err := validateRecipient(addr); err != nil {
-			return err
+			return nil, err
 		}
 		sanitised = append(sanitised, sanitiseHeader(addr))
 	}
 	to = sanitised
 
-	if e.cfg.Simulated {
-		sent := &Email{
-			ID:         fmt.Sprintf("sent-%d", time.Now().UnixNano()),
-			From:       from,
-			To:         to,
-			Subject:    subject,
-			Body:       body,
-			ReceivedAt: time.Now(),
+	sent := &Email{
+		ID:         fmt.Sprintf("sent-%d", time.Now().UnixNano()),
+		From:       from,
+		To:         to,
+		Subject:    subject,
+		Body:       body,
+		References: refs,
+		ReceivedAt: time.Now(),
+	}
+	if !e.cfg.Simulated {
+		if err := e.smtpSend(from, to, subject, body, refs); err != nil {
+			return nil, err
 		}
-		e.mu.Lock()
-		e.sent = append(e.sent, sent)
-		e.mu.Unlock()
-		return nil
 	}
-	return e.smtpSend(from, to, subject, body)
+	e.mu.Lock()
+	e.sent = append(e.sent, sent)
+	e.mu.Unlock()
+	return sent, nil
 }
 
-func (e *EmailAgent) smtpSend(from string, to []string, subject, body string) error {
-	auth := smtp.PlainAuth("", e.cfg.Username, e.cfg.Password.Value(), e.cfg.SMTPHost)
-	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s",
-		from, strings.Join(to, ","), subject, body)
+func (e *EmailAgent) smtpSend(from string, to []string, subject, body string, refs []string) error {
+	auth, err := e.smtpAuth()
+	if err != nil {
+		return err
+	}
+	var threading string
+	if len(refs) > 0 {
+		threading = fmt.Sprintf("In-Reply-To: %s\r\nReferences: %s\r\n", refs[len(refs)-1], strings.Join(refs, " "))
+	}
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n%s\r\n%s",
+		from, strings.Join(to, ","), subject, threading, body)
 	addr := fmt.Sprintf("%s:%d", e.cfg.SMTPHost, e.cfg.SMTPPort)
 	if e.cfg.TLS {
 		conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: e.cfg.SMTPHost})
@@ -286,7 +369,9 @@ func (e *EmailAgent) Inbox() []*Email {
 	return result
 }
 
-// FormatDigest returns a short email summary for the daily digest.
+// FormatDigest returns a short email summary for the daily digest. Emails
+// that have been through Summarise are listed with their summary and action
+// items (spam is left out).
 func (e *EmailAgent) FormatDigest() string {
 	inbox := e.Inbox()
 	if len(inbox) == 0 {
@@ -298,5 +383,18 @@ func (e *EmailAgent) FormatDigest() string {
 			urgent++
 		}
 	}
-	return fmt.Sprintf("📧 Inbox: %d emails (%d urgent)", len(inbox), urgent)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📧 Inbox: %d emails (%d urgent)", len(inbox), urgent)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, em := range inbox {
+		if em.Summary == "" || em.Priority == PrioritySpam {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n  • [%s] %s — %s", em.Priority, em.Subject, em.Summary)
+		for _, item := range em.ActionItems {
+			fmt.Fprintf(&sb, "\n      ☐ %s", item)
+		}
+	}
+	return sb.String()
 }
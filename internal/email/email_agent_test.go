@@ -1,8 +1,15 @@
 package email
 
 import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
 	"strings"
 	"testing"
+
+	"github.com/Omkar0612/nexus-ai/internal/types"
 )
 
 func TestEmailClassify(t *testing.T) {
@@ -80,3 +87,208 @@ func TestEmailAutoRule(t *testing.T) {
 		t.Errorf("expected 1 archived email, got %d", archived)
 	}
 }
+
+func TestXOAUTH2Auth(t *testing.T) {
+	auth := XOAUTH2Auth("me@example.com", NewSecret("tok123"), "smtp.gmail.com")
+	mech, resp, err := auth.Start(&smtp.ServerInfo{Name: "smtp.gmail.com", TLS: true})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if mech != "XOAUTH2" || string(resp) != "user=me@example.com\x01auth=Bearer tok123\x01\x01" {
+		t.Errorf("unexpected initial response %q %q", mech, resp)
+	}
+	if _, _, err := auth.Start(&smtp.ServerInfo{Name: "smtp.gmail.com", TLS: false}); err == nil {
+		t.Error("expected XOAUTH2 to be refused without TLS")
+	}
+}
+
+func TestSMTPAuthUsesTokenSource(t *testing.T) {
+	calls := 0
+	agent := New(EmailConfig{
+		SMTPHost: "smtp.office365.com",
+		Username: "me@corp.com",
+		Password: NewSecret("unused"),
+		TokenSource: func() (string, error) {
+			calls++
+			return "fresh-token", nil
+		},
+	})
+	auth, err := agent.smtpAuth()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, resp, err := auth.Start(&smtp.ServerInfo{Name: "smtp.office365.com", TLS: true})
+	if err != nil || !strings.Contains(string(resp), "Bearer fresh-token") || calls != 1 {
+		t.Errorf("expected refreshed token, got %q err=%v calls=%d", resp, err, calls)
+	}
+	if got := fmt.Sprintf("%v", agent.cfg); strings.Contains(got, "fresh-token") || strings.Contains(got, "unused") {
+		t.Errorf("config leaked a secret: %s", got)
+	}
+}
+
+// fakeIMAP serves a two-message INBOX on a loopback listener and records
+// the commands it receives.
+func fakeIMAP(t *testing.T, messages []string) (port int, cmds *[]string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	var got []string
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		fmt.Fprint(conn, "* OK IMAP4rev1 ready\r\n")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			tag, cmd, _ := strings.Cut(strings.TrimSpace(line), " ")
+			got = append(got, cmd)
+			switch {
+			case strings.HasPrefix(cmd, "EXAMINE"):
+				fmt.Fprintf(conn, "* %d EXISTS\r\n%s OK [READ-ONLY] done\r\n", len(messages), tag)
+			case strings.HasPrefix(cmd, "FETCH"):
+				for i, m := range messages {
+					fmt.Fprintf(conn, "* %d FETCH (UID %d FLAGS (\\Seen) BODY[] {%d}\r\n%s)\r\n", i+1, 100+i, len(m), m)
+				}
+				fmt.Fprintf(conn, "%s OK done\r\n", tag)
+			default:
+				fmt.Fprintf(conn, "%s OK done\r\n", tag)
+			}
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port, &got
+}
+
+func TestFetchInboxIMAP(t *testing.T) {
+	plain := "From: Alice <alice@example.com>\r\nTo: me@example.com\r\nSubject: =?UTF-8?Q?Invoice_=E2=82=AC12?=\r\n" +
+		"Date: Mon, 02 Mar 2026 10:00:00 +0000\r\n\r\nPlease pay the invoice.\r\n"
+	multi := "From: ops@example.com\r\nTo: me@example.com\r\nSubject: Server down\r\n" +
+		"Content-Type: multipart/alternative; boundary=XX\r\n\r\n" +
+		"--XX\r\nContent-Type: text/plain\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\nURGENT: db=3D1 is down\r\n" +
+		"--XX\r\nContent-Type: text/html\r\nContent-Transfer-Encoding: base64\r\n\r\nPGI+VVJHRU5UPC9iPg==\r\n--XX--\r\n"
+	port, cmds := fakeIMAP(t, []string{plain, multi})
+
+	a := New(EmailConfig{IMAPHost: "127.0.0.1", IMAPPort: port, Username: "me", Password: NewSecret("hunter2")})
+	got, err := a.FetchInbox(10)
+	if err != nil {
+		t.Fatalf("FetchInbox: %v", err)
+	}
+	if len(got) != 2 || len(a.Inbox()) != 2 {
+		t.Fatalf("fetched %d, inbox %d", len(got), len(a.Inbox()))
+	}
+	if got[0].Subject != "Invoice €12" || got[0].ID != "imap-100" || !got[0].Read || got[0].Priority != PriorityHigh {
+		t.Errorf("first = %+v", got[0])
+	}
+	if got[1].Body != "URGENT: db=1 is down" || got[1].HTMLBody != "<b>URGENT</b>" || got[1].Priority != PriorityUrgent {
+		t.Errorf("second body=%q html=%q priority=%s", got[1].Body, got[1].HTMLBody, got[1].Priority)
+	}
+	if (*cmds)[0] != `LOGIN "me" "hunter2"` || !strings.HasPrefix((*cmds)[2], "FETCH 1:2 ") {
+		t.Errorf("commands = %q", *cmds)
+	}
+
+	// Plaintext IMAP to a remote host is refused before connecting.
+	remote := New(EmailConfig{IMAPHost: "imap.example.com", IMAPPort: 143, Password: NewSecret("x")})
+	if _, err := remote.FetchInbox(5); err == nil || !strings.Contains(err.Error(), "unencrypted") {
+		t.Errorf("expected plaintext refusal, got %v", err)
+	}
+}
+
+func TestFetchInboxSimulated(t *testing.T) {
+	a := New(EmailConfig{Simulated: true, IMAPHost: "unreachable.invalid"})
+	a.IngestSimulated([]*Email{{ID: "1", Subject: "a"}, {ID: "2", Subject: "b"}, {ID: "3", Subject: "c"}})
+	got, err := a.FetchInbox(2)
+	if err != nil || len(got) != 2 || got[0].ID != "2" {
+		t.Fatalf("FetchInbox = %v, %v", got, err)
+	}
+}
+
+type cannedCompleter struct {
+	reply string
+	calls int
+	user  string
+}
+
+func (c *cannedCompleter) Complete(_ context.Context, _, userMsg string) (*types.AgentResult, error) {
+	c.calls++
+	c.user = userMsg
+	return &types.AgentResult{Content: c.reply}, nil
+}
+
+func TestSummarise(t *testing.T) {
+	a := New(EmailConfig{Simulated: true})
+	em := &Email{From: "cfo@example.com", Subject: "Invoice deadline today", Body: "Please pay invoice #42 by 5pm.\napi_key: sk-live-123"}
+	a.IngestSimulated([]*Email{em})
+	c := &cannedCompleter{reply: "SUMMARY: Invoice #42 must be paid by 5pm today.\nACTIONS:\n- Pay invoice #42\n- Confirm with the CFO"}
+
+	if err := a.Summarise(context.Background(), c, em); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(c.user, "sk-live-123") {
+		t.Error("secret sent to the LLM unredacted")
+	}
+	if em.Summary != "Invoice #42 must be paid by 5pm today." || len(em.ActionItems) != 2 {
+		t.Errorf("summary=%q actions=%q", em.Summary, em.ActionItems)
+	}
+	if err := a.Summarise(context.Background(), c, em); err != nil || c.calls != 1 {
+		t.Errorf("re-summarise: err=%v calls=%d, want cached", err, c.calls)
+	}
+	if d := a.FormatDigest(); !strings.Contains(d, "Invoice #42 must be paid") || !strings.Contains(d, "Confirm with the CFO") {
+		t.Errorf("digest = %q", d)
+	}
+
+	if s, actions := parseSummary("SUMMARY: FYI only\nACTIONS:\n- none"); s != "FYI only" || len(actions) != 0 {
+		t.Errorf("parseSummary = %q %q", s, actions)
+	}
+}
+
+func TestReplyThreads(t *testing.T) {
+	a := New(EmailConfig{Simulated: true, Username: "me@example.com"})
+	orig := &Email{
+		From:       "Alice <alice@example.com>",
+		Subject:    "Quarterly numbers",
+		Body:       "Can you send them?\nThanks",
+		MessageID:  "<m2@example.com>",
+		References: []string{"<m1@example.com>"},
+	}
+	if err := a.Reply(orig, "Attached."); err != nil {
+		t.Fatal(err)
+	}
+	if !orig.Replied || len(a.sent) != 1 {
+		t.Fatalf("replied=%v sent=%d", orig.Replied, len(a.sent))
+	}
+	r := a.sent[0]
+	if r.Subject != "Re: Quarterly numbers" || r.To[0] != "alice@example.com" {
+		t.Errorf("reply = %+v", r)
+	}
+	if strings.Join(r.References, " ") != "<m1@example.com> <m2@example.com>" {
+		t.Errorf("references = %q", r.References)
+	}
+	if !strings.Contains(r.Body, "> Can you send them?\n> Thanks") {
+		t.Errorf("original not quoted: %q", r.Body)
+	}
+
+	// A Message-ID carrying CRLF cannot inject headers, and "Re:" is not doubled.
+	evil := &Email{From: "bob@example.com", Subject: "RE: hi", MessageID: "<x@y>\r\nBcc: victim@example.com"}
+	if err := a.Reply(evil, "ok"); err != nil {
+		t.Fatal(err)
+	}
+	r = a.sent[1]
+	if r.Subject != "RE: hi" || strings.ContainsAny(strings.Join(r.References, ""), "\r\n") {
+		t.Errorf("reply = %+v", r)
+	}
+}
+
+func TestIMAPRejectsOversizedLiteral(t *testing.T) {
+	server := "* 1 FETCH (BODY[] {999999999999}\r\n"
+	c := &imapConn{r: bufio.NewReader(strings.NewReader(server))}
+	if _, err := c.read(); err == nil || !strings.Contains(err.Error(), "limit") {
+		t.Fatalf("expected oversized literal to be rejected, got %v", err)
+	}
+}
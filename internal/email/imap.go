@@ -0,0 +1,359 @@
+package email
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// imapTimeout bounds a whole FetchInbox session.
+const imapTimeout = 60 * time.Second
+
+// defaultFetchLimit is used when FetchInbox is called with limit <= 0.
+const defaultFetchLimit = 20
+
+// maxIMAPLiteral caps the size of a single literal read from the server, so
+// a hostile or broken server cannot make us allocate an arbitrary buffer.
+const maxIMAPLiteral = 25 << 20
+
+var (
+	imapLiteralRe = regexp.MustCompile(`\{(\d+)\}$`)
+	imapExistsRe  = regexp.MustCompile(`^\* (\d+) EXISTS`)
+	imapUIDRe     = regexp.MustCompile(`\bUID (\d+)`)
+	imapFlagsRe   = regexp.MustCompile(`\bFLAGS \(([^)]*)\)`)
+	imapDateRe    = regexp.MustCompile(`\bINTERNALDATE "([^"]+)"`)
+	htmlTagRe     = regexp.MustCompile(`(?s)<(script|style)[^>]*>.*?</(script|style)>|<[^>]+>`)
+)
+
+// FetchInbox fetches the most recent limit messages from the IMAP INBOX,
+// parses headers and plain/HTML bodies, classifies them and adds any not
+// yet seen to the inbox. The mailbox is opened read-only (EXAMINE with
+// BODY.PEEK), so fetching never marks mail as read on the server.
+//
+// In simulated mode no connection is made; the most recent ingested emails
+// are returned instead.
+func (e *EmailAgent) FetchInbox(limit int) ([]*Email, error) {
+	if limit <= 0 {
+		limit = defaultFetchLimit
+	}
+	if e.cfg.Simulated {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		start := len(e.inbox) - limit
+		if start < 0 {
+			start = 0
+		}
+		return append([]*Email(nil), e.inbox[start:]...), nil
+	}
+
+	fetched, err := e.imapFetch(limit)
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	known := make(map[string]bool, len(e.inbox))
+	for _, em := range e.inbox {
+		known[em.ID] = true
+	}
+	for _, em := range fetched {
+		em.Priority = Classify(em)
+		if !known[em.ID] {
+			e.inbox = append(e.inbox, em)
+		}
+	}
+	e.mu.Unlock()
+	return fetched, nil
+}
+
+// imapConn is a minimal IMAP4rev1 client: enough for LOGIN/AUTHENTICATE,
+// EXAMINE, FETCH and LOGOUT.
+type imapConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+}
+
+// imapResponse is one untagged or tagged response line, with any literals
+// ({n}-prefixed byte strings) it carried collected separately.
+type imapResponse struct {
+	line     string
+	literals [][]byte
+}
+
+func (e *EmailAgent) imapFetch(limit int) ([]*Email, error) {
+	addr := net.JoinHostPort(e.cfg.IMAPHost, strconv.Itoa(e.cfg.IMAPPort))
+	var (
+		conn net.Conn
+		err  error
+	)
+	if e.cfg.TLS {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: imapTimeout}, "tcp", addr, &tls.Config{ServerName: e.cfg.IMAPHost})
+	} else {
+		// Like smtp.PlainAuth, never send credentials in the clear to a
+		// remote server.
+		if !isLocalhost(e.cfg.IMAPHost) {
+			return nil, errors.New("email: refusing IMAP login over unencrypted connection")
+		}
+		conn, err = net.DialTimeout("tcp", addr, imapTimeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("email: imap dial: %w", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(imapTimeout))
+
+	c := &imapConn{conn: conn, r: bufio.NewReader(conn)}
+	greeting, err := c.read()
+	if err != nil {
+		return nil, fmt.Errorf("email: imap greeting: %w", err)
+	}
+	if !strings.HasPrefix(greeting.line, "* OK") && !strings.HasPrefix(greeting.line, "* PREAUTH") {
+		return nil, fmt.Errorf("email: imap greeting: %s", greeting.line)
+	}
+	if !strings.HasPrefix(greeting.line, "* PREAUTH") {
+		if err := e.imapLogin(c); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := c.command("EXAMINE INBOX")
+	if err != nil {
+		return nil, err
+	}
+	exists := 0
+	for _, r := range resp {
+		if m := imapExistsRe.FindStringSubmatch(r.line); m != nil {
+			exists, _ = strconv.Atoi(m[1])
+		}
+	}
+	var emails []*Email
+	if exists > 0 {
+		from := exists - limit + 1
+		if from < 1 {
+			from = 1
+		}
+		resp, err = c.command(fmt.Sprintf("FETCH %d:%d (UID FLAGS INTERNALDATE BODY.PEEK[])", from, exists))
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resp {
+			if !strings.Contains(r.line, " FETCH ") || len(r.literals) == 0 {
+				continue
+			}
+			em, err := parseFetched(r)
+			if err != nil {
+				return nil, err
+			}
+			emails = append(emails, em)
+		}
+	}
+	_, _ = c.command("LOGOUT")
+	return emails, nil
+}
+
+// imapLogin authenticates with XOAUTH2 when a token is configured,
+// otherwise with LOGIN and the password.
+func (e *EmailAgent) imapLogin(c *imapConn) error {
+	tok, oauth, err := e.accessToken()
+	if err != nil {
+		return err
+	}
+	if oauth {
+		_, err = c.command("AUTHENTICATE XOAUTH2 " + IMAPXOAUTH2(e.cfg.Username, tok))
+	} else {
+		_, err = c.command("LOGIN " + imapQuote(e.cfg.Username) + " " + imapQuote(e.cfg.Password.Value()))
+	}
+	if err != nil {
+		// The server's reply never contains the credential, but the command
+		// does — report only which step failed.
+		return fmt.Errorf("email: imap auth failed: %w", err)
+	}
+	return nil
+}
+
+// command sends a tagged command and collects responses up to its
+// completion. A continuation request ("+ ...") is answered with an empty
+// line; for XOAUTH2 that makes the server send its final NO.
+func (c *imapConn) command(cmd string) ([]imapResponse, error) {
+	c.tag++
+	tag := fmt.Sprintf("a%d", c.tag)
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, cmd); err != nil {
+		return nil, err
+	}
+	verb := strings.SplitN(cmd, " ", 2)[0]
+	var out []imapResponse
+	for {
+		r, err := c.read()
+		if err != nil {
+			return nil, fmt.Errorf("email: imap %s: %w", verb, err)
+		}
+		switch {
+		case strings.HasPrefix(r.line, "+"):
+			if _, err := io.WriteString(c.conn, "\r\n"); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(r.line, tag+" "):
+			status := strings.TrimPrefix(r.line, tag+" ")
+			if !strings.HasPrefix(status, "OK") {
+				return nil, fmt.Errorf("email: imap %s: %s", verb, status)
+			}
+			return out, nil
+		default:
+			out = append(out, r)
+		}
+	}
+}
+
+// read reads one response, following any literals onto continuation lines.
+func (c *imapConn) read() (imapResponse, error) {
+	var r imapResponse
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return r, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		r.line += line
+		m := imapLiteralRe.FindStringSubmatch(line)
+		if m == nil {
+			return r, nil
+		}
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n > maxIMAPLiteral {
+			return r, fmt.Errorf("literal of %s bytes exceeds the %d byte limit", m[1], maxIMAPLiteral)
+		}
+		lit := make([]byte, n)
+		if _, err := io.ReadFull(c.r, lit); err != nil {
+			return r, err
+		}
+		r.literals = append(r.literals, lit)
+	}
+}
+
+// imapQuote renders s as an IMAP quoted string.
+func imapQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+	return `"` + s + `"`
+}
+
+// parseFetched turns one FETCH response into an Email.
+func parseFetched(r imapResponse) (*Email, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(r.literals[0]))
+	if err != nil {
+		return nil, fmt.Errorf("email: parse message: %w", err)
+	}
+	em := &Email{}
+	if m := imapUIDRe.FindStringSubmatch(r.line); m != nil {
+		em.ID = "imap-" + m[1]
+	}
+	if m := imapFlagsRe.FindStringSubmatch(r.line); m != nil {
+		em.Read = strings.Contains(m[1], `\Seen`)
+		em.Replied = strings.Contains(m[1], `\Answered`)
+	}
+
+	dec := new(mime.WordDecoder)
+	decode := func(s string) string {
+		if d, err := dec.DecodeHeader(s); err == nil {
+			return d
+		}
+		return s
+	}
+	em.From = decode(msg.Header.Get("From"))
+	em.Subject = decode(msg.Header.Get("Subject"))
+	em.To = addressList(msg.Header, "To")
+	em.CC = addressList(msg.Header, "Cc")
+	if t, err := msg.Header.Date(); err == nil {
+		em.ReceivedAt = t
+	} else if m := imapDateRe.FindStringSubmatch(r.line); m != nil {
+		em.ReceivedAt, _ = time.Parse("02-Jan-2006 15:04:05 -0700", m[1])
+	}
+	em.MessageID = strings.TrimSpace(msg.Header.Get("Message-Id"))
+	em.References = strings.Fields(msg.Header.Get("References"))
+	if em.ID == "" {
+		em.ID = em.MessageID
+	}
+
+	plain, htmlBody := readBody(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	em.Body, em.HTMLBody = plain, htmlBody
+	if em.Body == "" && htmlBody != "" {
+		em.Body = htmlToText(htmlBody)
+	}
+	return em, nil
+}
+
+func addressList(h mail.Header, key string) []string {
+	list, err := h.AddressList(key)
+	if err != nil {
+		if v := h.Get(key); v != "" {
+			return []string{v}
+		}
+		return nil
+	}
+	out := make([]string, 0, len(list))
+	for _, a := range list {
+		out = append(out, a.Address)
+	}
+	return out
+}
+
+// readBody returns the first text/plain and text/html parts of a message,
+// descending into multipart containers.
+func readBody(contentType, encoding string, body io.Reader) (plain, htmlBody string) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err != nil {
+				break
+			}
+			p, h := readBody(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if plain == "" {
+				plain = p
+			}
+			if htmlBody == "" {
+				htmlBody = h
+			}
+		}
+		return plain, htmlBody
+	}
+
+	switch strings.ToLower(encoding) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body) // skips line breaks
+	}
+	data, _ := io.ReadAll(body)
+	switch mediaType {
+	case "text/plain":
+		return strings.TrimSpace(string(data)), ""
+	case "text/html":
+		return "", string(data)
+	}
+	return "", "" // attachments and other media are skipped
+}
+
+// htmlToText is a rough HTML → text conversion for classification and
+// summaries of HTML-only mail.
+func htmlToText(s string) string {
+	s = htmlTagRe.ReplaceAllString(s, " ")
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
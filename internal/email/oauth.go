@@ -0,0 +1,95 @@
+package email
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+)
+
+// TokenSource returns a current OAuth2 access token, refreshing it if needed.
+// It is called once per connection, so implementations should cache.
+type TokenSource func() (string, error)
+
+// xoauth2Auth implements smtp.Auth for the XOAUTH2 SASL mechanism used by
+// Gmail and Microsoft 365 once basic auth is disabled.
+type xoauth2Auth struct {
+	username string
+	token    SecretString
+	host     string
+}
+
+// XOAUTH2Auth returns an smtp.Auth that authenticates with an OAuth2 access token.
+// Like smtp.PlainAuth it refuses to send the token over an unencrypted
+// connection unless the server is localhost.
+func XOAUTH2Auth(username string, token SecretString, host string) smtp.Auth {
+	return &xoauth2Auth{username: username, token: token, host: host}
+}
+
+func (a *xoauth2Auth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("email: refusing XOAUTH2 over unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("email: wrong host name for XOAUTH2")
+	}
+	return "XOAUTH2", []byte(xoauth2String(a.username, a.token.Value())), nil
+}
+
+// Next handles the server challenge. On failure the server sends a base64
+// JSON error as a continuation; an empty response makes it return the final
+// SMTP error code instead of hanging.
+func (a *xoauth2Auth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if more {
+		return []byte{}, nil
+	}
+	return nil, nil
+}
+
+// xoauth2String builds the raw SASL XOAUTH2 initial response.
+func xoauth2String(username, token string) string {
+	return "user=" + username + "\x01auth=Bearer " + token + "\x01\x01"
+}
+
+// IMAPXOAUTH2 returns the base64 argument for the IMAP command
+// "AUTHENTICATE XOAUTH2 <arg>".
+func IMAPXOAUTH2(username string, token SecretString) string {
+	return base64.StdEncoding.EncodeToString([]byte(xoauth2String(username, token.Value())))
+}
+
+// accessToken returns the OAuth2 token to use, preferring TokenSource.
+// The second result is false when the agent is configured for password auth.
+func (e *EmailAgent) accessToken() (SecretString, bool, error) {
+	if e.cfg.TokenSource != nil {
+		tok, err := e.cfg.TokenSource()
+		if err != nil {
+			return SecretString{}, true, fmt.Errorf("email: refresh oauth2 token: %w", err)
+		}
+		return NewSecret(tok), true, nil
+	}
+	if e.cfg.OAuth2Token.Value() != "" {
+		return e.cfg.OAuth2Token, true, nil
+	}
+	return SecretString{}, false, nil
+}
+
+// smtpAuth picks XOAUTH2 when a token is configured, otherwise PLAIN.
+func (e *EmailAgent) smtpAuth() (smtp.Auth, error) {
+	tok, oauth, err := e.accessToken()
+	if err != nil {
+		return nil, err
+	}
+	if oauth {
+		return XOAUTH2Auth(e.cfg.Username, tok, e.cfg.SMTPHost), nil
+	}
+	return smtp.PlainAuth("", e.cfg.Username, e.cfg.Password.Value(), e.cfg.SMTPHost), nil
+}
+
+func isLocalhost(name string) bool {
+	if name == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(name)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,79 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Omkar0612/nexus-ai/internal/types"
+)
+
+// maxSummariseChars caps the body sent to the LLM; long threads are mostly
+// quoted history anyway.
+const maxSummariseChars = 8000
+
+// Completer is the subset of router.Router used by Summarise.
+type Completer interface {
+	Complete(ctx context.Context, systemPrompt, userMsg string) (*types.AgentResult, error)
+}
+
+const summariseSystemPrompt = `You summarise emails for a busy reader. Respond exactly in this format:
+SUMMARY: <one line, at most 25 words>
+ACTIONS:
+- <one action item the reader must do, per line>
+Write "- none" under ACTIONS if nothing is required.`
+
+// Summarise fills em.Summary and em.ActionItems using the LLM. The body is
+// passed through Redact first, so secrets never leave the machine. An email
+// that already has a summary is left untouched.
+func (e *EmailAgent) Summarise(ctx context.Context, r Completer, em *Email) error {
+	e.mu.Lock()
+	done := em.Summary != ""
+	e.mu.Unlock()
+	if done {
+		return nil
+	}
+
+	body := em.Body
+	if body == "" && em.HTMLBody != "" {
+		body = htmlToText(em.HTMLBody)
+	}
+	if len(body) > maxSummariseChars {
+		body = body[:maxSummariseChars] + "\n[truncated]"
+	}
+	user := fmt.Sprintf("From: %s\nSubject: %s\n\n%s", em.From, e.Redact(em.Subject), e.Redact(body))
+	res, err := r.Complete(ctx, summariseSystemPrompt, user)
+	if err != nil {
+		return fmt.Errorf("email: summarise: %w", err)
+	}
+	summary, actions := parseSummary(res.Content)
+	if summary == "" {
+		return fmt.Errorf("email: summarise: empty summary")
+	}
+
+	e.mu.Lock()
+	em.Summary, em.ActionItems = summary, actions
+	e.mu.Unlock()
+	return nil
+}
+
+// parseSummary extracts the SUMMARY line and the bulleted ACTIONS list. A
+// reply that ignores the format is used whole as the summary.
+func parseSummary(raw string) (summary string, actions []string) {
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(strings.ToUpper(line), "SUMMARY:"):
+			summary = strings.TrimSpace(line[len("SUMMARY:"):])
+		case strings.HasPrefix(line, "- "), strings.HasPrefix(line, "* "):
+			item := strings.TrimSpace(line[2:])
+			if item != "" && !strings.EqualFold(strings.TrimSuffix(item, "."), "none") {
+				actions = append(actions, item)
+			}
+		}
+	}
+	if summary == "" {
+		summary = strings.Join(strings.Fields(raw), " ")
+	}
+	return summary, actions
+}
@@ -0,0 +1,200 @@
+// Package enrich injects relevant local context — knowledge-base chunks and
+// semantic memories — into LLM calls. An Injector's Inject method plugs into
+// router.SetContextInjector, so every completion sees what the user's own
+// files and past conversations say about the message.
+package enrich
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Omkar0612/nexus-ai/internal/semantic"
+)
+
+const (
+	// DefaultMaxChars bounds the combined injected context.
+	DefaultMaxChars = 4000
+	// DefaultTopK is the number of results requested from each retriever.
+	DefaultTopK = 5
+)
+
+// memoryHeader introduces the semantic memory section, mirroring the
+// "[Knowledge Base Context]" header written by kb.BuildContext.
+const memoryHeader = "[Relevant Memories]\n"
+
+// KnowledgeSource is the lexical retriever. *kb.KnowledgeBase satisfies it.
+type KnowledgeSource interface {
+	BuildContext(query string, topK int, maxChars int) string
+}
+
+// MemorySource is the embedding retriever. *semantic.Store satisfies it.
+type MemorySource interface {
+	Search(ctx context.Context, query string, topK int) ([]semantic.Document, error)
+}
+
+// Injector builds the context block prepended to the system prompt.
+// It is safe for concurrent use; the toggle and budget may change at any
+// time.
+type Injector struct {
+	kb       KnowledgeSource
+	mem      MemorySource
+	topK     int
+	minScore float64
+
+	enabled  atomic.Bool
+	maxChars atomic.Int64
+}
+
+// Option configures an Injector.
+type Option func(*Injector)
+
+// WithMaxChars sets the character budget for the combined context.
+func WithMaxChars(n int) Option {
+	return func(in *Injector) { in.maxChars.Store(int64(n)) }
+}
+
+// WithTopK sets how many results each retriever returns.
+func WithTopK(n int) Option {
+	return func(in *Injector) { in.topK = n }
+}
+
+// WithMinScore drops semantic memories scoring below s (cosine similarity),
+// so loosely related memories are not injected just to fill the budget.
+func WithMinScore(s float64) Option {
+	return func(in *Injector) { in.minScore = s }
+}
+
+// New returns an enabled Injector over kb and mem. Either may be nil.
+func New(kb KnowledgeSource, mem MemorySource, opts ...Option) *Injector {
+	in := &Injector{kb: kb, mem: mem, topK: DefaultTopK}
+	in.enabled.Store(true)
+	in.maxChars.Store(DefaultMaxChars)
+	for _, o := range opts {
+		o(in)
+	}
+	if in.topK <= 0 {
+		in.topK = DefaultTopK
+	}
+	if in.maxChars.Load() <= 0 {
+		in.maxChars.Store(DefaultMaxChars)
+	}
+	return in
+}
+
+// SetEnabled turns injection on or off. A disabled Injector returns no
+// context and queries neither retriever.
+func (in *Injector) SetEnabled(on bool) { in.enabled.Store(on) }
+
+// Enabled reports whether injection is on.
+func (in *Injector) Enabled() bool { return in.enabled.Load() }
+
+// SetMaxChars sets the character budget; n <= 0 restores DefaultMaxChars.
+func (in *Injector) SetMaxChars(n int) {
+	if n <= 0 {
+		n = DefaultMaxChars
+	}
+	in.maxChars.Store(int64(n))
+}
+
+// MaxChars returns the character budget.
+func (in *Injector) MaxChars() int { return int(in.maxChars.Load()) }
+
+// Inject returns the context for userMsg: knowledge-base chunks followed by
+// semantic memories, at most MaxChars long. When memories are found the KB
+// gets half the budget and memories fill the rest; otherwise the KB may use
+// all of it. Memories already present in the KB context, or repeated, are
+// dropped.
+//
+// If the semantic search fails, the KB context is returned together with
+// the error, so a caller can still use it while Ollama is down.
+func (in *Injector) Inject(ctx context.Context, userMsg string) (string, error) {
+	query := strings.TrimSpace(userMsg)
+	if !in.Enabled() || query == "" {
+		return "", nil
+	}
+	budget := in.MaxChars()
+
+	var memories []semantic.Document
+	var memErr error
+	if in.mem != nil {
+		docs, err := in.mem.Search(ctx, query, in.topK)
+		if err != nil {
+			memErr = fmt.Errorf("enrich: semantic search: %w", err)
+		}
+		for _, d := range docs {
+			if d.Score >= in.minScore && strings.TrimSpace(d.Content) != "" {
+				memories = append(memories, d)
+			}
+		}
+	}
+
+	var kbCtx string
+	if in.kb != nil {
+		kbBudget := budget
+		if len(memories) > 0 {
+			kbBudget = budget / 2
+		}
+		// BuildContext's limit excludes its header, so check the total too.
+		if kbCtx = in.kb.BuildContext(query, in.topK, kbBudget); len(kbCtx) > budget {
+			kbCtx = ""
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(kbCtx)
+	if mem := memorySection(memories, normalise(kbCtx), budget-len(kbCtx)); mem != "" {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(mem)
+	}
+	return sb.String(), memErr
+}
+
+// memorySection formats memories within maxChars, skipping any whose text
+// already appears in seen (the normalised KB context) or earlier in the list.
+// Entries that do not fit are skipped so shorter ones can still be used.
+func memorySection(memories []semantic.Document, seen string, maxChars int) string {
+	// Reserve one byte for the separator written before the section.
+	remaining := maxChars - len(memoryHeader) - 1
+	if remaining <= 0 || len(memories) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	dup := make(map[string]bool)
+	for _, m := range memories {
+		key := normalise(m.Content)
+		if dup[key] || strings.Contains(seen, key) {
+			continue
+		}
+		dup[key] = true
+		entry := fmt.Sprintf("--- %s ---\n%s\n", memoryLabel(m), strings.TrimSpace(m.Content))
+		if len(entry) > remaining {
+			continue
+		}
+		sb.WriteString(entry)
+		remaining -= len(entry)
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	return memoryHeader + sb.String()
+}
+
+// memoryLabel names a memory by its title metadata, falling back to source.
+func memoryLabel(d semantic.Document) string {
+	if t := d.Metadata["title"]; t != "" {
+		return t
+	}
+	if d.Source != "" {
+		return d.Source
+	}
+	return "memory"
+}
+
+// normalise lowercases s and collapses whitespace for duplicate detection.
+func normalise(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
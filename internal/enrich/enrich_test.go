@@ -0,0 +1,121 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Omkar0612/nexus-ai/internal/kb"
+	"github.com/Omkar0612/nexus-ai/internal/semantic"
+)
+
+var (
+	_ KnowledgeSource = (*kb.KnowledgeBase)(nil)
+	_ MemorySource    = (*semantic.Store)(nil)
+)
+
+type fakeKB struct {
+	text    string
+	queried int
+}
+
+func (f *fakeKB) BuildContext(query string, topK int, maxChars int) string {
+	f.queried++
+	if f.text == "" {
+		return ""
+	}
+	chunk := "--- notes.md ---\n" + f.text + "\n"
+	if len(chunk) > maxChars {
+		return ""
+	}
+	return "[Knowledge Base Context]\n" + chunk
+}
+
+type fakeMemory struct {
+	docs []semantic.Document
+	err  error
+}
+
+func (f *fakeMemory) Search(ctx context.Context, query string, topK int) ([]semantic.Document, error) {
+	return f.docs, f.err
+}
+
+func TestInjectCombinesAndDedupes(t *testing.T) {
+	base := &fakeKB{text: "Vault keys rotate every 90 days."}
+	mem := &fakeMemory{docs: []semantic.Document{
+		{Content: "vault keys   rotate every 90 days.", Source: "kb", Score: 0.9},
+		{Content: "User prefers short answers.", Source: "conversation", Score: 0.8},
+		{Content: "User prefers short answers.", Source: "conversation", Score: 0.7},
+		{Content: "Unrelated memory.", Source: "notes", Score: 0.1},
+	}}
+	in := New(base, mem, WithMinScore(0.5))
+
+	out, err := in.Inject(context.Background(), "when do vault keys rotate?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "[Knowledge Base Context]") || !strings.Contains(out, memoryHeader) {
+		t.Fatalf("expected KB and memory sections, got:\n%s", out)
+	}
+	if n := strings.Count(strings.ToLower(out), "rotate every 90 days"); n != 1 {
+		t.Errorf("KB chunk repeated as memory (%d copies):\n%s", n, out)
+	}
+	if n := strings.Count(out, "User prefers short answers."); n != 1 {
+		t.Errorf("duplicate memory not dropped (%d copies):\n%s", n, out)
+	}
+	if strings.Contains(out, "Unrelated memory.") {
+		t.Errorf("memory below min score injected:\n%s", out)
+	}
+}
+
+func TestInjectRespectsBudget(t *testing.T) {
+	base := &fakeKB{text: strings.Repeat("k", 60)}
+	mem := &fakeMemory{docs: []semantic.Document{
+		{Content: strings.Repeat("m", 80), Source: "long", Score: 0.9},
+		{Content: "short memory", Source: "s", Score: 0.8},
+	}}
+	in := New(base, mem, WithMaxChars(200))
+
+	out, err := in.Inject(context.Background(), "query")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) > 200 {
+		t.Errorf("context is %d chars, budget 200:\n%s", len(out), out)
+	}
+	if !strings.Contains(out, "short memory") {
+		t.Errorf("a memory that fits should be kept:\n%s", out)
+	}
+
+	in.SetMaxChars(50)
+	if out, _ := in.Inject(context.Background(), "query"); len(out) > 50 {
+		t.Errorf("context is %d chars, budget 50", len(out))
+	}
+}
+
+func TestInjectDisabled(t *testing.T) {
+	base := &fakeKB{text: "anything"}
+	in := New(base, nil)
+	in.SetEnabled(false)
+
+	out, err := in.Inject(context.Background(), "query")
+	if out != "" || err != nil {
+		t.Fatalf("disabled injector returned %q, %v", out, err)
+	}
+	if base.queried != 0 {
+		t.Error("disabled injector should not query the KB")
+	}
+}
+
+func TestInjectKeepsKBContextWhenMemoryFails(t *testing.T) {
+	in := New(&fakeKB{text: "deploy on fridays"}, &fakeMemory{err: errors.New("connection refused")})
+
+	out, err := in.Inject(context.Background(), "deploy")
+	if err == nil {
+		t.Fatal("expected the semantic search error")
+	}
+	if !strings.Contains(out, "deploy on fridays") {
+		t.Errorf("KB context should survive a semantic failure, got %q", out)
+	}
+}
@@ -0,0 +1,133 @@
+// Package events provides a typed publish/subscribe bus that lets NEXUS
+// subsystems (loop detector, drift detector, cost tracker, HITL gate) emit
+// events once and have every interested sink — Telegram, dashboard, audit
+// log — receive them independently.
+//
+// Publish never blocks: a subscriber whose buffer is full misses the event
+// and the drop is counted, so a slow sink can never stall an agent.
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Kind identifies the type of an event.
+type Kind string
+
+const (
+	// KindAll subscribes to every event regardless of kind.
+	KindAll Kind = "*"
+
+	KindLoopDetected  Kind = "loop.detected"
+	KindDriftSignal   Kind = "drift.signal"
+	KindBudgetWarning Kind = "budget.warning"
+	KindBudgetBreach  Kind = "budget.breach"
+	KindHITLRequested Kind = "hitl.requested"
+	KindHITLDecision  Kind = "hitl.decision"
+)
+
+// Event is a single notification published on the bus.
+type Event struct {
+	Kind     Kind
+	Source   string // publishing subsystem, e.g. "loop_detector"
+	Severity string // low, medium, high
+	Message  string // human-readable summary, ready for Telegram/dashboard
+	// Payload carries the subsystem's own typed value (e.g. agents.LoopEvent).
+	Payload interface{}
+	At      time.Time
+}
+
+type subscriber struct {
+	kind Kind
+	ch   chan Event
+}
+
+// Bus is a fan-out publish/subscribe event bus. The zero value is not usable;
+// create one with NewBus.
+type Bus struct {
+	mu      sync.RWMutex
+	subs    []*subscriber
+	buffer  int
+	closed  bool
+	dropped atomic.Uint64
+}
+
+// NewBus creates a bus whose subscriber channels hold buffer events (default 64).
+func NewBus(buffer int) *Bus {
+	if buffer <= 0 {
+		buffer = 64
+	}
+	return &Bus{buffer: buffer}
+}
+
+// Subscribe returns a channel receiving every future event of kind.
+// Use KindAll to receive all events. The channel is closed by Unsubscribe or Close.
+func (b *Bus) Subscribe(kind Kind) <-chan Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := make(chan Event, b.buffer)
+	if b.closed {
+		close(ch)
+		return ch
+	}
+	b.subs = append(b.subs, &subscriber{kind: kind, ch: ch})
+	return ch
+}
+
+// Unsubscribe removes and closes a channel returned by Subscribe.
+func (b *Bus) Unsubscribe(ch <-chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, s := range b.subs {
+		if s.ch == ch {
+			close(s.ch)
+			b.subs = append(b.subs[:i], b.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Publish delivers e to every matching subscriber without blocking.
+// A zero At is set to the current time. Publishing on a nil or closed bus is a no-op.
+func (b *Bus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+	for _, s := range b.subs {
+		if s.kind != KindAll && s.kind != e.Kind {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			b.dropped.Add(1)
+		}
+	}
+}
+
+// Dropped returns the number of deliveries skipped because a subscriber was full.
+func (b *Bus) Dropped() uint64 { return b.dropped.Load() }
+
+// Close closes every subscriber channel. Later publishes are ignored.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for _, s := range b.subs {
+		close(s.ch)
+	}
+	b.subs = nil
+}
@@ -0,0 +1,61 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBusFanOut(t *testing.T) {
+	b := NewBus(4)
+	loops := b.Subscribe(KindLoopDetected)
+	all := b.Subscribe(KindAll)
+
+	b.Publish(Event{Kind: KindLoopDetected, Message: "loop"})
+	b.Publish(Event{Kind: KindBudgetBreach, Message: "budget"})
+
+	select {
+	case e := <-loops:
+		if e.Message != "loop" || e.At.IsZero() {
+			t.Errorf("unexpected event: %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("loop subscriber got nothing")
+	}
+	select {
+	case e := <-loops:
+		t.Errorf("loop subscriber should not receive %s", e.Kind)
+	default:
+	}
+	if len(all) != 2 {
+		t.Errorf("wildcard subscriber expected 2 events, got %d", len(all))
+	}
+}
+
+func TestBusDropsWhenFull(t *testing.T) {
+	b := NewBus(1)
+	_ = b.Subscribe(KindAll)
+	b.Publish(Event{Kind: KindDriftSignal})
+	b.Publish(Event{Kind: KindDriftSignal})
+	if b.Dropped() != 1 {
+		t.Errorf("expected 1 dropped event, got %d", b.Dropped())
+	}
+}
+
+func TestBusUnsubscribeAndClose(t *testing.T) {
+	b := NewBus(1)
+	ch := b.Subscribe(KindAll)
+	b.Unsubscribe(ch)
+	if _, ok := <-ch; ok {
+		t.Error("expected channel closed after Unsubscribe")
+	}
+
+	ch2 := b.Subscribe(KindAll)
+	b.Close()
+	if _, ok := <-ch2; ok {
+		t.Error("expected channel closed after Close")
+	}
+	b.Publish(Event{Kind: KindAll}) // must not panic
+
+	var nilBus *Bus
+	nilBus.Publish(Event{Kind: KindAll}) // must not panic
+}
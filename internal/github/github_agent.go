@@ -8,16 +8,21 @@ Security:
   - API error responses capped at 512 bytes (no internal detail leakage)
   - SearchCode query URL-escaped to prevent query-string injection
   - Response bodies limited to 4 MB (DoS protection)
+  - Rate-limited and 5xx responses retried with capped waits
 */
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/rs/zerolog/log"
 )
 
 // SecretString wraps a string and masks it in all fmt/log output.
@@ -38,6 +43,19 @@ func (s SecretString) GoString() string { return "github.SecretString([REDACTED]
 // maxResponseBytes is the maximum number of bytes read from any GitHub API response.
 const maxResponseBytes = 4 * 1024 * 1024 // 4 MB
 
+// issuesPerPage is the page size requested from list endpoints (GitHub's max).
+const issuesPerPage = 100
+
+// defaultMaxIssuePages is used when GitHubConfig.MaxIssuePages is zero.
+const defaultMaxIssuePages = 10
+
+// defaultMaxRetries is used when GitHubConfig.MaxRetries is zero.
+const defaultMaxRetries = 3
+
+// maxRetryWait caps a single wait between retries, however far away
+// Retry-After or X-RateLimit-Reset point.
+const maxRetryWait = 60 * time.Second
+
 // GitHubConfig holds GitHub API credentials.
 // Token is a SecretString — it will never appear in log files.
 type GitHubConfig struct {
@@ -46,6 +64,11 @@ type GitHubConfig struct {
 	Repo      string
 	BaseURL   string // default: https://api.github.com
 	Simulated bool
+	// MaxRetries is how often a rate-limited or 5xx request is retried
+	// (default 3; negative disables retries).
+	MaxRetries int
+	// MaxIssuePages bounds how many pages ListOpenIssues follows (default 10).
+	MaxIssuePages int
 }
 
 // Issue represents a GitHub issue.
@@ -74,6 +97,7 @@ type PullRequest struct {
 type GitHubAgent struct {
 	cfg    GitHubConfig
 	client *http.Client
+	sleep  func(time.Duration) // time.Sleep; replaced in tests
 }
 
 // New creates a GitHubAgent.
@@ -81,9 +105,16 @@ func New(cfg GitHubConfig) *GitHubAgent {
 	if cfg.BaseURL == "" {
 		cfg.BaseURL = "https://api.github.com"
 	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultMaxRetries
+	}
+	if cfg.MaxIssuePages <= 0 {
+		cfg.MaxIssuePages = defaultMaxIssuePages
+	}
 	return &GitHubAgent{
 		cfg:    cfg,
 		client: &http.Client{Timeout: 15 * time.Second},
+		sleep:  time.Sleep,
 	}
 }
 
@@ -130,21 +161,143 @@ func (g *GitHubAgent) CreateBranch(name, baseSHA string) error {
 	return g.post(fmt.Sprintf("/repos/%s/%s/git/refs", g.cfg.Owner, g.cfg.Repo), payload, nil)
 }
 
-// ListOpenIssues returns open issues for the configured repo.
-func (g *GitHubAgent) ListOpenIssues() ([]Issue, error) {
+// IssueFilter narrows ListOpenIssues. Zero values match everything.
+type IssueFilter struct {
+	Labels   []string // issues must carry all of these
+	Assignee string   // login, "none" or "*"
+}
+
+// query renders the filter as extra query-string parameters.
+func (f IssueFilter) query() string {
+	v := url.Values{}
+	if len(f.Labels) > 0 {
+		v.Set("labels", strings.Join(f.Labels, ","))
+	}
+	if f.Assignee != "" {
+		v.Set("assignee", f.Assignee)
+	}
+	if len(v) == 0 {
+		return ""
+	}
+	return "&" + v.Encode()
+}
+
+// OpenPR opens a pull request merging head into base.
+func (g *GitHubAgent) OpenPR(title, body, head, base string) (*PullRequest, error) {
+	if g.cfg.Simulated {
+		return &PullRequest{
+			Number: 998, Title: title, State: "open", Head: head, Base: base,
+			URL: fmt.Sprintf("https://github.com/%s/%s/pull/998", g.cfg.Owner, g.cfg.Repo),
+		}, nil
+	}
+	payload := map[string]string{
+		"title": title,
+		"body":  body,
+		"head":  head,
+		"base":  base,
+	}
+	// GitHub returns head/base as objects; PullRequest keeps just the ref.
+	var pr struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+		State  string `json:"state"`
+		URL    string `json:"html_url"`
+		Head   struct {
+			Ref string `json:"ref"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+	}
+	if err := g.post(fmt.Sprintf("/repos/%s/%s/pulls", g.cfg.Owner, g.cfg.Repo), payload, &pr); err != nil {
+		return nil, err
+	}
+	return &PullRequest{
+		Number: pr.Number, Title: pr.Title, State: pr.State,
+		Head: pr.Head.Ref, Base: pr.Base.Ref, URL: pr.URL,
+	}, nil
+}
+
+// MergePR merges a pull request. method is "merge", "squash" or "rebase"
+// (default "merge").
+func (g *GitHubAgent) MergePR(number int, method string) error {
+	if method == "" {
+		method = "merge"
+	}
+	switch method {
+	case "merge", "squash", "rebase":
+	default:
+		return fmt.Errorf("github: unknown merge method %q", method)
+	}
+	if g.cfg.Simulated {
+		return nil
+	}
+	payload := map[string]string{"merge_method": method}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = g.do(http.MethodPut, fmt.Sprintf("/repos/%s/%s/pulls/%d/merge", g.cfg.Owner, g.cfg.Repo, number), body, nil)
+	return err
+}
+
+// ListOpenIssues returns the open issues for the configured repo, following
+// Link rel="next" pages up to MaxIssuePages. Pull requests, which GitHub's
+// issues endpoint also returns, are left out so len() is the true count.
+func (g *GitHubAgent) ListOpenIssues(filter ...IssueFilter) ([]Issue, error) {
 	if g.cfg.Simulated {
 		return []Issue{
 			{Number: 1, Title: "Fix CI", State: "open", URL: "https://github.com/test/test/issues/1"},
 			{Number: 2, Title: "Add tests", State: "open", URL: "https://github.com/test/test/issues/2"},
 		}, nil
 	}
+	var f IssueFilter
+	if len(filter) > 0 {
+		f = filter[0]
+	}
+	path := fmt.Sprintf("/repos/%s/%s/issues?state=open&per_page=%d%s", g.cfg.Owner, g.cfg.Repo, issuesPerPage, f.query())
 	var issues []Issue
-	if err := g.get(fmt.Sprintf("/repos/%s/%s/issues?state=open", g.cfg.Owner, g.cfg.Repo), &issues); err != nil {
-		return nil, err
+	for page := 0; path != ""; page++ {
+		if page >= g.cfg.MaxIssuePages {
+			log.Warn().Int("pages", page).Int("issues", len(issues)).Msg("github: issue list truncated at MaxIssuePages")
+			break
+		}
+		var batch []struct {
+			Issue
+			PullRequest json.RawMessage `json:"pull_request"`
+		}
+		h, err := g.do(http.MethodGet, path, nil, &batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range batch {
+			if it.PullRequest == nil {
+				issues = append(issues, it.Issue)
+			}
+		}
+		path = g.nextPage(h.Get("Link"))
 	}
 	return issues, nil
 }
 
+// nextPage extracts the rel="next" target of a Link header as a path
+// relative to BaseURL. Links to any other host are ignored so the token is
+// never sent elsewhere.
+func (g *GitHubAgent) nextPage(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(part), ";")
+		if !ok || !strings.Contains(params, `rel="next"`) {
+			continue
+		}
+		target = strings.Trim(strings.TrimSpace(target), "<>")
+		if rest, ok := strings.CutPrefix(target, g.cfg.BaseURL); ok && strings.HasPrefix(rest, "/") {
+			return rest
+		}
+		return ""
+	}
+	return ""
+}
+
 // SearchCode searches for code across the repo.
 // The query is URL-escaped to prevent query-string injection via special characters.
 func (g *GitHubAgent) SearchCode(query string) (string, error) {
@@ -180,44 +333,88 @@ func (g *GitHubAgent) post(path string, payload interface{}, out interface{}) er
 	if err != nil {
 		return err
 	}
-	req, err := http.NewRequest(http.MethodPost, g.cfg.BaseURL+path, strings.NewReader(string(body)))
-	if err != nil {
-		return err
-	}
-	g.setHeaders(req)
-	resp, err := g.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("github: request failed: %w", err)
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 400 {
-		// Cap at 512 bytes — avoids leaking internal GitHub error details
-		// and prevents a crafted response from allocating huge buffers.
-		_, _ = io.ReadAll(io.LimitReader(resp.Body, 512)) // drain
-		return fmt.Errorf("github API error %d", resp.StatusCode)
-	}
-	if out != nil {
-		return json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(out)
-	}
-	return nil
+	_, err = g.do(http.MethodPost, path, body, out)
+	return err
 }
 
 func (g *GitHubAgent) get(path string, out interface{}) error {
-	req, err := http.NewRequest(http.MethodGet, g.cfg.BaseURL+path, nil)
-	if err != nil {
-		return err
+	_, err := g.do(http.MethodGet, path, nil, out)
+	return err
+}
+
+// do sends one API request, retrying rate-limited and 5xx responses up to
+// MaxRetries times. Other 4xx responses (404, 422, ...) fail immediately.
+// The successful response's headers are returned for pagination.
+func (g *GitHubAgent) do(method, path string, body []byte, out interface{}) (http.Header, error) {
+	for attempt := 0; ; attempt++ {
+		var rdr io.Reader
+		if body != nil {
+			rdr = bytes.NewReader(body)
+		}
+		req, err := http.NewRequest(method, g.cfg.BaseURL+path, rdr)
+		if err != nil {
+			return nil, err
+		}
+		g.setHeaders(req)
+		resp, err := g.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("github: request failed: %w", err)
+		}
+		if resp.StatusCode >= 400 {
+			// Cap at 512 bytes — avoids leaking internal GitHub error details
+			// and prevents a crafted response from allocating huge buffers.
+			_, _ = io.ReadAll(io.LimitReader(resp.Body, 512)) // drain
+			resp.Body.Close()
+			if attempt < g.cfg.MaxRetries && retryable(resp) {
+				wait := retryDelay(resp.Header, attempt, time.Now())
+				log.Warn().Int("status", resp.StatusCode).Dur("wait", wait).
+					Int("attempt", attempt+1).Msg("github: retrying request")
+				g.sleep(wait)
+				continue
+			}
+			return nil, fmt.Errorf("github API error %d", resp.StatusCode)
+		}
+		defer resp.Body.Close()
+		if out == nil {
+			return resp.Header, nil
+		}
+		return resp.Header, json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(out)
 	}
-	g.setHeaders(req)
-	resp, err := g.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("github: request failed: %w", err)
+}
+
+// retryable reports whether a failed response is transient: any 5xx, 429,
+// or a 403 that GitHub marks as a (primary or secondary) rate limit.
+func retryable(resp *http.Response) bool {
+	switch {
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode == http.StatusForbidden:
+		return resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0"
+	}
+	return false
+}
+
+// retryDelay picks how long to wait before the next attempt: Retry-After
+// (seconds) first, then X-RateLimit-Reset (unix time), else exponential
+// backoff from one second. The result is capped at maxRetryWait.
+func retryDelay(h http.Header, attempt int, now time.Time) time.Duration {
+	wait := time.Second << attempt
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			wait = time.Duration(secs) * time.Second
+		}
+	} else if v := h.Get("X-RateLimit-Reset"); v != "" {
+		if reset, err := strconv.ParseInt(v, 10, 64); err == nil {
+			wait = time.Unix(reset, 0).Sub(now)
+			if wait < 0 {
+				wait = 0
+			}
+		}
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 400 {
-		_, _ = io.ReadAll(io.LimitReader(resp.Body, 512)) // drain
-		return fmt.Errorf("github API error %d", resp.StatusCode)
+	if wait > maxRetryWait {
+		wait = maxRetryWait
 	}
-	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(out)
+	return wait
 }
 
 func (g *GitHubAgent) setHeaders(req *http.Request) {
@@ -1,7 +1,13 @@
 package github
 
 import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func simConfig() GitHubConfig {
@@ -29,6 +35,20 @@ func TestGitHubCommentSimulated(t *testing.T) {
 	}
 }
 
+func TestGitHubOpenPRSimulated(t *testing.T) {
+	g := New(simConfig())
+	pr, err := g.OpenPR("Title", "body", "feature", "main")
+	if err != nil {
+		t.Fatalf("OpenPR: %v", err)
+	}
+	if pr.Number == 0 || pr.URL == "" || pr.Head != "feature" {
+		t.Errorf("pr = %+v", pr)
+	}
+	if err := g.MergePR(pr.Number, "rebase"); err != nil {
+		t.Errorf("MergePR: %v", err)
+	}
+}
+
 func TestGitHubListOpenIssues(t *testing.T) {
 	g := New(simConfig())
 	issues, err := g.ListOpenIssues()
@@ -64,6 +84,137 @@ func TestFormatIssueList(t *testing.T) {
 	}
 }
 
+func TestGitHubRetriesRateLimit(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"number":5,"title":"Retry me"}]`))
+	}))
+	defer srv.Close()
+
+	g := New(GitHubConfig{BaseURL: srv.URL, Owner: "o", Repo: "r"})
+	var slept []time.Duration
+	g.sleep = func(d time.Duration) { slept = append(slept, d) }
+	issues, err := g.ListOpenIssues()
+	if err != nil {
+		t.Fatalf("ListOpenIssues: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Number != 5 {
+		t.Errorf("issues = %+v", issues)
+	}
+	if calls != 2 || len(slept) != 1 || slept[0] != 7*time.Second {
+		t.Errorf("calls = %d, slept = %v; want 2 calls and one 7s wait", calls, slept)
+	}
+}
+
+func TestGitHubNoRetryOnNotFound(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	g := New(GitHubConfig{BaseURL: srv.URL, Owner: "o", Repo: "r"})
+	g.sleep = func(time.Duration) {}
+	err := g.CommentOnIssue(1, "hi")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("err = %v, want 404", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestGitHubOpenAndMergePR(t *testing.T) {
+	var mergeBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/o/r/pulls":
+			_, _ = w.Write([]byte(`{"number":7,"title":"Fix","state":"open","html_url":"u","head":{"ref":"fix"},"base":{"ref":"main"}}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/repos/o/r/pulls/7/merge":
+			b, _ := io.ReadAll(r.Body)
+			mergeBody = string(b)
+			_, _ = w.Write([]byte(`{"merged":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	g := New(GitHubConfig{BaseURL: srv.URL, Owner: "o", Repo: "r"})
+	pr, err := g.OpenPR("Fix", "body", "fix", "main")
+	if err != nil {
+		t.Fatalf("OpenPR: %v", err)
+	}
+	if pr.Number != 7 || pr.Head != "fix" || pr.Base != "main" {
+		t.Errorf("pr = %+v", pr)
+	}
+	if err := g.MergePR(pr.Number, "squash"); err != nil {
+		t.Fatalf("MergePR: %v", err)
+	}
+	if !strings.Contains(mergeBody, `"merge_method":"squash"`) {
+		t.Errorf("merge body = %q", mergeBody)
+	}
+	if err := g.MergePR(7, "octopus"); err == nil {
+		t.Error("expected error for unknown merge method")
+	}
+}
+
+func TestGitHubListOpenIssuesPaginates(t *testing.T) {
+	var srv *httptest.Server
+	var query string
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "" {
+			query = r.URL.RawQuery
+			w.Header().Set("Link", `<`+srv.URL+r.URL.Path+`?state=open&page=2>; rel="next", <`+srv.URL+`/x?page=2>; rel="last"`)
+			_, _ = w.Write([]byte(`[{"number":1},{"number":2,"pull_request":{"url":"x"}}]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"number":3}]`))
+	}))
+	defer srv.Close()
+
+	g := New(GitHubConfig{BaseURL: srv.URL, Owner: "o", Repo: "r"})
+	issues, err := g.ListOpenIssues(IssueFilter{Labels: []string{"bug", "p1&x"}, Assignee: "me"})
+	if err != nil {
+		t.Fatalf("ListOpenIssues: %v", err)
+	}
+	if len(issues) != 2 || issues[0].Number != 1 || issues[1].Number != 3 {
+		t.Errorf("issues = %+v, want #1 and #3 (PR #2 skipped)", issues)
+	}
+	if !strings.Contains(query, "labels=bug%2Cp1%26x") || !strings.Contains(query, "assignee=me") {
+		t.Errorf("query = %q, want escaped labels and assignee", query)
+	}
+}
+
+func TestNextPageRejectsForeignHost(t *testing.T) {
+	g := New(GitHubConfig{BaseURL: "https://api.github.com"})
+	if p := g.nextPage(`<https://evil.example/repos?page=2>; rel="next"`); p != "" {
+		t.Errorf("nextPage = %q, want empty for foreign host", p)
+	}
+	if p := g.nextPage(`<https://api.github.com/repos/o/r/issues?page=3>; rel="next"`); p != "/repos/o/r/issues?page=3" {
+		t.Errorf("nextPage = %q", p)
+	}
+}
+
+func TestRetryDelayCapsReset(t *testing.T) {
+	now := time.Unix(1000, 0)
+	h := http.Header{}
+	h.Set("X-RateLimit-Reset", "4600")
+	if d := retryDelay(h, 0, now); d != maxRetryWait {
+		t.Errorf("delay = %v, want cap %v", d, maxRetryWait)
+	}
+	if d := retryDelay(http.Header{}, 2, now); d != 4*time.Second {
+		t.Errorf("backoff = %v, want 4s", d)
+	}
+}
+
 func containsStr(s, sub string) bool {
 	return len(s) >= len(sub) && (s == sub || (len(s) > 0 && (s[:len(sub)] == sub || containsStr(s[1:], sub))))
 }
@@ -0,0 +1,146 @@
+// Package goals is the persistent goal store behind the digest's stalled-goal
+// feed, the drift detector and the dashboard's goal counters.
+package goals
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// ErrNotFound is returned when no goal has the given ID.
+var ErrNotFound = errors.New("goals: goal not found")
+
+// Goal is a tracked long-term goal. Progress runs from 0 to 1; a goal at 1
+// is complete and never stalls.
+type Goal struct {
+	ID           string
+	Title        string
+	Progress     float64
+	LastActivity time.Time
+}
+
+// Done reports whether the goal is complete.
+func (g Goal) Done() bool { return g.Progress >= 1 }
+
+// Store persists goals in SQLite.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens (or creates) goals.db in dataDir (default ~/.nexus).
+func Open(dataDir string) (*Store, error) {
+	if dataDir == "" {
+		home, _ := os.UserHomeDir()
+		dataDir = filepath.Join(home, ".nexus")
+	}
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		return nil, fmt.Errorf("goals: mkdir: %w", err)
+	}
+	dbPath := filepath.Join(dataDir, "goals.db")
+	// Create with 0600 before sql.Open — prevents world-readable window.
+	f, err := os.OpenFile(dbPath, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("goals: create db file: %w", err)
+	}
+	f.Close()
+	db, err := sql.Open("sqlite3", dbPath+"?_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS goals (
+			id            TEXT PRIMARY KEY,
+			title         TEXT NOT NULL,
+			progress      REAL NOT NULL DEFAULT 0,
+			last_activity INTEGER NOT NULL,
+			created_at    INTEGER NOT NULL
+		);
+	`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("goals: migrate: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("g-%d", time.Now().UnixNano())
+	}
+	return "g-" + hex.EncodeToString(b)
+}
+
+// Add creates a goal with zero progress, active as of now.
+func (s *Store) Add(title string) (Goal, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return Goal{}, errors.New("goals: title must not be empty")
+	}
+	now := time.Now()
+	g := Goal{ID: newID(), Title: title, LastActivity: now}
+	if _, err := s.db.Exec(`INSERT INTO goals (id, title, progress, last_activity, created_at) VALUES (?,?,?,?,?)`,
+		g.ID, g.Title, 0, now.UnixNano(), now.UnixNano()); err != nil {
+		return Goal{}, fmt.Errorf("goals: add: %w", err)
+	}
+	return g, nil
+}
+
+// UpdateProgress sets a goal's progress (0–1) and marks it active now.
+func (s *Store) UpdateProgress(id string, progress float64) error {
+	if progress < 0 || progress > 1 {
+		return fmt.Errorf("goals: progress %.2f out of range [0, 1]", progress)
+	}
+	res, err := s.db.Exec(`UPDATE goals SET progress=?, last_activity=? WHERE id=?`,
+		progress, time.Now().UnixNano(), id)
+	if err != nil {
+		return fmt.Errorf("goals: update: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return nil
+}
+
+// List returns every goal, most recently active first.
+func (s *Store) List() ([]Goal, error) {
+	return s.query(`SELECT id, title, progress, last_activity FROM goals ORDER BY last_activity DESC`)
+}
+
+// Stalled returns unfinished goals with no activity in the last days days,
+// longest idle first.
+func (s *Store) Stalled(days int) ([]Goal, error) {
+	cutoff := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	return s.query(`SELECT id, title, progress, last_activity FROM goals
+		WHERE progress < 1 AND last_activity < ? ORDER BY last_activity`, cutoff.UnixNano())
+}
+
+func (s *Store) query(q string, args ...interface{}) ([]Goal, error) {
+	rows, err := s.db.Query(q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("goals: query: %w", err)
+	}
+	defer rows.Close()
+	var out []Goal
+	for rows.Next() {
+		var g Goal
+		var last int64
+		if err := rows.Scan(&g.ID, &g.Title, &g.Progress, &last); err != nil {
+			return nil, fmt.Errorf("goals: scan: %w", err)
+		}
+		g.LastActivity = time.Unix(0, last)
+		out = append(out, g)
+	}
+	return out, rows.Err()
+}
+
+// Close closes the database.
+func (s *Store) Close() error { return s.db.Close() }
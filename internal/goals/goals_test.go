@@ -0,0 +1,61 @@
+package goals
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGoalsAddUpdateList(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	g, err := s.Add("Ship NEXUS v2")
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := s.UpdateProgress(g.ID, 0.4); err != nil {
+		t.Fatalf("UpdateProgress: %v", err)
+	}
+	if err := s.UpdateProgress(g.ID, 1.5); err == nil {
+		t.Error("expected out-of-range progress to be rejected")
+	}
+	if err := s.UpdateProgress("g-missing", 0.1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	list, err := s.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 1 || list[0].Progress != 0.4 || list[0].Title != "Ship NEXUS v2" {
+		t.Errorf("unexpected goals: %+v", list)
+	}
+}
+
+func TestGoalsStalled(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	idle, _ := s.Add("Write the book")
+	done, _ := s.Add("Launch landing page")
+	_ = s.UpdateProgress(done.ID, 1)
+	fresh, _ := s.Add("Learn Rust")
+	old := time.Now().Add(-10 * 24 * time.Hour).UnixNano()
+	if _, err := s.db.Exec(`UPDATE goals SET last_activity=? WHERE id IN (?, ?)`, old, idle.ID, done.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	stalled, err := s.Stalled(7)
+	if err != nil {
+		t.Fatalf("Stalled: %v", err)
+	}
+	if len(stalled) != 1 || stalled[0].ID != idle.ID {
+		t.Errorf("expected only the idle unfinished goal, got %+v (fresh=%s)", stalled, fresh.ID)
+	}
+}
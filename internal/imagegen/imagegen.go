@@ -10,14 +10,22 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
+
+	"github.com/Omkar0612/nexus-ai/internal/media"
 )
 
+// ErrBlockedPrompt is returned by Generate when the prompt contains a blocked term.
+var ErrBlockedPrompt = errors.New("imagegen: prompt rejected by safety policy")
+
 // Backend selects the generation provider.
 type Backend string
 
@@ -27,24 +35,54 @@ const (
 	BackendReplicate Backend = "replicate"
 )
 
+// Mode selects how the image is produced.
+type Mode string
+
+const (
+	ModeTxt2Img Mode = "txt2img" // generate from the prompt alone (default)
+	ModeImg2Img Mode = "img2img" // transform InputImagePath guided by the prompt
+	ModeInpaint Mode = "inpaint" // repaint the white areas of MaskPath in InputImagePath
+)
+
+// defaultDenoisingStrength keeps the input's composition while letting the
+// prompt change details.
+const defaultDenoisingStrength = 0.75
+
 // Request describes an image generation request.
 type Request struct {
 	Prompt         string
 	NegativePrompt string
+	Mode           Mode    // default ModeTxt2Img; img2img/inpaint need the Stable Diffusion backend
+	InputImagePath string  // source image for img2img and inpaint, e.g. a previous Result.Path
+	MaskPath       string  // inpaint mask: white = repaint, black = keep
+	Strength       float64 // img2img/inpaint denoising strength 0-1; default 0.75
 	Width          int    // default 512
 	Height         int    // default 512
 	Steps          int    // default 20
 	OutputPath     string // save PNG to file; empty = temp file
+	// DownloadResult fetches URL-only results (Replicate) to OutputPath,
+	// fixing up the extension from the content type. Leave false to get
+	// just the remote URL.
+	DownloadResult bool
 }
 
 // Result holds the generation output.
 type Result struct {
 	Base64  string
-	Path    string
+	Path    string // local file, or the remote URL if not downloaded
+	URL     string // remote output URL for URL-only backends
 	Backend Backend
 	Latency time.Duration
 }
 
+// SafetyConfig is the prompt safety policy applied before any backend call.
+type SafetyConfig struct {
+	// BlockedTerms are matched case-insensitively on word boundaries.
+	BlockedTerms []string
+	// DefaultNegativePrompt is appended to every request's negative prompt.
+	DefaultNegativePrompt string
+}
+
 // Agent is the image generation agent.
 type Agent struct {
 	backend Backend
@@ -52,6 +90,8 @@ type Agent struct {
 	apiKey  string
 	model   string
 	client  *http.Client
+	safety  SafetyConfig
+	blocked map[string]*regexp.Regexp // term → word-boundary matcher
 }
 
 // Option configures the agent.
@@ -72,6 +112,12 @@ func WithReplicate(apiKey string) Option {
 	return func(a *Agent) { a.backend = BackendReplicate; a.apiKey = apiKey }
 }
 
+// WithSafety enables a prompt blocklist and a default negative prompt.
+// Use it whenever image generation is exposed to untrusted users.
+func WithSafety(cfg SafetyConfig) Option {
+	return func(a *Agent) { a.safety = cfg }
+}
+
 // New creates an image generation agent.
 // Defaults to local Stable Diffusion at http://127.0.0.1:7860.
 func New(opts ...Option) *Agent {
@@ -84,11 +130,47 @@ func New(opts ...Option) *Agent {
 	for _, o := range opts {
 		o(a)
 	}
+	a.blocked = make(map[string]*regexp.Regexp)
+	for _, term := range a.safety.BlockedTerms {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		a.blocked[term] = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(term) + `\b`)
+	}
 	return a
 }
 
+// checkPrompt rejects prompts containing a blocked term.
+func (a *Agent) checkPrompt(prompt string) error {
+	for term, re := range a.blocked {
+		if re.MatchString(prompt) {
+			return fmt.Errorf("%w: contains blocked term %q", ErrBlockedPrompt, term)
+		}
+	}
+	return nil
+}
+
+// applyNegativeDefault appends the configured default negative prompt.
+func (a *Agent) applyNegativeDefault(neg string) string {
+	def := strings.TrimSpace(a.safety.DefaultNegativePrompt)
+	switch {
+	case def == "":
+		return neg
+	case strings.TrimSpace(neg) == "":
+		return def
+	default:
+		return neg + ", " + def
+	}
+}
+
 // Generate creates an image from the request.
+// Prompts matching the safety blocklist are rejected with ErrBlockedPrompt.
 func (a *Agent) Generate(ctx context.Context, req Request) (*Result, error) {
+	if err := a.checkPrompt(req.Prompt); err != nil {
+		return nil, err
+	}
+	req.NegativePrompt = a.applyNegativeDefault(req.NegativePrompt)
 	if req.Width == 0 { req.Width = 512 }
 	if req.Height == 0 { req.Height = 512 }
 	if req.Steps == 0 { req.Steps = 20 }
@@ -96,6 +178,12 @@ func (a *Agent) Generate(ctx context.Context, req Request) (*Result, error) {
 		req.OutputPath = filepath.Join(os.TempDir(),
 			fmt.Sprintf("nexus-img-%d.png", time.Now().UnixNano()))
 	}
+	if req.Mode == "" {
+		req.Mode = ModeTxt2Img
+	}
+	if err := validateMode(req, a.backend); err != nil {
+		return nil, err
+	}
 
 	var result *Result
 	var err error
@@ -118,6 +206,13 @@ func (a *Agent) Generate(ctx context.Context, req Request) (*Result, error) {
 		}
 		result.Path = req.OutputPath
 	}
+	if req.DownloadResult && result.URL != "" {
+		path, err := a.downloadResult(ctx, result.URL, req.OutputPath)
+		if err != nil {
+			return nil, fmt.Errorf("imagegen: download %s: %w", result.URL, err)
+		}
+		result.Path = path
+	}
 	return result, nil
 }
 
@@ -165,6 +260,9 @@ type SDResponse struct {
 }
 
 func (a *Agent) generateSD(ctx context.Context, req Request) (*Result, error) {
+	if req.Mode != ModeTxt2Img {
+		return a.generateSDImg2Img(ctx, req)
+	}
 	start := time.Now()
 	var sdResp SDResponse
 	if err := a.doJSON(ctx, a.sdURL+"/sdapi/v1/txt2img", sdRequest{
@@ -179,6 +277,77 @@ func (a *Agent) generateSD(ctx context.Context, req Request) (*Result, error) {
 	return &Result{Base64: sdResp.Images[0], Backend: BackendSD, Latency: time.Since(start)}, nil
 }
 
+// validateMode checks that req's mode has the inputs it needs and is
+// supported by backend.
+func validateMode(req Request, backend Backend) error {
+	switch req.Mode {
+	case ModeTxt2Img:
+		return nil
+	case ModeImg2Img, ModeInpaint:
+	default:
+		return fmt.Errorf("imagegen: unknown mode %q", req.Mode)
+	}
+	if backend != BackendSD {
+		return fmt.Errorf("imagegen: %s is only supported by the %s backend", req.Mode, BackendSD)
+	}
+	if req.InputImagePath == "" {
+		return fmt.Errorf("imagegen: %s needs InputImagePath", req.Mode)
+	}
+	if req.Mode == ModeInpaint && req.MaskPath == "" {
+		return fmt.Errorf("imagegen: inpaint needs MaskPath")
+	}
+	return nil
+}
+
+type sdImg2ImgRequest struct {
+	sdRequest
+	InitImages        []string `json:"init_images"`
+	Mask              string   `json:"mask,omitempty"`
+	DenoisingStrength float64  `json:"denoising_strength"`
+	InpaintingFill    int      `json:"inpainting_fill,omitempty"` // 1 = start from original pixels
+}
+
+func (a *Agent) generateSDImg2Img(ctx context.Context, req Request) (*Result, error) {
+	start := time.Now()
+	src, err := readBase64(req.InputImagePath)
+	if err != nil {
+		return nil, fmt.Errorf("imagegen[sd]: input image: %w", err)
+	}
+	body := sdImg2ImgRequest{
+		sdRequest: sdRequest{
+			Prompt: req.Prompt, NegativePrompt: req.NegativePrompt,
+			Width: req.Width, Height: req.Height, Steps: req.Steps,
+		},
+		InitImages:        []string{src},
+		DenoisingStrength: req.Strength,
+	}
+	if body.DenoisingStrength <= 0 {
+		body.DenoisingStrength = defaultDenoisingStrength
+	}
+	if req.Mode == ModeInpaint {
+		if body.Mask, err = readBase64(req.MaskPath); err != nil {
+			return nil, fmt.Errorf("imagegen[sd]: mask: %w", err)
+		}
+		body.InpaintingFill = 1
+	}
+	var sdResp SDResponse
+	if err := a.doJSON(ctx, a.sdURL+"/sdapi/v1/img2img", body, &sdResp, ""); err != nil {
+		return nil, fmt.Errorf("imagegen[sd]: %w", err)
+	}
+	if len(sdResp.Images) == 0 {
+		return nil, fmt.Errorf("imagegen[sd]: no images returned")
+	}
+	return &Result{Base64: sdResp.Images[0], Backend: BackendSD, Latency: time.Since(start)}, nil
+}
+
+func readBase64(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
 // --- Together AI (FLUX.1-schnell, free credits) ---
 
 type togetherImgRequest struct {
@@ -245,13 +414,23 @@ func (a *Agent) generateReplicate(ctx context.Context, req Request) (*Result, er
 	if pred.Error != "" {
 		return nil, fmt.Errorf("imagegen[replicate]: %s", pred.Error)
 	}
-	path := ""
+	url := ""
 	if len(pred.Output) > 0 {
-		path = pred.Output[0]
+		url = pred.Output[0]
 	}
-	return &Result{Path: path, Backend: BackendReplicate, Latency: time.Since(start)}, nil
+	return &Result{Path: url, URL: url, Backend: BackendReplicate, Latency: time.Since(start)}, nil
 }
 
+// maxDownloadBytes caps a downloaded image (50 MB).
+const maxDownloadBytes = 50 << 20
+
+// downloadResult fetches url into path (see media.Download) and returns the
+// path written.
+func (a *Agent) downloadResult(ctx context.Context, url, path string) (string, error) {
+	return media.Download(ctx, a.client, url, path, maxDownloadBytes)
+}
+
+
 func saveBase64(b64, path string) error {
 	data, err := base64.StdEncoding.DecodeString(b64)
 	if err != nil {
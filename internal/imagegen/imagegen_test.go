@@ -3,8 +3,11 @@ package imagegen
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -55,3 +58,108 @@ func TestDefaultsApplied(t *testing.T) {
 		t.Fatalf("Generate with defaults: %v", err)
 	}
 }
+
+func TestSafetyBlocksPrompt(t *testing.T) {
+	called := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		json.NewEncoder(w).Encode(SDResponse{Images: []string{"aGVsbG8="}})
+	}))
+	defer ts.Close()
+
+	a := New(WithStableDiffusion(ts.URL), WithSafety(SafetyConfig{BlockedTerms: []string{"gore"}}))
+	_, err := a.Generate(context.Background(), Request{Prompt: "A scene full of GORE", OutputPath: t.TempDir() + "/x.png"})
+	if !errors.Is(err, ErrBlockedPrompt) {
+		t.Fatalf("expected ErrBlockedPrompt, got %v", err)
+	}
+	if called {
+		t.Error("backend must not be called for a blocked prompt")
+	}
+
+	// Word-boundary match: "goredale" is not "gore".
+	if _, err := a.Generate(context.Background(), Request{Prompt: "the goredale valley", OutputPath: t.TempDir() + "/y.png"}); err != nil {
+		t.Errorf("unexpected rejection: %v", err)
+	}
+}
+
+func TestSafetyDefaultNegativePrompt(t *testing.T) {
+	var got sdRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		json.NewEncoder(w).Encode(SDResponse{Images: []string{"aGVsbG8="}})
+	}))
+	defer ts.Close()
+
+	a := New(WithStableDiffusion(ts.URL), WithSafety(SafetyConfig{DefaultNegativePrompt: "nsfw, blurry"}))
+	_, err := a.Generate(context.Background(), Request{Prompt: "a cat", NegativePrompt: "dogs", OutputPath: t.TempDir() + "/z.png"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if got.NegativePrompt != "dogs, nsfw, blurry" {
+		t.Errorf("unexpected negative prompt: %q", got.NegativePrompt)
+	}
+}
+
+func TestDownloadResultSniffsExtension(t *testing.T) {
+	jpeg := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 'J', 'F', 'I', 'F', 0x00}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(jpeg)
+	}))
+	defer ts.Close()
+
+	a := New(WithReplicate("key"))
+	path, err := a.downloadResult(context.Background(), ts.URL+"/img", filepath.Join(t.TempDir(), "out.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Ext(path) != ".jpg" {
+		t.Errorf("path = %s, want sniffed .jpg extension", path)
+	}
+	if data, _ := os.ReadFile(path); len(data) != len(jpeg) {
+		t.Errorf("downloaded %d bytes, want %d", len(data), len(jpeg))
+	}
+}
+
+func TestGenerateSDInpaint(t *testing.T) {
+	dir := t.TempDir()
+	input, mask := filepath.Join(dir, "in.png"), filepath.Join(dir, "mask.png")
+	_ = os.WriteFile(input, []byte("input"), 0o644)
+	_ = os.WriteFile(mask, []byte("mask"), 0o644)
+
+	var got map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sdapi/v1/img2img" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		json.NewEncoder(w).Encode(SDResponse{Images: []string{"aGVsbG8="}})
+	}))
+	defer ts.Close()
+
+	a := New(WithStableDiffusion(ts.URL))
+	_, err := a.Generate(context.Background(), Request{
+		Prompt: "make the sky bluer", Mode: ModeInpaint,
+		InputImagePath: input, MaskPath: mask,
+		OutputPath: filepath.Join(dir, "out.png"),
+	})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	images, _ := got["init_images"].([]interface{})
+	if len(images) != 1 || images[0] != "aW5wdXQ=" || got["mask"] != "bWFzaw==" {
+		t.Errorf("img2img body = %v", got)
+	}
+	if got["denoising_strength"] != 0.75 || got["prompt"] != "make the sky bluer" {
+		t.Errorf("img2img body = %v", got)
+	}
+
+	// Inpainting without a mask, or on a backend without img2img, is rejected.
+	if _, err := a.Generate(context.Background(), Request{Prompt: "x", Mode: ModeInpaint, InputImagePath: input}); err == nil {
+		t.Error("expected error for inpaint without mask")
+	}
+	if _, err := New(WithReplicate("k")).Generate(context.Background(), Request{Prompt: "x", Mode: ModeImg2Img, InputImagePath: input}); err == nil {
+		t.Error("expected error for img2img on replicate")
+	}
+}
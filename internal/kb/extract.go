@@ -0,0 +1,327 @@
+package kb
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// ErrUnsupportedPDF is returned for PDFs whose text cannot be extracted:
+// encrypted files, scanned images, or fonts without a plain text encoding.
+// Such files are skipped rather than indexed as binary.
+var ErrUnsupportedPDF = errors.New("unsupported or encrypted PDF")
+
+// readTextFile returns the indexable text of path, extracting it from PDF
+// and DOCX containers.
+func readTextFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".pdf":
+		return extractPDF(data)
+	case ".docx":
+		return extractDOCX(data)
+	}
+	return string(data), nil
+}
+
+// extractPDF pulls the text shown by Tj/TJ/'/" operators out of a PDF's
+// content streams. It handles uncompressed and FlateDecode streams with
+// simple (byte) font encodings, which covers most text-based PDFs produced
+// by word processors and LaTeX; anything else yields ErrUnsupportedPDF.
+func extractPDF(data []byte) (string, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return "", fmt.Errorf("%w: missing PDF header", ErrUnsupportedPDF)
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return "", fmt.Errorf("%w: document is encrypted", ErrUnsupportedPDF)
+	}
+	var sb strings.Builder
+	rest := data
+	for {
+		start := bytes.Index(rest, []byte("stream"))
+		if start < 0 {
+			break
+		}
+		// The stream dictionary sits between the last "obj" and "stream".
+		dict := rest[:start]
+		if i := bytes.LastIndex(dict, []byte("obj")); i >= 0 {
+			dict = dict[i:]
+		}
+		body := rest[start+len("stream"):]
+		body = bytes.TrimPrefix(body, []byte("\r"))
+		body = bytes.TrimPrefix(body, []byte("\n"))
+		end := bytes.Index(body, []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		raw := bytes.TrimRight(body[:end], "\r\n")
+		rest = body[end+len("endstream"):]
+		content, ok := decodePDFStream(dict, raw)
+		if !ok {
+			continue
+		}
+		sb.WriteString(pdfContentText(content))
+	}
+	text := strings.TrimSpace(sb.String())
+	if text == "" || !mostlyPrintable(text) {
+		return "", fmt.Errorf("%w: no extractable text", ErrUnsupportedPDF)
+	}
+	return text, nil
+}
+
+// decodePDFStream applies the stream's filter. Only FlateDecode and
+// unfiltered streams can hold readable content.
+func decodePDFStream(dict, raw []byte) ([]byte, bool) {
+	if !bytes.Contains(dict, []byte("/Filter")) {
+		return raw, true
+	}
+	if !bytes.Contains(dict, []byte("/FlateDecode")) {
+		return nil, false
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, false
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(zr)
+	if err != nil && len(out) == 0 {
+		return nil, false
+	}
+	return out, true
+}
+
+// pdfContentText interprets the text operators of a content stream.
+func pdfContentText(content []byte) string {
+	var sb strings.Builder
+	var operands []string // string operands since the last operator
+	var inArray bool
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '(':
+			s, n := pdfLiteral(content[i:])
+			operands = append(operands, s)
+			i += n
+		case c == '<' && i+1 < len(content) && content[i+1] != '<':
+			end := bytes.IndexByte(content[i:], '>')
+			if end < 0 {
+				return sb.String()
+			}
+			operands = append(operands, pdfHex(content[i+1:i+end]))
+			i += end + 1
+		case c == '[':
+			inArray = true
+			i++
+		case c == ']':
+			inArray = false
+			i++
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(content) && (content[j] == '.' || (content[j] >= '0' && content[j] <= '9')) {
+				j++
+			}
+			// A large negative kerning inside a TJ array is a word gap.
+			if inArray && c == '-' && j-i > 3 {
+				operands = append(operands, " ")
+			}
+			i = j
+		case isPDFRegular(c):
+			j := i
+			for j < len(content) && isPDFRegular(content[j]) {
+				j++
+			}
+			switch string(content[i:j]) {
+			case "Tj", "TJ":
+				sb.WriteString(strings.Join(operands, ""))
+			case "T*", "Td", "TD", "ET":
+				sb.WriteString("\n")
+			}
+			operands = operands[:0]
+			i = j
+		default:
+			// ' and " show a string on the next line.
+			if c == '\'' || c == '"' {
+				sb.WriteString("\n" + strings.Join(operands, ""))
+				operands = operands[:0]
+			}
+			i++
+		}
+	}
+	return collapseBlankLines(sb.String())
+}
+
+func isPDFRegular(c byte) bool {
+	return c > ' ' && !strings.ContainsRune("()<>[]{}/%'\"", rune(c)) && c < 0x7f
+}
+
+// pdfLiteral decodes a (...) string starting at b[0], returning it and the
+// number of bytes consumed.
+func pdfLiteral(b []byte) (string, int) {
+	var sb strings.Builder
+	depth := 0
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch c {
+		case '(':
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case ')':
+			depth--
+			if depth == 0 {
+				return sb.String(), i + 1
+			}
+		case '\\':
+			i++
+			if i >= len(b) {
+				return sb.String(), i
+			}
+			switch e := b[i]; e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// line continuation
+			default:
+				if e >= '0' && e <= '7' {
+					v, n := 0, 0
+					for n < 3 && i < len(b) && b[i] >= '0' && b[i] <= '7' {
+						v = v*8 + int(b[i]-'0')
+						i++
+						n++
+					}
+					i--
+					sb.WriteByte(byte(v))
+				} else {
+					sb.WriteByte(e)
+				}
+			}
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String(), len(b)
+}
+
+func pdfHex(b []byte) string {
+	clean := make([]byte, 0, len(b)+1)
+	for _, c := range b {
+		if !unicode.IsSpace(rune(c)) {
+			clean = append(clean, c)
+		}
+	}
+	if len(clean)%2 == 1 {
+		clean = append(clean, '0')
+	}
+	out, err := hex.DecodeString(string(clean))
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+func collapseBlankLines(s string) string {
+	lines := strings.Split(s, "\n")
+	out := lines[:0]
+	for _, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			out = append(out, strings.TrimRight(l, " "))
+		}
+	}
+	if len(out) == 0 {
+		return ""
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+// mostlyPrintable guards against indexing glyph IDs from CID fonts.
+func mostlyPrintable(s string) bool {
+	var total, good int
+	for _, r := range s {
+		total++
+		if unicode.IsPrint(r) || unicode.IsSpace(r) {
+			good++
+		}
+	}
+	return total > 0 && good*10 >= total*9
+}
+
+// extractDOCX returns the paragraph text of word/document.xml.
+func extractDOCX(data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("docx: %w", err)
+	}
+	var doc *zip.File
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			doc = f
+			break
+		}
+	}
+	if doc == nil {
+		return "", errors.New("docx: word/document.xml not found")
+	}
+	rc, err := doc.Open()
+	if err != nil {
+		return "", fmt.Errorf("docx: %w", err)
+	}
+	defer rc.Close()
+
+	var sb strings.Builder
+	dec := xml.NewDecoder(rc)
+	inText := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("docx: parse document.xml: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteByte('\t')
+			case "br", "cr":
+				sb.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				sb.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
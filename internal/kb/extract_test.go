@@ -0,0 +1,83 @@
+package kb
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// minimalPDF builds a one-page PDF whose content stream shows text.
+func minimalPDF(content string, compress bool) []byte {
+	stream, filter := []byte(content), ""
+	if compress {
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		zw.Write(stream)
+		zw.Close()
+		stream, filter = buf.Bytes(), " /Filter /FlateDecode"
+	}
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	b.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	b.WriteString("2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n")
+	b.WriteString("3 0 obj\n<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>\nendobj\n")
+	fmt.Fprintf(&b, "4 0 obj\n<< /Length %d%s >>\nstream\n", len(stream), filter)
+	b.Write(stream)
+	b.WriteString("\nendstream\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return b.Bytes()
+}
+
+func TestExtractPDF(t *testing.T) {
+	content := "BT /F1 12 Tf 72 720 Td (Quarterly \\(Q3\\) revenue) Tj T* [(grew) -250 (forty)] TJ ET"
+	for _, compress := range []bool{false, true} {
+		text, err := extractPDF(minimalPDF(content, compress))
+		if err != nil {
+			t.Fatalf("compress=%v: %v", compress, err)
+		}
+		if !strings.Contains(text, "Quarterly (Q3) revenue") || !strings.Contains(text, "grew forty") {
+			t.Errorf("compress=%v: unexpected text %q", compress, text)
+		}
+	}
+
+	enc := bytes.Replace(minimalPDF(content, false), []byte("<< /Root"), []byte("<< /Encrypt 5 0 R /Root"), 1)
+	if _, err := extractPDF(enc); !errors.Is(err, ErrUnsupportedPDF) {
+		t.Errorf("expected ErrUnsupportedPDF for encrypted PDF, got %v", err)
+	}
+}
+
+func TestKBIndexesPDFAndDOCX(t *testing.T) {
+	dir := t.TempDir()
+	pdf := minimalPDF("BT (The heliograph protocol uses mirrors) Tj ET", true)
+	if err := os.WriteFile(filepath.Join(dir, "report.pdf"), pdf, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var docx bytes.Buffer
+	zw := zip.NewWriter(&docx)
+	w, _ := zw.Create("word/document.xml")
+	w.Write([]byte(`<?xml version="1.0"?><w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		`<w:p><w:r><w:t>Semaphore towers</w:t></w:r><w:r><w:t xml:space="preserve"> relay signals</w:t></w:r></w:p></w:body></w:document>`))
+	zw.Close()
+	if err := os.WriteFile(filepath.Join(dir, "notes.docx"), docx.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	kbase, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kbase.WaitReady(5 * time.Second)
+	if r := kbase.Search("heliograph mirrors", 1); len(r) != 1 || r[0].DocTitle != "report.pdf" {
+		t.Errorf("PDF text not searchable: %+v", r)
+	}
+	if r := kbase.Search("semaphore signals", 1); len(r) != 1 || !strings.Contains(r[0].Chunk.Text, "Semaphore towers relay signals") {
+		t.Errorf("DOCX text not searchable: %+v", r)
+	}
+}
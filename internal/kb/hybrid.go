@@ -0,0 +1,115 @@
+package kb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/Omkar0612/nexus-ai/internal/semantic"
+)
+
+// rrfK is the Reciprocal Rank Fusion constant from Cormack et al.; it damps
+// the advantage of the very top ranks so neither retriever dominates.
+const rrfK = 60
+
+// SemanticSearcher is the embedding retriever used by HybridSearch.
+// *semantic.Store satisfies it.
+type SemanticSearcher interface {
+	Search(ctx context.Context, query string, topK int) ([]semantic.Document, error)
+}
+
+// HybridSearch runs the TF-IDF index and the semantic store for query and
+// fuses both rankings with Reciprocal Rank Fusion:
+//
+//	score = (1-w)/(60+lexicalRank) + w/(60+semanticRank)
+//
+// semanticWeight w is clamped to [0,1]: 0 ranks purely lexically (exact
+// error strings, identifiers), 1 purely by embeddings (conceptual queries),
+// 0.5 weighs both equally. A chunk found by both retrievers is merged when
+// the semantic document carries "path" and "chunk" metadata matching the KB
+// chunk, as when the store is seeded from KB documents.
+//
+// If the semantic retriever fails, the lexical ranking is returned together
+// with the error so callers can degrade gracefully when Ollama is down.
+func (kb *KnowledgeBase) HybridSearch(ctx context.Context, sem SemanticSearcher, query string, topK int, semanticWeight float64) ([]SearchResult, error) {
+	if topK <= 0 {
+		topK = 5
+	}
+	w := max(0, min(1, semanticWeight))
+	pool := max(topK*4, 20)
+
+	type fused struct {
+		res   SearchResult
+		score float64
+	}
+	byKey := make(map[string]*fused)
+	var order []string
+	add := func(key string, r SearchResult, rrf float64) {
+		f, ok := byKey[key]
+		if !ok {
+			f = &fused{res: r}
+			byKey[key] = f
+			order = append(order, key)
+		}
+		f.score += rrf
+	}
+
+	if w < 1 {
+		for rank, r := range kb.Search(query, pool) {
+			add(chunkKey(r.DocPath, r.Chunk.Index), r, (1-w)/float64(rrfK+rank+1))
+		}
+	}
+
+	var semErr error
+	if sem != nil && w > 0 {
+		docs, err := sem.Search(ctx, query, pool)
+		if err != nil {
+			semErr = fmt.Errorf("kb: hybrid semantic search: %w", err)
+		}
+		for rank, d := range docs {
+			key, r := semanticResult(d)
+			add(key, r, w/float64(rrfK+rank+1))
+		}
+	}
+
+	results := make([]SearchResult, 0, len(order))
+	for _, key := range order {
+		f := byKey[key]
+		f.res.Score = f.score
+		f.res.Chunk.Score = f.score
+		results = append(results, f.res)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results, semErr
+}
+
+func chunkKey(path string, index int) string {
+	return path + "#" + strconv.Itoa(index)
+}
+
+// semanticResult maps a semantic document onto a SearchResult and the key
+// used to match it against KB chunks.
+func semanticResult(d semantic.Document) (string, SearchResult) {
+	path := d.Metadata["path"]
+	idx, err := strconv.Atoi(d.Metadata["chunk"])
+	key := chunkKey(path, idx)
+	if path == "" || err != nil {
+		key = "semantic:" + strconv.FormatInt(d.ID, 10)
+		if path == "" {
+			path = d.Source
+		}
+	}
+	title := d.Metadata["title"]
+	if title == "" {
+		title = path
+	}
+	return key, SearchResult{
+		Chunk:    Chunk{DocID: path, Index: idx, Text: d.Content, Tokens: tokenize(d.Content)},
+		DocTitle: title,
+		DocPath:  path,
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
 	"sync"
@@ -41,6 +42,8 @@ type Document struct {
 	Tags      []string
 	IndexedAt time.Time
 	Size      int64
+	ModTime   time.Time // file modtime at indexing; zero for AddText documents
+	TagsTime  time.Time // sidecar ".tags" modtime at indexing; zero if there was none
 }
 
 // Chunk is a retrievable piece of a document
@@ -60,18 +63,60 @@ type SearchResult struct {
 	Score    float64
 }
 
-// KnowledgeBase manages local document indexing and retrieval
+// KnowledgeBase manages local document indexing and retrieval.
+//
+// Searches always run against an immutable index snapshot; indexing builds
+// a new snapshot on the side and swaps it in atomically, so a search during
+// a reindex never sees a half-built corpus or a stale IDF.
 type KnowledgeBase struct {
 	mu        sync.RWMutex
-	docs      map[string]*Document
+	snap      *index
 	dir       string
-	chunkSize int    // chars per chunk
-	overlap   int    // char overlap between chunks
-	idf       map[string]float64
-	dirty     bool   // idf needs rebuild
+	chunkSize int // chars per chunk
+	overlap   int // char overlap between chunks
+	workers   int // concurrent file indexers
+
+	indexMu sync.Mutex // serialises IndexDirectory runs
+	stateMu sync.Mutex
+	state   IndexingState
+	ready   chan struct{}
+	once    sync.Once
+}
+
+// index is an immutable snapshot of the corpus. IDF is computed lazily,
+// once per snapshot.
+type index struct {
+	docs    map[string]*Document
+	idfOnce sync.Once
+	idf     map[string]float64
 }
 
-// New creates or opens a KnowledgeBase rooted at dir
+func (ix *index) IDF() map[string]float64 {
+	ix.idfOnce.Do(func() { ix.idf = buildIDF(ix.docs) })
+	return ix.idf
+}
+
+// IndexingState reports background indexing progress.
+type IndexingState struct {
+	Indexing   bool
+	Total      int // files queued in the current/last run
+	Done       int // files processed so far
+	Errors     int
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// String renders the state for the CLI, e.g. "indexing 120/500 files".
+func (s IndexingState) String() string {
+	if s.Indexing {
+		return fmt.Sprintf("indexing %d/%d files", s.Done, s.Total)
+	}
+	return "ready"
+}
+
+// New creates or opens a KnowledgeBase rooted at dir. The directory is
+// indexed in the background; use IndexingState or WaitReady to follow it.
+// Search works immediately and sees files as soon as the first pass lands.
 func New(dir string) (*KnowledgeBase, error) {
 	if dir == "" {
 		home, _ := os.UserHomeDir()
@@ -81,23 +126,59 @@ func New(dir string) (*KnowledgeBase, error) {
 		return nil, err
 	}
 	kb := &KnowledgeBase{
-		docs:      make(map[string]*Document),
+		snap:      &index{docs: make(map[string]*Document)},
 		dir:       dir,
 		chunkSize: 800,
 		overlap:   100,
-		idf:       make(map[string]float64),
+		workers:   runtime.NumCPU(),
+		ready:     make(chan struct{}),
+	}
+	kb.state.Indexing = true
+	go func() {
+		if err := kb.IndexDirectory(); err != nil {
+			fmt.Fprintf(os.Stderr, "KB index error: %v\n", err)
+		}
+	}()
+	return kb, nil
+}
+
+// IndexingState returns the current background indexing progress.
+func (kb *KnowledgeBase) IndexingState() IndexingState {
+	kb.stateMu.Lock()
+	defer kb.stateMu.Unlock()
+	return kb.state
+}
+
+// WaitReady blocks until the initial directory index is complete or timeout elapses.
+func (kb *KnowledgeBase) WaitReady(timeout time.Duration) bool {
+	select {
+	case <-kb.ready:
+		return true
+	case <-time.After(timeout):
+		return false
 	}
-	return kb, kb.IndexDirectory()
 }
 
-// IndexDirectory scans the KB directory and indexes all supported files
+// IndexDirectory scans the KB directory, indexes new or modified supported
+// files (by modtime and size, and the modtime of their sidecar tags) using a
+// pool of workers, drops documents whose
+// files were deleted, and swaps the result in. When nothing changed the
+// current snapshot, and its IDF, are kept.
 func (kb *KnowledgeBase) IndexDirectory() error {
+	kb.indexMu.Lock()
+	defer kb.indexMu.Unlock()
+	defer kb.once.Do(func() { close(kb.ready) })
+
 	supported := map[string]bool{
 		".md": true, ".txt": true, ".go": true,
 		".py": true, ".json": true, ".toml": true,
 		".yaml": true, ".yml": true, ".ts": true, ".js": true,
+		".pdf": true, ".docx": true,
 	}
-	return filepath.Walk(kb.dir, func(path string, info os.FileInfo, err error) error {
+	current := kb.snapshot()
+	var paths []string
+	seen := make(map[string]bool)
+	err := filepath.Walk(kb.dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -105,38 +186,114 @@ func (kb *KnowledgeBase) IndexDirectory() error {
 		if !supported[ext] {
 			return nil
 		}
-		if existing, ok := kb.docs[path]; ok {
-			if !info.ModTime().After(existing.IndexedAt) {
+		seen[path] = true
+		if existing, ok := current.docs[path]; ok {
+			if existing.ModTime.Equal(info.ModTime()) && existing.Size == info.Size() &&
+				existing.TagsTime.Equal(sidecarModTime(path)) {
 				return nil
 			}
 		}
-		return kb.IndexFile(path)
+		paths = append(paths, path)
+		return nil
 	})
+	kb.setState(func(s *IndexingState) {
+		*s = IndexingState{Indexing: true, Total: len(paths), StartedAt: time.Now()}
+	})
+	if err != nil {
+		kb.setState(func(s *IndexingState) { s.Indexing = false; s.FinishedAt = time.Now() })
+		return err
+	}
+
+	jobs := make(chan string)
+	results := make(chan *Document)
+	var wg sync.WaitGroup
+	workers := kb.workers
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				doc, err := kb.loadDocument(path)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "KB: skipping %s: %v\n", path, err)
+					kb.setState(func(s *IndexingState) { s.Done++; s.Errors++ })
+					continue
+				}
+				results <- doc
+			}
+		}()
+	}
+	go func() {
+		for _, p := range paths {
+			jobs <- p
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	var docs []*Document
+	for doc := range results {
+		docs = append(docs, doc)
+		kb.setState(func(s *IndexingState) { s.Done++ })
+	}
+	var removed []string
+	if err == nil {
+		for id, d := range current.docs {
+			if !d.ModTime.IsZero() && !seen[d.Path] && kb.inDir(d.Path) {
+				removed = append(removed, id)
+			}
+		}
+	}
+	if len(docs) > 0 || len(removed) > 0 {
+		kb.apply(docs, removed)
+	}
+	kb.setState(func(s *IndexingState) { s.Indexing = false; s.FinishedAt = time.Now() })
+	return nil
 }
 
 // IndexFile reads, chunks, and indexes a single file
 func (kb *KnowledgeBase) IndexFile(path string) error {
-	content, err := readTextFile(path)
+	doc, err := kb.loadDocument(path)
 	if err != nil {
 		return err
 	}
+	kb.publish(doc)
+	return nil
+}
+
+// loadDocument reads and chunks path without touching the live index.
+// Tags come from a YAML front-matter "tags:" entry of a Markdown file and/or
+// a sidecar file ("<path>.tags"). Other formats are indexed verbatim, since a
+// leading "---" in YAML starts a document, not front matter.
+func (kb *KnowledgeBase) loadDocument(path string) (*Document, error) {
+	content, err := readTextFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	if strings.ToLower(filepath.Ext(path)) == ".md" {
+		content, tags = splitFrontMatter(content)
+	}
 	info, _ := os.Stat(path)
 	doc := &Document{
 		ID:        path,
 		Path:      path,
 		Title:     filepath.Base(path),
 		Content:   content,
+		Tags:      mergeTags(tags, readSidecarTags(path)),
 		IndexedAt: time.Now(),
+		TagsTime:  sidecarModTime(path),
 	}
 	if info != nil {
 		doc.Size = info.Size()
+		doc.ModTime = info.ModTime()
 	}
 	doc.Chunks = kb.chunkDocument(doc)
-	kb.mu.Lock()
-	kb.docs[path] = doc
-	kb.dirty = true
-	kb.mu.Unlock()
-	return nil
+	return doc, nil
 }
 
 // AddText indexes an in-memory string (useful for adding notes programmatically)
@@ -147,31 +304,91 @@ func (kb *KnowledgeBase) AddText(id, title, content string, tags []string) {
 		IndexedAt: time.Now(),
 	}
 	doc.Chunks = kb.chunkDocument(doc)
+	kb.publish(doc)
+}
+
+// publish swaps in a new snapshot containing docs on top of the current one.
+func (kb *KnowledgeBase) publish(docs ...*Document) {
+	kb.apply(docs, nil)
+}
+
+// apply swaps in a new snapshot with docs upserted and the removed IDs dropped.
+func (kb *KnowledgeBase) apply(docs []*Document, removed []string) {
 	kb.mu.Lock()
-	kb.docs[id] = doc
-	kb.dirty = true
-	kb.mu.Unlock()
+	defer kb.mu.Unlock()
+	next := make(map[string]*Document, len(kb.snap.docs)+len(docs))
+	for id, d := range kb.snap.docs {
+		next[id] = d
+	}
+	for _, id := range removed {
+		delete(next, id)
+	}
+	for _, d := range docs {
+		next[d.ID] = d
+	}
+	kb.snap = &index{docs: next}
+}
+
+// inDir reports whether path lies inside the KB directory.
+func (kb *KnowledgeBase) inDir(path string) bool {
+	rel, err := filepath.Rel(kb.dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (kb *KnowledgeBase) snapshot() *index {
+	kb.mu.RLock()
+	defer kb.mu.RUnlock()
+	return kb.snap
+}
+
+func (kb *KnowledgeBase) setState(fn func(*IndexingState)) {
+	kb.stateMu.Lock()
+	fn(&kb.state)
+	kb.stateMu.Unlock()
 }
 
 // Search returns the top-k most relevant chunks for a query
 func (kb *KnowledgeBase) Search(query string, topK int) []SearchResult {
+	return kb.search(query, topK, nil)
+}
+
+// SearchTagged is Search restricted to documents carrying at least one of
+// tags (case-insensitive). With no tags it behaves like Search. IDF is still
+// computed over the whole corpus so scores stay comparable across scopes.
+func (kb *KnowledgeBase) SearchTagged(query string, tags []string, topK int) []SearchResult {
+	if len(tags) == 0 {
+		return kb.search(query, topK, nil)
+	}
+	want := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		want[strings.ToLower(strings.TrimSpace(t))] = true
+	}
+	return kb.search(query, topK, func(d *Document) bool {
+		for _, t := range d.Tags {
+			if want[strings.ToLower(t)] {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// search scores the chunks of every document accepted by keep (all if nil).
+func (kb *KnowledgeBase) search(query string, topK int, keep func(*Document) bool) []SearchResult {
 	if topK <= 0 {
 		topK = 5
 	}
-	kb.mu.Lock()
-	if kb.dirty {
-		kb.rebuildIDF()
-		kb.dirty = false
-	}
-	kb.mu.Unlock()
+	snap := kb.snapshot()
+	idf := snap.IDF()
 
 	queryTokens := tokenize(query)
 	var results []SearchResult
-
-	kb.mu.RLock()
-	for _, doc := range kb.docs {
+	for _, doc := range snap.docs {
+		if keep != nil && !keep(doc) {
+			continue
+		}
 		for _, chunk := range doc.Chunks {
-			score := kb.tfidfScore(queryTokens, chunk)
+			score := tfidfScore(idf, queryTokens, chunk)
 			if score > 0 {
 				results = append(results, SearchResult{
 					Chunk:    chunk,
@@ -182,7 +399,6 @@ func (kb *KnowledgeBase) Search(query string, topK int) []SearchResult {
 			}
 		}
 	}
-	kb.mu.RUnlock()
 
 	sort.Slice(results, func(i, j int) bool {
 		return results[i].Score > results[j].Score
@@ -215,17 +431,16 @@ func (kb *KnowledgeBase) BuildContext(query string, topK int, maxChars int) stri
 
 // Stats returns a summary of the indexed knowledge base
 func (kb *KnowledgeBase) Stats() string {
-	kb.mu.RLock()
-	defer kb.mu.RUnlock()
-	if len(kb.docs) == 0 {
-		return fmt.Sprintf("📁 Knowledge Base empty.\nDrop files into: %s\nSupported: .md .txt .go .py .json .toml .yaml", kb.dir)
+	docs := kb.snapshot().docs
+	if len(docs) == 0 {
+		return fmt.Sprintf("📁 Knowledge Base empty.\nDrop files into: %s\nSupported: .md .txt .pdf .docx .go .py .json .toml .yaml", kb.dir)
 	}
 	totalChunks := 0
-	for _, d := range kb.docs {
+	for _, d := range docs {
 		totalChunks += len(d.Chunks)
 	}
 	return fmt.Sprintf("📚 Knowledge Base: %d documents | %d chunks indexed\nDirectory: %s",
-		len(kb.docs), totalChunks, kb.dir)
+		len(docs), totalChunks, kb.dir)
 }
 
 // WatchAndReindex polls for file changes every interval and re-indexes
@@ -274,13 +489,13 @@ func (kb *KnowledgeBase) chunkDocument(doc *Document) []Chunk {
 	return chunks
 }
 
-// rebuildIDF recomputes inverse document frequency for all indexed terms.
+// buildIDF computes inverse document frequency for all indexed terms.
 // Uses (n+2)/(freq+1) smoothing so that IDF is always positive, even
 // when the corpus contains only a single document/chunk.
-func (kb *KnowledgeBase) rebuildIDF() {
+func buildIDF(docs map[string]*Document) map[string]float64 {
 	df := make(map[string]int)
 	n := 0
-	for _, doc := range kb.docs {
+	for _, doc := range docs {
 		for _, chunk := range doc.Chunks {
 			seen := make(map[string]bool)
 			for _, tok := range chunk.Tokens {
@@ -292,14 +507,16 @@ func (kb *KnowledgeBase) rebuildIDF() {
 			n++
 		}
 	}
+	idf := make(map[string]float64, len(df))
 	for term, freq := range df {
 		// +2 in numerator ensures log result > 0 even for single-doc corpora:
 		// log((1+2)/(1+1)) = log(1.5) ≈ 0.405 > 0
-		kb.idf[term] = math.Log(float64(n+2) / float64(freq+1))
+		idf[term] = math.Log(float64(n+2) / float64(freq+1))
 	}
+	return idf
 }
 
-func (kb *KnowledgeBase) tfidfScore(queryTokens []string, chunk Chunk) float64 {
+func tfidfScore(idf map[string]float64, queryTokens []string, chunk Chunk) float64 {
 	tf := make(map[string]int)
 	for _, tok := range chunk.Tokens {
 		tf[tok]++
@@ -308,7 +525,7 @@ func (kb *KnowledgeBase) tfidfScore(queryTokens []string, chunk Chunk) float64 {
 	for _, qt := range queryTokens {
 		if count, ok := tf[qt]; ok {
 			tfScore := float64(count) / float64(len(chunk.Tokens)+1)
-			idfScore := kb.idf[qt]
+			idfScore := idf[qt]
 			score += tfScore * idfScore
 		}
 	}
@@ -329,11 +546,3 @@ func tokenize(text string) []string {
 	}
 	return tokens
 }
-
-func readTextFile(path string) (string, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return "", err
-	}
-	return string(data), nil
-}
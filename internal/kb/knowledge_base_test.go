@@ -1,9 +1,15 @@
 package kb
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/Omkar0612/nexus-ai/internal/semantic"
 )
 
 func TestKBAddAndSearch(t *testing.T) {
@@ -92,3 +98,218 @@ func TestKBStats(t *testing.T) {
 		t.Error("expected stats after adding document")
 	}
 }
+
+func TestKBBackgroundIndexing(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 40; i++ {
+		body := fmt.Sprintf("note %d about the scheduler and the drift detector", i)
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("n%02d.md", i)), []byte(body), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	kbase, err := New(dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	// Searching while the initial pass runs must be safe.
+	_ = kbase.Search("scheduler", 3)
+
+	if !kbase.WaitReady(5 * time.Second) {
+		t.Fatal("initial index did not finish")
+	}
+	st := kbase.IndexingState()
+	if st.Indexing || st.Total != 40 || st.Done != 40 || st.String() != "ready" {
+		t.Errorf("unexpected state after indexing: %+v", st)
+	}
+	if got := len(kbase.Search("scheduler drift", 100)); got != 40 {
+		t.Errorf("expected 40 results, got %d", got)
+	}
+}
+
+func TestKBReindexKeepsConcurrentAdds(t *testing.T) {
+	dir := t.TempDir()
+	kbase, err := New(dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	kbase.WaitReady(5 * time.Second)
+	if err := os.WriteFile(filepath.Join(dir, "a.md"), []byte("alpha bravo charlie"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- kbase.IndexDirectory() }()
+	kbase.AddText("note", "Note", "delta echo foxtrot", nil)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if len(kbase.Search("alpha", 3)) == 0 || len(kbase.Search("foxtrot", 3)) == 0 {
+		t.Error("expected both indexed file and added note to be searchable")
+	}
+}
+
+type fakeSemantic []semantic.Document
+
+func (f fakeSemantic) Search(ctx context.Context, query string, topK int) ([]semantic.Document, error) {
+	return f, nil
+}
+
+func TestKBHybridSearchFusesRankings(t *testing.T) {
+	kbase, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	kbase.AddText("errors.md", "Errors", "connection refused dialing tcp port 11434", nil)
+	kbase.AddText("ops.md", "Ops", "restart the ollama service when the connection drops", nil)
+
+	sem := fakeSemantic{
+		{ID: 1, Content: "troubleshooting local model servers", Metadata: map[string]string{"title": "Guide"}},
+		{ID: 2, Content: "restart the ollama service when the connection drops", Metadata: map[string]string{"path": "ops.md", "chunk": "0"}},
+	}
+	ctx := context.Background()
+
+	lexical, err := kbase.HybridSearch(ctx, sem, "connection refused", 5, 0)
+	if err != nil || len(lexical) != 2 || lexical[0].DocPath != "errors.md" {
+		t.Fatalf("weight 0 should rank lexically, got %+v (err %v)", lexical, err)
+	}
+
+	hybrid, err := kbase.HybridSearch(ctx, sem, "connection refused", 5, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hybrid) != 3 {
+		t.Fatalf("expected ops.md merged across retrievers (3 results), got %d", len(hybrid))
+	}
+	if hybrid[0].DocPath != "ops.md" {
+		t.Errorf("chunk found by both retrievers should rank first, got %s", hybrid[0].DocPath)
+	}
+
+	semanticOnly, _ := kbase.HybridSearch(ctx, sem, "connection refused", 5, 1)
+	if semanticOnly[0].DocTitle != "Guide" {
+		t.Errorf("weight 1 should follow the semantic ranking, got %s", semanticOnly[0].DocTitle)
+	}
+}
+
+func TestKBReindexDropsDeletedAndSkipsUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	kbase, err := New(dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	kbase.WaitReady(5 * time.Second)
+	gone := filepath.Join(dir, "gone.md")
+	if err := os.WriteFile(gone, []byte("zebra migration patterns"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "kept.md"), []byte("zebra stripes"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := kbase.IndexDirectory(); err != nil {
+		t.Fatal(err)
+	}
+
+	before := kbase.snapshot()
+	if err := kbase.IndexDirectory(); err != nil {
+		t.Fatal(err)
+	}
+	if kbase.snapshot() != before {
+		t.Error("reindex without changes should keep the current snapshot")
+	}
+	if st := kbase.IndexingState(); st.Total != 0 {
+		t.Errorf("unchanged files should be skipped, %d were queued", st.Total)
+	}
+
+	if err := os.Remove(gone); err != nil {
+		t.Fatal(err)
+	}
+	if err := kbase.IndexDirectory(); err != nil {
+		t.Fatal(err)
+	}
+	results := kbase.Search("zebra migration", 10)
+	for _, r := range results {
+		if r.DocPath == gone {
+			t.Fatalf("deleted file still in results: %+v", r)
+		}
+	}
+	if len(results) != 1 {
+		t.Errorf("expected only kept.md, got %d results", len(results))
+	}
+}
+
+func TestKBSearchTagged(t *testing.T) {
+	dir := t.TempDir()
+	kbase, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kbase.WaitReady(5 * time.Second)
+
+	alpha := filepath.Join(dir, "alpha.md")
+	if err := os.WriteFile(alpha, []byte("---\ntitle: Alpha\ntags: [project-alpha, roadmap]\n---\nDeployment checklist for launch"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	beta := filepath.Join(dir, "beta.md")
+	if err := os.WriteFile(beta, []byte("---\ntags:\n  - project-beta\n---\nDeployment checklist for beta"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "gamma.txt"), []byte("Deployment checklist for gamma"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "gamma.txt.tags"), []byte("project-gamma, ops\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := kbase.IndexDirectory(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(kbase.Search("deployment checklist", 10)); got != 3 {
+		t.Fatalf("expected 3 unscoped results, got %d", got)
+	}
+	scoped := kbase.SearchTagged("deployment checklist", []string{"Project-Beta"}, 10)
+	if len(scoped) != 1 || scoped[0].DocPath != beta {
+		t.Errorf("expected only beta.md, got %+v", scoped)
+	}
+	if strings.Contains(scoped[0].Chunk.Text, "tags:") {
+		t.Error("front-matter should be stripped from indexed content")
+	}
+	if got := kbase.SearchTagged("deployment checklist", []string{"ops", "roadmap"}, 10); len(got) != 2 {
+		t.Errorf("expected alpha.md and gamma.txt for any-of tags, got %d", len(got))
+	}
+}
+
+func TestKBYAMLKeepsFirstDocumentAndSidecarEditsReindex(t *testing.T) {
+	dir := t.TempDir()
+	kbase, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kbase.WaitReady(5 * time.Second)
+
+	manifest := filepath.Join(dir, "deploy.yaml")
+	if err := os.WriteFile(manifest, []byte("---\nkind: Service\ntags: [wrong]\n---\nkind: Deployment\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := kbase.IndexDirectory(); err != nil {
+		t.Fatal(err)
+	}
+	doc := kbase.snapshot().docs[manifest]
+	if doc == nil || !strings.Contains(doc.Content, "Service") || len(doc.Tags) != 0 {
+		t.Fatalf("YAML documents should be indexed verbatim, got %+v", doc)
+	}
+
+	sidecar := manifest + ".tags"
+	if err := os.WriteFile(sidecar, []byte("infra\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// Make the change visible even on filesystems with coarse timestamps.
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(sidecar, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := kbase.IndexDirectory(); err != nil {
+		t.Fatal(err)
+	}
+	if got := kbase.SearchTagged("deployment", []string{"infra"}, 10); len(got) != 1 {
+		t.Errorf("sidecar tags added after indexing were not picked up, got %d results", len(got))
+	}
+}
@@ -0,0 +1,121 @@
+package kb
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// splitFrontMatter strips a leading YAML front-matter block ("---" lines)
+// from content and returns its tags. Both the inline form
+//
+//	tags: [go, kb]
+//
+// and the block list form ("tags:" followed by "- go" lines) are read;
+// other keys are ignored.
+func splitFrontMatter(content string) (string, []string) {
+	rest, ok := strings.CutPrefix(content, "---\n")
+	if !ok {
+		rest, ok = strings.CutPrefix(content, "---\r\n")
+	}
+	if !ok {
+		return content, nil
+	}
+	var header []string
+	body := ""
+	found := false
+	lines := strings.SplitAfter(rest, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "---" {
+			body = strings.Join(lines[i+1:], "")
+			found = true
+			break
+		}
+		header = append(header, strings.TrimRight(line, "\r\n"))
+	}
+	if !found {
+		return content, nil
+	}
+
+	var tags []string
+	inList := false
+	for _, line := range header {
+		trimmed := strings.TrimSpace(line)
+		if inList {
+			if item, ok := strings.CutPrefix(trimmed, "- "); ok {
+				tags = append(tags, cleanTag(item))
+				continue
+			}
+			inList = false
+		}
+		value, ok := strings.CutPrefix(trimmed, "tags:")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			inList = true
+			continue
+		}
+		tags = append(tags, splitTagList(value)...)
+	}
+	return body, mergeTags(tags)
+}
+
+// readSidecarTags reads tags from "<path>.tags", one or more per line,
+// comma-separated. IndexDirectory reindexes a file when its sidecar's
+// modtime changes (see sidecarModTime).
+func readSidecarTags(path string) []string {
+	data, err := os.ReadFile(path + ".tags")
+	if err != nil {
+		return nil
+	}
+	var tags []string
+	for _, line := range strings.Split(string(data), "\n") {
+		tags = append(tags, splitTagList(line)...)
+	}
+	return tags
+}
+
+// sidecarModTime returns the modtime of "<path>.tags", or the zero time if
+// there is no sidecar.
+func sidecarModTime(path string) time.Time {
+	info, err := os.Stat(path + ".tags")
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// splitTagList parses "[a, b]" or "a, b".
+func splitTagList(s string) []string {
+	s = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(s), "["), "]")
+	var tags []string
+	for _, t := range strings.Split(s, ",") {
+		if t = cleanTag(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+func cleanTag(t string) string {
+	return strings.Trim(strings.TrimSpace(t), `"'`)
+}
+
+// mergeTags concatenates tag lists, dropping blanks and duplicates.
+func mergeTags(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, l := range lists {
+		for _, t := range l {
+			key := strings.ToLower(t)
+			if t == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
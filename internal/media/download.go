@@ -0,0 +1,72 @@
+// Package media holds helpers shared by the generation agents (imagegen,
+// music) for fetching the files their backends produce.
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrTooLarge is returned when a download exceeds its size limit.
+var ErrTooLarge = errors.New("media: download too large")
+
+// Extensions maps served content types to file extensions.
+var Extensions = map[string]string{
+	"image/png":    ".png",
+	"image/jpeg":   ".jpg",
+	"image/webp":   ".webp",
+	"image/gif":    ".gif",
+	"audio/wav":    ".wav",
+	"audio/x-wav":  ".wav",
+	"audio/wave":   ".wav",
+	"audio/mpeg":   ".mp3",
+	"audio/ogg":    ".ogg",
+	"audio/flac":   ".flac",
+	"audio/x-flac": ".flac",
+}
+
+// Download fetches url with client into path, swapping path's extension for
+// the one matching the content type (header, else sniffed). Bodies over
+// maxBytes are rejected with ErrTooLarge and nothing is written. It returns
+// the path written.
+func Download(ctx context.Context, client *http.Client, url, path string, maxBytes int64) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > maxBytes {
+		return "", fmt.Errorf("%w: over %d bytes", ErrTooLarge, maxBytes)
+	}
+	ctype, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	ext, ok := Extensions[ctype]
+	if !ok {
+		ctype, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+		ext, ok = Extensions[ctype]
+	}
+	if ok {
+		path = strings.TrimSuffix(path, filepath.Ext(path)) + ext
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,35 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDownloadRejectsOversizedBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer ts.Close()
+
+	out := filepath.Join(t.TempDir(), "out.png")
+	if _, err := Download(context.Background(), ts.Client(), ts.URL, out, 99); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Error("oversized download should not be written")
+	}
+	path, err := Download(context.Background(), ts.Client(), ts.URL, out, 100)
+	if err != nil {
+		t.Fatalf("download at the limit: %v", err)
+	}
+	if data, _ := os.ReadFile(path); len(data) != 100 {
+		t.Errorf("downloaded %d bytes, want 100", len(data))
+	}
+}
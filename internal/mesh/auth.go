@@ -0,0 +1,143 @@
+package mesh
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MeshSecretEnv names the environment variable holding the shared secret
+// every node in the mesh signs its mDNS announcement with.
+const MeshSecretEnv = "NEXUS_MESH_SECRET"
+
+// TXT record keys of the signed announcement fields: the signature, the
+// node's IPv4 addresses and the Unix time it was signed.
+const (
+	sigKey  = "sig"
+	addrKey = "addr"
+	tsKey   = "ts"
+)
+
+// Announcements are re-signed every announceInterval, and older than
+// maxAnnouncementAge (or that far in the future) are rejected, so a captured
+// announcement can only be replayed briefly, and only pointing at the
+// addresses it was signed with.
+const (
+	announceInterval   = time.Minute
+	maxAnnouncementAge = 5 * time.Minute
+)
+
+var (
+	// ErrNoMeshSecret is returned by Discovery.Start when no shared secret is
+	// configured; without one, peers cannot be authenticated.
+	ErrNoMeshSecret = errors.New("mesh: " + MeshSecretEnv + " not set")
+	// ErrBadSignature means an announcement was unsigned or its HMAC did not
+	// match the shared secret.
+	ErrBadSignature = errors.New("mesh: missing or invalid announcement signature")
+	// ErrStaleAnnouncement means a correctly signed announcement was too old
+	// (or too far in the future) to accept, e.g. a replay.
+	ErrStaleAnnouncement = errors.New("mesh: stale announcement")
+)
+
+// signAnnouncement returns txt with addr=, ts= and sig= records appended.
+// The HMAC-SHA256 covers the instance name, port, the addresses, the
+// signing time and every other TXT record, so a peer can't alter its
+// advertised ID, port, address or hardware profile without the secret.
+func signAnnouncement(secret []byte, instance string, port int, txt []string, addrs []net.IP, now time.Time) []string {
+	ips := make([]string, len(addrs))
+	for i, ip := range addrs {
+		ips[i] = ip.String()
+	}
+	signed := make([]string, 0, len(txt)+3)
+	signed = append(signed, txt...)
+	signed = append(signed, addrKey+"="+strings.Join(ips, ","), tsKey+"="+strconv.FormatInt(now.Unix(), 10))
+	return append(signed, sigKey+"="+announcementMAC(secret, instance, port, signed))
+}
+
+// verifyAnnouncement checks the sig= and ts= records of an announcement and
+// returns its remaining TXT records as key/value pairs.
+func verifyAnnouncement(secret []byte, instance string, port int, txt []string, now time.Time) (map[string]string, error) {
+	fields := make(map[string]string, len(txt))
+	unsigned := make([]string, 0, len(txt))
+	var sig string
+	for _, rec := range txt {
+		k, v, _ := strings.Cut(rec, "=")
+		if k == sigKey {
+			sig = v
+			continue
+		}
+		fields[k] = v
+		unsigned = append(unsigned, rec)
+	}
+	if sig == "" || len(secret) == 0 {
+		return nil, ErrBadSignature
+	}
+	want := announcementMAC(secret, instance, port, unsigned)
+	if !hmac.Equal([]byte(sig), []byte(want)) {
+		return nil, ErrBadSignature
+	}
+	ts, err := strconv.ParseInt(fields[tsKey], 10, 64)
+	if err != nil {
+		return nil, ErrBadSignature
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > maxAnnouncementAge || age < -maxAnnouncementAge {
+		return nil, ErrStaleAnnouncement
+	}
+	return fields, nil
+}
+
+// announcedAddr returns the first of seen (the addresses mDNS resolved the
+// announcement to) that the announcement was signed with.
+func announcedAddr(fields map[string]string, seen []net.IP) (net.IP, bool) {
+	for _, s := range strings.Split(fields[addrKey], ",") {
+		signed := net.ParseIP(s)
+		if signed == nil {
+			continue
+		}
+		for _, ip := range seen {
+			if ip.Equal(signed) {
+				return ip, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// localIPv4 returns the node's non-loopback IPv4 addresses, the ones mDNS
+// advertises it on.
+func localIPv4() []net.IP {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	var ips []net.IP
+	for _, a := range addrs {
+		if n, ok := a.(*net.IPNet); ok && !n.IP.IsLoopback() && n.IP.To4() != nil {
+			ips = append(ips, n.IP.To4())
+		}
+	}
+	return ips
+}
+
+// announcementMAC computes the hex HMAC of the canonical announcement:
+// instance, port and the sorted TXT records, newline-separated.
+func announcementMAC(secret []byte, instance string, port int, txt []string) string {
+	sorted := append([]string(nil), txt...)
+	sort.Strings(sorted)
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%s\n%d\n%s", instance, port, strings.Join(sorted, "\n"))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// secretFromEnv reads the shared mesh secret from MeshSecretEnv.
+func secretFromEnv() []byte {
+	return []byte(os.Getenv(MeshSecretEnv))
+}
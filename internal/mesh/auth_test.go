@@ -0,0 +1,61 @@
+package mesh
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestAnnouncementSignature(t *testing.T) {
+	secret := []byte("s3cret")
+	now := time.Unix(1_800_000_000, 0)
+	addrs := []net.IP{net.ParseIP("192.168.1.20")}
+	txt := []string{"id=nexus-a", "gpu=true", "cpu=M2"}
+	signed := signAnnouncement(secret, "nexus-a", 7700, txt, addrs, now)
+
+	fields, err := verifyAnnouncement(secret, "nexus-a", 7700, signed, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("valid announcement rejected: %v", err)
+	}
+	if fields["gpu"] != "true" || fields["cpu"] != "M2" {
+		t.Fatalf("fields = %v", fields)
+	}
+
+	cases := map[string]func() (map[string]string, error){
+		"unsigned": func() (map[string]string, error) { return verifyAnnouncement(secret, "nexus-a", 7700, txt, now) },
+		"wrong secret": func() (map[string]string, error) {
+			return verifyAnnouncement([]byte("other"), "nexus-a", 7700, signed, now)
+		},
+		"other port": func() (map[string]string, error) { return verifyAnnouncement(secret, "nexus-a", 7701, signed, now) },
+		"spoofed id": func() (map[string]string, error) { return verifyAnnouncement(secret, "nexus-b", 7700, signed, now) },
+		"tampered cpu": func() (map[string]string, error) {
+			forged := append([]string{"id=nexus-a", "gpu=true", "cpu=Threadripper"}, signed[3:]...)
+			return verifyAnnouncement(secret, "nexus-a", 7700, forged, now)
+		},
+		"tampered addr": func() (map[string]string, error) {
+			forged := append([]string(nil), signed...)
+			forged[3] = "addr=192.168.1.66"
+			return verifyAnnouncement(secret, "nexus-a", 7700, forged, now)
+		},
+	}
+	for name, verify := range cases {
+		if _, err := verify(); err != ErrBadSignature {
+			t.Errorf("%s: err = %v, want ErrBadSignature", name, err)
+		}
+	}
+
+	if _, err := verifyAnnouncement(secret, "nexus-a", 7700, signed, now.Add(maxAnnouncementAge+time.Second)); err != ErrStaleAnnouncement {
+		t.Errorf("replayed old announcement: err = %v, want ErrStaleAnnouncement", err)
+	}
+}
+
+func TestAnnouncedAddr(t *testing.T) {
+	fields := map[string]string{addrKey: "192.168.1.20,10.0.0.5"}
+	if ip, ok := announcedAddr(fields, []net.IP{net.ParseIP("10.0.0.5")}); !ok || ip.String() != "10.0.0.5" {
+		t.Errorf("announcedAddr = %v, %v", ip, ok)
+	}
+	// A replay from another host resolves to that host's address.
+	if _, ok := announcedAddr(fields, []net.IP{net.ParseIP("192.168.1.66")}); ok {
+		t.Error("announcement accepted from an address it was not signed with")
+	}
+}
@@ -3,6 +3,7 @@ package mesh
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/grandcat/zeroconf"
@@ -19,37 +20,72 @@ type Discovery struct {
 	network   *Network
 	localNode *Node
 	server    *zeroconf.Server
+	secret    []byte
 }
 
-// NewDiscovery initializes the mDNS service.
+// NewDiscovery initializes the mDNS service. The shared secret used to sign
+// and verify announcements is read from NEXUS_MESH_SECRET.
 func NewDiscovery(net *Network, local *Node) *Discovery {
 	return &Discovery{
 		network:   net,
 		localNode: local,
+		secret:    secretFromEnv(),
 	}
 }
 
-// Start begins advertising the local node and listening for others.
+// SetSecret overrides the shared secret taken from NEXUS_MESH_SECRET.
+func (d *Discovery) SetSecret(secret string) {
+	d.secret = []byte(secret)
+}
+
+// Start begins advertising the local node and listening for others. Every
+// announcement is HMAC-signed with the shared secret together with the
+// node's addresses and the current time, and re-signed every minute. Peers
+// whose announcement is unsigned, fails verification, is stale or resolves
+// to an address it was not signed for are never registered.
+// Start returns ErrNoMeshSecret if no secret is configured.
 func (d *Discovery) Start(ctx context.Context, port int) error {
+	if len(d.secret) == 0 {
+		return ErrNoMeshSecret
+	}
+
 	// 1. Advertise Local Node
 	txtRecords := []string{
 		fmt.Sprintf("id=%s", d.localNode.ID),
 		fmt.Sprintf("gpu=%t", d.localNode.Profile.HasGPU),
 		fmt.Sprintf("cpu=%s", d.localNode.Profile.CPUModel),
 	}
+	if caps := d.localNode.Profile.Capabilities; len(caps) > 0 {
+		txtRecords = append(txtRecords, "caps="+strings.Join(caps, ","))
+	}
 
+	announcement := func() []string {
+		return signAnnouncement(d.secret, d.localNode.ID, port, txtRecords, localIPv4(), time.Now())
+	}
 	server, err := zeroconf.Register(
 		d.localNode.ID,
 		ServiceName,
 		Domain,
 		port,
-		txtRecords,
+		announcement(),
 		nil,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to register mDNS service: %w", err)
 	}
 	d.server = server
+	go func() {
+		ticker := time.NewTicker(announceInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				server.SetText(announcement())
+			}
+		}
+	}()
 	log.Info().Str("service", ServiceName).Msg("📡 Broadcasting NEXUS Node to local mesh...")
 
 	// 2. Discover Peers
@@ -66,25 +102,32 @@ func (d *Discovery) Start(ctx context.Context, port int) error {
 				continue
 			}
 
-			// Parse TXT records for hardware profile
-			hasGPU := false
-			for _, txt := range entry.Text {
-				if txt == "gpu=true" {
-					hasGPU = true
-				}
+			if len(entry.AddrIPv4) == 0 {
+				continue
 			}
 
-			if len(entry.AddrIPv4) == 0 {
+			fields, err := verifyAnnouncement(d.secret, entry.Instance, entry.Port, entry.Text, time.Now())
+			if err != nil {
+				log.Warn().Err(err).Str("instance", entry.Instance).Str("addr", entry.AddrIPv4[0].String()).
+					Msg("mesh: rejected peer announcement")
+				continue
+			}
+			ip, ok := announcedAddr(fields, entry.AddrIPv4)
+			if !ok {
+				log.Warn().Str("instance", entry.Instance).Str("addr", entry.AddrIPv4[0].String()).
+					Msg("mesh: rejected peer announcement for an address it was not signed with")
 				continue
 			}
 
-			peerAddr := fmt.Sprintf("%s:%d", entry.AddrIPv4[0].String(), entry.Port)
+			peerAddr := fmt.Sprintf("%s:%d", ip.String(), entry.Port)
 
 			peer := &Node{
 				ID:      entry.Instance,
 				Address: peerAddr,
 				Profile: HardwareProfile{
-					HasGPU: hasGPU,
+					HasGPU:       fields["gpu"] == "true",
+					CPUModel:     fields["cpu"],
+					Capabilities: ParseCapabilities(fields["caps"]),
 				},
 				LastSeen: time.Now(),
 			}
@@ -108,3 +151,15 @@ func (d *Discovery) Stop() {
 		d.server.Shutdown()
 	}
 }
+
+// ParseCapabilities parses a comma-separated capability list, as carried in
+// the caps= TXT record or NEXUS_MESH_CAPABILITIES.
+func ParseCapabilities(s string) []string {
+	var caps []string
+	for _, c := range strings.Split(s, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			caps = append(caps, c)
+		}
+	}
+	return caps
+}
@@ -0,0 +1,84 @@
+package mesh
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Identity is the durable identity of the local mesh node. It is persisted so
+// a restarted node keeps its peer ID instead of appearing as a brand-new peer.
+type Identity struct {
+	PeerID    string    `json:"peer_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DefaultIdentityPath returns ~/.nexus/mesh/identity.
+func DefaultIdentityPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".nexus", "mesh", "identity")
+}
+
+// LoadOrCreateIdentity reads the identity at path, generating and persisting a
+// new one if none exists. If reset is true any existing identity is discarded.
+// If path is empty, DefaultIdentityPath is used. The file is written with 0600.
+func LoadOrCreateIdentity(path string, reset bool) (*Identity, error) {
+	if path == "" {
+		path = DefaultIdentityPath()
+	}
+	if !reset {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var id Identity
+			if err := json.Unmarshal(data, &id); err != nil {
+				return nil, fmt.Errorf("mesh: corrupt identity file %s (use --reset-identity): %w", path, err)
+			}
+			if id.PeerID == "" {
+				return nil, fmt.Errorf("mesh: identity file %s has no peer_id (use --reset-identity)", path)
+			}
+			return &id, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("mesh: read identity: %w", err)
+		}
+	}
+
+	peerID, err := generateSecurePeerID()
+	if err != nil {
+		return nil, err
+	}
+	id := &Identity{PeerID: peerID, CreatedAt: time.Now().UTC()}
+	if err := saveIdentity(path, id); err != nil {
+		return nil, err
+	}
+	return id, nil
+}
+
+// saveIdentity writes id to path atomically with owner-only permissions.
+func saveIdentity(path string, id *Identity) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("mesh: mkdir: %w", err)
+	}
+	data, err := json.MarshalIndent(id, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("mesh: write identity: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// generateSecurePeerID returns a crypto/rand peer ID such as "nexus-1a2b3c4d5e6f7a8b".
+func generateSecurePeerID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("mesh: generate peer id: %w", err)
+	}
+	return "nexus-" + hex.EncodeToString(b), nil
+}
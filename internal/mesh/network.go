@@ -2,27 +2,44 @@ package mesh
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
+// ErrNoCapablePeer is returned by RouteTask when neither the local node nor any
+// peer advertises the task's RequiredCapability.
+var ErrNoCapablePeer = errors.New("mesh: no node advertises required capability")
+
 // Network manages peer discovery and intelligent task routing across the local network.
 type Network struct {
-	mu         sync.RWMutex
-	localNode  *Node
-	peers      map[string]*Node
-	client     NodeClient
+	mu        sync.RWMutex
+	localNode *Node
+	peers     map[string]*Node
+	client    NodeClient
+	queue     *TaskQueue
+
+	resultMu    sync.Mutex
+	completed   map[string]*TaskResult      // unclaimed results by task ID
+	order       []string                    // completed IDs, oldest first, for GetResult
+	resultReady chan struct{}               // closed and replaced when a result is left unclaimed
+	waiters     map[string]chan *TaskResult // GetResultByID callers by task ID
 }
 
 // NewNetwork initializes the P2P Mesh engine.
 func NewNetwork(local *Node, client NodeClient) *Network {
 	return &Network{
-		localNode: local,
-		peers:     make(map[string]*Node),
-		client:    client,
+		localNode:   local,
+		peers:       make(map[string]*Node),
+		client:      client,
+		queue:       NewTaskQueue(DefaultQueueCapacity),
+		completed:   make(map[string]*TaskResult),
+		resultReady: make(chan struct{}),
+		waiters:     make(map[string]chan *TaskResult),
 	}
 }
 
@@ -62,15 +79,18 @@ func (n *Network) RouteTask(ctx context.Context, req *TaskRequest) (*TaskRespons
 	n.mu.RLock()
 	defer n.mu.RUnlock()
 
-	// Hardware routing logic
+	// Hardware routing logic. Only peers advertising the task's required
+	// capability are eligible; the local node too must have it to run the task.
 	var bestPeer *Node
+	localCapable := n.localNode.Profile.HasCapability(req.RequiredCapability)
 
 	switch req.TaskType {
 	case "IMAGE_GEN", "LOCAL_LLM":
 		// These require a GPU. If local node lacks a GPU, find a peer that has one.
-		if !n.localNode.Profile.HasGPU {
+		if !n.localNode.Profile.HasGPU || !localCapable {
 			for _, peer := range n.peers {
-				if peer.Profile.HasGPU && peer.Profile.LoadAverage < 0.8 {
+				if peer.Profile.HasCapability(req.RequiredCapability) &&
+					peer.Profile.HasGPU && peer.Profile.LoadAverage < 0.8 {
 					bestPeer = peer
 					break
 				}
@@ -79,7 +99,13 @@ func (n *Network) RouteTask(ctx context.Context, req *TaskRequest) (*TaskRespons
 	default:
 		// For standard tasks, route to the node with the lowest CPU load.
 		lowestLoad := n.localNode.Profile.LoadAverage
+		if !localCapable {
+			lowestLoad = math.Inf(1)
+		}
 		for _, peer := range n.peers {
+			if !peer.Profile.HasCapability(req.RequiredCapability) {
+				continue
+			}
 			if peer.Profile.LoadAverage < lowestLoad {
 				lowestLoad = peer.Profile.LoadAverage
 				bestPeer = peer
@@ -87,6 +113,9 @@ func (n *Network) RouteTask(ctx context.Context, req *TaskRequest) (*TaskRespons
 		}
 	}
 
+	if bestPeer == nil && !localCapable {
+		return nil, fmt.Errorf("%w: %q", ErrNoCapablePeer, req.RequiredCapability)
+	}
 	if bestPeer == nil {
 		log.Info().Str("task", req.TaskType).Msg("Executing task locally.")
 		return n.executeLocally(ctx, req)
@@ -2,6 +2,8 @@ package mesh
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -72,3 +74,53 @@ func TestMeshNetwork_PruneDeadPeers(t *testing.T) {
 		t.Errorf("expected 0 peers after pruning, got %d", len(net.peers))
 	}
 }
+
+func TestIdentityPersistsAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mesh", "identity")
+
+	first, err := LoadOrCreateIdentity(path, false)
+	if err != nil {
+		t.Fatalf("LoadOrCreateIdentity: %v", err)
+	}
+	second, err := LoadOrCreateIdentity(path, false)
+	if err != nil {
+		t.Fatalf("LoadOrCreateIdentity (reload): %v", err)
+	}
+	if first.PeerID != second.PeerID {
+		t.Errorf("peer ID changed across restarts: %s -> %s", first.PeerID, second.PeerID)
+	}
+
+	reset, err := LoadOrCreateIdentity(path, true)
+	if err != nil {
+		t.Fatalf("LoadOrCreateIdentity (reset): %v", err)
+	}
+	if reset.PeerID == first.PeerID {
+		t.Error("expected a new peer ID after reset")
+	}
+}
+
+func TestMeshNetwork_RequiredCapability(t *testing.T) {
+	client := &mockClient{}
+	net := NewNetwork(&Node{ID: "local", Profile: HardwareProfile{LoadAverage: 0.5}}, client)
+	net.RegisterPeer(&Node{ID: "idle", Address: "10.0.0.2:7070", Profile: HardwareProfile{LoadAverage: 0.1}})
+	net.RegisterPeer(&Node{ID: "sd-busy", Address: "10.0.0.3:7070", Profile: HardwareProfile{
+		LoadAverage: 0.4, Capabilities: []string{"stable-diffusion"},
+	}})
+	net.RegisterPeer(&Node{ID: "sd-idle", Address: "10.0.0.4:7070", Profile: HardwareProfile{
+		LoadAverage: 0.2, Capabilities: []string{"ollama", "Stable-Diffusion"},
+	}})
+
+	// Among capable peers the lowest load still wins; "idle" is skipped.
+	req := &TaskRequest{TaskType: "RENDER", RequiredCapability: "stable-diffusion"}
+	if _, err := net.RouteTask(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if client.dispatchedTo != "10.0.0.4:7070" {
+		t.Errorf("dispatched to %q, want sd-idle", client.dispatchedTo)
+	}
+
+	req = &TaskRequest{TaskType: "RENDER", RequiredCapability: "whisper"}
+	if _, err := net.RouteTask(context.Background(), req); !errors.Is(err, ErrNoCapablePeer) {
+		t.Errorf("err = %v, want ErrNoCapablePeer", err)
+	}
+}
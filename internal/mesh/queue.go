@@ -0,0 +1,322 @@
+package mesh
+
+import (
+	"container/heap"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultQueueCapacity bounds the number of pending tasks per node.
+const DefaultQueueCapacity = 256
+
+var (
+	// ErrQueueFull is returned by SubmitTask when the queue is at capacity.
+	ErrQueueFull = errors.New("mesh: task queue is full")
+	// ErrQueueClosed is returned once the queue has been closed.
+	ErrQueueClosed = errors.New("mesh: task queue is closed")
+)
+
+// QueueStats reports pending task depth, overall and per priority.
+type QueueStats struct {
+	Depth      int         `json:"depth"`
+	Capacity   int         `json:"capacity"`
+	ByPriority map[int]int `json:"by_priority"`
+}
+
+type queuedTask struct {
+	req *TaskRequest
+	seq uint64 // FIFO tie-break within a priority
+}
+
+// taskHeap orders tasks by descending Priority, then by submission order.
+type taskHeap []queuedTask
+
+func (h taskHeap) Len() int { return len(h) }
+func (h taskHeap) Less(i, j int) bool {
+	if h[i].req.Priority != h[j].req.Priority {
+		return h[i].req.Priority > h[j].req.Priority
+	}
+	return h[i].seq < h[j].seq
+}
+func (h taskHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *taskHeap) Push(x interface{}) { *h = append(*h, x.(queuedTask)) }
+func (h *taskHeap) Pop() interface{} {
+	old := *h
+	t := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return t
+}
+
+// TaskQueue is a bounded priority queue. Producers may fail fast (Push) or
+// block until there is room (PushWait); consumers block in Pop.
+type TaskQueue struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	items    taskHeap
+	capacity int
+	seq      uint64
+	closed   bool
+}
+
+// NewTaskQueue creates a queue holding at most capacity tasks.
+func NewTaskQueue(capacity int) *TaskQueue {
+	if capacity <= 0 {
+		capacity = DefaultQueueCapacity
+	}
+	q := &TaskQueue{capacity: capacity}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// Push enqueues req, returning ErrQueueFull immediately if there is no room.
+func (q *TaskQueue) Push(req *TaskRequest) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	if len(q.items) >= q.capacity {
+		return ErrQueueFull
+	}
+	q.push(req)
+	return nil
+}
+
+// PushWait enqueues req, blocking until there is room or ctx is done.
+func (q *TaskQueue) PushWait(ctx context.Context, req *TaskRequest) error {
+	stop := context.AfterFunc(ctx, q.wake)
+	defer stop()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.items) >= q.capacity && !q.closed {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		q.cond.Wait()
+	}
+	if q.closed {
+		return ErrQueueClosed
+	}
+	q.push(req)
+	return nil
+}
+
+// Pop removes the highest-priority task, blocking until one is available,
+// ctx is done, or the queue is closed.
+func (q *TaskQueue) Pop(ctx context.Context) (*TaskRequest, error) {
+	stop := context.AfterFunc(ctx, q.wake)
+	defer stop()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.items) == 0 && !q.closed {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		q.cond.Wait()
+	}
+	if len(q.items) == 0 {
+		return nil, ErrQueueClosed
+	}
+	t := heap.Pop(&q.items).(queuedTask)
+	q.cond.Broadcast() // room for blocked producers
+	return t.req, nil
+}
+
+// Stats returns the current depth by priority.
+func (q *TaskQueue) Stats() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	s := QueueStats{Depth: len(q.items), Capacity: q.capacity, ByPriority: make(map[int]int)}
+	for _, t := range q.items {
+		s.ByPriority[t.req.Priority]++
+	}
+	return s
+}
+
+// Close wakes all waiters; pending tasks can still be drained with Pop.
+func (q *TaskQueue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
+
+// push adds req. Caller must hold q.mu.
+func (q *TaskQueue) push(req *TaskRequest) {
+	q.seq++
+	heap.Push(&q.items, queuedTask{req: req, seq: q.seq})
+	q.cond.Broadcast()
+}
+
+func (q *TaskQueue) wake() {
+	q.mu.Lock()
+	q.cond.Broadcast()
+	q.mu.Unlock()
+}
+
+// SubmitTask queues req for asynchronous routing and returns its task ID.
+// It fails fast with ErrQueueFull when the queue is at capacity.
+func (n *Network) SubmitTask(req *TaskRequest) (string, error) {
+	if err := ensureTaskID(req); err != nil {
+		return "", err
+	}
+	if err := n.queue.Push(req); err != nil {
+		return "", err
+	}
+	return req.ID, nil
+}
+
+// SubmitTaskWait is like SubmitTask but applies backpressure: it blocks until
+// there is room in the queue or ctx is done.
+func (n *Network) SubmitTaskWait(ctx context.Context, req *TaskRequest) (string, error) {
+	if err := ensureTaskID(req); err != nil {
+		return "", err
+	}
+	if err := n.queue.PushWait(ctx, req); err != nil {
+		return "", err
+	}
+	return req.ID, nil
+}
+
+// ProcessQueue routes queued tasks with the given number of workers until ctx
+// is done. Results are delivered through GetResultByID or GetResult.
+func (n *Network) ProcessQueue(ctx context.Context, workers int) {
+	if workers <= 0 {
+		workers = 1
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				req, err := n.queue.Pop(ctx)
+				if err != nil {
+					return
+				}
+				res := &TaskResult{TaskID: req.ID}
+				resp, err := n.RouteTask(ctx, req)
+				res.Response = resp
+				if err != nil {
+					res.Error = err.Error()
+				}
+				n.deliver(res)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// deliver hands res to a GetResultByID caller waiting on its task ID, or
+// leaves it unclaimed for whichever of GetResultByID or GetResult asks first.
+// At most DefaultQueueCapacity results are held unclaimed.
+func (n *Network) deliver(res *TaskResult) {
+	n.resultMu.Lock()
+	defer n.resultMu.Unlock()
+	if ch, ok := n.waiters[res.TaskID]; ok {
+		delete(n.waiters, res.TaskID)
+		ch <- res
+		return
+	}
+	if len(n.completed) >= DefaultQueueCapacity {
+		log.Warn().Str("task_id", res.TaskID).Msg("mesh result queue full, dropping result")
+		return
+	}
+	n.completed[res.TaskID] = res
+	n.order = append(n.order, res.TaskID)
+	close(n.resultReady)
+	n.resultReady = make(chan struct{})
+}
+
+// GetResult returns the next completed task result in FIFO order, waiting up
+// to timeout. It may return any task's result; submitters waiting on their own
+// task should use GetResultByID. Results already claimed by ID are not
+// returned.
+func (n *Network) GetResult(timeout time.Duration) (*TaskResult, error) {
+	deadline := time.After(timeout)
+	for {
+		n.resultMu.Lock()
+		if len(n.order) > 0 {
+			id := n.order[0]
+			n.order = n.order[1:]
+			res := n.completed[id]
+			delete(n.completed, id)
+			n.resultMu.Unlock()
+			return res, nil
+		}
+		ready := n.resultReady
+		n.resultMu.Unlock()
+		select {
+		case <-ready:
+		case <-deadline:
+			return nil, fmt.Errorf("mesh: no result within %s", timeout)
+		}
+	}
+}
+
+// GetResultByID returns the result of the task with the given ID, waiting up
+// to timeout. Only one caller may wait on a task ID at a time.
+func (n *Network) GetResultByID(taskID string, timeout time.Duration) (*TaskResult, error) {
+	n.resultMu.Lock()
+	if res, ok := n.completed[taskID]; ok {
+		delete(n.completed, taskID)
+		for i, id := range n.order {
+			if id == taskID {
+				n.order = append(n.order[:i], n.order[i+1:]...)
+				break
+			}
+		}
+		n.resultMu.Unlock()
+		return res, nil
+	}
+	if _, ok := n.waiters[taskID]; ok {
+		n.resultMu.Unlock()
+		return nil, fmt.Errorf("mesh: task %s already has a waiting caller", taskID)
+	}
+	ch := make(chan *TaskResult, 1)
+	n.waiters[taskID] = ch
+	n.resultMu.Unlock()
+
+	select {
+	case res := <-ch:
+		return res, nil
+	case <-time.After(timeout):
+	}
+	n.resultMu.Lock()
+	defer n.resultMu.Unlock()
+	if n.waiters[taskID] == ch {
+		delete(n.waiters, taskID)
+	}
+	select {
+	case res := <-ch: // delivered while timing out
+		return res, nil
+	default:
+		return nil, fmt.Errorf("mesh: no result for task %s within %s", taskID, timeout)
+	}
+}
+
+// QueueStats reports pending task depth by priority.
+func (n *Network) QueueStats() QueueStats {
+	return n.queue.Stats()
+}
+
+func ensureTaskID(req *TaskRequest) error {
+	if req.ID != "" {
+		return nil
+	}
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("mesh: generate task id: %w", err)
+	}
+	req.ID = "task-" + hex.EncodeToString(b)
+	return nil
+}
@@ -0,0 +1,153 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTaskQueue_PriorityOrder(t *testing.T) {
+	q := NewTaskQueue(10)
+	for _, r := range []*TaskRequest{
+		{ID: "low", Priority: 1},
+		{ID: "high", Priority: 9},
+		{ID: "mid-a", Priority: 5},
+		{ID: "mid-b", Priority: 5},
+	} {
+		if err := q.Push(r); err != nil {
+			t.Fatalf("push %s: %v", r.ID, err)
+		}
+	}
+	if s := q.Stats(); s.Depth != 4 || s.ByPriority[5] != 2 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		r, err := q.Pop(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, r.ID)
+	}
+	want := []string{"high", "mid-a", "mid-b", "low"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestTaskQueue_Backpressure(t *testing.T) {
+	q := NewTaskQueue(1)
+	if err := q.Push(&TaskRequest{ID: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Push(&TaskRequest{ID: "b"}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := q.PushWait(ctx, &TaskRequest{ID: "b"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- q.PushWait(context.Background(), &TaskRequest{ID: "c"}) }()
+	time.Sleep(10 * time.Millisecond)
+	if _, err := q.Pop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("blocked push failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("blocked push was not released")
+	}
+}
+
+func TestNetwork_SubmitAndProcess(t *testing.T) {
+	net := NewNetwork(&Node{ID: "local"}, &mockClient{})
+	id, err := net.SubmitTask(&TaskRequest{TaskType: "SUMMARY"})
+	if err != nil || id == "" {
+		t.Fatalf("submit: id=%q err=%v", id, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go net.ProcessQueue(ctx, 2)
+
+	res, err := net.GetResult(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TaskID != id || res.Response == nil {
+		t.Errorf("unexpected result: %+v", res)
+	}
+}
+
+func TestNetwork_GetResultByID(t *testing.T) {
+	net := NewNetwork(&Node{ID: "local"}, &mockClient{})
+	idA, err := net.SubmitTask(&TaskRequest{TaskType: "SUMMARY", Priority: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	idB, err := net.SubmitTask(&TaskRequest{TaskType: "SUMMARY", Priority: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// B is higher priority so it completes first; A's caller must still get A.
+	got := make(chan *TaskResult, 2)
+	for _, id := range []string{idA, idB} {
+		go func(id string) {
+			res, err := net.GetResultByID(id, 2*time.Second)
+			if err != nil {
+				t.Error(err)
+			} else if res.TaskID != id {
+				t.Errorf("caller for %s got result of %s", id, res.TaskID)
+			}
+			got <- res
+		}(id)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go net.ProcessQueue(ctx, 1)
+
+	seen := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		res := <-got
+		if res == nil {
+			t.Fatal("missing result")
+		}
+		seen[res.TaskID] = true
+	}
+	if !seen[idA] || !seen[idB] {
+		t.Fatalf("results = %v, want %s and %s", seen, idA, idB)
+	}
+	if _, err := net.GetResult(50 * time.Millisecond); err == nil {
+		t.Error("results claimed by ID were also delivered via GetResult")
+	}
+}
+
+func TestNetwork_ResultsClaimedByIDDoNotFillQueue(t *testing.T) {
+	net := NewNetwork(&Node{ID: "local"}, &mockClient{})
+	for i := 0; i < DefaultQueueCapacity+10; i++ {
+		id := fmt.Sprintf("task-%d", i)
+		net.deliver(&TaskResult{TaskID: id})
+		if _, err := net.GetResultByID(id, time.Second); err != nil {
+			t.Fatalf("result %d: %v", i, err)
+		}
+	}
+	if len(net.order) != 0 || len(net.completed) != 0 {
+		t.Errorf("claimed results still held: order=%d completed=%d", len(net.order), len(net.completed))
+	}
+	net.deliver(&TaskResult{TaskID: "last"})
+	if res, err := net.GetResult(time.Second); err != nil || res.TaskID != "last" {
+		t.Errorf("GetResult = %v, %v", res, err)
+	}
+}
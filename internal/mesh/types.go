@@ -2,8 +2,7 @@ package mesh
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"strings"
 	"time"
 )
 
@@ -13,6 +12,23 @@ type HardwareProfile struct {
 	TotalRAM     uint64  `json:"total_ram"`
 	CPUModel     string  `json:"cpu_model"`
 	LoadAverage  float64 `json:"load_average"`
+	// Capabilities lists the services this node can run, e.g. "stable-diffusion"
+	// or "ollama". Tasks with a RequiredCapability only route to nodes listing it.
+	Capabilities []string `json:"capabilities,omitempty"`
+}
+
+// HasCapability reports whether the profile advertises capability. An empty
+// capability is always satisfied.
+func (p HardwareProfile) HasCapability(capability string) bool {
+	if capability == "" {
+		return true
+	}
+	for _, c := range p.Capabilities {
+		if strings.EqualFold(c, capability) {
+			return true
+		}
+	}
+	return false
 }
 
 // Node represents a single instance of NEXUS running on a device (Phone, PC, VPS).
@@ -25,8 +41,13 @@ type Node struct {
 
 // TaskRequest represents a payload sent from a weak node to a strong node.
 type TaskRequest struct {
+	ID       string `json:"id,omitempty"`
 	TaskType string `json:"task_type"` // e.g., "IMAGE_GEN", "LLM_INFERENCE"
 	Payload  []byte `json:"payload"`
+	Priority int    `json:"priority,omitempty"` // higher is dispatched first
+	// RequiredCapability, if set, restricts routing to nodes whose profile
+	// advertises it (see HardwareProfile.Capabilities).
+	RequiredCapability string `json:"required_capability,omitempty"`
 }
 
 // TaskResponse represents the result of offloaded computation.
@@ -35,6 +56,13 @@ type TaskResponse struct {
 	Error  string `json:"error,omitempty"`
 }
 
+// TaskResult pairs a queued task with its outcome.
+type TaskResult struct {
+	TaskID   string        `json:"task_id"`
+	Response *TaskResponse `json:"response,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
 // NodeClient handles the HTTP communication between peers in the mesh.
 type NodeClient interface {
 	Dispatch(ctx context.Context, targetAddress string, req *TaskRequest) (*TaskResponse, error)
@@ -0,0 +1,316 @@
+package mobile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// DefaultDispatchTimeout bounds how long a dispatched CLI command may run.
+const DefaultDispatchTimeout = 60 * time.Second
+
+// maxReplyLen keeps replies under Telegram's 4096-character message limit.
+const maxReplyLen = 4000
+
+// ErrUnauthorised is returned by Dispatch for senders not on the allowlist,
+// and for everyone while no allowlist is configured.
+var ErrUnauthorised = errors.New("mobile: user not allowed")
+
+// DefaultDispatchCommands are the commands Dispatch runs when SetCommandTree
+// is given no allowlist: read-only reports that neither spend money, change
+// state nor reach outside the host.
+var DefaultDispatchCommands = []string{
+	"audit show",
+	"calendar today", "calendar tomorrow", "calendar week",
+	"calendar conflicts", "calendar free", "calendar digest",
+	"cost today", "cost month", "cost status", "cost suggest",
+	"kb search", "kb stats",
+	"n8n list",
+	"skills list",
+}
+
+// deniedFlags point commands at other files or configuration and are never
+// accepted from chat, even on allowed commands.
+var deniedFlags = map[string]bool{"config": true, "c": true, "data-dir": true, "dir": true}
+
+var ansiRe = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// SetCommandTree lets Dispatch run nexus CLI commands (cli.Root()) from chat
+// messages. allowed lists the command paths that may run, e.g. "cost status";
+// a path also allows its subcommands. nil means DefaultDispatchCommands.
+// timeout <= 0 uses DefaultDispatchTimeout.
+//
+// Commands must write to cmd.OutOrStdout() for their output to reach the
+// reply; anything printed to os.Stdout goes to the daemon's console.
+func (t *TelegramCompanion) SetCommandTree(root *cobra.Command, allowed []string, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultDispatchTimeout
+	}
+	if allowed == nil {
+		allowed = DefaultDispatchCommands
+	}
+	paths := make(map[string]bool, len(allowed))
+	for _, a := range allowed {
+		paths[strings.Join(strings.Fields(a), " ")] = true
+	}
+	t.mu.Lock()
+	t.root = root
+	t.commands = paths
+	t.dispatchTimeout = timeout
+	t.mu.Unlock()
+}
+
+// Dispatch parses text as nexus CLI arguments ("/audit show --last 7d" or
+// "nexus audit show --last 7d"), runs the matching command and returns its
+// output as a reply. Only senders on the user allowlist may dispatch (with
+// no allowlist configured nobody may), and only allowed commands run.
+//
+// Output is captured through the command tree's SetOut/SetErr writers, so
+// commands run one at a time and a message arriving mid-run is told to wait.
+// A command still running at the timeout is abandoned (its remaining output
+// is discarded).
+func (t *TelegramCompanion) Dispatch(userID int64, text string) (string, error) {
+	t.mu.RLock()
+	root, commands, timeout := t.root, t.commands, t.dispatchTimeout
+	noAllowlist := len(t.allowed) == 0
+	t.mu.RUnlock()
+	if noAllowlist {
+		return "", fmt.Errorf("%w: no AllowedUserIDs configured", ErrUnauthorised)
+	}
+	if !t.isAllowed(userID) {
+		return "", ErrUnauthorised
+	}
+	if root == nil {
+		return "", errors.New("mobile: no command tree configured")
+	}
+
+	args, err := splitArgs(text)
+	if err != nil {
+		return "", err
+	}
+	if len(args) > 0 {
+		args[0] = strings.TrimPrefix(args[0], "/")
+		if i := strings.Index(args[0], "@"); i > 0 {
+			args[0] = args[0][:i] // "/audit@nexus_bot"
+		}
+		if args[0] == root.Name() {
+			args = args[1:]
+		}
+	}
+	if len(args) == 0 {
+		return usage(root, commands, ""), nil
+	}
+	cmd, _, err := root.Find(args)
+	if err != nil || cmd == root {
+		return usage(root, commands, args[0]), nil
+	}
+	path := strings.TrimPrefix(cmd.CommandPath(), root.Name()+" ")
+	if !commandAllowed(commands, path) {
+		log.Warn().Int64("user", userID).Str("command", path).Msg("TG: refused command not allowed from chat")
+		return fmt.Sprintf("🚫 /%s is not enabled for chat.\n\n%s", path, usage(root, commands, "")), nil
+	}
+	if f := deniedFlag(args); f != "" {
+		return fmt.Sprintf("🚫 --%s cannot be set from chat.", f), nil
+	}
+
+	if !t.running.TryLock() {
+		return "⏳ Another command is still running — try again shortly.", nil
+	}
+	out, err := runCaptured(root, args, timeout, t.running.Unlock)
+	reply := strings.TrimSpace(ansiRe.ReplaceAllString(out, ""))
+	if len(reply) > maxReplyLen {
+		reply = reply[:maxReplyLen] + "\n…(truncated)"
+	}
+	if err != nil {
+		return reply, fmt.Errorf("nexus %s: %w", args[0], err)
+	}
+	if reply == "" {
+		reply = "✅ Done."
+	}
+	return reply, nil
+}
+
+// commandAllowed reports whether path ("cost status") or one of its parent
+// commands is in commands.
+func commandAllowed(commands map[string]bool, path string) bool {
+	for p := path; p != ""; {
+		if commands[p] {
+			return true
+		}
+		i := strings.LastIndex(p, " ")
+		if i < 0 {
+			break
+		}
+		p = p[:i]
+	}
+	return false
+}
+
+// deniedFlag returns the name of the first flag in args that chat may not
+// set, or "".
+func deniedFlag(args []string) string {
+	for _, a := range args {
+		if a == "--" {
+			break
+		}
+		if !strings.HasPrefix(a, "-") || a == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(strings.TrimLeft(a, "-"), "=")
+		if deniedFlags[name] {
+			return name
+		}
+	}
+	return ""
+}
+
+// captureWriter collects a command's output until closed; writes after that
+// (from a command abandoned at its timeout) are discarded.
+type captureWriter struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		w.buf.Write(p)
+	}
+	return len(p), nil
+}
+
+// close stops collecting and returns what was written.
+func (w *captureWriter) close() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	return w.buf.String()
+}
+
+// runCaptured executes root with args, capturing its output. done is called
+// once the command has actually finished, which may be after runCaptured has
+// returned on timeout.
+func runCaptured(root *cobra.Command, args []string, timeout time.Duration, done func()) (string, error) {
+	w := &captureWriter{}
+	resetFlags(root)
+	root.SetArgs(args)
+	root.SetOut(w)
+	root.SetErr(w)
+	root.SilenceUsage = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	finished := make(chan error, 1)
+	go func() {
+		defer done()
+		finished <- root.ExecuteContext(ctx)
+	}()
+
+	var runErr error
+	select {
+	case runErr = <-finished:
+	case <-ctx.Done():
+		runErr = fmt.Errorf("timed out after %s", timeout)
+		log.Warn().Strs("args", args).Dur("timeout", timeout).Msg("TG: dispatched command timed out")
+	}
+	return w.close(), runErr
+}
+
+// resetFlags restores every flag in the tree to its default, since cobra
+// keeps parsed values between Execute calls.
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			_ = sv.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, c := range cmd.Commands() {
+		resetFlags(c)
+	}
+}
+
+// usage lists the commands allowed from chat, noting an unknown one.
+func usage(root *cobra.Command, commands map[string]bool, unknown string) string {
+	var names []string
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		for _, sub := range c.Commands() {
+			if !sub.IsAvailableCommand() {
+				continue
+			}
+			path := strings.TrimPrefix(sub.CommandPath(), root.Name()+" ")
+			if commands[path] {
+				names = append(names, fmt.Sprintf("  /%s — %s", path, sub.Short))
+			} else {
+				walk(sub)
+			}
+		}
+	}
+	walk(root)
+	sort.Strings(names)
+	var sb strings.Builder
+	if unknown != "" {
+		fmt.Fprintf(&sb, "❓ Unknown command: %s\n\n", unknown)
+	}
+	sb.WriteString("Usage: /<command> [args]\n\n" + strings.Join(names, "\n"))
+	return sb.String()
+}
+
+// splitArgs splits a message into arguments, honouring single and double
+// quotes and backslash escapes.
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	var quote rune
+	inArg, escaped := false, false
+	for _, r := range s {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("mobile: unterminated quote")
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
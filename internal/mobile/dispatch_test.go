@@ -0,0 +1,117 @@
+package mobile
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func testTree() *cobra.Command {
+	root := &cobra.Command{Use: "nexus"}
+	echo := &cobra.Command{
+		Use:   "echo",
+		Short: "Print arguments",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := strings.Join(args, " ")
+			if upper, _ := cmd.Flags().GetBool("upper"); upper {
+				out = strings.ToUpper(out)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "\033[32m"+out+"\033[0m")
+			return nil
+		},
+	}
+	echo.Flags().Bool("upper", false, "upper-case output")
+	slow := &cobra.Command{
+		Use:   "slow",
+		Short: "Never finishes on its own",
+		Run: func(cmd *cobra.Command, args []string) {
+			<-cmd.Context().Done()
+		},
+	}
+	admin := &cobra.Command{Use: "admin", Short: "Change things"}
+	admin.AddCommand(&cobra.Command{
+		Use:   "reset",
+		Short: "Reset everything",
+		Run:   func(cmd *cobra.Command, args []string) { fmt.Fprintln(cmd.OutOrStdout(), "reset!") },
+	})
+	echo.Flags().String("data-dir", "", "data directory")
+	root.AddCommand(echo, slow, admin)
+	return root
+}
+
+func TestDispatchRunsCommand(t *testing.T) {
+	bot := New(BotConfig{Simulated: true, AllowedUserIDs: []int64{7}})
+	bot.SetCommandTree(testTree(), []string{"echo", "slow"}, time.Second)
+
+	got, err := bot.Dispatch(7, `/echo@nexus_bot --upper "hello world"`)
+	if err != nil || got != "HELLO WORLD" {
+		t.Fatalf("Dispatch = %q, %v", got, err)
+	}
+	// Flags must not leak into the next run.
+	if got, _ := bot.Dispatch(7, "nexus echo again"); got != "again" {
+		t.Errorf("second Dispatch = %q, want flags reset", got)
+	}
+	if _, err := bot.Dispatch(8, "/echo hi"); !errors.Is(err, ErrUnauthorised) {
+		t.Errorf("expected ErrUnauthorised, got %v", err)
+	}
+	if got, _ := bot.Dispatch(7, "/frobnicate"); !strings.Contains(got, "Unknown command: frobnicate") ||
+		!strings.Contains(got, "/echo — Print arguments") {
+		t.Errorf("unknown command reply = %q", got)
+	}
+}
+
+func TestDispatchCommandAllowlist(t *testing.T) {
+	bot := New(BotConfig{Simulated: true, AllowedUserIDs: []int64{7}})
+	bot.SetCommandTree(testTree(), []string{"echo"}, time.Second)
+
+	got, err := bot.Dispatch(7, "/admin reset")
+	if err != nil || !strings.Contains(got, "not enabled") || strings.Contains(got, "reset!") {
+		t.Errorf("disallowed command: %q, %v", got, err)
+	}
+	if strings.Contains(got, "/admin") && !strings.Contains(got, "/admin reset is not enabled") {
+		t.Errorf("usage should only list allowed commands: %q", got)
+	}
+	if got, _ := bot.Dispatch(7, "/echo --data-dir=/etc hi"); !strings.Contains(got, "--data-dir cannot be set") {
+		t.Errorf("path flag accepted: %q", got)
+	}
+
+	bot.SetCommandTree(testTree(), []string{"admin reset"}, time.Second)
+	if got, err := bot.Dispatch(7, "/admin reset"); err != nil || got != "reset!" {
+		t.Errorf("explicitly allowed command = %q, %v", got, err)
+	}
+}
+
+func TestDispatchRequiresUserAllowlist(t *testing.T) {
+	bot := New(BotConfig{Simulated: true})
+	bot.SetCommandTree(testTree(), []string{"echo"}, time.Second)
+	if _, err := bot.Dispatch(1, "/echo hi"); !errors.Is(err, ErrUnauthorised) {
+		t.Errorf("expected ErrUnauthorised without an allowlist, got %v", err)
+	}
+}
+
+func TestDispatchTimeout(t *testing.T) {
+	bot := New(BotConfig{Simulated: true, AllowedUserIDs: []int64{1}})
+	bot.SetCommandTree(testTree(), []string{"slow"}, 50*time.Millisecond)
+	if _, err := bot.Dispatch(1, "/slow"); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	got, err := splitArgs(`write draft "a b" 'c "d"' e\ f`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"write", "draft", "a b", `c "d"`, "e f"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitArgs = %q, want %q", got, want)
+	}
+	if _, err := splitArgs(`say "unterminated`); err == nil {
+		t.Error("expected error for unterminated quote")
+	}
+}
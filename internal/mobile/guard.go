@@ -0,0 +1,90 @@
+package mobile
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// userRate is a fixed-window message counter for one sender.
+type userRate struct {
+	windowStart time.Time
+	count       int
+	warned      bool // the sender has been told to slow down this window
+}
+
+// Allow adds a Telegram user ID to the allowlist at runtime.
+func (t *TelegramCompanion) Allow(userID int64) {
+	t.mu.Lock()
+	t.allowed[userID] = true
+	t.mu.Unlock()
+}
+
+// Revoke removes a Telegram user ID from the allowlist. Revoking the last
+// ID leaves the allowlist empty, which denies everyone until Allow is called.
+func (t *TelegramCompanion) Revoke(userID int64) {
+	t.mu.Lock()
+	delete(t.allowed, userID)
+	t.mu.Unlock()
+}
+
+// AllowedUsers returns the allowlisted user IDs in ascending order.
+func (t *TelegramCompanion) AllowedUsers() []int64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	ids := make([]int64, 0, len(t.allowed))
+	for id := range t.allowed {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// isAllowed reports whether userID is on the allowlist. An empty allowlist
+// admits nobody.
+func (t *TelegramCompanion) isAllowed(userID int64) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if len(t.allowed) == 0 {
+		log.Warn().Int64("user", userID).Msg("TG: denied user — the allowlist is empty")
+		return false
+	}
+	return t.allowed[userID]
+}
+
+// admit applies the allowlist and per-user rate limit to an incoming
+// message or button tap. When it refuses, reply is what to tell the sender,
+// or "" to drop the update silently.
+func (t *TelegramCompanion) admit(userID int64, username, text string) (reply string, ok bool) {
+	if !t.isAllowed(userID) {
+		log.Warn().Int64("user", userID).Str("username", username).
+			Str("text", truncate(text, 40)).Msg("TG: denied message from user not on allowlist")
+		return fmt.Sprintf("🚫 Unauthorised — sorry, this NEXUS bot is private. Ask its owner to allow your Telegram ID (%d).", userID), false
+	}
+	if t.cfg.RateLimit < 0 {
+		return "", true
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	r := t.rates[userID]
+	if r == nil || now.Sub(r.windowStart) >= t.cfg.RateWindow {
+		r = &userRate{windowStart: now}
+		t.rates[userID] = r
+	}
+	r.count++
+	if r.count <= t.cfg.RateLimit {
+		return "", true
+	}
+	if r.warned {
+		return "", false
+	}
+	r.warned = true
+	log.Warn().Int64("user", userID).Str("username", username).Int("limit", t.cfg.RateLimit).
+		Msg("TG: rate limit exceeded")
+	wait := r.windowStart.Add(t.cfg.RateWindow).Sub(now).Round(time.Second)
+	return fmt.Sprintf("⏳ Slow down — max %d messages per %s. Try again in %s.",
+		t.cfg.RateLimit, t.cfg.RateWindow, wait), false
+}
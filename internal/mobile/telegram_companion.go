@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
 )
 
 // TelegramMessage is a received Telegram update
@@ -43,43 +44,97 @@ type TelegramMessage struct {
 			FileID string `json:"file_id"`
 		} `json:"voice,omitempty"`
 	} `json:"message"`
+	CallbackQuery *CallbackQuery `json:"callback_query,omitempty"`
+}
+
+// CallbackQuery is a tap on an inline keyboard button
+type CallbackQuery struct {
+	ID   string `json:"id"`
+	From struct {
+		ID       int64  `json:"id"`
+		Username string `json:"username"`
+	} `json:"from"`
+	Message *struct {
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+	} `json:"message,omitempty"`
+	Data string `json:"data"`
 }
 
 // OutboundMessage is a message sent to Telegram
 type OutboundMessage struct {
-	ChatID    int64  `json:"chat_id"`
-	Text      string `json:"text"`
-	ParseMode string `json:"parse_mode,omitempty"` // Markdown | HTML
+	ChatID      int64       `json:"chat_id"`
+	Text        string      `json:"text"`
+	ParseMode   string      `json:"parse_mode,omitempty"`   // Markdown | HTML
+	ReplyMarkup interface{} `json:"reply_markup,omitempty"` // e.g. agents.InlineKeyboardMarkup
 }
 
 // BotConfig holds Telegram bot settings
 type BotConfig struct {
 	Token         string
-	AllowedUserIDs []int64
+	AllowedUserIDs []int64 // users who may talk to the bot; empty = nobody (see Allow)
 	AdminChatID   int64
 	Simulated     bool
+
+	RateLimit  int           // messages per user per RateWindow (default 20, <0 disables)
+	RateWindow time.Duration // default 1 minute
+	FFmpegBin  string        // transcodes voice notes for Whisper (default "ffmpeg")
 }
 
 // CommandHandler is a function that handles a bot command
 type CommandHandler func(chatID int64, args string) string
 
+// CallbackHandler handles an inline keyboard tap carrying data by username
+// and returns the text to show the user. agents.HITLGate.HandleCallback has
+// this signature and can be passed directly.
+type CallbackHandler func(data, username string) (string, error)
+
 // TelegramCompanion manages the NEXUS Telegram bot
 type TelegramCompanion struct {
 	cfg        BotConfig
 	handlers   map[string]CommandHandler
+	onCallback CallbackHandler
+
+	root            *cobra.Command  // see SetCommandTree
+	commands        map[string]bool // command paths Dispatch may run
+	dispatchTimeout time.Duration
+	running         sync.Mutex // held while a dispatched command runs
+
+	allowed map[int64]bool // see Allow / Revoke
+	rates   map[int64]*userRate
+
 	mu         sync.RWMutex
 	sentLog    []OutboundMessage
 	client     *http.Client
 	baseURL    string
+	fileURL    string // base for downloading files returned by getFile
+	stt        Transcriber
 }
 
 // New creates a TelegramCompanion
 func New(cfg BotConfig) *TelegramCompanion {
+	if cfg.RateLimit == 0 {
+		cfg.RateLimit = 20
+	}
+	if cfg.RateWindow <= 0 {
+		cfg.RateWindow = time.Minute
+	}
+	allowed := make(map[int64]bool, len(cfg.AllowedUserIDs))
+	for _, id := range cfg.AllowedUserIDs {
+		allowed[id] = true
+	}
+	if len(allowed) == 0 && !cfg.Simulated {
+		log.Warn().Msg("TG: no AllowedUserIDs configured — the bot will refuse everyone until a user is allowed")
+	}
 	return &TelegramCompanion{
 		cfg:      cfg,
+		allowed:  allowed,
+		rates:    make(map[int64]*userRate),
 		handlers: make(map[string]CommandHandler),
 		client:   &http.Client{Timeout: 10 * time.Second},
 		baseURL:  fmt.Sprintf("https://api.telegram.org/bot%s", cfg.Token),
+		fileURL:  fmt.Sprintf("https://api.telegram.org/file/bot%s", cfg.Token),
 	}
 }
 
@@ -90,12 +145,25 @@ func (t *TelegramCompanion) RegisterCommand(cmd string, handler CommandHandler)
 	t.mu.Unlock()
 }
 
+// SetCallbackHandler sets the handler for inline keyboard taps
+func (t *TelegramCompanion) SetCallbackHandler(handler CallbackHandler) {
+	t.mu.Lock()
+	t.onCallback = handler
+	t.mu.Unlock()
+}
+
 // Send sends a message to a Telegram chat
 func (t *TelegramCompanion) Send(chatID int64, text string) error {
+	return t.SendWithMarkup(chatID, text, nil)
+}
+
+// SendWithMarkup sends a message with reply markup such as an inline keyboard
+func (t *TelegramCompanion) SendWithMarkup(chatID int64, text string, markup interface{}) error {
 	msg := OutboundMessage{
-		ChatID:    chatID,
-		Text:      text,
-		ParseMode: "Markdown",
+		ChatID:      chatID,
+		Text:        text,
+		ParseMode:   "Markdown",
+		ReplyMarkup: markup,
 	}
 	if t.cfg.Simulated {
 		t.mu.Lock()
@@ -104,11 +172,15 @@ func (t *TelegramCompanion) Send(chatID int64, text string) error {
 		log.Debug().Int64("chat", chatID).Str("text", truncate(text, 80)).Msg("TG (sim): send")
 		return nil
 	}
-	body, err := json.Marshal(msg)
+	return t.call("sendMessage", msg)
+}
+
+func (t *TelegramCompanion) call(method string, payload interface{}) error {
+	body, err := json.Marshal(payload)
 	if err != nil {
 		return err
 	}
-	resp, err := t.client.Post(t.baseURL+"/sendMessage", "application/json", bytes.NewReader(body))
+	resp, err := t.client.Post(t.baseURL+"/"+method, "application/json", bytes.NewReader(body))
 	if err != nil {
 		return err
 	}
@@ -121,24 +193,35 @@ func (t *TelegramCompanion) Send(chatID int64, text string) error {
 
 // HandleUpdate processes a single Telegram update
 func (t *TelegramCompanion) HandleUpdate(update TelegramMessage) {
+	if update.CallbackQuery != nil {
+		t.handleCallback(update.CallbackQuery)
+		return
+	}
 	userID := update.Message.From.ID
 	chatID := update.Message.Chat.ID
 
-	if !t.isAllowed(userID) {
-		log.Warn().Int64("user", userID).Msg("TG: unauthorised user")
-		t.Send(chatID, "🚫 Unauthorised. Contact the NEXUS admin.")
+	if reply, ok := t.admit(userID, update.Message.From.Username, update.Message.Text); !ok {
+		if reply != "" {
+			t.Send(chatID, reply)
+		}
 		return
 	}
 
 	text := strings.TrimSpace(update.Message.Text)
 	if text == "" {
 		if update.Message.Voice != nil {
-			t.Send(chatID, "🎤 Voice message received. Transcription support requires Whisper integration.")
+			t.handleVoice(userID, chatID, update.Message.Voice.FileID)
 		}
 		return
 	}
+	if reply := t.respond(userID, chatID, text); reply != "" {
+		t.Send(chatID, reply)
+	}
+}
 
-	// Parse command
+// respond routes a command message to its registered handler, or to
+// Dispatch when a command tree is set, and returns the reply.
+func (t *TelegramCompanion) respond(userID, chatID int64, text string) string {
 	parts := strings.SplitN(text, " ", 2)
 	cmd := strings.ToLower(strings.TrimPrefix(parts[0], "/"))
 	args := ""
@@ -148,17 +231,62 @@ func (t *TelegramCompanion) HandleUpdate(update TelegramMessage) {
 
 	t.mu.RLock()
 	handler, ok := t.handlers[cmd]
+	hasTree := t.root != nil
 	t.mu.RUnlock()
 
+	if !ok && hasTree && strings.HasPrefix(text, "/") {
+		reply, err := t.Dispatch(userID, text)
+		if err != nil {
+			reply = strings.TrimSpace(reply + "\n⚠️ " + err.Error())
+		}
+		return reply
+	}
 	if !ok {
 		// Default: echo command list
-		t.Send(chatID, t.helpText())
+		return t.helpText()
+	}
+	return handler(chatID, args)
+}
+
+func (t *TelegramCompanion) handleCallback(q *CallbackQuery) {
+	var chatID int64
+	if q.Message != nil {
+		chatID = q.Message.Chat.ID
+	}
+	if reply, ok := t.admit(q.From.ID, q.From.Username, q.Data); !ok {
+		t.answerCallback(q.ID, reply)
+		return
+	}
+	t.mu.RLock()
+	handler := t.onCallback
+	t.mu.RUnlock()
+	if handler == nil {
+		t.answerCallback(q.ID, "")
 		return
 	}
+	by := q.From.Username
+	if by == "" {
+		by = fmt.Sprintf("tg:%d", q.From.ID)
+	}
+	reply, err := handler(q.Data, by)
+	if err != nil {
+		reply = "⚠️ " + err.Error()
+	}
+	t.answerCallback(q.ID, reply)
+	if chatID != 0 && reply != "" {
+		t.Send(chatID, reply)
+	}
+}
 
-	response := handler(chatID, args)
-	if response != "" {
-		t.Send(chatID, response)
+// answerCallback stops the button's loading spinner, showing text as a toast
+func (t *TelegramCompanion) answerCallback(id, text string) {
+	if t.cfg.Simulated {
+		log.Debug().Str("callback", id).Str("text", text).Msg("TG (sim): answer callback")
+		return
+	}
+	payload := map[string]string{"callback_query_id": id, "text": truncate(text, 190)}
+	if err := t.call("answerCallbackQuery", payload); err != nil {
+		log.Warn().Err(err).Msg("TG: answer callback failed")
 	}
 }
 
@@ -180,18 +308,6 @@ func (t *TelegramCompanion) SentLog() []OutboundMessage {
 	return result
 }
 
-func (t *TelegramCompanion) isAllowed(userID int64) bool {
-	if len(t.cfg.AllowedUserIDs) == 0 {
-		return true // no allowlist = allow all
-	}
-	for _, id := range t.cfg.AllowedUserIDs {
-		if id == userID {
-			return true
-		}
-	}
-	return false
-}
-
 func (t *TelegramCompanion) helpText() string {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
@@ -1,11 +1,16 @@
 package mobile
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
+	"time"
+
+	"github.com/Omkar0612/nexus-ai/internal/agents"
 )
 
 func simBot() *TelegramCompanion {
-	return New(BotConfig{Simulated: true, AdminChatID: 12345})
+	return New(BotConfig{Simulated: true, AdminChatID: 12345, AllowedUserIDs: []int64{0}})
 }
 
 func TestTelegramSendSimulated(t *testing.T) {
@@ -42,7 +47,7 @@ func TestTelegramCommandRouting(t *testing.T) {
 			From: struct {
 				ID       int64  `json:"id"`
 				Username string `json:"username"`
-			}{ID: 0}, // allowed (no allowlist)
+			}{ID: 0}, // on simBot's allowlist
 			Chat: struct {
 				ID int64 `json:"id"`
 			}{ID: 12345},
@@ -135,6 +140,128 @@ func TestTelegramUnknownCommand(t *testing.T) {
 	}
 }
 
+func TestTelegramCallbackRouting(t *testing.T) {
+	bot := New(BotConfig{Simulated: true, AllowedUserIDs: []int64{7}})
+	var gotBy, gotData string
+	bot.SetCallbackHandler(func(data, by string) (string, error) {
+		gotBy, gotData = by, data
+		return "✅ Approved hitl-1", nil
+	})
+	var update TelegramMessage
+	raw := `{"update_id":1,"callback_query":{"id":"cb1","from":{"id":7,"username":"omkar"},` +
+		`"message":{"chat":{"id":42}},"data":"hitl:approve:hitl-1"}}`
+	if err := json.Unmarshal([]byte(raw), &update); err != nil {
+		t.Fatal(err)
+	}
+	bot.HandleUpdate(update)
+	if gotBy != "omkar" || gotData != "hitl:approve:hitl-1" {
+		t.Errorf("handler got (%q, %q)", gotBy, gotData)
+	}
+	sent := bot.SentLog()
+	if len(sent) != 1 || sent[0].ChatID != 42 || !containsStr(sent[0].Text, "Approved") {
+		t.Errorf("expected confirmation in chat 42, got %+v", sent)
+	}
+
+	gotData = ""
+	update.CallbackQuery.From.ID = 8
+	bot.HandleUpdate(update)
+	if gotData != "" {
+		t.Error("callback from a non-allowlisted user must not reach the handler")
+	}
+}
+
 func containsStr(s, sub string) bool {
 	return len(s) >= len(sub) && (s == sub || (len(s) > 0 && (s[:len(sub)] == sub || containsStr(s[1:], sub))))
 }
+
+func TestTelegramRateLimit(t *testing.T) {
+	bot := New(BotConfig{Simulated: true, AllowedUserIDs: []int64{5, 6}, RateLimit: 2, RateWindow: time.Hour})
+	bot.RegisterCommand("status", func(chatID int64, args string) string { return "ok" })
+	var update TelegramMessage
+	if err := json.Unmarshal([]byte(`{"message":{"from":{"id":5},"chat":{"id":5},"text":"/status"}}`), &update); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		bot.HandleUpdate(update)
+	}
+	sent := bot.SentLog()
+	if len(sent) != 3 || !containsStr(sent[2].Text, "Slow down") {
+		t.Fatalf("expected 2 replies and one slow-down notice, got %+v", sent)
+	}
+	update.Message.From.ID, update.Message.Chat.ID = 6, 6
+	bot.HandleUpdate(update)
+	if len(bot.SentLog()) != 4 {
+		t.Error("rate limit should be per user")
+	}
+}
+
+func TestTelegramAllowRevoke(t *testing.T) {
+	bot := New(BotConfig{Simulated: true, AllowedUserIDs: []int64{1}})
+	if bot.isAllowed(2) {
+		t.Fatal("user 2 should be denied")
+	}
+	bot.Allow(2)
+	if !bot.isAllowed(2) || len(bot.AllowedUsers()) != 2 {
+		t.Errorf("Allow did not take effect: %v", bot.AllowedUsers())
+	}
+	bot.Revoke(2)
+	if bot.isAllowed(2) {
+		t.Error("Revoke did not take effect")
+	}
+}
+
+func TestTelegramRevokingLastUserDeniesEveryone(t *testing.T) {
+	bot := New(BotConfig{Simulated: true, AllowedUserIDs: []int64{1}})
+	ran := false
+	bot.RegisterCommand("status", func(chatID int64, args string) string {
+		ran = true
+		return "ok"
+	})
+	bot.Revoke(1)
+	var update TelegramMessage
+	if err := json.Unmarshal([]byte(`{"message":{"from":{"id":1},"chat":{"id":1},"text":"/status"}}`), &update); err != nil {
+		t.Fatal(err)
+	}
+	bot.HandleUpdate(update)
+	if ran {
+		t.Error("an empty allowlist must not admit anyone")
+	}
+	if sent := bot.SentLog(); len(sent) != 1 || !containsStr(sent[0].Text, "Unauthorised") {
+		t.Errorf("expected an unauthorised reply, got %+v", sent)
+	}
+}
+
+func TestTelegramCallbackDrivesHITLGate(t *testing.T) {
+	sent := make(chan *agents.ApprovalRequest, 1)
+	gate := agents.NewHITLGate(5*time.Second, func(req *agents.ApprovalRequest) error {
+		sent <- req
+		return nil
+	})
+	bot := New(BotConfig{Simulated: true, AllowedUserIDs: []int64{7}})
+	bot.SetCallbackHandler(gate.HandleCallback)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- gate.Execute(context.Background(), "drop table users", "migration", "high",
+			func(ctx context.Context) error { return nil })
+	}()
+	req := <-sent
+	approve := agents.ApprovalKeyboard(req).InlineKeyboard[0][0].CallbackData
+
+	bot.handleCallback(&CallbackQuery{
+		ID:   "cb1",
+		From: struct {
+			ID       int64  `json:"id"`
+			Username string `json:"username"`
+		}{ID: 7, Username: "omkar"},
+		Data: approve,
+	})
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("approved action failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("tap on the approve button did not reach the gate")
+	}
+}
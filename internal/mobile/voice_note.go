@@ -0,0 +1,143 @@
+package mobile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/Omkar0612/nexus-ai/internal/voice"
+	"github.com/rs/zerolog/log"
+)
+
+// maxVoiceNoteBytes caps voice note downloads; Telegram bots can fetch at
+// most 20 MB anyway.
+const maxVoiceNoteBytes = 20 << 20
+
+// Transcriber turns a 16 kHz mono WAV file into text;
+// *voice.VoiceInterface implements it with whisper.cpp.
+type Transcriber interface {
+	Transcribe(wavPath string) (voice.TranscriptEvent, error)
+}
+
+// SetTranscriber enables voice notes: each one is downloaded, transcoded
+// to WAV with ffmpeg, transcribed, and handled as if its text had been
+// typed as a command.
+func (t *TelegramCompanion) SetTranscriber(stt Transcriber) {
+	t.mu.Lock()
+	t.stt = stt
+	t.mu.Unlock()
+}
+
+func (t *TelegramCompanion) handleVoice(userID, chatID int64, fileID string) {
+	t.mu.RLock()
+	stt := t.stt
+	t.mu.RUnlock()
+	if stt == nil {
+		t.Send(chatID, "🎤 Voice message received. Transcription support requires Whisper integration.")
+		return
+	}
+	text, err := t.transcribeVoice(stt, fileID)
+	if err != nil {
+		log.Error().Err(err).Int64("user", userID).Msg("TG: voice note transcription failed")
+		t.Send(chatID, "⚠️ Sorry, I couldn't transcribe that voice note.")
+		return
+	}
+	if text == "" {
+		t.Send(chatID, "🎤 I couldn't hear anything in that voice note.")
+		return
+	}
+	reply := t.respond(userID, chatID, spokenCommand(text))
+	t.Send(chatID, fmt.Sprintf("🎤 _%s_\n\n%s", text, reply))
+}
+
+// transcribeVoice fetches a voice note and returns its transcript. All
+// intermediate files live in one temp dir removed on return.
+func (t *TelegramCompanion) transcribeVoice(stt Transcriber, fileID string) (string, error) {
+	dir, err := os.MkdirTemp("", "nexus-tg-voice-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	ogg := filepath.Join(dir, "note.oga")
+	if err := t.downloadFile(fileID, ogg); err != nil {
+		return "", err
+	}
+	wav := filepath.Join(dir, "note.wav")
+	bin := t.cfg.FFmpegBin
+	if bin == "" {
+		bin = "ffmpeg"
+	}
+	cmd := exec.Command(bin, "-y", "-loglevel", "error", "-i", ogg, "-ar", "16000", "-ac", "1", "-c:a", "pcm_s16le", wav)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("mobile: ffmpeg: %w — output: %s", err, truncate(string(out), 200))
+	}
+	evt, err := stt.Transcribe(wav)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(evt.Text), nil
+}
+
+// downloadFile resolves fileID with getFile and saves the file to dst.
+func (t *TelegramCompanion) downloadFile(fileID, dst string) error {
+	resp, err := t.client.Get(t.baseURL + "/getFile?file_id=" + url.QueryEscape(fileID))
+	if err != nil {
+		return fmt.Errorf("mobile: getFile: %w", err)
+	}
+	var meta struct {
+		OK     bool `json:"ok"`
+		Result struct {
+			FilePath string `json:"file_path"`
+		} `json:"result"`
+		Description string `json:"description"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&meta)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("mobile: getFile: %w", err)
+	}
+	if !meta.OK || meta.Result.FilePath == "" {
+		return fmt.Errorf("mobile: getFile: %s", meta.Description)
+	}
+
+	resp, err = t.client.Get(t.fileURL + "/" + meta.Result.FilePath)
+	if err != nil {
+		return fmt.Errorf("mobile: download voice note: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("mobile: download voice note: %s", resp.Status)
+	}
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, io.LimitReader(resp.Body, maxVoiceNoteBytes+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("mobile: download voice note: %w", err)
+	}
+	if n > maxVoiceNoteBytes {
+		return errors.New("mobile: voice note too large")
+	}
+	return nil
+}
+
+// spokenCommand turns a transcript such as "Audit show, last 7d." into a
+// command message "/audit show, last 7d".
+func spokenCommand(text string) string {
+	text = strings.TrimRight(strings.TrimSpace(text), ".!?")
+	parts := strings.SplitN(text, " ", 2)
+	parts[0] = "/" + strings.ToLower(strings.Trim(strings.TrimPrefix(parts[0], "/"), ",;:"))
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,80 @@
+package mobile
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Omkar0612/nexus-ai/internal/voice"
+)
+
+type fakeSTT struct{ wav string }
+
+func (f *fakeSTT) Transcribe(wavPath string) (voice.TranscriptEvent, error) {
+	if _, err := os.Stat(wavPath); err != nil {
+		return voice.TranscriptEvent{}, err
+	}
+	f.wav = wavPath
+	return voice.TranscriptEvent{Text: " Status please. ", Confidence: 0.9}, nil
+}
+
+func TestTelegramVoiceNote(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ffmpeg is a shell script")
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getFile") && r.URL.Query().Get("file_id") == "voice-1":
+			fmt.Fprint(w, `{"ok":true,"result":{"file_path":"voice/file_1.oga"}}`)
+		case r.URL.Path == "/file/botTOKEN/voice/file_1.oga":
+			fmt.Fprint(w, "OggS fake audio")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	// Fake ffmpeg: copy the -i input to the last argument.
+	ffmpeg := filepath.Join(t.TempDir(), "ffmpeg")
+	script := "#!/bin/sh\nwhile [ $# -gt 1 ]; do [ \"$1\" = \"-i\" ] && in=\"$2\"; shift; done\ncp \"$in\" \"$1\"\n"
+	if err := os.WriteFile(ffmpeg, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	bot := New(BotConfig{Simulated: true, AllowedUserIDs: []int64{1}, FFmpegBin: ffmpeg})
+	bot.baseURL, bot.fileURL = srv.URL+"/botTOKEN", srv.URL+"/file/botTOKEN"
+	stt := &fakeSTT{}
+	bot.SetTranscriber(stt)
+	bot.RegisterCommand("status", func(chatID int64, args string) string {
+		return "🟢 NEXUS is running (" + args + ")"
+	})
+
+	var update TelegramMessage
+	update.Message.From.ID, update.Message.Chat.ID = 1, 1
+	update.Message.Voice = &struct {
+		FileID string `json:"file_id"`
+	}{FileID: "voice-1"}
+	bot.HandleUpdate(update)
+
+	sent := bot.SentLog()
+	if len(sent) != 1 {
+		t.Fatalf("expected one reply, got %+v", sent)
+	}
+	if !containsStr(sent[0].Text, "Status please.") || !containsStr(sent[0].Text, "NEXUS is running (please)") {
+		t.Errorf("reply should carry transcript and response: %q", sent[0].Text)
+	}
+	if _, err := os.Stat(filepath.Dir(stt.wav)); !os.IsNotExist(err) {
+		t.Errorf("temp dir %s not cleaned up", filepath.Dir(stt.wav))
+	}
+}
+
+func TestSpokenCommand(t *testing.T) {
+	if got := spokenCommand("Audit, show last 7d."); got != "/audit show last 7d" {
+		t.Errorf("spokenCommand = %q", got)
+	}
+}
@@ -14,6 +14,8 @@ import (
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/Omkar0612/nexus-ai/internal/media"
 )
 
 // Backend selects the music generation provider.
@@ -30,11 +32,16 @@ type Request struct {
 	Prompt     string
 	Duration   time.Duration
 	OutputPath string
+	// DownloadResult fetches URL-only results (Replicate) to OutputPath,
+	// fixing up the extension from the content type. Leave false to get
+	// just the remote URL.
+	DownloadResult bool
 }
 
 // Result holds the generation output.
 type Result struct {
-	Path    string
+	Path    string // local file, or the remote URL if not downloaded
+	URL     string // remote output URL for URL-only backends
 	Backend Backend
 	Latency time.Duration
 }
@@ -88,7 +95,16 @@ func (a *Agent) Generate(ctx context.Context, req Request) (*Result, error) {
 	case BackendAudioCraft:
 		return a.generateAudioCraft(ctx, req)
 	case BackendReplicate:
-		return a.generateReplicate(ctx, req)
+		result, err := a.generateReplicate(ctx, req)
+		if err != nil || !req.DownloadResult || result.URL == "" {
+			return result, err
+		}
+		path, err := a.downloadResult(ctx, result.URL, req.OutputPath)
+		if err != nil {
+			return nil, fmt.Errorf("music: download %s: %w", result.URL, err)
+		}
+		result.Path = path
+		return result, nil
 	case BackendStub:
 		return a.generateStub(req)
 	default:
@@ -192,7 +208,17 @@ func (a *Agent) generateReplicate(ctx context.Context, req Request) (*Result, er
 	if len(pred.Output) > 0 {
 		outURL = pred.Output[0]
 	}
-	return &Result{Path: outURL, Backend: BackendReplicate, Latency: time.Since(start)}, nil
+	return &Result{Path: outURL, URL: outURL, Backend: BackendReplicate, Latency: time.Since(start)}, nil
+}
+
+// maxDownloadBytes caps a downloaded track (200 MB, well over ten minutes
+// of uncompressed stereo WAV).
+const maxDownloadBytes = 200 << 20
+
+// downloadResult fetches url into path (see media.Download) and returns the
+// path written.
+func (a *Agent) downloadResult(ctx context.Context, url, path string) (string, error) {
+	return media.Download(ctx, a.client, url, path, maxDownloadBytes)
 }
 
 // silentWAV is a minimal valid 44-byte WAV file with 0 data samples.
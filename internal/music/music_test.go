@@ -2,7 +2,10 @@ package music
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -55,3 +58,23 @@ func TestMusicDurationDefault(t *testing.T) {
 		t.Fatalf("Generate with default duration: %v", err)
 	}
 }
+
+func TestDownloadResult(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = w.Write([]byte("ID3 fake mp3"))
+	}))
+	defer ts.Close()
+
+	a := New()
+	path, err := a.downloadResult(context.Background(), ts.URL+"/out", filepath.Join(t.TempDir(), "song.wav"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Ext(path) != ".mp3" {
+		t.Errorf("path = %s, want .mp3 extension from content type", path)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "ID3 fake mp3" {
+		t.Errorf("downloaded %q", data)
+	}
+}
@@ -0,0 +1,264 @@
+package n8n
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ifConditions is the "conditions" parameter of an n8n IF node. Version 2+
+// nodes carry a list of {leftValue, operator, rightValue} entries and a
+// combinator; version 1 nodes group {value1, operation, value2} entries by
+// value type and combine them with the node's "combineOperation" parameter.
+type ifConditions struct {
+	// IF v2+
+	Conditions []struct {
+		LeftValue  interface{} `json:"leftValue"`
+		RightValue interface{} `json:"rightValue"`
+		Operator   struct {
+			Type      string `json:"type"`
+			Operation string `json:"operation"`
+		} `json:"operator"`
+	} `json:"conditions"`
+	Combinator string `json:"combinator"`
+	Options    struct {
+		CaseSensitive *bool `json:"caseSensitive"`
+	} `json:"options"`
+
+	// IF v1
+	Boolean  []ifV1Condition `json:"boolean"`
+	Number   []ifV1Condition `json:"number"`
+	String   []ifV1Condition `json:"string"`
+	DateTime []ifV1Condition `json:"dateTime"`
+}
+
+type ifV1Condition struct {
+	Value1    interface{} `json:"value1"`
+	Operation string      `json:"operation"`
+	Value2    interface{} `json:"value2"`
+}
+
+// ifV1Operations maps IF v1 operation names to their v2 equivalents.
+var ifV1Operations = map[string]string{
+	"equal":        "equals",
+	"notEqual":     "notEquals",
+	"smaller":      "lt",
+	"smallerEqual": "lte",
+	"larger":       "gt",
+	"largerEqual":  "gte",
+	"isEmpty":      "empty",
+	"isNotEmpty":   "notEmpty",
+}
+
+// ifV1Defaults is the operation n8n assumes when a v1 entry omits it.
+var ifV1Defaults = map[string]string{"boolean": "equal", "number": "smaller", "string": "equal"}
+
+// evalIfConditions evaluates the "conditions" parameter of an n8n IF node
+// (either version) against scope. combineOperation is the v1 node's
+// "combineOperation" parameter ("all" or "any"); v2 nodes ignore it.
+func evalIfConditions(raw interface{}, combineOperation string, scope map[string]interface{}) (bool, error) {
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return false, fmt.Errorf("n8n: if conditions: %w", err)
+	}
+	var c ifConditions
+	if err := json.Unmarshal(b, &c); err != nil {
+		return false, fmt.Errorf("n8n: if conditions: %w", err)
+	}
+	if len(c.DateTime) > 0 {
+		return false, fmt.Errorf("n8n: if conditions: dateTime comparisons are not supported")
+	}
+
+	type check struct {
+		typ, op     string
+		left, right interface{}
+	}
+	var checks []check
+	matchAny := combineOperation == "any"
+	caseSensitive := true
+	if len(c.Conditions) > 0 {
+		for _, cond := range c.Conditions {
+			checks = append(checks, check{cond.Operator.Type, cond.Operator.Operation, cond.LeftValue, cond.RightValue})
+		}
+		matchAny = strings.EqualFold(c.Combinator, "or")
+		if c.Options.CaseSensitive != nil {
+			caseSensitive = *c.Options.CaseSensitive
+		}
+	} else {
+		groups := []struct {
+			typ  string
+			list []ifV1Condition
+		}{{"boolean", c.Boolean}, {"number", c.Number}, {"string", c.String}}
+		for _, g := range groups {
+			typ := g.typ
+			for _, cond := range g.list {
+				op := cond.Operation
+				if op == "" {
+					op = ifV1Defaults[typ]
+				}
+				if v2, ok := ifV1Operations[op]; ok {
+					op = v2
+				}
+				checks = append(checks, check{typ, op, cond.Value1, cond.Value2})
+			}
+		}
+	}
+	if len(checks) == 0 {
+		return false, fmt.Errorf("n8n: if node has no conditions")
+	}
+
+	for _, ch := range checks {
+		left, err := resolveValue(ch.left, scope)
+		if err != nil {
+			return false, err
+		}
+		right, err := resolveValue(ch.right, scope)
+		if err != nil {
+			return false, err
+		}
+		ok, err := applyOperator(ch.typ, ch.op, left, right, caseSensitive)
+		if err != nil {
+			return false, err
+		}
+		if ok && matchAny {
+			return true, nil
+		}
+		if !ok && !matchAny {
+			return false, nil
+		}
+	}
+	return !matchAny, nil
+}
+
+// resolveValue evaluates an n8n parameter value: "={{ $json.x }}" resolves
+// the expression (see evalCondition for the operand syntax), any other "="
+// string is a literal, and non-strings pass through.
+func resolveValue(v interface{}, scope map[string]interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok || !strings.HasPrefix(s, "=") {
+		return v, nil
+	}
+	s = strings.TrimSpace(s[1:])
+	if !strings.HasPrefix(s, "{{") || !strings.HasSuffix(s, "}}") {
+		return s, nil
+	}
+	val, err := operand(s[2:len(s)-2], scope)
+	if err != nil {
+		return nil, fmt.Errorf("n8n: expression %q: %w", s, err)
+	}
+	return val, nil
+}
+
+// applyOperator applies an IF v2 operator of type typ to left and right.
+func applyOperator(typ, op string, left, right interface{}, caseSensitive bool) (bool, error) {
+	switch op {
+	case "exists":
+		return left != nil, nil
+	case "notExists":
+		return left == nil, nil
+	case "empty":
+		return isEmpty(left), nil
+	case "notEmpty":
+		return !isEmpty(left), nil
+	}
+	switch typ {
+	case "boolean":
+		switch op {
+		case "true":
+			return truthy(left), nil
+		case "false":
+			return !truthy(left), nil
+		case "equals":
+			return truthy(left) == truthy(right), nil
+		case "notEquals":
+			return truthy(left) != truthy(right), nil
+		}
+	case "number":
+		l, lok := numberOf(left)
+		r, rok := numberOf(right)
+		if !lok || !rok {
+			return op == "notEquals", nil
+		}
+		switch op {
+		case "equals":
+			return l == r, nil
+		case "notEquals":
+			return l != r, nil
+		case "gt":
+			return l > r, nil
+		case "gte":
+			return l >= r, nil
+		case "lt":
+			return l < r, nil
+		case "lte":
+			return l <= r, nil
+		}
+	case "string":
+		l, r := stringOf(left), stringOf(right)
+		if !caseSensitive {
+			l, r = strings.ToLower(l), strings.ToLower(r)
+		}
+		switch op {
+		case "equals":
+			return l == r, nil
+		case "notEquals":
+			return l != r, nil
+		case "contains":
+			return strings.Contains(l, r), nil
+		case "notContains":
+			return !strings.Contains(l, r), nil
+		case "startsWith":
+			return strings.HasPrefix(l, r), nil
+		case "notStartsWith":
+			return !strings.HasPrefix(l, r), nil
+		case "endsWith":
+			return strings.HasSuffix(l, r), nil
+		case "notEndsWith":
+			return !strings.HasSuffix(l, r), nil
+		case "regex", "notRegex":
+			re, err := regexp.Compile(r)
+			if err != nil {
+				return false, fmt.Errorf("n8n: if regex %q: %w", r, err)
+			}
+			return re.MatchString(l) == (op == "regex"), nil
+		}
+	}
+	return false, fmt.Errorf("n8n: unsupported if operator %s/%s", typ, op)
+}
+
+func numberOf(v interface{}) (float64, bool) {
+	if f, ok := toFloat(v); ok {
+		return f, true
+	}
+	if s, ok := v.(string); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func stringOf(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+func isEmpty(v interface{}) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case string:
+		return x == ""
+	case []interface{}:
+		return len(x) == 0
+	case map[string]interface{}:
+		return len(x) == 0
+	}
+	return false
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -33,7 +34,11 @@ func NewDeployer(baseURL, apiKey string) *Deployer {
 func (d *Deployer) Deploy(ctx context.Context, wf *Workflow) error {
 	log.Info().Str("target", d.BaseURL).Msg("🚀 Deploying workflow to n8n instance...")
 
-	payload, err := json.Marshal(wf)
+	doc, err := normalizeExport(wf)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(doc)
 	if err != nil {
 		return fmt.Errorf("failed to marshal workflow: %w", err)
 	}
@@ -0,0 +1,400 @@
+package n8n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ExecutionStatus is the lifecycle state of a workflow execution or a single node run.
+type ExecutionStatus string
+
+const (
+	StatusRunning ExecutionStatus = "running"
+	StatusSuccess ExecutionStatus = "success"
+	StatusFailed  ExecutionStatus = "failed"
+	StatusSkipped ExecutionStatus = "skipped"
+)
+
+// BranchKey is the output key a handler sets to choose which of its "main"
+// outputs the executor follows, as an int index or a bool (true = output 0,
+// false = output 1, matching the n8n IF node). The executor removes it from
+// the stored output. Without it, every output is followed.
+const BranchKey = "$branch"
+
+// ErrTimeout is wrapped by every error caused by a node timeout or an
+// exceeded workflow deadline, so callers can test for it with errors.Is.
+var ErrTimeout = errors.New("execution timed out")
+
+// NodeHandler executes a single workflow node.
+//
+// input holds the outputs of the node's direct upstream nodes merged into one
+// map: each parent's Output keys are copied in connection order, so with a
+// single parent input is exactly that parent's output and with several the
+// later parent wins on a key conflict. Root nodes get an empty map. input is
+// a fresh copy and may be modified. data is the execution-wide data map
+// passed to Execute.
+type NodeHandler func(ctx context.Context, node Node, input, data map[string]interface{}) (map[string]interface{}, error)
+
+// NodeResult records the outcome of one node run. Branch is the output index
+// the node selected via BranchKey, or -1 when all outputs were followed.
+type NodeResult struct {
+	Node      string
+	Status    ExecutionStatus
+	Output    map[string]interface{}
+	Branch    int
+	Error     string
+	TimedOut  bool
+	StartedAt time.Time
+	Duration  time.Duration
+}
+
+// ExecutionContext tracks the state of a single workflow execution.
+// NodeResults is keyed by node name, matching the keys of Workflow.Connections.
+// Order lists nodes in the order they ran; Unreachable lists nodes that could
+// never run because they sit on (or downstream of) a connection cycle.
+type ExecutionContext struct {
+	WorkflowName string
+	Status       ExecutionStatus
+	Data         map[string]interface{}
+	NodeResults  map[string]*NodeResult
+	Order        []string
+	Unreachable  []string
+	FailedNode   string
+	Error        string
+	StartedAt    time.Time
+	FinishedAt   time.Time
+}
+
+// Executor runs compiled workflows locally by dispatching each node to the
+// handler registered for its n8n node type.
+type Executor struct {
+	// DefaultNodeTimeout applies to nodes without TimeoutSeconds. Zero disables it.
+	DefaultNodeTimeout time.Duration
+
+	handlers map[string]NodeHandler
+	mu       sync.RWMutex
+}
+
+// NewExecutor creates an executor with no registered handlers.
+func NewExecutor() *Executor {
+	return &Executor{
+		DefaultNodeTimeout: 60 * time.Second,
+		handlers:           make(map[string]NodeHandler),
+	}
+}
+
+// RegisterHandler binds a handler to an n8n node type, e.g. "n8n-nodes-base.httpRequest".
+func (e *Executor) RegisterHandler(nodeType string, h NodeHandler) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.handlers[nodeType] = h
+}
+
+// Execute runs wf in topological order: every node runs exactly once, after
+// all of its inbound "main" connections have completed.
+// An overall deadline can be set on ctx, or via the n8n "executionTimeout"
+// workflow setting (seconds). The returned ExecutionContext is always non-nil
+// and reflects which node failed or timed out.
+func (e *Executor) Execute(ctx context.Context, wf *Workflow, data map[string]interface{}) (*ExecutionContext, error) {
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	execCtx := &ExecutionContext{
+		WorkflowName: wf.Name,
+		Status:       StatusRunning,
+		Data:         data,
+		NodeResults:  make(map[string]*NodeResult),
+		StartedAt:    time.Now(),
+	}
+
+	if secs := workflowTimeout(wf); secs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(secs)*time.Second)
+		defer cancel()
+	}
+
+	err := e.executeDAG(ctx, wf, execCtx)
+	execCtx.FinishedAt = time.Now()
+	if err != nil {
+		execCtx.Status = StatusFailed
+		execCtx.Error = err.Error()
+		log.Warn().Str("workflow", wf.Name).Str("node", execCtx.FailedNode).Err(err).Msg("n8n workflow execution failed")
+		return execCtx, err
+	}
+	execCtx.Status = StatusSuccess
+	return execCtx, nil
+}
+
+// executeDAG runs every reachable node of wf at most once, in topological
+// order. A node with inbound connections runs only if at least one of them
+// is active, i.e. comes from a node that succeeded and followed that output;
+// the rest are recorded as skipped.
+func (e *Executor) executeDAG(ctx context.Context, wf *Workflow, execCtx *ExecutionContext) error {
+	order, inbound, unreachable, err := topoOrder(wf)
+	if err != nil {
+		return err
+	}
+	if len(unreachable) > 0 {
+		execCtx.Unreachable = unreachable
+		log.Warn().Str("workflow", wf.Name).Strs("nodes", unreachable).Msg("n8n workflow has unreachable nodes (connection cycle)")
+	}
+	for _, n := range order {
+		var parents []string
+		for _, in := range inbound[n.Name] {
+			if r := execCtx.NodeResults[in.from]; r != nil && r.follows(in.output) && !containsString(parents, in.from) {
+				parents = append(parents, in.from)
+			}
+		}
+		if len(inbound[n.Name]) > 0 && len(parents) == 0 {
+			execCtx.NodeResults[n.Name] = &NodeResult{Node: n.Name, Status: StatusSkipped, Branch: -1}
+			continue
+		}
+		if err := e.executeNode(ctx, n, parents, execCtx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// edge is one inbound "main" connection: from's output index output.
+type edge struct {
+	from   string
+	output int
+}
+
+// follows reports whether a node with this result passes control along its
+// output index output.
+func (r *NodeResult) follows(output int) bool {
+	return r.Status == StatusSuccess && (r.Branch < 0 || r.Branch == output)
+}
+
+// topoOrder sorts wf.Nodes with Kahn's algorithm over the "main" connections.
+// Ties keep the Workflow.Nodes order so runs are deterministic. inbound maps
+// each node to its incoming edges in connection order; nodes that never reach
+// in-degree zero are returned as unreachable.
+func topoOrder(wf *Workflow) (order []Node, inbound map[string][]edge, unreachable []string, err error) {
+	byName := make(map[string]Node, len(wf.Nodes))
+	for _, n := range wf.Nodes {
+		byName[n.Name] = n
+	}
+	indegree := make(map[string]int, len(wf.Nodes))
+	children := make(map[string][]string)
+	inbound = make(map[string][]edge)
+	for _, n := range wf.Nodes {
+		for output, targets := range wf.Connections[n.Name]["main"] {
+			for _, t := range targets {
+				if _, ok := byName[t.Node]; !ok {
+					return nil, nil, nil, fmt.Errorf("node %q connects to unknown node %q", n.Name, t.Node)
+				}
+				indegree[t.Node]++
+				children[n.Name] = append(children[n.Name], t.Node)
+				inbound[t.Node] = append(inbound[t.Node], edge{from: n.Name, output: output})
+			}
+		}
+	}
+
+	var queue []string
+	for _, n := range wf.Nodes {
+		if indegree[n.Name] == 0 {
+			queue = append(queue, n.Name)
+		}
+	}
+	done := make(map[string]bool, len(wf.Nodes))
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		done[name] = true
+		order = append(order, byName[name])
+		for _, c := range children[name] {
+			indegree[c]--
+			if indegree[c] == 0 {
+				queue = append(queue, c)
+			}
+		}
+	}
+	for _, n := range wf.Nodes {
+		if !done[n.Name] {
+			unreachable = append(unreachable, n.Name)
+		}
+	}
+	return order, inbound, unreachable, nil
+}
+
+// executeNode runs one node under its timeout. parents are the upstream
+// nodes with an active connection to it, all of which have already completed.
+func (e *Executor) executeNode(ctx context.Context, node Node, parents []string, execCtx *ExecutionContext) error {
+	if err := ctx.Err(); err != nil {
+		return e.fail(execCtx, node, &NodeResult{Node: node.Name, StartedAt: time.Now()}, deadlineErr(err, "workflow deadline exceeded before node %q", node.Name))
+	}
+
+	e.mu.RLock()
+	handler, ok := e.handlers[node.Type]
+	e.mu.RUnlock()
+	if !ok {
+		switch node.Type {
+		case NodeMerge:
+			handler, ok = mergeHandler, true
+		case NodeIf:
+			handler, ok = ifHandler, true
+		}
+	}
+	result := &NodeResult{Node: node.Name, Status: StatusRunning, Branch: -1, StartedAt: time.Now()}
+	execCtx.NodeResults[node.Name] = result
+	execCtx.Order = append(execCtx.Order, node.Name)
+	if !ok {
+		return e.fail(execCtx, node, result, fmt.Errorf("no handler registered for node type %q", node.Type))
+	}
+
+	timeout := e.DefaultNodeTimeout
+	if node.TimeoutSeconds > 0 {
+		timeout = time.Duration(node.TimeoutSeconds) * time.Second
+	}
+	nodeCtx, cancel := ctx, context.CancelFunc(func() {})
+	if timeout > 0 {
+		nodeCtx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+
+	input := upstreamInput(parents, execCtx)
+	type outcome struct {
+		out map[string]interface{}
+		err error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		out, err := handler(nodeCtx, node, input, execCtx.Data)
+		done <- outcome{out, err}
+	}()
+
+	select {
+	case o := <-done:
+		result.Duration = time.Since(result.StartedAt)
+		if o.err != nil {
+			return e.fail(execCtx, node, result, fmt.Errorf("node %q: %w", node.Name, o.err))
+		}
+		if b, ok := branchOf(o.out); ok {
+			result.Branch = b
+			delete(o.out, BranchKey)
+		} else if _, set := o.out[BranchKey]; set {
+			return e.fail(execCtx, node, result, fmt.Errorf("node %q: invalid %s value %v", node.Name, BranchKey, o.out[BranchKey]))
+		}
+		result.Status = StatusSuccess
+		result.Output = o.out
+	case <-nodeCtx.Done():
+		result.Duration = time.Since(result.StartedAt)
+		if ctx.Err() != nil {
+			return e.fail(execCtx, node, result, deadlineErr(ctx.Err(), "workflow deadline exceeded in node %q", node.Name))
+		}
+		return e.fail(execCtx, node, result, fmt.Errorf("node %q timed out after %s: %w", node.Name, timeout, ErrTimeout))
+	}
+	return nil
+}
+
+// upstreamInput merges the outputs of parents into a new map (see NodeHandler).
+func upstreamInput(parents []string, execCtx *ExecutionContext) map[string]interface{} {
+	input := make(map[string]interface{})
+	for _, p := range parents {
+		if r := execCtx.NodeResults[p]; r != nil {
+			for k, v := range r.Output {
+				input[k] = v
+			}
+		}
+	}
+	return input
+}
+
+// mergeHandler is the built-in NodeMerge handler: its output is the merged
+// input of all parent branches.
+func mergeHandler(ctx context.Context, node Node, input, data map[string]interface{}) (map[string]interface{}, error) {
+	return input, nil
+}
+
+// ifHandler is the built-in NodeIf handler. It evaluates the n8n
+// "conditions" parameter (see evalIfConditions) or, for hand-written
+// workflows, the "condition" expression (see evalCondition) against the node
+// input, falling back to the execution data. It passes its input through and
+// selects output 0 when the condition holds and output 1 otherwise.
+func ifHandler(ctx context.Context, node Node, input, data map[string]interface{}) (map[string]interface{}, error) {
+	scope := make(map[string]interface{}, len(data)+len(input))
+	for k, v := range data {
+		scope[k] = v
+	}
+	for k, v := range input {
+		scope[k] = v
+	}
+	var ok bool
+	var err error
+	if conds, set := node.Parameters["conditions"]; set {
+		combine, _ := node.Parameters["combineOperation"].(string)
+		ok, err = evalIfConditions(conds, combine, scope)
+	} else if cond, _ := node.Parameters["condition"].(string); cond != "" {
+		ok, err = evalCondition(cond, scope)
+	} else {
+		return nil, errors.New(`if node requires a "conditions" or "condition" parameter`)
+	}
+	if err != nil {
+		return nil, err
+	}
+	input[BranchKey] = ok
+	return input, nil
+}
+
+// branchOf reads the BranchKey value from a handler output.
+func branchOf(out map[string]interface{}) (int, bool) {
+	switch v := out[BranchKey].(type) {
+	case bool:
+		if v {
+			return 0, true
+		}
+		return 1, true
+	case int:
+		return v, v >= 0
+	case float64:
+		return int(v), v >= 0 && v == float64(int(v))
+	}
+	return 0, false
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// fail marks result and the execution as failed on node.
+func (e *Executor) fail(execCtx *ExecutionContext, node Node, result *NodeResult, err error) error {
+	result.Status = StatusFailed
+	result.Error = err.Error()
+	result.TimedOut = errors.Is(err, ErrTimeout)
+	execCtx.NodeResults[node.Name] = result
+	execCtx.FailedNode = node.Name
+	return err
+}
+
+// deadlineErr wraps ErrTimeout when ctxErr is a deadline, otherwise the cancellation itself.
+func deadlineErr(ctxErr error, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if errors.Is(ctxErr, context.DeadlineExceeded) {
+		return fmt.Errorf("%s: %w", msg, ErrTimeout)
+	}
+	return fmt.Errorf("%s: %w", msg, ctxErr)
+}
+
+// workflowTimeout reads the n8n "executionTimeout" setting in seconds.
+func workflowTimeout(wf *Workflow) int {
+	switch v := wf.Settings["executionTimeout"].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
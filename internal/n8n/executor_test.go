@@ -0,0 +1,354 @@
+package n8n
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func linearWorkflow(nodes ...Node) *Workflow {
+	wf := &Workflow{Name: "test", Nodes: nodes, Connections: map[string]map[string][][]ConnectionTarget{}}
+	for i := 0; i+1 < len(nodes); i++ {
+		wf.Connections[nodes[i].Name] = map[string][][]ConnectionTarget{
+			"main": {{{Node: nodes[i+1].Name, Type: "main", Index: 0}}},
+		}
+	}
+	return wf
+}
+
+func TestExecutor_RunsChain(t *testing.T) {
+	e := NewExecutor()
+	var order []string
+	e.RegisterHandler("test.step", func(ctx context.Context, n Node, input, data map[string]interface{}) (map[string]interface{}, error) {
+		order = append(order, n.Name)
+		return map[string]interface{}{"ok": true}, nil
+	})
+
+	wf := linearWorkflow(
+		Node{Name: "A", Type: "test.step"},
+		Node{Name: "B", Type: "test.step"},
+	)
+	exec, err := e.Execute(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exec.Status != StatusSuccess {
+		t.Errorf("expected success, got %s", exec.Status)
+	}
+	if len(order) != 2 || order[0] != "A" || order[1] != "B" {
+		t.Errorf("unexpected order: %v", order)
+	}
+}
+
+func TestExecutor_NodeTimeout(t *testing.T) {
+	e := NewExecutor()
+	e.RegisterHandler("test.fast", func(ctx context.Context, n Node, input, data map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	})
+	e.RegisterHandler("test.hang", func(ctx context.Context, n Node, input, data map[string]interface{}) (map[string]interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	e.DefaultNodeTimeout = 50 * time.Millisecond
+
+	wf := linearWorkflow(
+		Node{Name: "Fetch", Type: "test.fast"},
+		Node{Name: "Hang", Type: "test.hang"},
+	)
+	exec, err := e.Execute(context.Background(), wf, nil)
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+	if exec.Status != StatusFailed || exec.FailedNode != "Hang" {
+		t.Errorf("expected failed on Hang, got %s on %q", exec.Status, exec.FailedNode)
+	}
+	if r := exec.NodeResults["Hang"]; r == nil || !r.TimedOut {
+		t.Errorf("expected Hang to be marked timed out, got %+v", r)
+	}
+	if r := exec.NodeResults["Fetch"]; r == nil || r.Status != StatusSuccess {
+		t.Errorf("expected Fetch success, got %+v", r)
+	}
+}
+
+func TestExecutor_WorkflowDeadline(t *testing.T) {
+	e := NewExecutor()
+	e.RegisterHandler("test.slow", func(ctx context.Context, n Node, input, data map[string]interface{}) (map[string]interface{}, error) {
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+		}
+		return nil, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	exec, err := e.Execute(ctx, linearWorkflow(Node{Name: "Slow", Type: "test.slow"}), nil)
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+	if exec.FailedNode != "Slow" {
+		t.Errorf("expected Slow to fail, got %q", exec.FailedNode)
+	}
+}
+
+func TestExecutor_MissingHandler(t *testing.T) {
+	exec, err := NewExecutor().Execute(context.Background(), linearWorkflow(Node{Name: "X", Type: "unknown"}), nil)
+	if err == nil {
+		t.Fatal("expected error for unregistered node type")
+	}
+	if exec.Status != StatusFailed {
+		t.Errorf("expected failed status, got %s", exec.Status)
+	}
+}
+
+func TestExecutor_MergeRunsOnceAfterAllParents(t *testing.T) {
+	e := NewExecutor()
+	runs := map[string]int{}
+	e.RegisterHandler("test.step", func(ctx context.Context, n Node, input, data map[string]interface{}) (map[string]interface{}, error) {
+		runs[n.Name]++
+		return map[string]interface{}{n.Name: true}, nil
+	})
+	edge := func(to ...string) map[string][][]ConnectionTarget {
+		var ts []ConnectionTarget
+		for _, n := range to {
+			ts = append(ts, ConnectionTarget{Node: n, Type: "main"})
+		}
+		return map[string][][]ConnectionTarget{"main": {ts}}
+	}
+	wf := &Workflow{
+		Name: "diamond",
+		Nodes: []Node{
+			{Name: "Merge", Type: NodeMerge},
+			{Name: "Start", Type: "test.step"},
+			{Name: "Left", Type: "test.step"},
+			{Name: "Right", Type: "test.step"},
+		},
+		Connections: map[string]map[string][][]ConnectionTarget{
+			"Start": edge("Left", "Right"),
+			"Left":  edge("Merge"),
+			"Right": edge("Merge"),
+		},
+	}
+	exec, err := e.Execute(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := exec.Order; len(got) != 4 || got[0] != "Start" || got[3] != "Merge" {
+		t.Errorf("unexpected order: %v", got)
+	}
+	for name, n := range runs {
+		if n != 1 {
+			t.Errorf("%s ran %d times", name, n)
+		}
+	}
+	out := exec.NodeResults["Merge"].Output
+	if out["Left"] != true || out["Right"] != true {
+		t.Errorf("merge should combine parent outputs, got %v", out)
+	}
+}
+
+func TestExecutor_ReportsUnreachableCycle(t *testing.T) {
+	e := NewExecutor()
+	e.RegisterHandler("test.step", func(ctx context.Context, n Node, input, data map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	})
+	wf := linearWorkflow(
+		Node{Name: "A", Type: "test.step"},
+		Node{Name: "B", Type: "test.step"},
+		Node{Name: "C", Type: "test.step"},
+	)
+	wf.Connections["C"] = map[string][][]ConnectionTarget{"main": {{{Node: "B", Type: "main"}}}}
+	exec, err := e.Execute(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(exec.Unreachable) != 2 || exec.Unreachable[0] != "B" || exec.Unreachable[1] != "C" {
+		t.Errorf("expected B and C unreachable, got %v", exec.Unreachable)
+	}
+	if len(exec.Order) != 1 {
+		t.Errorf("only A should run, got %v", exec.Order)
+	}
+}
+
+func TestExecutor_PassesUpstreamOutput(t *testing.T) {
+	e := NewExecutor()
+	e.RegisterHandler("test.fetch", func(ctx context.Context, n Node, input, data map[string]interface{}) (map[string]interface{}, error) {
+		if len(input) != 0 {
+			t.Errorf("root node should get empty input, got %v", input)
+		}
+		return map[string]interface{}{"items": []string{"a", "b"}}, nil
+	})
+	e.RegisterHandler("test.transform", func(ctx context.Context, n Node, input, data map[string]interface{}) (map[string]interface{}, error) {
+		items, _ := input["items"].([]string)
+		return map[string]interface{}{"count": len(items)}, nil
+	})
+	wf := linearWorkflow(
+		Node{Name: "Fetch", Type: "test.fetch"},
+		Node{Name: "Transform", Type: "test.transform"},
+	)
+	exec, err := e.Execute(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := exec.NodeResults["Transform"].Output["count"]; got != 2 {
+		t.Errorf("transform should see fetch output, got count=%v", got)
+	}
+}
+
+func conditionalWorkflow() *Workflow {
+	return &Workflow{
+		Name: "conditional",
+		Nodes: []Node{
+			{Name: "Check", Type: NodeIf, Parameters: map[string]interface{}{"condition": "{{ $json.value > 0 }}"}},
+			{Name: "action-true", Type: "test.step"},
+			{Name: "action-false", Type: "test.step"},
+		},
+		Connections: map[string]map[string][][]ConnectionTarget{
+			"Check": {"main": {
+				{{Node: "action-true", Type: "main"}},
+				{{Node: "action-false", Type: "main"}},
+			}},
+		},
+	}
+}
+
+func TestExecutor_ConditionalBranches(t *testing.T) {
+	for _, tc := range []struct {
+		value      float64
+		ran, skipd string
+	}{
+		{value: 5, ran: "action-true", skipd: "action-false"},
+		{value: -1, ran: "action-false", skipd: "action-true"},
+	} {
+		e := NewExecutor()
+		e.RegisterHandler("test.step", func(ctx context.Context, n Node, input, data map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"seen": input["value"]}, nil
+		})
+		exec, err := e.Execute(context.Background(), conditionalWorkflow(), map[string]interface{}{"value": tc.value})
+		if err != nil {
+			t.Fatalf("value=%v: unexpected error: %v", tc.value, err)
+		}
+		if r := exec.NodeResults[tc.ran]; r.Status != StatusSuccess {
+			t.Errorf("value=%v: expected %s to run, got %s", tc.value, tc.ran, r.Status)
+		}
+		if r := exec.NodeResults[tc.skipd]; r.Status != StatusSkipped {
+			t.Errorf("value=%v: expected %s skipped, got %s", tc.value, tc.skipd, r.Status)
+		}
+		if _, leaked := exec.NodeResults["Check"].Output[BranchKey]; leaked {
+			t.Errorf("value=%v: %s should be stripped from output", tc.value, BranchKey)
+		}
+	}
+}
+
+func TestEvalCondition(t *testing.T) {
+	scope := map[string]interface{}{
+		"value":  3.0,
+		"status": "ok",
+		"user":   map[string]interface{}{"name": "a && b"},
+	}
+	cases := map[string]bool{
+		"{{ $json.value > 0 }}":                     true,
+		"$json.value <= 2":                          false,
+		`$json.status == "ok" && $json.value != 3`:  false,
+		`$json.status == 'bad' || $json.value >= 3`: true,
+		`$json.user.name == "a && b"`:               true,
+		"$json.missing > 1":                         false,
+		"$json.status":                              true,
+	}
+	for expr, want := range cases {
+		got, err := evalCondition(expr, scope)
+		if err != nil {
+			t.Errorf("%s: %v", expr, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s = %v, want %v", expr, got, want)
+		}
+	}
+	if _, err := evalCondition("$json.value > foo", scope); err == nil {
+		t.Error("expected error for unsupported operand")
+	}
+}
+
+func TestEvalIfConditions(t *testing.T) {
+	scope := map[string]interface{}{"value": 3.0, "status": "OK", "tags": []interface{}{}}
+	v2 := func(combinator string, conds ...map[string]interface{}) interface{} {
+		list := make([]interface{}, len(conds))
+		for i, c := range conds {
+			list[i] = c
+		}
+		return map[string]interface{}{
+			"options":    map[string]interface{}{"caseSensitive": false},
+			"conditions": list,
+			"combinator": combinator,
+		}
+	}
+	cond := func(left interface{}, typ, op string, right interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"leftValue":  left,
+			"rightValue": right,
+			"operator":   map[string]interface{}{"type": typ, "operation": op},
+		}
+	}
+	cases := []struct {
+		name    string
+		conds   interface{}
+		combine string
+		want    bool
+	}{
+		{"v2 number gt", v2("and", cond("={{ $json.value }}", "number", "gt", 2)), "", true},
+		{"v2 string equals ignoring case", v2("and", cond("={{ $json.status }}", "string", "equals", "ok")), "", true},
+		{"v2 and fails", v2("and", cond("={{ $json.value }}", "number", "gt", 2), cond("={{ $json.value }}", "number", "lt", 1)), "", false},
+		{"v2 or passes", v2("or", cond("={{ $json.value }}", "number", "gt", 5), cond("={{ $json.tags }}", "array", "empty", nil)), "", true},
+		{"v1 all", map[string]interface{}{
+			"number": []interface{}{map[string]interface{}{"value1": "={{$json.value}}", "operation": "larger", "value2": 1}},
+			"string": []interface{}{map[string]interface{}{"value1": "={{$json.status}}", "value2": "OK"}},
+		}, "", true},
+		{"v1 any", map[string]interface{}{
+			"number": []interface{}{
+				map[string]interface{}{"value1": "={{$json.value}}", "operation": "larger", "value2": 10},
+				map[string]interface{}{"value1": "={{$json.value}}", "operation": "equal", "value2": 3},
+			},
+		}, "any", true},
+	}
+	for _, tc := range cases {
+		got, err := evalIfConditions(tc.conds, tc.combine, scope)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if _, err := evalIfConditions(v2("and", cond(1, "number", "between", 2)), "", scope); err == nil {
+		t.Error("expected an error for an unsupported operator")
+	}
+}
+
+func TestExecutor_IfNodeWithN8NConditions(t *testing.T) {
+	wf := conditionalWorkflow()
+	wf.Nodes[0].TypeVersion = 2
+	wf.Nodes[0].Parameters = map[string]interface{}{
+		"conditions": map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{
+				"leftValue":  "={{ $json.value }}",
+				"rightValue": 0,
+				"operator":   map[string]interface{}{"type": "number", "operation": "gt"},
+			}},
+			"combinator": "and",
+		},
+	}
+	e := NewExecutor()
+	e.RegisterHandler("test.step", func(ctx context.Context, n Node, input, data map[string]interface{}) (map[string]interface{}, error) {
+		return nil, nil
+	})
+	exec, err := e.Execute(context.Background(), wf, map[string]interface{}{"value": -2.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := exec.NodeResults["action-false"]; r.Status != StatusSuccess {
+		t.Errorf("expected the false branch to run, got %s", r.Status)
+	}
+}
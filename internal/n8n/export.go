@@ -0,0 +1,145 @@
+package n8n
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// nodeTypeAliases maps the short NEXUS node kinds (as produced by hand-written
+// or LLM-compiled workflows) to concrete n8n node types.
+var nodeTypeAliases = map[string]string{
+	"trigger":   "n8n-nodes-base.scheduleTrigger",
+	"schedule":  "n8n-nodes-base.scheduleTrigger",
+	"webhook":   "n8n-nodes-base.webhook",
+	"action":    "n8n-nodes-base.httpRequest",
+	"http":      "n8n-nodes-base.httpRequest",
+	"condition": NodeIf,
+	"merge":     NodeMerge,
+}
+
+// n8nExport is the importable n8n workflow document. Unlike Workflow, every
+// key n8n's importer expects is always present.
+type n8nExport struct {
+	Name        string                                     `json:"name"`
+	Nodes       []Node                                     `json:"nodes"`
+	Connections map[string]map[string][][]ConnectionTarget `json:"connections"`
+	Settings    map[string]interface{}                     `json:"settings"`
+}
+
+// ExportN8N loads the workflow saved under workflowID in the default store
+// (see OpenStore) and renders it with RenderN8N.
+func ExportN8N(workflowID string) (string, error) {
+	store, err := OpenStore("")
+	if err != nil {
+		return "", err
+	}
+	wf, err := store.Load(workflowID)
+	if err != nil {
+		return "", err
+	}
+	return RenderN8N(wf)
+}
+
+// RenderN8N renders wf as n8n workflow JSON that can be pasted into the n8n
+// editor or imported with "n8n import:workflow". Short NEXUS node kinds are
+// mapped to n8n node types, and missing node IDs, type versions, positions
+// and parameters are filled in. wf itself is not modified.
+func RenderN8N(wf *Workflow) (string, error) {
+	doc, err := normalizeExport(wf)
+	if err != nil {
+		return "", err
+	}
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("n8n: marshal export: %w", err)
+	}
+	return string(out), nil
+}
+
+func normalizeExport(wf *Workflow) (*n8nExport, error) {
+	doc := &n8nExport{
+		Name:        wf.Name,
+		Nodes:       make([]Node, 0, len(wf.Nodes)),
+		Connections: make(map[string]map[string][][]ConnectionTarget),
+		Settings:    make(map[string]interface{}),
+	}
+	if doc.Name == "" {
+		doc.Name = "NEXUS workflow"
+	}
+	for k, v := range wf.Settings {
+		doc.Settings[k] = v
+	}
+
+	names := make(map[string]bool, len(wf.Nodes))
+	for i, n := range wf.Nodes {
+		if n.Name == "" {
+			return nil, fmt.Errorf("n8n: node %d has no name", i)
+		}
+		if names[n.Name] {
+			return nil, fmt.Errorf("n8n: duplicate node name %q", n.Name)
+		}
+		names[n.Name] = true
+
+		if t, ok := nodeTypeAliases[strings.ToLower(n.Type)]; ok {
+			n.Type = t
+		}
+		if n.ID == "" {
+			id, err := newNodeID()
+			if err != nil {
+				return nil, err
+			}
+			n.ID = id
+		}
+		if n.TypeVersion == 0 {
+			n.TypeVersion = 1
+		}
+		if len(n.Position) != 2 {
+			n.Position = []float64{float64(250 + 200*i), 300}
+		}
+		params := make(map[string]interface{}, len(n.Parameters))
+		for k, v := range n.Parameters {
+			params[k] = v
+		}
+		n.Parameters = params
+		doc.Nodes = append(doc.Nodes, n)
+	}
+
+	for from, outputs := range wf.Connections {
+		if !names[from] {
+			return nil, fmt.Errorf("n8n: connection from unknown node %q", from)
+		}
+		outCopy := make(map[string][][]ConnectionTarget, len(outputs))
+		for kind, branches := range outputs {
+			branchCopy := make([][]ConnectionTarget, len(branches))
+			for i, targets := range branches {
+				branchCopy[i] = make([]ConnectionTarget, len(targets))
+				for j, t := range targets {
+					if !names[t.Node] {
+						return nil, fmt.Errorf("n8n: node %q connects to unknown node %q", from, t.Node)
+					}
+					if t.Type == "" {
+						t.Type = kind
+					}
+					branchCopy[i][j] = t
+				}
+			}
+			outCopy[kind] = branchCopy
+		}
+		doc.Connections[from] = outCopy
+	}
+	return doc, nil
+}
+
+// newNodeID returns a random RFC 4122 version 4 UUID, the format n8n uses
+// for node IDs.
+func newNodeID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("n8n: generate node id: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,88 @@
+package n8n
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExportN8N_RoundTrip(t *testing.T) {
+	wf := &Workflow{
+		Name: "Daily check",
+		Nodes: []Node{
+			{Name: "Every day", Type: "trigger"},
+			{Name: "Positive?", Type: "condition", Parameters: map[string]interface{}{"value": "{{ $json.value > 0 }}"}},
+			{Name: "Notify", Type: "action"},
+		},
+		Connections: map[string]map[string][][]ConnectionTarget{
+			"Every day": {"main": {{{Node: "Positive?"}}}},
+			"Positive?": {"main": {{{Node: "Notify"}}, {}}},
+		},
+	}
+	out, err := RenderN8N(wf)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	for _, key := range []string{`"nodes"`, `"connections"`, `"settings"`} {
+		if !strings.Contains(out, key) {
+			t.Errorf("export missing %s", key)
+		}
+	}
+
+	var back Workflow
+	if err := json.Unmarshal([]byte(out), &back); err != nil {
+		t.Fatalf("export is not valid workflow JSON: %v", err)
+	}
+	want := []string{"n8n-nodes-base.scheduleTrigger", "n8n-nodes-base.if", "n8n-nodes-base.httpRequest"}
+	for i, n := range back.Nodes {
+		if n.Type != want[i] {
+			t.Errorf("node %q type = %s, want %s", n.Name, n.Type, want[i])
+		}
+		if n.ID == "" || n.TypeVersion != 1 || len(n.Position) != 2 || n.Parameters == nil {
+			t.Errorf("node %q not normalised: %+v", n.Name, n)
+		}
+	}
+	if got := back.Connections["Every day"]["main"][0][0]; got.Node != "Positive?" || got.Type != "main" {
+		t.Errorf("unexpected connection: %+v", got)
+	}
+	if wf.Nodes[0].Type != "trigger" || wf.Nodes[0].ID != "" {
+		t.Error("RenderN8N must not modify the input workflow")
+	}
+
+	again, err := RenderN8N(&back)
+	if err != nil || again != out {
+		t.Errorf("re-exporting an export should be stable (err=%v)", err)
+	}
+}
+
+func TestExportN8N_RejectsUnknownTarget(t *testing.T) {
+	wf := &Workflow{
+		Nodes:       []Node{{Name: "A", Type: "action"}},
+		Connections: map[string]map[string][][]ConnectionTarget{"A": {"main": {{{Node: "Missing"}}}}},
+	}
+	if _, err := RenderN8N(wf); err == nil {
+		t.Fatal("expected error for connection to unknown node")
+	}
+}
+
+func TestExportN8N_ByWorkflowID(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store, err := OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := store.Save(&Workflow{Name: "Ping", Nodes: []Node{{Name: "Ping", Type: "http"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := ExportN8N(id)
+	if err != nil {
+		t.Fatalf("export %s: %v", id, err)
+	}
+	if !strings.Contains(out, `"n8n-nodes-base.httpRequest"`) {
+		t.Errorf("export of %s not rendered as n8n: %s", id, out)
+	}
+	if _, err := ExportN8N("no-such-workflow"); err == nil {
+		t.Error("expected error for unknown workflow ID")
+	}
+}
@@ -0,0 +1,199 @@
+package n8n
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// evalCondition evaluates a small subset of n8n expressions, enough for IF
+// nodes:
+//
+//	{{ $json.value > 0 }}
+//	{{ $json.status == "ok" && $json.retries < 3 }}
+//
+// Operands are $json paths (dotted, resolved against scope), numbers, quoted
+// strings, true, false and null. Comparisons are ==, !=, <, <=, > and >=;
+// they may be joined with && and || (&& binds tighter, no parentheses). A
+// bare operand is tested for truthiness. The {{ }} wrapper is optional.
+func evalCondition(expr string, scope map[string]interface{}) (bool, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "{{") && strings.HasSuffix(expr, "}}") {
+		expr = strings.TrimSpace(expr[2 : len(expr)-2])
+	}
+	if expr == "" {
+		return false, fmt.Errorf("n8n: empty condition")
+	}
+	for _, or := range splitOutsideQuotes(expr, "||") {
+		all := true
+		for _, and := range splitOutsideQuotes(or, "&&") {
+			ok, err := evalComparison(strings.TrimSpace(and), scope)
+			if err != nil {
+				return false, fmt.Errorf("n8n: condition %q: %w", expr, err)
+			}
+			if !ok {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+var comparisonOps = []string{"==", "!=", ">=", "<=", ">", "<"}
+
+func evalComparison(expr string, scope map[string]interface{}) (bool, error) {
+	for _, op := range comparisonOps {
+		parts := splitOutsideQuotes(expr, op)
+		if len(parts) == 1 {
+			continue
+		}
+		if len(parts) != 2 {
+			return false, fmt.Errorf("chained %q comparison", op)
+		}
+		l, err := operand(parts[0], scope)
+		if err != nil {
+			return false, err
+		}
+		r, err := operand(parts[1], scope)
+		if err != nil {
+			return false, err
+		}
+		return compare(l, r, op)
+	}
+	v, err := operand(expr, scope)
+	if err != nil {
+		return false, err
+	}
+	return truthy(v), nil
+}
+
+func operand(s string, scope map[string]interface{}) (interface{}, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return nil, fmt.Errorf("missing operand")
+	case s == "true":
+		return true, nil
+	case s == "false":
+		return false, nil
+	case s == "null":
+		return nil, nil
+	case len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0]:
+		return s[1 : len(s)-1], nil
+	case s == "$json" || strings.HasPrefix(s, "$json."):
+		var cur interface{} = scope
+		for _, key := range strings.Split(strings.TrimPrefix(strings.TrimPrefix(s, "$json"), "."), ".") {
+			if key == "" {
+				continue
+			}
+			m, ok := cur.(map[string]interface{})
+			if !ok {
+				return nil, nil
+			}
+			cur = m[key]
+		}
+		return cur, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("unsupported operand %q", s)
+}
+
+func compare(l, r interface{}, op string) (bool, error) {
+	lf, lok := toFloat(l)
+	rf, rok := toFloat(r)
+	if lok && rok {
+		switch op {
+		case "==":
+			return lf == rf, nil
+		case "!=":
+			return lf != rf, nil
+		case ">":
+			return lf > rf, nil
+		case ">=":
+			return lf >= rf, nil
+		case "<":
+			return lf < rf, nil
+		case "<=":
+			return lf <= rf, nil
+		}
+	}
+	switch op {
+	case "==":
+		return fmt.Sprint(l) == fmt.Sprint(r), nil
+	case "!=":
+		return fmt.Sprint(l) != fmt.Sprint(r), nil
+	}
+	ls, lstr := l.(string)
+	rs, rstr := r.(string)
+	if !lstr || !rstr {
+		return false, nil // ordering a missing or mismatched value is false, as in JS
+	}
+	switch op {
+	case ">":
+		return ls > rs, nil
+	case ">=":
+		return ls >= rs, nil
+	case "<":
+		return ls < rs, nil
+	default:
+		return ls <= rs, nil
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func truthy(v interface{}) bool {
+	switch x := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return x
+	case string:
+		return x != ""
+	}
+	if f, ok := toFloat(v); ok {
+		return f != 0
+	}
+	return true
+}
+
+// splitOutsideQuotes splits s on sep, ignoring occurrences inside quotes.
+func splitOutsideQuotes(s, sep string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case strings.HasPrefix(s[i:], sep):
+			parts = append(parts, s[start:i])
+			i += len(sep) - 1
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
@@ -0,0 +1,119 @@
+package n8n
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Store keeps compiled workflows as JSON files, one per workflow, so they
+// can be exported or run later by ID.
+type Store struct {
+	dir string
+}
+
+// StoredWorkflow describes a saved workflow.
+type StoredWorkflow struct {
+	ID      string
+	Name    string
+	Nodes   int
+	SavedAt time.Time
+}
+
+var (
+	slugRe = regexp.MustCompile(`[^a-z0-9]+`)
+	idRe   = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+)
+
+// OpenStore opens (or creates) a workflow store in dir (default ~/.nexus/n8n).
+func OpenStore(dir string) (*Store, error) {
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".nexus", "n8n")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("n8n: store mkdir: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Save writes wf under a new ID derived from its name, e.g.
+// "daily-check-3f9a", and returns the ID.
+func (s *Store) Save(wf *Workflow) (string, error) {
+	slug := strings.Trim(slugRe.ReplaceAllString(strings.ToLower(wf.Name), "-"), "-")
+	if len(slug) > 40 {
+		slug = strings.TrimRight(slug[:40], "-")
+	}
+	if slug == "" {
+		slug = "workflow"
+	}
+	var b [2]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("n8n: generate workflow id: %w", err)
+	}
+	id := slug + "-" + hex.EncodeToString(b[:])
+	data, err := json.MarshalIndent(wf, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("n8n: marshal workflow: %w", err)
+	}
+	if err := os.WriteFile(s.path(id), data, 0o600); err != nil {
+		return "", fmt.Errorf("n8n: save workflow: %w", err)
+	}
+	return id, nil
+}
+
+// Load reads the workflow saved under id.
+func (s *Store) Load(id string) (*Workflow, error) {
+	if !idRe.MatchString(id) {
+		return nil, fmt.Errorf("n8n: invalid workflow id %q", id)
+	}
+	data, err := os.ReadFile(s.path(id))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("n8n: workflow %q not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("n8n: load workflow: %w", err)
+	}
+	var wf Workflow
+	if err := json.Unmarshal(data, &wf); err != nil {
+		return nil, fmt.Errorf("n8n: decode workflow %q: %w", id, err)
+	}
+	return &wf, nil
+}
+
+// List returns the saved workflows, newest first. Unreadable files are skipped.
+func (s *Store) List() ([]StoredWorkflow, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("n8n: list workflows: %w", err)
+	}
+	var out []StoredWorkflow
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() {
+			continue
+		}
+		wf, err := s.Load(id)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		out = append(out, StoredWorkflow{ID: id, Name: wf.Name, Nodes: len(wf.Nodes), SavedAt: info.ModTime()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].SavedAt.After(out[j].SavedAt) })
+	return out, nil
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
@@ -0,0 +1,35 @@
+package n8n
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStoreSaveLoadList(t *testing.T) {
+	s, err := OpenStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wf := &Workflow{Name: "Daily Check: GitHub → Slack", Nodes: []Node{{Name: "Every day", Type: "trigger"}}}
+	id, err := s.Save(wf)
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if !strings.HasPrefix(id, "daily-check-github-slack-") {
+		t.Errorf("unexpected id %q", id)
+	}
+	got, err := s.Load(id)
+	if err != nil || got.Name != wf.Name || len(got.Nodes) != 1 {
+		t.Fatalf("Load = %+v, %v", got, err)
+	}
+	list, err := s.List()
+	if err != nil || len(list) != 1 || list[0].ID != id || list[0].Nodes != 1 {
+		t.Errorf("List = %+v, %v", list, err)
+	}
+	if _, err := s.Load("../etc/passwd"); err == nil {
+		t.Error("path traversal id should be rejected")
+	}
+	if _, err := s.Load("missing-0000"); err == nil {
+		t.Error("expected not-found error")
+	}
+}
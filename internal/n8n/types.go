@@ -1,11 +1,13 @@
 package n8n
 
+import "context"
+
 // Workflow represents a standard n8n workflow JSON structure.
 type Workflow struct {
-	Name        string                               `json:"name"`
-	Nodes       []Node                               `json:"nodes"`
-	Connections map[string]map[string][]ConnectionTarget `json:"connections"`
-	Settings    map[string]interface{}               `json:"settings,omitempty"`
+	Name        string                                     `json:"name"`
+	Nodes       []Node                                     `json:"nodes"`
+	Connections map[string]map[string][][]ConnectionTarget `json:"connections"`
+	Settings    map[string]interface{}                     `json:"settings,omitempty"`
 }
 
 // Node represents a single step or integration in an n8n workflow.
@@ -16,8 +18,20 @@ type Node struct {
 	Type        string                 `json:"type"`
 	TypeVersion float64                `json:"typeVersion"`
 	Position    []float64              `json:"position"`
+	// TimeoutSeconds bounds a single execution of this node. Zero means the
+	// executor default applies.
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
 }
 
+// NodeMerge is the n8n node type that joins several branches. When no
+// handler is registered for it, the Executor merges the parents' outputs.
+const NodeMerge = "n8n-nodes-base.merge"
+
+// NodeIf is the n8n conditional node type. Its first "main" output is the
+// true branch and its second the false branch; when no handler is registered
+// for it, the Executor evaluates its "condition" parameter itself.
+const NodeIf = "n8n-nodes-base.if"
+
 // ConnectionTarget represents the destination of an n8n node connection.
 type ConnectionTarget struct {
 	Node  string `json:"node"`
@@ -0,0 +1,103 @@
+package phone
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// IVROption is one entry of an inbound call menu.
+type IVROption struct {
+	Digit string // key the caller presses, "0"-"9", "*" or "#"
+	Label string // spoken in the menu, e.g. "the drift report"
+	// Run executes the NEXUS command for the caller and returns the text
+	// to speak back.
+	Run func(from string) string
+}
+
+// IVRMenu is the keypad menu played to inbound callers.
+type IVRMenu struct {
+	Greeting string // default "Welcome to NEXUS."
+	Options  []IVROption
+}
+
+// SetIVR enables the keypad menu for inbound calls.
+func (p *PhoneAgent) SetIVR(menu IVRMenu) {
+	if menu.Greeting == "" {
+		menu.Greeting = "Welcome to NEXUS."
+	}
+	p.ivr = &menu
+}
+
+func (m *IVRMenu) option(digit string) (IVROption, bool) {
+	for _, o := range m.Options {
+		if o.Digit == digit {
+			return o, true
+		}
+	}
+	return IVROption{}, false
+}
+
+// prompt is the spoken menu: "Press 1 for the drift report. Press 2 for ...".
+func (m *IVRMenu) prompt() string {
+	var sb strings.Builder
+	for _, o := range m.Options {
+		fmt.Fprintf(&sb, " Press %s for %s.", o.Digit, o.Label)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// gatherTwiML asks for one digit, posting it to action. prefix is spoken
+// before the menu (e.g. after an invalid choice).
+func (m *IVRMenu) gatherTwiML(action, prefix string) string {
+	intro := m.Greeting
+	if prefix != "" {
+		intro = prefix
+	}
+	return fmt.Sprintf(`<Response><Gather numDigits="1" action="%s" method="POST">`+
+		`<Say voice="alice">%s %s</Say></Gather>`+
+		`<Say voice="alice">No selection received. Goodbye.</Say></Response>`,
+		xmlEscape(action), xmlEscape(intro), xmlEscape(m.prompt()))
+}
+
+// gatherAction is where Twilio posts the pressed digit: the configured
+// webhook URL, or this same path.
+func (p *PhoneAgent) gatherAction(r *http.Request) string {
+	if p.cfg.WebhookURL != "" {
+		return p.cfg.WebhookURL
+	}
+	return r.URL.Path
+}
+
+// handleIVR runs the command for the pressed digit, records the choice and
+// speaks the result. HandleWebhook has already verified the request came
+// from Twilio; callers not in AllowedCallers are refused.
+func (p *PhoneAgent) handleIVR(w http.ResponseWriter, r *http.Request) {
+	digit := r.FormValue("Digits")
+	opt, ok := p.ivr.option(digit)
+	if !ok {
+		fmt.Fprint(w, p.ivr.gatherTwiML(p.gatherAction(r), "Sorry, that is not an option."))
+		return
+	}
+	from := r.FormValue("From")
+	if !p.callerAllowed(from) {
+		fmt.Fprint(w, `<Response><Say voice="alice">Sorry, this line is private. Goodbye.</Say><Hangup/></Response>`)
+		return
+	}
+	result := opt.Run(from)
+	if result == "" {
+		result = "Done."
+	}
+	sid := r.FormValue("CallSid")
+	if _, found := p.update(sid, func(rec *CallRecord) {
+		rec.Selection = digit
+		rec.Transcript = result
+	}); !found {
+		p.store(&CallRecord{
+			SID: sid, Direction: "inbound", Type: "call",
+			From: r.FormValue("From"), To: r.FormValue("To"),
+			Status: CallInProgress, Selection: digit, Transcript: result,
+		})
+	}
+	fmt.Fprintf(w, `<Response><Say voice="alice">%s</Say><Hangup/></Response>`, xmlEscape(result))
+}
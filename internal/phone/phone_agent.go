@@ -14,13 +14,12 @@ NEXUS PhoneAgent:
 */
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
-	"net/url"
-	"strings"
 	"sync"
 	"time"
+
+	"github.com/rs/zerolog/log"
 )
 
 // CallStatus represents the state of a phone call
@@ -33,6 +32,14 @@ const (
 	CallInProgress CallStatus = "in-progress"
 	CallCompleted  CallStatus = "completed"
 	CallFailed     CallStatus = "failed"
+
+	// Further statuses reported by Twilio for messages and calls.
+	CallSent        CallStatus = "sent"
+	CallDelivered   CallStatus = "delivered"
+	CallUndelivered CallStatus = "undelivered"
+	CallBusy        CallStatus = "busy"
+	CallNoAnswer    CallStatus = "no-answer"
+	CallCanceled    CallStatus = "canceled"
 )
 
 // CallRecord stores a call or SMS event
@@ -48,6 +55,7 @@ type CallRecord struct {
 	Cost       float64
 	CreatedAt  time.Time
 	Transcript string
+	Selection  string // IVR digit pressed on an inbound call
 }
 
 // PhoneConfig holds Twilio credentials and settings
@@ -58,6 +66,18 @@ type PhoneConfig struct {
 	WebhookURL  string // public URL for Twilio callbacks
 	RateLimit   int    // max calls per hour
 	Simulated   bool   // true = log only, no real API calls
+
+	// AllowedCallers are the only numbers (E.164) whose IVR selections run
+	// commands; other callers are told the line is private. An empty list
+	// admits no caller unless AllowAllCallers is set.
+	AllowedCallers  []string
+	AllowAllCallers bool // let any caller run IVR commands
+
+	APIBaseURL    string        // default https://api.twilio.com
+	MaxRetries    int           // retries of transient API failures (default 2; negative disables retries)
+	RetryBackoff  time.Duration // first retry delay, doubling (default 1s)
+	PollInterval  time.Duration // delivery status poll interval (default 3s)
+	StatusTimeout time.Duration // stop polling after this long (default 2m)
 }
 
 // PhoneAgent manages calls and SMS for NEXUS
@@ -68,16 +88,41 @@ type PhoneAgent struct {
 	callsThisHour int
 	hourWindow time.Time
 	onInbound  func(CallRecord)
+	onStatus   func(CallRecord)
+	client     *http.Client
+	ivr        *IVRMenu
 }
 
 // New creates a PhoneAgent
 func New(cfg PhoneConfig) *PhoneAgent {
+	if cfg.APIBaseURL == "" {
+		cfg.APIBaseURL = "https://api.twilio.com"
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 2
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = time.Second
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 3 * time.Second
+	}
+	if cfg.StatusTimeout <= 0 {
+		cfg.StatusTimeout = 2 * time.Minute
+	}
 	return &PhoneAgent{
 		cfg:        cfg,
 		hourWindow: time.Now(),
+		client:     &http.Client{Timeout: 15 * time.Second},
 	}
 }
 
+// SetStatusHandler sets a callback fired when polling settles an outbound
+// call or SMS on its final status (or gives up at StatusTimeout).
+func (p *PhoneAgent) SetStatusHandler(fn func(CallRecord)) {
+	p.onStatus = fn
+}
+
 // SetInboundHandler sets the callback for incoming calls/SMS
 func (p *PhoneAgent) SetInboundHandler(fn func(CallRecord)) {
 	p.onInbound = fn
@@ -110,6 +155,7 @@ func (p *PhoneAgent) Call(to, message string) (*CallRecord, error) {
 		return rec, err
 	}
 	p.store(rec)
+	go p.pollStatus("Calls", rec.SID)
 	return rec, nil
 }
 
@@ -139,68 +185,34 @@ func (p *PhoneAgent) SMS(to, body string) (*CallRecord, error) {
 		return rec, err
 	}
 	p.store(rec)
+	go p.pollStatus("Messages", rec.SID)
 	return rec, nil
 }
 
-func (p *PhoneAgent) twilioCall(to, message string, rec *CallRecord) error {
-	twiml := fmt.Sprintf(`<Response><Say voice="alice">%s</Say></Response>`, message)
-	data := url.Values{}
-	data.Set("To", to)
-	data.Set("From", p.cfg.FromNumber)
-	data.Set("Twiml", twiml)
-	_, err := p.twilioRequest("Calls", data)
-	if err != nil {
-		return err
-	}
-	rec.Status = CallInitiated
-	return nil
-}
-
-func (p *PhoneAgent) twilioSMS(to, body string, rec *CallRecord) error {
-	data := url.Values{}
-	data.Set("To", to)
-	data.Set("From", p.cfg.FromNumber)
-	data.Set("Body", body)
-	_, err := p.twilioRequest("Messages", data)
-	if err != nil {
-		return err
-	}
-	rec.Status = CallCompleted
-	return nil
-}
-
-func (p *PhoneAgent) twilioRequest(endpoint string, data url.Values) ([]byte, error) {
-	apiURL := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s/%s.json",
-		p.cfg.AccountSID, endpoint)
-	client := &http.Client{Timeout: 15 * time.Second}
-	req, err := http.NewRequest(http.MethodPost, apiURL, strings.NewReader(data.Encode()))
-	if err != nil {
-		return nil, err
-	}
-	req.SetBasicAuth(p.cfg.AccountSID, p.cfg.AuthToken)
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	var result []byte
-	json.NewDecoder(resp.Body).Decode(&result)
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("twilio error: %s", resp.Status)
-	}
-	return result, nil
-}
-
-// HandleWebhook processes inbound Twilio webhook callbacks
+// HandleWebhook processes inbound Twilio webhook callbacks. With an IVR
+// menu set (SetIVR), inbound calls hear the menu and the digit they press
+// comes back to this handler as a second request; see handleIVR. Requests
+// without a valid X-Twilio-Signature (computed with AuthToken over
+// WebhookURL) are rejected with 403.
 func (p *PhoneAgent) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	_ = r.ParseForm()
+	if !p.verifySignature(r) {
+		log.Warn().Str("remote", r.RemoteAddr).Msg("phone: rejected webhook with invalid Twilio signature")
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+	callSID := r.FormValue("CallSid")
+	w.Header().Set("Content-Type", "text/xml")
+	if callSID != "" && p.ivr != nil && r.FormValue("Digits") != "" {
+		p.handleIVR(w, r)
+		return
+	}
 	callType := "sms"
-	if r.FormValue("CallSid") != "" {
+	if callSID != "" {
 		callType = "call"
 	}
 	rec := CallRecord{
-		SID:       r.FormValue("CallSid") + r.FormValue("SmsSid"),
+		SID:       callSID + r.FormValue("SmsSid"),
 		Direction: "inbound",
 		Type:      callType,
 		From:      r.FormValue("From"),
@@ -213,7 +225,10 @@ func (p *PhoneAgent) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	if p.onInbound != nil {
 		p.onInbound(rec)
 	}
-	w.Header().Set("Content-Type", "text/xml")
+	if callType == "call" && p.ivr != nil {
+		fmt.Fprint(w, p.ivr.gatherTwiML(p.gatherAction(r), ""))
+		return
+	}
 	fmt.Fprint(w, `<Response><Say voice="alice">NEXUS received your message. Processing now.</Say></Response>`)
 }
 
@@ -240,6 +255,20 @@ func (p *PhoneAgent) store(rec *CallRecord) {
 	}
 }
 
+// update applies fn to the stored record with the given SID and returns the
+// updated copy.
+func (p *PhoneAgent) update(sid string, fn func(*CallRecord)) (CallRecord, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i := len(p.records) - 1; i >= 0; i-- {
+		if p.records[i].SID == sid {
+			fn(&p.records[i])
+			return p.records[i], true
+		}
+	}
+	return CallRecord{}, false
+}
+
 // History returns all stored call/SMS records
 func (p *PhoneAgent) History() []CallRecord {
 	p.mu.Lock()
@@ -1,10 +1,14 @@
 package phone
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func simConfig() PhoneConfig {
@@ -71,3 +75,184 @@ func TestPhoneHistory(t *testing.T) {
 		t.Errorf("expected 2 records, got %d", len(history))
 	}
 }
+
+// fakeTwilio fails the first `flaky` message creates with 503, then
+// accepts them and reports "sent" until polled twice, then "delivered".
+func fakeTwilio(t *testing.T, flaky int32) (*httptest.Server, *int32) {
+	t.Helper()
+	var creates, polls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/Messages.json"):
+			if atomic.AddInt32(&creates, 1) <= flaky {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprint(w, `{"code":20503,"message":"Service unavailable"}`)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"sid":"SMreal123","status":"queued"}`)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/Messages/SMreal123.json"):
+			status := "sent"
+			if atomic.AddInt32(&polls, 1) >= 2 {
+				status = "delivered"
+			}
+			fmt.Fprintf(w, `{"sid":"SMreal123","status":%q,"price":"-0.0079"}`, status)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return srv, &creates
+}
+
+func twilioConfig(url string, rateLimit int) PhoneConfig {
+	return PhoneConfig{
+		AccountSID: "AC1", AuthToken: "tok", FromNumber: "+1000", RateLimit: rateLimit,
+		APIBaseURL: url, RetryBackoff: time.Millisecond, PollInterval: 5 * time.Millisecond,
+		StatusTimeout: time.Second,
+	}
+}
+
+func TestPhoneSMSRetryAndDeliveryStatus(t *testing.T) {
+	srv, creates := fakeTwilio(t, 1)
+	defer srv.Close()
+	p := New(twilioConfig(srv.URL, 10))
+	final := make(chan CallRecord, 1)
+	p.SetStatusHandler(func(rec CallRecord) { final <- rec })
+
+	rec, err := p.SMS("+1555", "drift alert")
+	if err != nil {
+		t.Fatalf("SMS: %v", err)
+	}
+	if *creates != 2 {
+		t.Errorf("expected one retry, got %d creates", *creates)
+	}
+	if rec.SID != "SMreal123" || rec.Status != CallQueued {
+		t.Errorf("record should carry Twilio's SID and status, got %s/%s", rec.SID, rec.Status)
+	}
+	select {
+	case got := <-final:
+		if got.Status != CallDelivered || got.Cost != 0.0079 {
+			t.Errorf("final record = %+v", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("status handler never fired")
+	}
+	if h := p.History(); h[len(h)-1].Status != CallDelivered {
+		t.Errorf("history not updated: %+v", h[len(h)-1])
+	}
+}
+
+func TestPhoneSMSRetryRespectsRateLimit(t *testing.T) {
+	srv, creates := fakeTwilio(t, 5)
+	defer srv.Close()
+	p := New(twilioConfig(srv.URL, 2))
+	rec, err := p.SMS("+1555", "drift alert")
+	if err == nil || !strings.Contains(err.Error(), "rate limit") {
+		t.Fatalf("expected retry to stop at the rate limit, got %v", err)
+	}
+	if rec.Status != CallFailed || *creates != 2 {
+		t.Errorf("status=%s creates=%d, want failed after 2 attempts", rec.Status, *creates)
+	}
+}
+
+func TestPhoneNegativeMaxRetriesDisablesRetries(t *testing.T) {
+	srv, creates := fakeTwilio(t, 1)
+	defer srv.Close()
+	cfg := twilioConfig(srv.URL, 10)
+	cfg.MaxRetries = -1
+	p := New(cfg)
+	if _, err := p.SMS("+1555", "drift alert"); err == nil {
+		t.Fatal("expected the transient failure to be returned without a retry")
+	}
+	if *creates != 1 {
+		t.Errorf("expected a single attempt, got %d creates", *creates)
+	}
+}
+
+func TestPhoneIVR(t *testing.T) {
+	cfg := simConfig()
+	cfg.AllowAllCallers = true
+	p := New(cfg)
+	p.SetIVR(IVRMenu{Options: []IVROption{
+		{Digit: "1", Label: "the drift report", Run: func(string) string { return "No drift detected & all good." }},
+		{Digit: "2", Label: "your budget", Run: func(string) string { return "You have spent 3 dollars." }},
+	}})
+	post := func(form string) string {
+		req := httptest.NewRequest(http.MethodPost, "/phone/webhook", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		p.HandleWebhook(w, req)
+		return w.Body.String()
+	}
+
+	menu := post("CallSid=CA1&From=%2B1555&To=%2B1000")
+	if !strings.Contains(menu, `<Gather numDigits="1" action="/phone/webhook"`) ||
+		!strings.Contains(menu, "Press 1 for the drift report.") {
+		t.Fatalf("unexpected menu TwiML: %s", menu)
+	}
+	if again := post("CallSid=CA1&From=%2B1555&Digits=9"); !strings.Contains(again, "not an option") {
+		t.Errorf("invalid digit should replay the menu: %s", again)
+	}
+	reply := post("CallSid=CA1&From=%2B1555&Digits=1")
+	if !strings.Contains(reply, "No drift detected &amp; all good.") {
+		t.Errorf("expected escaped command result, got %s", reply)
+	}
+	h := p.History()
+	if len(h) != 1 || h[0].Selection != "1" || h[0].Transcript == "" {
+		t.Errorf("selection not recorded on the call: %+v", h)
+	}
+}
+
+func TestPhoneIVRWithoutAllowedCallersRefusesEveryone(t *testing.T) {
+	p := New(simConfig())
+	var ran int
+	p.SetIVR(IVRMenu{Options: []IVROption{
+		{Digit: "1", Label: "the drift report", Run: func(string) string { ran++; return "No drift detected." }},
+	}})
+	req := httptest.NewRequest(http.MethodPost, "/phone/webhook", strings.NewReader("CallSid=CA1&From=%2B1555&Digits=1"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	p.HandleWebhook(w, req)
+	if ran != 0 || !strings.Contains(w.Body.String(), "private") {
+		t.Errorf("empty AllowedCallers let a caller run a command: ran=%d body=%s", ran, w.Body.String())
+	}
+}
+
+func TestPhoneWebhookSignature(t *testing.T) {
+	const hook = "https://nexus.example.com/phone/webhook"
+	p := New(PhoneConfig{AuthToken: "secret", WebhookURL: hook, AllowedCallers: []string{"+1555"}})
+	var ran int
+	p.SetIVR(IVRMenu{Options: []IVROption{
+		{Digit: "2", Label: "your budget", Run: func(string) string { ran++; return "You have spent 3 dollars." }},
+	}})
+	post := func(form, sig string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/phone/webhook", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if sig != "" {
+			req.Header.Set("X-Twilio-Signature", sig)
+		}
+		w := httptest.NewRecorder()
+		p.HandleWebhook(w, req)
+		return w
+	}
+	sign := func(form string) string {
+		v, _ := url.ParseQuery(form)
+		return twilioSignature("secret", hook, v)
+	}
+
+	form := "CallSid=CA1&Digits=2&From=%2B1555"
+	if w := post(form, ""); w.Code != http.StatusForbidden || ran != 0 {
+		t.Fatalf("unsigned request: code=%d ran=%d", w.Code, ran)
+	}
+	if w := post(form, sign("CallSid=CA1&Digits=1&From=%2B1555")); w.Code != http.StatusForbidden || ran != 0 {
+		t.Fatalf("signature for other params accepted: code=%d", w.Code)
+	}
+	if w := post(form, sign(form)); w.Code != http.StatusOK || ran != 1 || !strings.Contains(w.Body.String(), "3 dollars") {
+		t.Fatalf("signed request: code=%d ran=%d body=%s", w.Code, ran, w.Body.String())
+	}
+	other := "CallSid=CA2&Digits=2&From=%2B1666"
+	if w := post(other, sign(other)); !strings.Contains(w.Body.String(), "private") || ran != 1 {
+		t.Errorf("caller outside AllowedCallers ran a command: %s", w.Body.String())
+	}
+}
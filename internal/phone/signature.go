@@ -0,0 +1,77 @@
+package phone
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// twilioSignature computes X-Twilio-Signature for a request to fullURL with
+// the given POST parameters: base64(HMAC-SHA1(authToken, url + each
+// parameter name and value, sorted by name)).
+func twilioSignature(authToken, fullURL string, params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	sb.WriteString(fullURL)
+	for _, k := range keys {
+		vals := append([]string(nil), params[k]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			sb.WriteString(k)
+			sb.WriteString(v)
+		}
+	}
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(sb.String()))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// requestURL is the URL Twilio signed: WebhookURL when configured, since a
+// proxy in front of NEXUS changes scheme and host, else the URL as received.
+func (p *PhoneAgent) requestURL(r *http.Request) string {
+	if p.cfg.WebhookURL != "" {
+		return p.cfg.WebhookURL
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
+
+// verifySignature reports whether r carries a valid X-Twilio-Signature.
+// r.ParseForm must have been called. Simulated agents without an AuthToken
+// accept unsigned requests; real ones reject everything they cannot verify.
+func (p *PhoneAgent) verifySignature(r *http.Request) bool {
+	if p.cfg.AuthToken == "" {
+		return p.cfg.Simulated
+	}
+	got := r.Header.Get("X-Twilio-Signature")
+	if got == "" {
+		return false
+	}
+	want := twilioSignature(p.cfg.AuthToken, p.requestURL(r), r.PostForm)
+	return hmac.Equal([]byte(got), []byte(want))
+}
+
+// callerAllowed reports whether from may use the IVR menu. With no
+// AllowedCallers, only AllowAllCallers admits anyone.
+func (p *PhoneAgent) callerAllowed(from string) bool {
+	if p.cfg.AllowAllCallers {
+		return true
+	}
+	for _, n := range p.cfg.AllowedCallers {
+		if n == from {
+			return true
+		}
+	}
+	return false
+}
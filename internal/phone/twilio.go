@@ -0,0 +1,198 @@
+package phone
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// twilioResource is the part of a Twilio Message or Call resource NEXUS uses.
+type twilioResource struct {
+	SID          string `json:"sid"`
+	Status       string `json:"status"`
+	Duration     string `json:"duration"` // calls only; seconds as a string
+	Price        string `json:"price"`    // negative, e.g. "-0.0075"; empty until billed
+	ErrorCode    *int   `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// twilioError is a non-2xx Twilio API response.
+type twilioError struct {
+	HTTPStatus int
+	Code       int    `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *twilioError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("twilio error %d (HTTP %d): %s", e.Code, e.HTTPStatus, e.Message)
+	}
+	return fmt.Sprintf("twilio error: HTTP %d", e.HTTPStatus)
+}
+
+// transient reports whether a failed request is worth retrying: transport
+// errors, rate limiting and server errors. An undecodable 2xx body is not
+// retried, since the message may already have been sent.
+func transient(err error) bool {
+	var te *twilioError
+	if errors.As(err, &te) {
+		return te.HTTPStatus == http.StatusTooManyRequests || te.HTTPStatus >= 500
+	}
+	var ue *url.Error
+	return errors.As(err, &ue)
+}
+
+// finalStatus reports whether Twilio will not change status again.
+func finalStatus(s CallStatus) bool {
+	switch s {
+	case CallDelivered, CallUndelivered, CallFailed, CallCompleted,
+		CallBusy, CallNoAnswer, CallCanceled, "received", "read":
+		return true
+	}
+	return false
+}
+
+func (p *PhoneAgent) twilioCall(to, message string, rec *CallRecord) error {
+	twiml := fmt.Sprintf(`<Response><Say voice="alice">%s</Say></Response>`, xmlEscape(message))
+	data := url.Values{}
+	data.Set("To", to)
+	data.Set("From", p.cfg.FromNumber)
+	data.Set("Twiml", twiml)
+	res, err := p.createWithRetry("Calls", data)
+	if err != nil {
+		return err
+	}
+	applyResource(rec, res)
+	return nil
+}
+
+func (p *PhoneAgent) twilioSMS(to, body string, rec *CallRecord) error {
+	data := url.Values{}
+	data.Set("To", to)
+	data.Set("From", p.cfg.FromNumber)
+	data.Set("Body", body)
+	res, err := p.createWithRetry("Messages", data)
+	if err != nil {
+		return err
+	}
+	applyResource(rec, res)
+	return nil
+}
+
+// createWithRetry POSTs a new resource, retrying transient failures with
+// exponential backoff. Every retry counts against the hourly rate limit, so
+// a flapping API cannot turn one alert into a burst of calls.
+func (p *PhoneAgent) createWithRetry(endpoint string, data url.Values) (*twilioResource, error) {
+	backoff := p.cfg.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		res, err := p.twilioRequest(http.MethodPost, endpoint+".json", data)
+		if err == nil {
+			return res, nil
+		}
+		if !transient(err) || attempt >= p.cfg.MaxRetries {
+			return nil, err
+		}
+		if rlErr := p.checkRateLimit(); rlErr != nil {
+			return nil, fmt.Errorf("%w (retry skipped: %v)", err, rlErr)
+		}
+		log.Warn().Err(err).Int("attempt", attempt+1).Str("endpoint", endpoint).Msg("phone: retrying Twilio request")
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// pollStatus follows an outbound resource until Twilio reports a final
+// status or StatusTimeout passes, updating the stored record as it goes.
+func (p *PhoneAgent) pollStatus(endpoint, sid string) {
+	deadline := time.Now().Add(p.cfg.StatusTimeout)
+	var rec CallRecord
+	for time.Now().Before(deadline) {
+		time.Sleep(p.cfg.PollInterval)
+		res, err := p.twilioRequest(http.MethodGet, endpoint+"/"+url.PathEscape(sid)+".json", nil)
+		if err != nil {
+			log.Debug().Err(err).Str("sid", sid).Msg("phone: status poll failed")
+			continue
+		}
+		var ok bool
+		rec, ok = p.update(sid, func(r *CallRecord) { applyResource(r, res) })
+		if !ok {
+			return
+		}
+		if finalStatus(rec.Status) {
+			break
+		}
+	}
+	if rec.SID == "" {
+		rec, _ = p.update(sid, func(*CallRecord) {})
+	}
+	if p.onStatus != nil {
+		p.onStatus(rec)
+	}
+}
+
+// applyResource copies Twilio's view of a resource onto rec.
+func applyResource(rec *CallRecord, res *twilioResource) {
+	if res.SID != "" {
+		rec.SID = res.SID
+	}
+	if res.Status != "" {
+		rec.Status = CallStatus(res.Status)
+	}
+	if d, err := strconv.Atoi(res.Duration); err == nil {
+		rec.Duration = d
+	}
+	if price, err := strconv.ParseFloat(res.Price, 64); err == nil {
+		rec.Cost = -price
+	}
+	if res.ErrorCode != nil {
+		rec.Transcript = fmt.Sprintf("twilio error %d: %s", *res.ErrorCode, res.ErrorMessage)
+	}
+}
+
+func (p *PhoneAgent) twilioRequest(method, path string, data url.Values) (*twilioResource, error) {
+	apiURL := fmt.Sprintf("%s/2010-04-01/Accounts/%s/%s", p.cfg.APIBaseURL, p.cfg.AccountSID, path)
+	var body io.Reader
+	if data != nil {
+		body = strings.NewReader(data.Encode())
+	}
+	req, err := http.NewRequest(method, apiURL, body)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(p.cfg.AccountSID, p.cfg.AuthToken)
+	if data != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		te := &twilioError{HTTPStatus: resp.StatusCode}
+		_ = json.Unmarshal(raw, te)
+		return nil, te
+	}
+	var res twilioResource
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("twilio: decode response: %w", err)
+	}
+	return &res, nil
+}
+
+var twimlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+
+// xmlEscape makes text safe to embed in TwiML.
+func xmlEscape(s string) string { return twimlEscaper.Replace(s) }
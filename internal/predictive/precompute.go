@@ -0,0 +1,130 @@
+package predictive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TaskExecutor runs a predicted task ahead of time. context is the Context of
+// the user's most recent run of taskType.
+type TaskExecutor func(ctx context.Context, taskType string, context map[string]any) (any, error)
+
+// Option configures a PredictiveEngine.
+type Option func(*PredictiveEngine)
+
+// WithTaskExecutor sets the executor used to pre-compute predicted tasks.
+// Without one, pre-computation only simulates and marks results Simulated.
+func WithTaskExecutor(exec TaskExecutor) Option {
+	return func(p *PredictiveEngine) { p.executor = exec }
+}
+
+// PrecomputedPrediction is a prediction together with its pre-computed result.
+type PrecomputedPrediction struct {
+	Prediction
+	Result      any
+	Error       string
+	ComputeTime time.Duration
+	ComputedAt  time.Time
+	// Simulated is true when no TaskExecutor was registered and Result is
+	// a placeholder rather than real output.
+	Simulated bool
+}
+
+// Precompute runs every current prediction that has not been computed yet
+// and caches the result for GetPrediction. It returns how many tasks ran.
+func (p *PredictiveEngine) Precompute(ctx context.Context, now time.Time) int {
+	p.mu.RLock()
+	preds := p.generatePredictions(now)
+	exec := p.executor
+	todo := make([]Prediction, 0, len(preds))
+	taskCtx := make(map[string]map[string]any)
+	for _, pr := range preds {
+		if cached, ok := p.precomputed[pr.TaskType]; ok && cached.PatternID == pr.PatternID && cached.ExpectedTime.Equal(pr.ExpectedTime) {
+			continue
+		}
+		todo = append(todo, pr)
+		taskCtx[pr.TaskType] = p.lastContext(pr.TaskType)
+	}
+	p.mu.RUnlock()
+
+	ran := 0
+	for _, pr := range todo {
+		if ctx.Err() != nil {
+			break
+		}
+		res := p.executePreComputation(ctx, exec, pr, taskCtx[pr.TaskType])
+		p.mu.Lock()
+		p.precomputed[pr.TaskType] = res
+		p.mu.Unlock()
+		ran++
+	}
+	return ran
+}
+
+// executePreComputation runs exec for pr, or simulates when exec is nil.
+func (p *PredictiveEngine) executePreComputation(ctx context.Context, exec TaskExecutor, pr Prediction, taskCtx map[string]any) *PrecomputedPrediction {
+	res := &PrecomputedPrediction{Prediction: pr}
+	start := time.Now()
+	if exec == nil {
+		res.Result = fmt.Sprintf("simulated pre-computation for %s", pr.TaskType)
+		res.Simulated = true
+	} else {
+		out, err := exec(ctx, pr.TaskType, taskCtx)
+		res.Result = out
+		if err != nil {
+			res.Error = err.Error()
+			log.Warn().Err(err).Str("task", pr.TaskType).Msg("predictive pre-computation failed")
+		}
+	}
+	res.ComputeTime = time.Since(start)
+	res.ComputedAt = time.Now()
+	return res
+}
+
+// GetPrediction returns the latest pre-computed result for taskType.
+func (p *PredictiveEngine) GetPrediction(taskType string) (*PrecomputedPrediction, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	res, ok := p.precomputed[taskType]
+	if !ok {
+		return nil, false
+	}
+	cp := *res
+	return &cp, true
+}
+
+// StartPrecompute learns and pre-computes every interval until ctx is done.
+func (p *PredictiveEngine) StartPrecompute(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.Learn()
+				p.Precompute(ctx, time.Now())
+			}
+		}
+	}()
+}
+
+// lastContext copies the Context of the most recent taskType record.
+// Caller must hold p.mu.
+func (p *PredictiveEngine) lastContext(taskType string) map[string]any {
+	for i := len(p.history) - 1; i >= 0; i-- {
+		if p.history[i].TaskType != taskType {
+			continue
+		}
+		cp := make(map[string]any, len(p.history[i].Context))
+		for k, v := range p.history[i].Context {
+			cp[k] = v
+		}
+		return cp
+	}
+	return map[string]any{}
+}
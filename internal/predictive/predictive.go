@@ -0,0 +1,311 @@
+package predictive
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// PatternType distinguishes how a pattern was learned.
+type PatternType string
+
+const (
+	// PatternTemporal: the task tends to run at the same hour every day.
+	PatternTemporal PatternType = "temporal"
+	// PatternSequential: the task tends to follow another task.
+	PatternSequential PatternType = "sequential"
+)
+
+// maxIntervals caps the observed gaps kept per sequential pattern.
+const maxIntervals = 50
+
+// TaskRecord is one observed user task.
+type TaskRecord struct {
+	TaskType  string
+	Timestamp time.Time
+	Context   map[string]any
+}
+
+// UserPattern is a learned habit.
+type UserPattern struct {
+	ID          string
+	Type        PatternType
+	TaskType    string // the predicted task
+	Trigger     string // sequential only: the task that precedes TaskType
+	Hour        int    // temporal only: hour of day (local time)
+	Occurrences int
+	Confidence  float64
+	LastSeen    time.Time
+	// Intervals holds the most recent observed gaps between Trigger and
+	// TaskType (sequential only); MedianInterval is their median.
+	Intervals      []time.Duration
+	MedianInterval time.Duration
+}
+
+// Prediction is a task the user is expected to run.
+type Prediction struct {
+	TaskType     string
+	ExpectedTime time.Time
+	Confidence   float64
+	PatternID    string
+}
+
+// Config tunes the pattern learner.
+type Config struct {
+	HistorySize    int           // TaskRecords retained (default 1000)
+	MinOccurrences int           // observations before a pattern predicts (default 3)
+	MinConfidence  float64       // minimum confidence to predict (default 0.5)
+	SequenceWindow time.Duration // max gap for A→B to count as a sequence (default 2h)
+	// DecayHalfLife halves a pattern's confidence for every half-life since
+	// it was LastSeen, so abandoned habits fade. Zero disables decay.
+	DecayHalfLife time.Duration
+	// DecayFloor prunes patterns whose decayed confidence drops below it
+	// (default 0.1; only used with DecayHalfLife).
+	DecayFloor float64
+}
+
+// PredictiveEngine learns temporal and sequential habits from the user's
+// task history and predicts what they will ask for next, so the event-driven
+// Engine can pre-compute it.
+type PredictiveEngine struct {
+	mu       sync.RWMutex
+	cfg      Config
+	history  []TaskRecord
+	patterns map[string]*UserPattern
+
+	executor    TaskExecutor
+	precomputed map[string]*PrecomputedPrediction // by task type
+
+	dbPath string
+	db     *sql.DB // nil unless WithDatabase succeeded
+}
+
+// NewPredictiveEngine creates a pattern learner with cfg, filling zero fields with defaults.
+func NewPredictiveEngine(cfg Config, opts ...Option) *PredictiveEngine {
+	if cfg.HistorySize <= 0 {
+		cfg.HistorySize = 1000
+	}
+	if cfg.MinOccurrences <= 0 {
+		cfg.MinOccurrences = 3
+	}
+	if cfg.MinConfidence <= 0 {
+		cfg.MinConfidence = 0.5
+	}
+	if cfg.SequenceWindow <= 0 {
+		cfg.SequenceWindow = 2 * time.Hour
+	}
+	if cfg.DecayFloor <= 0 {
+		cfg.DecayFloor = 0.1
+	}
+	p := &PredictiveEngine{
+		cfg:         cfg,
+		patterns:    make(map[string]*UserPattern),
+		precomputed: make(map[string]*PrecomputedPrediction),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.dbPath != "" {
+		p.openStore()
+	}
+	return p
+}
+
+// RecordTask records that the user just ran taskType.
+func (p *PredictiveEngine) RecordTask(taskType string, ctx map[string]any) {
+	p.RecordTaskAt(taskType, time.Now(), ctx)
+}
+
+// RecordTaskAt records a task observed at a specific time (e.g. when backfilling).
+func (p *PredictiveEngine) RecordTaskAt(taskType string, at time.Time, ctx map[string]any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.history = append(p.history, TaskRecord{TaskType: taskType, Timestamp: at, Context: ctx})
+	sort.SliceStable(p.history, func(i, j int) bool { return p.history[i].Timestamp.Before(p.history[j].Timestamp) })
+	if len(p.history) > p.cfg.HistorySize {
+		p.history = p.history[len(p.history)-p.cfg.HistorySize:]
+	}
+}
+
+// Learn re-derives all patterns from the current history and, with
+// WithDatabase, flushes history and patterns to disk.
+func (p *PredictiveEngine) Learn() {
+	p.LearnAt(time.Now())
+}
+
+// LearnAt is Learn with an explicit current time for confidence decay.
+func (p *PredictiveEngine) LearnAt(now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.learnPatterns(now)
+	if p.db != nil {
+		if err := p.flush(); err != nil {
+			log.Warn().Err(err).Msg("predictive: failed to persist patterns")
+		}
+	}
+}
+
+// Patterns returns a snapshot of the learned patterns.
+func (p *PredictiveEngine) Patterns() []UserPattern {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	out := make([]UserPattern, 0, len(p.patterns))
+	for _, pat := range p.patterns {
+		cp := *pat
+		cp.Intervals = append([]time.Duration(nil), pat.Intervals...)
+		out = append(out, cp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Predict returns upcoming predicted tasks after now, soonest first.
+func (p *PredictiveEngine) Predict(now time.Time) []Prediction {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.generatePredictions(now)
+}
+
+// learnPatterns rebuilds p.patterns, decaying and pruning stale ones.
+// Caller must hold p.mu.
+func (p *PredictiveEngine) learnPatterns(now time.Time) {
+	learned := make(map[string]*UserPattern)
+	detected := append(p.detectTemporalPatterns(), p.detectSequentialPatterns()...)
+	for _, pat := range detected {
+		if p.cfg.DecayHalfLife > 0 {
+			if age := now.Sub(pat.LastSeen); age > 0 {
+				pat.Confidence *= math.Pow(0.5, float64(age)/float64(p.cfg.DecayHalfLife))
+			}
+			if pat.Confidence < p.cfg.DecayFloor {
+				continue
+			}
+		}
+		learned[pat.ID] = pat
+	}
+	p.patterns = learned
+}
+
+// detectTemporalPatterns finds tasks that recur at the same hour on distinct days.
+func (p *PredictiveEngine) detectTemporalPatterns() []*UserPattern {
+	days := make(map[string]bool)
+	seen := make(map[string]map[string]bool) // "task@hour" → set of days
+	last := make(map[string]time.Time)
+	for _, r := range p.history {
+		day := r.Timestamp.Format("2006-01-02")
+		days[day] = true
+		key := fmt.Sprintf("%s@%02d", r.TaskType, r.Timestamp.Hour())
+		if seen[key] == nil {
+			seen[key] = make(map[string]bool)
+		}
+		seen[key][day] = true
+		if r.Timestamp.After(last[key]) {
+			last[key] = r.Timestamp
+		}
+	}
+	var out []*UserPattern
+	for key, d := range seen {
+		rec := last[key]
+		out = append(out, &UserPattern{
+			ID:          "temporal:" + key,
+			Type:        PatternTemporal,
+			TaskType:    key[:len(key)-3],
+			Hour:        rec.Hour(),
+			Occurrences: len(d),
+			Confidence:  float64(len(d)) / float64(len(days)),
+			LastSeen:    rec,
+		})
+	}
+	return out
+}
+
+// detectSequentialPatterns finds A→B task pairs within SequenceWindow and
+// learns the distribution of the gap between them.
+func (p *PredictiveEngine) detectSequentialPatterns() []*UserPattern {
+	triggers := make(map[string]int)
+	byKey := make(map[string]*UserPattern)
+	for i := 0; i+1 < len(p.history); i++ {
+		a, b := p.history[i], p.history[i+1]
+		triggers[a.TaskType]++
+		gap := b.Timestamp.Sub(a.Timestamp)
+		if a.TaskType == b.TaskType || gap > p.cfg.SequenceWindow {
+			continue
+		}
+		key := a.TaskType + "->" + b.TaskType
+		pat, ok := byKey[key]
+		if !ok {
+			pat = &UserPattern{ID: "sequential:" + key, Type: PatternSequential, TaskType: b.TaskType, Trigger: a.TaskType}
+			byKey[key] = pat
+		}
+		pat.Occurrences++
+		pat.LastSeen = b.Timestamp
+		pat.Intervals = append(pat.Intervals, gap)
+		if len(pat.Intervals) > maxIntervals {
+			pat.Intervals = pat.Intervals[len(pat.Intervals)-maxIntervals:]
+		}
+	}
+	out := make([]*UserPattern, 0, len(byKey))
+	for _, pat := range byKey {
+		pat.Confidence = float64(pat.Occurrences) / float64(triggers[pat.Trigger])
+		pat.MedianInterval = medianDuration(pat.Intervals)
+		out = append(out, pat)
+	}
+	return out
+}
+
+// generatePredictions turns trusted patterns into predictions. Caller must hold p.mu.
+func (p *PredictiveEngine) generatePredictions(now time.Time) []Prediction {
+	var lastTask *TaskRecord
+	if len(p.history) > 0 {
+		lastTask = &p.history[len(p.history)-1]
+	}
+	var out []Prediction
+	for _, pat := range p.patterns {
+		if pat.Occurrences < p.cfg.MinOccurrences || pat.Confidence < p.cfg.MinConfidence {
+			continue
+		}
+		var expected time.Time
+		switch pat.Type {
+		case PatternTemporal:
+			expected = time.Date(now.Year(), now.Month(), now.Day(), pat.Hour, 0, 0, 0, now.Location())
+			if !expected.After(now) {
+				expected = expected.Add(24 * time.Hour)
+			}
+		case PatternSequential:
+			if lastTask == nil || lastTask.TaskType != pat.Trigger {
+				continue
+			}
+			// Use the learned gap — if A is usually followed by B after 30m, predict 30m.
+			expected = lastTask.Timestamp.Add(pat.MedianInterval)
+			if expected.Before(now) {
+				continue
+			}
+		}
+		out = append(out, Prediction{
+			TaskType:     pat.TaskType,
+			ExpectedTime: expected,
+			Confidence:   pat.Confidence,
+			PatternID:    pat.ID,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ExpectedTime.Before(out[j].ExpectedTime) })
+	return out
+}
+
+// medianDuration returns the median of ds (0 for an empty slice).
+func medianDuration(ds []time.Duration) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	s := append([]time.Duration(nil), ds...)
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+	mid := len(s) / 2
+	if len(s)%2 == 0 {
+		return (s[mid-1] + s[mid]) / 2
+	}
+	return s[mid]
+}
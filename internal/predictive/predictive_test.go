@@ -0,0 +1,170 @@
+package predictive
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSequentialPredictionUsesLearnedInterval(t *testing.T) {
+	p := NewPredictiveEngine(Config{})
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	// "standup" is followed by "triage" after ~30 minutes, every day.
+	for day, gap := range []time.Duration{28 * time.Minute, 30 * time.Minute, 32 * time.Minute} {
+		start := base.Add(time.Duration(day) * 24 * time.Hour)
+		p.RecordTaskAt("standup", start, nil)
+		p.RecordTaskAt("triage", start.Add(gap), nil)
+	}
+	last := base.Add(3 * 24 * time.Hour)
+	p.RecordTaskAt("standup", last, nil)
+	p.Learn()
+
+	var got *Prediction
+	for _, pr := range p.Predict(last.Add(time.Minute)) {
+		if pr.PatternID == "sequential:standup->triage" {
+			pr := pr
+			got = &pr
+		}
+	}
+	if got == nil {
+		t.Fatal("expected a sequential prediction for triage")
+	}
+	if want := last.Add(30 * time.Minute); !got.ExpectedTime.Equal(want) {
+		t.Errorf("ExpectedTime = %s, want %s", got.ExpectedTime, want)
+	}
+}
+
+func TestSequentialPatternIgnoresGapsOutsideWindow(t *testing.T) {
+	p := NewPredictiveEngine(Config{SequenceWindow: time.Hour})
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	p.RecordTaskAt("a", base, nil)
+	p.RecordTaskAt("b", base.Add(3*time.Hour), nil)
+	p.Learn()
+	for _, pat := range p.Patterns() {
+		if pat.Type == PatternSequential {
+			t.Errorf("unexpected sequential pattern %s", pat.ID)
+		}
+	}
+}
+
+func TestMedianDuration(t *testing.T) {
+	if got := medianDuration([]time.Duration{3, 1, 2}); got != 2 {
+		t.Errorf("odd median = %d, want 2", got)
+	}
+	if got := medianDuration([]time.Duration{4, 1, 2, 3}); got != 2 {
+		t.Errorf("even median = %d, want 2", got)
+	}
+	if got := medianDuration(nil); got != 0 {
+		t.Errorf("empty median = %d, want 0", got)
+	}
+}
+
+// dailyHabit records taskType at 09:00 on three consecutive days.
+func dailyHabit(p *PredictiveEngine, taskType string, ctx map[string]any) time.Time {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	for day := 0; day < 3; day++ {
+		p.RecordTaskAt(taskType, base.AddDate(0, 0, day), ctx)
+	}
+	p.Learn()
+	return base.AddDate(0, 0, 2).Add(time.Hour)
+}
+
+func TestPrecomputeUsesTaskExecutor(t *testing.T) {
+	var gotCtx map[string]any
+	exec := func(ctx context.Context, taskType string, taskCtx map[string]any) (any, error) {
+		gotCtx = taskCtx
+		time.Sleep(5 * time.Millisecond)
+		return "report for " + taskType, nil
+	}
+	p := NewPredictiveEngine(Config{}, WithTaskExecutor(exec))
+	now := dailyHabit(p, "digest", map[string]any{"inbox": "work"})
+
+	if n := p.Precompute(context.Background(), now); n != 1 {
+		t.Fatalf("expected 1 pre-computed task, got %d", n)
+	}
+	res, ok := p.GetPrediction("digest")
+	if !ok {
+		t.Fatal("expected cached prediction")
+	}
+	if res.Simulated || res.Result != "report for digest" || res.ComputeTime < 5*time.Millisecond {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if gotCtx["inbox"] != "work" {
+		t.Errorf("executor should receive the task context, got %v", gotCtx)
+	}
+	if n := p.Precompute(context.Background(), now); n != 0 {
+		t.Errorf("already computed prediction should not rerun, ran %d", n)
+	}
+}
+
+func TestPrecomputeWithoutExecutorIsSimulated(t *testing.T) {
+	p := NewPredictiveEngine(Config{})
+	now := dailyHabit(p, "digest", nil)
+	p.Precompute(context.Background(), now)
+	res, ok := p.GetPrediction("digest")
+	if !ok || !res.Simulated {
+		t.Fatalf("expected simulated result, got %+v", res)
+	}
+}
+
+func TestPatternsPersistAcrossRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "predictive.db")
+	p := NewPredictiveEngine(Config{HistorySize: 6}, WithDatabase(path))
+	now := dailyHabit(p, "digest", map[string]any{"inbox": "work"})
+	p.RecordTaskAt("a", now.Add(-3*time.Minute), nil)
+	p.RecordTaskAt("b", now.Add(-2*time.Minute), nil)
+	p.RecordTaskAt("c", now.Add(-time.Minute), nil)
+	p.Learn()
+	want := p.Patterns()
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := NewPredictiveEngine(Config{HistorySize: 4}, WithDatabase(path))
+	defer restarted.Close()
+	got := restarted.Patterns()
+	if len(got) != len(want) || len(got) == 0 {
+		t.Fatalf("expected %d restored patterns, got %d", len(want), len(got))
+	}
+	if len(restarted.history) != 4 || restarted.history[3].TaskType != "c" {
+		t.Errorf("history should be the newest 4 records, got %+v", restarted.history)
+	}
+	if restarted.history[0].Context["inbox"] != "work" {
+		t.Errorf("task context not restored: %+v", restarted.history[0])
+	}
+	found := false
+	for _, pr := range restarted.Predict(now) {
+		found = found || pr.TaskType == "digest"
+	}
+	if !found {
+		t.Error("restored temporal pattern should predict without relearning")
+	}
+}
+
+func TestConfidenceDecaysAndPrunesStalePatterns(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	p := NewPredictiveEngine(Config{DecayHalfLife: week})
+	lastRun := dailyHabit(p, "morning-report", nil)
+
+	p.LearnAt(lastRun)
+	fresh := p.Patterns()
+	if len(fresh) != 1 || fresh[0].Confidence < 0.99 {
+		t.Fatalf("expected one undecayed pattern, got %+v", fresh)
+	}
+
+	p.LearnAt(lastRun.Add(week))
+	if got := p.Patterns(); len(got) != 1 || got[0].Confidence > 0.51 || got[0].Confidence < 0.49 {
+		t.Errorf("one half-life should halve confidence, got %+v", got)
+	}
+
+	p.LearnAt(lastRun.Add(2 * week))
+	if len(p.Patterns()) != 1 || len(p.Predict(lastRun.Add(2*week))) != 0 {
+		t.Error("pattern decayed below MinConfidence should be kept but no longer predicted")
+	}
+
+	p.LearnAt(lastRun.Add(4 * week))
+	if got := p.Patterns(); len(got) != 0 {
+		t.Errorf("pattern unused for 4 half-lives should be pruned, got %+v", got)
+	}
+}
@@ -0,0 +1,180 @@
+package predictive
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/rs/zerolog/log"
+)
+
+// WithDatabase persists learned patterns and the rolling task history to
+// the SQLite file at path. NewPredictiveEngine loads any saved state (at
+// most HistorySize records) and every Learn flushes the current state back.
+// If the database cannot be opened the engine logs a warning and runs in
+// memory only.
+func WithDatabase(path string) Option {
+	return func(p *PredictiveEngine) { p.dbPath = path }
+}
+
+// openDB opens (or creates) the database, creating the file with 0600
+// before sql.Open as the other NEXUS stores do.
+func openDB(path string) (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("predictive: create db file: %w", err)
+	}
+	f.Close()
+	db, err := sql.Open("sqlite3", path+"?_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS task_history (
+			id        INTEGER PRIMARY KEY AUTOINCREMENT,
+			task_type TEXT NOT NULL,
+			ts        INTEGER NOT NULL,
+			context   TEXT NOT NULL DEFAULT '{}'
+		);
+		CREATE INDEX IF NOT EXISTS idx_task_history_ts ON task_history(ts);
+		CREATE TABLE IF NOT EXISTS patterns (
+			id              TEXT PRIMARY KEY,
+			type            TEXT NOT NULL,
+			task_type       TEXT NOT NULL,
+			trigger_task    TEXT NOT NULL DEFAULT '',
+			hour            INTEGER NOT NULL DEFAULT 0,
+			occurrences     INTEGER NOT NULL DEFAULT 0,
+			confidence      REAL NOT NULL DEFAULT 0,
+			last_seen       INTEGER NOT NULL DEFAULT 0,
+			intervals       TEXT NOT NULL DEFAULT '[]',
+			median_interval INTEGER NOT NULL DEFAULT 0
+		);
+	`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("predictive: migrate: %w", err)
+	}
+	return db, nil
+}
+
+// load reads saved history and patterns. Caller must hold p.mu.
+func (p *PredictiveEngine) load() error {
+	rows, err := p.db.Query(`SELECT task_type, ts, context FROM task_history ORDER BY ts DESC, id DESC LIMIT ?`, p.cfg.HistorySize)
+	if err != nil {
+		return err
+	}
+	var history []TaskRecord
+	for rows.Next() {
+		var r TaskRecord
+		var ts int64
+		var ctxJSON string
+		if err := rows.Scan(&r.TaskType, &ts, &ctxJSON); err != nil {
+			rows.Close()
+			return err
+		}
+		r.Timestamp = time.Unix(0, ts)
+		_ = json.Unmarshal([]byte(ctxJSON), &r.Context)
+		history = append(history, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+	p.history = history
+
+	rows, err = p.db.Query(`SELECT id, type, task_type, trigger_task, hour, occurrences, confidence, last_seen, intervals, median_interval FROM patterns`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var pat UserPattern
+		var lastSeen, median int64
+		var intervalsJSON string
+		if err := rows.Scan(&pat.ID, &pat.Type, &pat.TaskType, &pat.Trigger, &pat.Hour, &pat.Occurrences,
+			&pat.Confidence, &lastSeen, &intervalsJSON, &median); err != nil {
+			return err
+		}
+		pat.LastSeen = time.Unix(0, lastSeen)
+		pat.MedianInterval = time.Duration(median)
+		_ = json.Unmarshal([]byte(intervalsJSON), &pat.Intervals)
+		p.patterns[pat.ID] = &pat
+	}
+	return rows.Err()
+}
+
+// flush replaces the saved state with the in-memory one. Caller must hold p.mu.
+func (p *PredictiveEngine) flush() error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM task_history`); err != nil {
+		return err
+	}
+	for _, r := range p.history {
+		ctxJSON, err := json.Marshal(r.Context)
+		if err != nil {
+			ctxJSON = []byte("{}")
+		}
+		if _, err := tx.Exec(`INSERT INTO task_history (task_type, ts, context) VALUES (?, ?, ?)`,
+			r.TaskType, r.Timestamp.UnixNano(), string(ctxJSON)); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM patterns`); err != nil {
+		return err
+	}
+	for _, pat := range p.patterns {
+		intervals, _ := json.Marshal(pat.Intervals)
+		if _, err := tx.Exec(
+			`INSERT INTO patterns (id, type, task_type, trigger_task, hour, occurrences, confidence, last_seen, intervals, median_interval)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			pat.ID, string(pat.Type), pat.TaskType, pat.Trigger, pat.Hour, pat.Occurrences,
+			pat.Confidence, pat.LastSeen.UnixNano(), string(intervals), int64(pat.MedianInterval),
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// openStore opens the configured database and loads saved state.
+// Caller must hold p.mu.
+func (p *PredictiveEngine) openStore() {
+	db, err := openDB(p.dbPath)
+	if err != nil {
+		log.Warn().Err(err).Msg("predictive: persistence disabled")
+		return
+	}
+	p.db = db
+	if err := p.load(); err != nil {
+		log.Warn().Err(err).Msg("predictive: failed to load saved patterns")
+	}
+}
+
+// Close flushes state and closes the database, if persistence is enabled.
+func (p *PredictiveEngine) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.db == nil {
+		return nil
+	}
+	err := p.flush()
+	if cerr := p.db.Close(); err == nil {
+		err = cerr
+	}
+	p.db = nil
+	return err
+}
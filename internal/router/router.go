@@ -1,11 +1,14 @@
 // Package router provides an LLM provider router with automatic fallback
-// and a simple circuit-breaker (3 consecutive failures → mark unhealthy).
+// and a simple circuit-breaker (3 consecutive failures → mark unhealthy,
+// then one probe request per cooldown until the provider recovers).
 package router
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -22,6 +25,14 @@ import (
 // marked unhealthy and skipped by the router.
 const circuitThreshold = 3
 
+// circuitCooldown is how long an unhealthy provider is skipped before a
+// single half-open probe request is let through. Each failed probe doubles
+// the cooldown, up to circuitMaxCooldown.
+const (
+	circuitCooldown    = 30 * time.Second
+	circuitMaxCooldown = 10 * time.Minute
+)
+
 // sharedTransport is a tuned http.Transport reused by all router instances.
 var sharedTransport = &http.Transport{
 	MaxIdleConns:        100,
@@ -54,19 +65,71 @@ type Provider struct {
 	Model    string
 	Healthy  bool
 	failures atomic.Int32 // consecutive failure counter — circuit breaker
+
+	mu        sync.Mutex // guards Healthy and the half-open state below
+	openUntil time.Time  // no calls before this while unhealthy
+	cooldown  time.Duration
+	probing   bool // a half-open probe is in flight
 }
 
-// recordFailure increments the failure counter and marks unhealthy at threshold.
+// recordFailure increments the failure counter and marks unhealthy at
+// threshold. A failed half-open probe reopens the circuit for twice as long.
 func (p *Provider) recordFailure() {
-	if p.failures.Add(1) >= circuitThreshold {
-		p.Healthy = false
+	n := p.failures.Add(1)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case p.probing:
+		p.probing = false
+		p.open(min(p.cooldown*2, circuitMaxCooldown))
+		log.Warn().Str("provider", p.Name).Dur("cooldown", p.cooldown).Msg("provider probe failed")
+	case p.Healthy && n >= circuitThreshold:
+		p.open(circuitCooldown)
 	}
 }
 
 // recordSuccess resets the circuit breaker.
 func (p *Provider) recordSuccess() {
 	p.failures.Store(0)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.probing {
+		log.Info().Str("provider", p.Name).Msg("provider recovered")
+	}
 	p.Healthy = true
+	p.probing = false
+	p.cooldown = 0
+}
+
+// markUnhealthy opens the circuit, e.g. after a failed health check.
+func (p *Provider) markUnhealthy() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Healthy {
+		p.open(circuitCooldown)
+	}
+}
+
+// open marks p unhealthy for cooldown. Caller must hold p.mu.
+func (p *Provider) open(cooldown time.Duration) {
+	p.Healthy = false
+	p.cooldown = cooldown
+	p.openUntil = time.Now().Add(cooldown)
+}
+
+// allow reports whether a call may go to p: always while healthy, and once
+// the cooldown has passed for exactly one probe while unhealthy.
+func (p *Provider) allow(now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Healthy {
+		return true
+	}
+	if p.probing || now.Before(p.openUntil) {
+		return false
+	}
+	p.probing = true
+	return true
 }
 
 // Router selects the best available LLM provider with automatic fallback.
@@ -74,6 +137,44 @@ type Router struct {
 	primary   *Provider
 	fallbacks []*Provider
 	client    *http.Client
+	maxTokens int // default max_tokens when CompletionOptions.MaxTokens is 0
+	gate      BudgetGate
+	inject    ContextInjector
+	onUsage   UsageRecorder
+}
+
+// BudgetGate is consulted before every completion; when it returns false
+// the call fails with ErrBudgetPaused. Wrap telemetry.CostTracker.Gate for
+// the daemon's user.
+type BudgetGate func() (allowed bool, reason string)
+
+// UsageRecorder is told the token usage of every successful completion,
+// e.g. to feed telemetry.CostTracker.Record.
+type UsageRecorder func(provider, model string, tokensIn, tokensOut int)
+
+// ErrBudgetPaused is returned while the budget gate blocks LLM calls.
+var ErrBudgetPaused = errors.New("router: LLM calls paused")
+
+// ContextInjector returns local context relevant to userMsg; it is
+// prepended to the system prompt of every completion. A non-empty context
+// returned with an error is still used. enrich.Injector.Inject fits.
+type ContextInjector func(ctx context.Context, userMsg string) (string, error)
+
+// CompletionOptions tunes a single completion. Zero values mean "use the
+// default": MaxTokens falls back to LLMConfig.MaxTokens (or 2048), and a nil
+// Temperature or TopP is omitted so the provider default applies. Temperature
+// and TopP are pointers so that an explicit 0 (deterministic output) can be
+// requested; build them with Float.
+type CompletionOptions struct {
+	Temperature *float64
+	MaxTokens   int
+	TopP        *float64
+	Stop        []string
+}
+
+// Float returns a pointer to v, for CompletionOptions.Temperature and TopP.
+func Float(v float64) *float64 {
+	return &v
 }
 
 // New creates a new LLM router from config.
@@ -82,14 +183,63 @@ func New(cfg types.LLMConfig) *Router {
 	if timeout == 0 {
 		timeout = 120 * time.Second
 	}
-	return &Router{
+	maxTokens := cfg.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = 2048
+	}
+	r := &Router{
 		primary:   providerFromConfig(cfg),
 		fallbacks: []*Provider{},
+		maxTokens: maxTokens,
 		client: &http.Client{
 			Timeout:   timeout,
 			Transport: sharedTransport,
 		},
 	}
+	for _, fb := range cfg.Fallbacks {
+		r.AddFallback(providerFromConfig(fb))
+	}
+	if cfg.Fallback != "" {
+		r.AddFallback(providerFromConfig(types.LLMConfig{Provider: cfg.Fallback}))
+	}
+	return r
+}
+
+// ParseFallbacks parses a comma-separated fallback chain such as
+// "ollama,openai:gpt-4o-mini". Each entry is provider[:model]; everything
+// after the first colon is the model, so "ollama:llama3.2:3b" works.
+// apiKey, if non-nil, supplies the API key for each provider.
+func ParseFallbacks(spec string, apiKey func(provider string) string) []types.LLMConfig {
+	var out []types.LLMConfig
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		provider, model, _ := strings.Cut(entry, ":")
+		cfg := types.LLMConfig{Provider: strings.ToLower(provider), Model: model}
+		if apiKey != nil {
+			cfg.APIKey = apiKey(cfg.Provider)
+		}
+		out = append(out, cfg)
+	}
+	return out
+}
+
+// SetBudgetGate installs gate, checked before every provider call.
+func (r *Router) SetBudgetGate(gate BudgetGate) {
+	r.gate = gate
+}
+
+// SetUsageRecorder installs record, called after every successful completion.
+func (r *Router) SetUsageRecorder(record UsageRecorder) {
+	r.onUsage = record
+}
+
+// SetContextInjector installs inject, run before every completion. Pass nil
+// to stop injecting context.
+func (r *Router) SetContextInjector(inject ContextInjector) {
+	r.inject = inject
 }
 
 // AddFallback registers a fallback provider.
@@ -97,78 +247,206 @@ func (r *Router) AddFallback(p *Provider) {
 	r.fallbacks = append(r.fallbacks, p)
 }
 
-// Complete sends a completion request, falling back on error.
+// Complete sends a completion request with default options, falling back on error.
 func (r *Router) Complete(ctx context.Context, systemPrompt, userMsg string) (*types.AgentResult, error) {
+	return r.CompleteWithOptions(ctx, systemPrompt, userMsg, CompletionOptions{})
+}
+
+// CompleteWithOptions is Complete with per-request sampling options.
+func (r *Router) CompleteWithOptions(ctx context.Context, systemPrompt, userMsg string, opts CompletionOptions) (*types.AgentResult, error) {
+	systemPrompt = r.withContext(ctx, systemPrompt, userMsg)
+	return r.complete(ctx, func(p *Provider) (string, usage, bool, error) {
+		content, u, err := r.callProvider(ctx, p, systemPrompt, userMsg, opts)
+		return content, u, false, err
+	})
+}
+
+// CompleteStream is like Complete but streams the completion, calling onToken
+// for every content delta as it arrives. Providers that ignore "stream": true
+// and answer with a plain JSON completion are handled transparently (onToken
+// receives the whole content once). Fallback applies only until the first
+// token has been delivered; after that a failure is returned as-is so the
+// caller never sees output from two providers spliced together.
+func (r *Router) CompleteStream(ctx context.Context, systemPrompt, userMsg string, onToken func(string)) (*types.AgentResult, error) {
+	systemPrompt = r.withContext(ctx, systemPrompt, userMsg)
+	return r.complete(ctx, func(p *Provider) (string, usage, bool, error) {
+		return r.streamProvider(ctx, p, systemPrompt, userMsg, CompletionOptions{}, onToken)
+	})
+}
+
+// withContext prepends injected context to systemPrompt. A failing
+// injector is logged and never fails the completion.
+func (r *Router) withContext(ctx context.Context, systemPrompt, userMsg string) string {
+	if r.inject == nil {
+		return systemPrompt
+	}
+	extra, err := r.inject(ctx, userMsg)
+	if err != nil {
+		log.Warn().Err(err).Msg("router: context injection failed")
+	}
+	if extra == "" {
+		return systemPrompt
+	}
+	if systemPrompt == "" {
+		return extra
+	}
+	return extra + "\n\n" + systemPrompt
+}
+
+// complete runs call against each healthy provider in order until one succeeds.
+// call reports started=true once output has reached the caller, which stops fallback.
+func (r *Router) complete(ctx context.Context, call func(p *Provider) (content string, u usage, started bool, err error)) (*types.AgentResult, error) {
+	if r.gate != nil {
+		if ok, reason := r.gate(); !ok {
+			return nil, fmt.Errorf("%w: %s", ErrBudgetPaused, reason)
+		}
+	}
 	start := time.Now()
 	providers := append([]*Provider{r.primary}, r.fallbacks...)
 	var lastErr error
 	for _, p := range providers {
-		if !p.Healthy {
+		if !p.allow(time.Now()) {
 			continue
 		}
-		content, tokIn, tokOut, err := r.callProvider(ctx, p, systemPrompt, userMsg)
+		content, u, started, err := call(p)
 		if err != nil {
+			p.recordFailure()
+			if started {
+				return nil, fmt.Errorf("provider %s failed mid-stream: %w", p.Name, err)
+			}
 			// Log provider name only — not the APIKey.
 			log.Warn().Str("provider", p.Name).Err(err).Msg("provider failed, trying fallback")
-			p.recordFailure()
 			lastErr = err
 			continue
 		}
 		p.recordSuccess()
-		return &types.AgentResult{
+		result := &types.AgentResult{
 			Content:   content,
 			Agent:     "router",
 			Model:     p.Name + "/" + p.Model,
 			LatencyMs: time.Since(start).Milliseconds(),
-			TokensIn:  tokIn,
-			TokensOut: tokOut,
-		}, nil
+			TokensIn:  u.in,
+			TokensOut: u.out,
+		}
+		if u.estimated {
+			result.Meta = map[string]string{"tokens_estimated": "true"}
+		}
+		if r.onUsage != nil {
+			r.onUsage(p.Name, p.Model, u.in, u.out)
+		}
+		return result, nil
 	}
 	return nil, fmt.Errorf("all providers failed: %w", lastErr)
 }
 
-// callProvider sends a chat completion request to a single provider.
-func (r *Router) callProvider(ctx context.Context, p *Provider, system, user string) (string, int, int, error) {
+// usage is the token accounting for one completion.
+// estimated is set when the provider omitted usage and counts were approximated.
+type usage struct {
+	in, out   int
+	estimated bool
+}
+
+// withEstimate fills in estimated counts when the provider reported none.
+func (u usage) withEstimate(system, user, content string) usage {
+	if u.in == 0 && u.out == 0 {
+		return usage{in: estimatePromptTokens(system, user), out: EstimateTokens(content), estimated: true}
+	}
+	return u
+}
+
+// newChatRequest builds an OpenAI-compatible /chat/completions request.
+func (r *Router) newChatRequest(ctx context.Context, p *Provider, system, user string, opts CompletionOptions, stream bool) (*http.Request, error) {
 	type message struct {
 		Role    string `json:"role"`
 		Content string `json:"content"`
 	}
+	type streamOptions struct {
+		IncludeUsage bool `json:"include_usage"`
+	}
 	body := struct {
-		Model     string    `json:"model"`
-		Messages  []message `json:"messages"`
-		MaxTokens int       `json:"max_tokens"`
+		Model         string         `json:"model"`
+		Messages      []message      `json:"messages"`
+		MaxTokens     int            `json:"max_tokens"`
+		Temperature   *float64       `json:"temperature,omitempty"`
+		TopP          *float64       `json:"top_p,omitempty"`
+		Stop          []string       `json:"stop,omitempty"`
+		Stream        bool           `json:"stream,omitempty"`
+		StreamOptions *streamOptions `json:"stream_options,omitempty"`
 	}{
 		Model: p.Model,
 		Messages: []message{
 			{Role: "system", Content: system},
 			{Role: "user", Content: user},
 		},
-		MaxTokens: 2048,
+		MaxTokens:   r.maxTokens,
+		Temperature: opts.Temperature,
+		TopP:        opts.TopP,
+		Stop:        opts.Stop,
+	}
+	if opts.MaxTokens > 0 {
+		body.MaxTokens = opts.MaxTokens
+	}
+	if stream {
+		body.Stream = true
+		body.StreamOptions = &streamOptions{IncludeUsage: true}
 	}
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(body); err != nil {
-		return "", 0, 0, fmt.Errorf("router: encode: %w", err)
+		return nil, fmt.Errorf("router: encode: %w", err)
 	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
 		p.BaseURL+"/chat/completions", &buf)
 	if err != nil {
-		return "", 0, 0, err
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
 	if p.APIKey.Value() != "" {
 		req.Header.Set("Authorization", "Bearer "+p.APIKey.Value())
 	}
+	return req, nil
+}
+
+// do sends req and converts HTTP error statuses into errors.
+func (r *Router) do(p *Provider, req *http.Request) (*http.Response, error) {
 	resp, err := r.client.Do(req)
 	if err != nil {
-		return "", 0, 0, err
+		return nil, err
 	}
-	defer resp.Body.Close()
 	if resp.StatusCode >= 400 {
 		// Drain body to allow connection reuse; log internally but don't propagate raw body.
 		b, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
 		log.Debug().Str("provider", p.Name).Int("status", resp.StatusCode).Bytes("body", b).Msg("provider error response")
-		return "", 0, 0, fmt.Errorf("provider %s HTTP %d", p.Name, resp.StatusCode)
+		return nil, fmt.Errorf("provider %s HTTP %d", p.Name, resp.StatusCode)
 	}
+	return resp, nil
+}
+
+// callProvider sends a chat completion request to a single provider.
+// Ollama and some local servers omit the usage block; token counts are then
+// estimated so cost tracking and budgets keep working.
+func (r *Router) callProvider(ctx context.Context, p *Provider, system, user string, opts CompletionOptions) (string, usage, error) {
+	req, err := r.newChatRequest(ctx, p, system, user, opts, false)
+	if err != nil {
+		return "", usage{}, err
+	}
+	resp, err := r.do(p, req)
+	if err != nil {
+		return "", usage{}, err
+	}
+	defer resp.Body.Close()
+	content, u, err := decodeCompletion(p, resp.Body)
+	if err != nil {
+		return "", usage{}, err
+	}
+	return content, u.withEstimate(system, user, content), nil
+}
+
+// decodeCompletion parses a non-streaming chat completion body.
+func decodeCompletion(p *Provider, body io.Reader) (string, usage, error) {
 	var res struct {
 		Choices []struct {
 			Message struct {
@@ -180,14 +458,89 @@ func (r *Router) callProvider(ctx context.Context, p *Provider, system, user str
 			CompletionTokens int `json:"completion_tokens"`
 		} `json:"usage"`
 	}
-	if err := json.NewDecoder(io.LimitReader(resp.Body, 4*1024*1024)).Decode(&res); err != nil {
-		return "", 0, 0, fmt.Errorf("router: decode: %w", err)
+	if err := json.NewDecoder(io.LimitReader(body, 4*1024*1024)).Decode(&res); err != nil {
+		return "", usage{}, fmt.Errorf("router: decode: %w", err)
 	}
 	if len(res.Choices) == 0 {
-		return "", 0, 0, fmt.Errorf("empty response from %s", p.Name)
+		return "", usage{}, fmt.Errorf("empty response from %s", p.Name)
 	}
-	return strings.TrimSpace(res.Choices[0].Message.Content),
-		res.Usage.PromptTokens, res.Usage.CompletionTokens, nil
+	content := strings.TrimSpace(res.Choices[0].Message.Content)
+	return content, usage{in: res.Usage.PromptTokens, out: res.Usage.CompletionTokens}, nil
+}
+
+// streamProvider sends a streaming chat completion request and parses the
+// SSE "data:" lines, calling onToken per delta. started reports whether any
+// token reached onToken.
+func (r *Router) streamProvider(ctx context.Context, p *Provider, system, user string, opts CompletionOptions, onToken func(string)) (content string, u usage, started bool, err error) {
+	req, err := r.newChatRequest(ctx, p, system, user, opts, true)
+	if err != nil {
+		return "", usage{}, false, err
+	}
+	resp, err := r.do(p, req)
+	if err != nil {
+		return "", usage{}, false, err
+	}
+	defer resp.Body.Close()
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		content, u, err := decodeCompletion(p, resp.Body)
+		if err != nil {
+			return "", usage{}, false, err
+		}
+		if onToken != nil && content != "" {
+			onToken(content)
+		}
+		return content, u.withEstimate(system, user, content), content != "", nil
+	}
+
+	var sb strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue // blank separators, comments, "event:" lines
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Usage *struct {
+				PromptTokens     int `json:"prompt_tokens"`
+				CompletionTokens int `json:"completion_tokens"`
+			} `json:"usage"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", usage{}, started, fmt.Errorf("router: decode stream chunk: %w", err)
+		}
+		if chunk.Usage != nil {
+			u = usage{in: chunk.Usage.PromptTokens, out: chunk.Usage.CompletionTokens}
+		}
+		for _, c := range chunk.Choices {
+			if c.Delta.Content == "" {
+				continue
+			}
+			sb.WriteString(c.Delta.Content)
+			started = true
+			if onToken != nil {
+				onToken(c.Delta.Content)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", usage{}, started, fmt.Errorf("router: read stream: %w", err)
+	}
+	if sb.Len() == 0 {
+		return "", usage{}, false, fmt.Errorf("empty response from %s", p.Name)
+	}
+	content = strings.TrimSpace(sb.String())
+	return content, u.withEstimate(system, user, content), started, nil
 }
 
 // HealthCheck pings all providers in parallel and marks them healthy/unhealthy.
@@ -201,7 +554,7 @@ func (r *Router) HealthCheck(ctx context.Context) {
 			defer wg.Done()
 			req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.BaseURL+"/models", nil)
 			if err != nil {
-				p.Healthy = false
+				p.markUnhealthy()
 				return
 			}
 			if p.APIKey.Value() != "" {
@@ -209,7 +562,7 @@ func (r *Router) HealthCheck(ctx context.Context) {
 			}
 			resp, err := r.client.Do(req)
 			if err != nil || resp.StatusCode >= 500 {
-				p.Healthy = false
+				p.markUnhealthy()
 				log.Warn().Str("provider", p.Name).Msg("provider unhealthy")
 			} else {
 				p.recordSuccess()
@@ -223,6 +576,25 @@ func (r *Router) HealthCheck(ctx context.Context) {
 	wg.Wait()
 }
 
+// defaultModels is the model used for each provider when its config names
+// none, e.g. a bare "groq" in NEXUS_LLM_FALLBACKS. Unknown providers default
+// to the Ollama endpoint and so to its model.
+var defaultModels = map[string]string{
+	"groq":      "llama-3.3-70b-versatile",
+	"anthropic": "claude-3-5-haiku-latest",
+	"openai":    "gpt-4o-mini",
+	"together":  "meta-llama/Llama-3.3-70B-Instruct-Turbo",
+	"ollama":    "llama3.2",
+}
+
+// defaultModel returns the default model for provider.
+func defaultModel(provider string) string {
+	if m, ok := defaultModels[strings.ToLower(provider)]; ok {
+		return m
+	}
+	return defaultModels["ollama"]
+}
+
 func providerFromConfig(cfg types.LLMConfig) *Provider {
 	baseURL := cfg.BaseURL
 	if baseURL == "" {
@@ -243,7 +615,7 @@ func providerFromConfig(cfg types.LLMConfig) *Provider {
 	}
 	model := cfg.Model
 	if model == "" {
-		model = "llama3.2"
+		model = defaultModel(cfg.Provider)
 	}
 	return &Provider{
 		Name:    cfg.Provider,
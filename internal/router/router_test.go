@@ -0,0 +1,359 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Omkar0612/nexus-ai/internal/types"
+)
+
+func mockProvider(response string, withUsage bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		body := map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"content": response}},
+			},
+		}
+		if withUsage {
+			body["usage"] = map[string]int{"prompt_tokens": 10, "completion_tokens": 20}
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+}
+
+func newTestRouter(url string) *Router {
+	return New(types.LLMConfig{Provider: "test", BaseURL: url, Model: "test-model", TimeoutSec: 5})
+}
+
+func TestCompleteReportsProviderUsage(t *testing.T) {
+	srv := mockProvider("hello there", true)
+	defer srv.Close()
+
+	res, err := newTestRouter(srv.URL).Complete(context.Background(), "sys", "hi")
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if res.TokensIn != 10 || res.TokensOut != 20 {
+		t.Errorf("expected 10/20 tokens, got %d/%d", res.TokensIn, res.TokensOut)
+	}
+	if res.Meta["tokens_estimated"] != "" {
+		t.Error("provider usage should not be flagged as estimated")
+	}
+}
+
+func TestCompleteEstimatesMissingUsage(t *testing.T) {
+	srv := mockProvider("The quick brown fox jumps over the lazy dog.", false)
+	defer srv.Close()
+
+	res, err := newTestRouter(srv.URL).Complete(context.Background(), "You are helpful.", "Tell me a sentence.")
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if res.TokensIn == 0 || res.TokensOut == 0 {
+		t.Errorf("expected estimated non-zero tokens, got %d/%d", res.TokensIn, res.TokensOut)
+	}
+	if res.Meta["tokens_estimated"] != "true" {
+		t.Errorf("expected tokens_estimated flag, got %v", res.Meta)
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	if EstimateTokens("") != 0 {
+		t.Error("empty text should be 0 tokens")
+	}
+	got := EstimateTokens("The quick brown fox jumps over the lazy dog.")
+	// cl100k encodes this sentence as 10 tokens.
+	if got < 8 || got > 13 {
+		t.Errorf("estimate %d too far from 10", got)
+	}
+	if EstimateTokens("你好世界") != 4 {
+		t.Errorf("expected one token per CJK rune")
+	}
+}
+
+func TestNewWiresFallbackChain(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	up := mockProvider("from fallback", true)
+	defer up.Close()
+
+	r := New(types.LLMConfig{
+		Provider: "groq", BaseURL: down.URL, TimeoutSec: 5,
+		Fallbacks: []types.LLMConfig{{Provider: "ollama", BaseURL: up.URL, Model: "llama3.2"}},
+	})
+	res, err := r.Complete(context.Background(), "sys", "hi")
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if res.Content != "from fallback" || res.Model != "ollama/llama3.2" {
+		t.Errorf("expected fallback result, got %q from %s", res.Content, res.Model)
+	}
+}
+
+func TestParseFallbacks(t *testing.T) {
+	got := ParseFallbacks(" ollama:llama3.2:3b , OpenAI ,", func(p string) string { return "key-" + p })
+	if len(got) != 2 {
+		t.Fatalf("expected 2 fallbacks, got %d", len(got))
+	}
+	if got[0].Provider != "ollama" || got[0].Model != "llama3.2:3b" {
+		t.Errorf("unexpected first fallback: %+v", got[0])
+	}
+	if got[1].Provider != "openai" || got[1].Model != "" || got[1].APIKey != "key-openai" {
+		t.Errorf("unexpected second fallback: %+v", got[1])
+	}
+}
+
+func TestProviderFromConfigDefaultModel(t *testing.T) {
+	for provider, want := range map[string]string{
+		"openai": "gpt-4o-mini",
+		"Groq":   "llama-3.3-70b-versatile",
+		"ollama": "llama3.2",
+		"custom": "llama3.2",
+	} {
+		if got := providerFromConfig(types.LLMConfig{Provider: provider}).Model; got != want {
+			t.Errorf("%s: default model = %q, want %q", provider, got, want)
+		}
+	}
+	if got := providerFromConfig(types.LLMConfig{Provider: "openai", Model: "gpt-4o"}).Model; got != "gpt-4o" {
+		t.Errorf("explicit model overridden: %q", got)
+	}
+}
+
+func TestCompleteStreamParsesSSE(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["stream"] != true {
+			t.Errorf("expected stream=true in request, got %v", body["stream"])
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"choices":[{"delta":{"content":"Hel"}}]}`,
+			`{"choices":[{"delta":{"content":"lo"}}]}`,
+			`{"choices":[],"usage":{"prompt_tokens":7,"completion_tokens":2}}`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	var tokens []string
+	res, err := newTestRouter(srv.URL).CompleteStream(context.Background(), "sys", "hi", func(tok string) {
+		tokens = append(tokens, tok)
+	})
+	if err != nil {
+		t.Fatalf("CompleteStream: %v", err)
+	}
+	if res.Content != "Hello" || len(tokens) != 2 {
+		t.Errorf("expected Hello in 2 deltas, got %q / %v", res.Content, tokens)
+	}
+	if res.TokensIn != 7 || res.TokensOut != 2 {
+		t.Errorf("expected usage 7/2, got %d/%d", res.TokensIn, res.TokensOut)
+	}
+}
+
+func TestCompleteStreamNonStreamingProvider(t *testing.T) {
+	srv := mockProvider("whole answer", false)
+	defer srv.Close()
+
+	var got string
+	res, err := newTestRouter(srv.URL).CompleteStream(context.Background(), "sys", "hi", func(tok string) { got += tok })
+	if err != nil {
+		t.Fatalf("CompleteStream: %v", err)
+	}
+	if got != "whole answer" || res.Content != "whole answer" || res.Meta["tokens_estimated"] != "true" {
+		t.Errorf("unexpected fallback result: tokens=%q res=%+v", got, res)
+	}
+}
+
+func TestCompleteWithOptionsThreadsBody(t *testing.T) {
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"ok"}}]}`)
+	}))
+	defer srv.Close()
+	r := newTestRouter(srv.URL)
+
+	if _, err := r.CompleteWithOptions(context.Background(), "s", "u", CompletionOptions{
+		Temperature: Float(0.2), MaxTokens: 64, TopP: Float(0.9), Stop: []string{"\n\n"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if body["temperature"] != 0.2 || body["max_tokens"] != float64(64) || body["top_p"] != 0.9 {
+		t.Errorf("options not threaded into body: %v", body)
+	}
+	if stop, _ := body["stop"].([]interface{}); len(stop) != 1 {
+		t.Errorf("expected stop sequence, got %v", body["stop"])
+	}
+
+	if _, err := r.CompleteWithOptions(context.Background(), "s", "u", CompletionOptions{
+		Temperature: Float(0), TopP: Float(0),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := body["temperature"]; !ok || v != float64(0) {
+		t.Errorf("explicit temperature 0 should be sent, got %v", body)
+	}
+	if v, ok := body["top_p"]; !ok || v != float64(0) {
+		t.Errorf("explicit top_p 0 should be sent, got %v", body)
+	}
+
+	if _, err := r.Complete(context.Background(), "s", "u"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := body["temperature"]; ok || body["max_tokens"] != float64(2048) {
+		t.Errorf("Complete should use defaults, got %v", body)
+	}
+}
+
+func TestBudgetGateBlocksCalls(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"ok"}}]}`)
+	}))
+	defer srv.Close()
+	r := newTestRouter(srv.URL)
+	r.SetBudgetGate(func() (bool, string) { return false, "budget breached" })
+
+	if _, err := r.Complete(context.Background(), "s", "u"); !errors.Is(err, ErrBudgetPaused) {
+		t.Fatalf("expected ErrBudgetPaused, got %v", err)
+	}
+	if hits != 0 {
+		t.Errorf("provider should not be called while paused, got %d hits", hits)
+	}
+}
+
+func TestUsageRecorderSeesSuccessfulCalls(t *testing.T) {
+	srv := mockProvider("hi", true)
+	defer srv.Close()
+	r := newTestRouter(srv.URL)
+	var got []string
+	r.SetUsageRecorder(func(provider, model string, in, out int) {
+		got = append(got, fmt.Sprintf("%s/%s %d/%d", provider, model, in, out))
+	})
+	if _, err := r.Complete(context.Background(), "s", "u"); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != "test/test-model 10/20" {
+		t.Errorf("recorded usage = %q", got)
+	}
+}
+
+func TestContextInjectorPrependsToSystemPrompt(t *testing.T) {
+	var system string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct{ Role, Content string } `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		system = ""
+		for _, m := range body.Messages {
+			if m.Role == "system" {
+				system = m.Content
+			}
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"ok"}}]}`)
+	}))
+	defer srv.Close()
+	r := newTestRouter(srv.URL)
+	r.SetContextInjector(func(ctx context.Context, userMsg string) (string, error) {
+		return "[ctx for " + userMsg + "]", errors.New("semantic store down")
+	})
+
+	if _, err := r.Complete(context.Background(), "be brief", "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if system != "[ctx for hello]\n\nbe brief" {
+		t.Errorf("unexpected system prompt %q", system)
+	}
+
+	r.SetContextInjector(nil)
+	if _, err := r.Complete(context.Background(), "be brief", "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if system != "be brief" {
+		t.Errorf("injector removed but system prompt is %q", system)
+	}
+}
+
+func TestCircuitBreakerHalfOpenProbe(t *testing.T) {
+	var hits int
+	fail := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if fail {
+			http.Error(w, "down", http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"ok"}}]}`)
+	}))
+	defer srv.Close()
+	r := newTestRouter(srv.URL)
+	p := r.primary
+	ctx := context.Background()
+
+	for i := 0; i < circuitThreshold; i++ {
+		r.Complete(ctx, "s", "u")
+	}
+	if p.Healthy || p.cooldown != circuitCooldown {
+		t.Fatalf("expected open circuit with base cooldown, healthy=%v cooldown=%v", p.Healthy, p.cooldown)
+	}
+	if _, err := r.Complete(ctx, "s", "u"); err == nil || hits != circuitThreshold {
+		t.Fatalf("open circuit should skip the provider, got err=%v hits=%d", err, hits)
+	}
+
+	// Cooldown over: one probe goes through, fails, and doubles the cooldown.
+	p.openUntil = time.Now().Add(-time.Second)
+	if !p.allow(time.Now()) || p.allow(time.Now()) {
+		t.Fatal("expected exactly one half-open probe")
+	}
+	p.probing = false
+	r.Complete(ctx, "s", "u")
+	if hits != circuitThreshold+1 || p.Healthy || p.cooldown != 2*circuitCooldown {
+		t.Fatalf("failed probe: hits=%d healthy=%v cooldown=%v", hits, p.Healthy, p.cooldown)
+	}
+	if time.Until(p.openUntil) < circuitCooldown {
+		t.Errorf("failed probe should extend the cooldown, reopens in %v", time.Until(p.openUntil))
+	}
+
+	// A successful probe closes the circuit.
+	fail = false
+	p.openUntil = time.Now().Add(-time.Second)
+	if _, err := r.Complete(ctx, "s", "u"); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if !p.Healthy || p.cooldown != 0 || p.failures.Load() != 0 {
+		t.Errorf("successful probe should reset the breaker, healthy=%v cooldown=%v", p.Healthy, p.cooldown)
+	}
+}
+
+func TestCircuitCooldownIsCapped(t *testing.T) {
+	p := &Provider{Name: "p", Healthy: true}
+	for i := 0; i < circuitThreshold; i++ {
+		p.recordFailure()
+	}
+	for i := 0; i < 10; i++ {
+		p.openUntil = time.Time{}
+		if !p.allow(time.Now()) {
+			t.Fatal("probe not allowed after cooldown")
+		}
+		p.recordFailure()
+	}
+	if p.cooldown != circuitMaxCooldown {
+		t.Errorf("cooldown = %v, want %v", p.cooldown, circuitMaxCooldown)
+	}
+}
@@ -0,0 +1,45 @@
+package router
+
+import "unicode"
+
+// Per-message framing overhead used by OpenAI-style chat formats
+// (role markers + separators), and the fixed reply-priming cost.
+const (
+	tokensPerMessage = 4
+	tokensReplyPrime = 3
+)
+
+// EstimateTokens approximates the BPE token count of text without a vocabulary.
+// It tracks cl100k-style tokenizers closely enough for cost accounting:
+// short words are one token, long words split roughly every five characters,
+// punctuation and non-Latin runes (CJK etc.) count one token each.
+func EstimateTokens(text string) int {
+	tokens, run := 0, 0
+	flush := func() {
+		if run > 0 {
+			tokens += 1 + (run-1)/5
+			run = 0
+		}
+	}
+	for _, r := range text {
+		switch {
+		case r > unicode.MaxLatin1 && unicode.IsLetter(r):
+			flush()
+			tokens++
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			run++
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			flush()
+			tokens++
+		}
+	}
+	flush()
+	return tokens
+}
+
+// estimatePromptTokens approximates the prompt size of a system+user chat request.
+func estimatePromptTokens(system, user string) int {
+	return EstimateTokens(system) + EstimateTokens(user) + 2*tokensPerMessage + tokensReplyPrime
+}
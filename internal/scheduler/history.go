@@ -0,0 +1,149 @@
+package scheduler
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/rs/zerolog/log"
+)
+
+const sqliteTimeFormat = "2006-01-02 15:04:05.000000"
+
+// HistoryStore persists JobRuns in SQLite so job history survives restarts.
+// The DB file is created with 0600 before sql.Open, as in internal/audit.
+type HistoryStore struct {
+	db *sql.DB
+}
+
+// OpenHistory opens (or creates) scheduler.db in dataDir (~/.nexus if empty).
+func OpenHistory(dataDir string) (*HistoryStore, error) {
+	if dataDir == "" {
+		home, _ := os.UserHomeDir()
+		dataDir = filepath.Join(home, ".nexus")
+	}
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return nil, err
+	}
+	dbPath := filepath.Join(dataDir, "scheduler.db")
+	f, err := os.OpenFile(dbPath, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("scheduler: create db file: %w", err)
+	}
+	f.Close()
+	db, err := sql.Open("sqlite3", dbPath+"?_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	h := &HistoryStore{db: db}
+	return h, h.migrate()
+}
+
+func (h *HistoryStore) migrate() error {
+	_, err := h.db.Exec(`
+		CREATE TABLE IF NOT EXISTS job_runs (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			job_id      TEXT NOT NULL,
+			status      TEXT NOT NULL,
+			started_at  TEXT NOT NULL,
+			finished_at TEXT NOT NULL,
+			output      TEXT DEFAULT '',
+			error       TEXT DEFAULT ''
+		);
+		CREATE INDEX IF NOT EXISTS idx_job_runs_job ON job_runs(job_id, started_at);
+	`)
+	return err
+}
+
+// Record writes a single run.
+func (h *HistoryStore) Record(run JobRun) error {
+	_, err := h.db.Exec(
+		`INSERT INTO job_runs (job_id,status,started_at,finished_at,output,error) VALUES (?,?,?,?,?,?)`,
+		run.JobID, string(run.Status),
+		run.StartedAt.UTC().Format(sqliteTimeFormat), run.FinishedAt.UTC().Format(sqliteTimeFormat),
+		run.Output, run.Error,
+	)
+	return err
+}
+
+// History returns the most recent runs of jobID, newest first.
+// An empty jobID returns runs of all jobs; limit <= 0 defaults to 50.
+func (h *HistoryStore) History(jobID string, limit int) ([]JobRun, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	query := `SELECT job_id,status,started_at,finished_at,output,error FROM job_runs`
+	var args []interface{}
+	if jobID != "" {
+		query += ` WHERE job_id=?`
+		args = append(args, jobID)
+	}
+	query += ` ORDER BY started_at DESC, id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := h.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var runs []JobRun
+	for rows.Next() {
+		var r JobRun
+		var status, started, finished string
+		if err := rows.Scan(&r.JobID, &status, &started, &finished, &r.Output, &r.Error); err != nil {
+			return nil, err
+		}
+		r.Status = JobStatus(status)
+		r.StartedAt, _ = time.Parse(sqliteTimeFormat, started)
+		r.FinishedAt, _ = time.Parse(sqliteTimeFormat, finished)
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
+// Close closes the database.
+func (h *HistoryStore) Close() error { return h.db.Close() }
+
+// SetHistoryStore persists every recorded run to store in addition to the
+// in-memory Job.History ring.
+func (s *Scheduler) SetHistoryStore(store *HistoryStore) {
+	s.history = store
+}
+
+// History returns the latest runs of jobID, newest first. With a
+// HistoryStore this includes runs from previous processes; otherwise it
+// falls back to the in-memory history of the registered job.
+func (s *Scheduler) History(jobID string, limit int) ([]JobRun, error) {
+	if s.history != nil {
+		return s.history.History(jobID, limit)
+	}
+	s.mu.RLock()
+	job, ok := s.jobs[jobID]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("job %s not found", jobID)
+	}
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if limit <= 0 {
+		limit = 50
+	}
+	out := make([]JobRun, 0, limit)
+	for i := len(job.History) - 1; i >= 0 && len(out) < limit; i-- {
+		out = append(out, job.History[i])
+	}
+	return out, nil
+}
+
+// persistRun writes run to the history store, if one is configured.
+func (s *Scheduler) persistRun(run JobRun) {
+	if s.history == nil {
+		return
+	}
+	if err := s.history.Record(run); err != nil {
+		log.Warn().Err(err).Str("job", run.JobID).Msg("scheduler: failed to persist job run")
+	}
+}
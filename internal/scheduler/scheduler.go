@@ -14,7 +14,7 @@ NEXUS SmartScheduler goes beyond basic cron:
      - 'only if file appears in /tmp/reports/'
      - 'only if last run failed'
   3. Event-driven triggers: fire when an event occurs
-  4. Missed-run detection + configurable catch-up
+  4. Missed-run detection + configurable catch-up (see SetStateFile)
   5. Per-job retry policy with backoff
   6. Human-readable schedule descriptions
 
@@ -81,6 +81,10 @@ type Job struct {
 	RetryBackoff time.Duration
 	CatchUpMissed bool
 	Enabled      bool
+	// OnSuccess / OnFailure are invoked after a run resolves (after retries).
+	// Skipped runs trigger neither.
+	OnSuccess    func(JobRun)
+	OnFailure    func(JobRun)
 	// runtime state
 	LastRun      time.Time
 	NextRun      time.Time
@@ -90,6 +94,9 @@ type Job struct {
 	mu           sync.Mutex
 }
 
+// maxDeadLetters caps the number of permanently-failed runs retained.
+const maxDeadLetters = 200
+
 // Scheduler manages all registered jobs
 type Scheduler struct {
 	jobs    map[string]*Job
@@ -97,6 +104,13 @@ type Scheduler struct {
 	ctx     context.Context
 	cancel  context.CancelFunc
 	tick    time.Duration
+	deadMu      sync.Mutex
+	deadLetters []JobRun
+	stateMu     sync.Mutex
+	statePath   string // empty = state persistence disabled
+	saved       map[string]jobState
+	missed      map[string]time.Time
+	history     *HistoryStore // optional durable run history
 }
 
 // New creates a new smart scheduler
@@ -128,13 +142,16 @@ func (s *Scheduler) Register(job *Job) error {
 	s.mu.Lock()
 	s.jobs[job.ID] = job
 	s.mu.Unlock()
+	s.restoreState(job)
 	s.scheduleNext(job)
+	s.saveState()
 	log.Info().Str("job", job.ID).Str("trigger", string(job.Trigger)).Msg("job registered")
 	return nil
 }
 
 // Start begins the scheduler loop
 func (s *Scheduler) Start() {
+	s.catchUp()
 	go s.loop()
 	log.Info().Dur("tick", s.tick).Msg("NEXUS SmartScheduler started")
 }
@@ -229,7 +246,35 @@ func (s *Scheduler) runJob(job *Job) {
 		log.Info().Str("job", job.ID).Msg("job completed successfully")
 	}
 	s.recordRun(job, run)
+	if run.Status == StatusFailed {
+		s.addDeadLetter(run)
+		if job.OnFailure != nil {
+			job.OnFailure(run)
+		}
+	} else if job.OnSuccess != nil {
+		job.OnSuccess(run)
+	}
 	s.scheduleNext(job)
+	s.saveState()
+}
+
+// addDeadLetter records a run that failed all of its retries.
+func (s *Scheduler) addDeadLetter(run JobRun) {
+	s.deadMu.Lock()
+	defer s.deadMu.Unlock()
+	s.deadLetters = append(s.deadLetters, run)
+	if len(s.deadLetters) > maxDeadLetters {
+		s.deadLetters = s.deadLetters[len(s.deadLetters)-maxDeadLetters:]
+	}
+}
+
+// DeadLetters returns runs that permanently failed after exhausting retries, oldest first.
+func (s *Scheduler) DeadLetters() []JobRun {
+	s.deadMu.Lock()
+	defer s.deadMu.Unlock()
+	out := make([]JobRun, len(s.deadLetters))
+	copy(out, s.deadLetters)
+	return out
 }
 
 func (s *Scheduler) scheduleNext(job *Job) {
@@ -254,11 +299,12 @@ func (s *Scheduler) scheduleNext(job *Job) {
 
 func (s *Scheduler) recordRun(job *Job, run JobRun) {
 	job.mu.Lock()
-	defer job.mu.Unlock()
 	job.History = append(job.History, run)
 	if len(job.History) > 50 {
 		job.History = job.History[len(job.History)-50:]
 	}
+	job.mu.Unlock()
+	s.persistRun(run)
 }
 
 // Enable re-activates a disabled job
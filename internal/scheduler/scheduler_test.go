@@ -2,6 +2,11 @@ package scheduler
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -73,6 +78,51 @@ func TestSchedulerConditionSkip(t *testing.T) {
 	}
 }
 
+func TestSchedulerFailureHookAndDeadLetter(t *testing.T) {
+	s := New(time.Second)
+	var failed, succeeded []JobRun
+	job := &Job{
+		ID: "flaky", Name: "Flaky",
+		Trigger: TriggerInterval, Interval: time.Minute,
+		MaxRetries: 1, RetryBackoff: time.Millisecond,
+		Handler:   func(ctx context.Context) error { return errors.New("boom") },
+		OnFailure: func(r JobRun) { failed = append(failed, r) },
+		OnSuccess: func(r JobRun) { succeeded = append(succeeded, r) },
+	}
+	_ = s.Register(job)
+	s.runJob(job)
+
+	if len(failed) != 1 || len(succeeded) != 0 {
+		t.Fatalf("expected 1 failure hook call, got failed=%d succeeded=%d", len(failed), len(succeeded))
+	}
+	if failed[0].Error != "boom" {
+		t.Errorf("unexpected error in run: %q", failed[0].Error)
+	}
+	dead := s.DeadLetters()
+	if len(dead) != 1 || dead[0].JobID != "flaky" {
+		t.Errorf("expected flaky in dead letters, got %+v", dead)
+	}
+}
+
+func TestSchedulerSuccessHook(t *testing.T) {
+	s := New(time.Second)
+	called := false
+	job := &Job{
+		ID: "ok", Name: "OK",
+		Trigger: TriggerInterval, Interval: time.Minute,
+		Handler:   func(ctx context.Context) error { return nil },
+		OnSuccess: func(r JobRun) { called = r.Status == StatusSuccess },
+	}
+	_ = s.Register(job)
+	s.runJob(job)
+	if !called {
+		t.Error("expected OnSuccess to be called with a successful run")
+	}
+	if len(s.DeadLetters()) != 0 {
+		t.Error("successful run must not be dead-lettered")
+	}
+}
+
 func TestFileExistsCondition(t *testing.T) {
 	cond := FileExistsCondition("/tmp/nexus_test_file_does_not_exist_xyz")
 	ok, reason := cond(context.Background())
@@ -97,3 +147,83 @@ func TestParseCronNext(t *testing.T) {
 func containsStr(s, sub string) bool {
 	return len(s) > 0 && len(sub) > 0 && (s == sub || len(s) >= len(sub) && (s[:len(sub)] == sub || containsStr(s[1:], sub)))
 }
+
+func TestSchedulerCatchUpMissed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	past := time.Now().Add(-3 * time.Hour)
+	seed := `{"digest":{"next_run":"` + past.Format(time.RFC3339) + `"},"other":{"next_run":"` + past.Format(time.RFC3339) + `"}}`
+	if err := os.WriteFile(path, []byte(seed), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(time.Hour)
+	defer s.Stop()
+	if err := s.SetStateFile(path); err != nil {
+		t.Fatalf("SetStateFile: %v", err)
+	}
+	var digestRuns, otherRuns atomic.Int32
+	done := make(chan struct{}, 1)
+	s.Register(&Job{ID: "digest", Trigger: TriggerInterval, Interval: time.Hour, CatchUpMissed: true,
+		Handler: func(ctx context.Context) error { digestRuns.Add(1); return nil },
+		OnSuccess: func(JobRun) { done <- struct{}{} },
+	})
+	s.Register(&Job{ID: "other", Trigger: TriggerInterval, Interval: time.Hour,
+		Handler: func(ctx context.Context) error { otherRuns.Add(1); return nil },
+	})
+	s.Start()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("catch-up run did not fire")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if digestRuns.Load() != 1 || otherRuns.Load() != 0 {
+		t.Errorf("expected exactly one catch-up run, got digest=%d other=%d", digestRuns.Load(), otherRuns.Load())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved map[string]jobState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if !saved["digest"].NextRun.After(time.Now()) || saved["digest"].LastRun.IsZero() {
+		t.Errorf("expected persisted LastRun and future NextRun, got %+v", saved["digest"])
+	}
+}
+
+func TestSchedulerHistoryPersists(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenHistory(dir)
+	if err != nil {
+		t.Fatalf("OpenHistory: %v", err)
+	}
+	s := New(time.Hour)
+	s.SetHistoryStore(store)
+	job := &Job{ID: "backup", Trigger: TriggerInterval, Interval: time.Hour,
+		Handler: func(ctx context.Context) error { return nil }}
+	s.Register(job)
+	s.runJob(job)
+	s.runJob(job)
+	s.Stop()
+	store.Close()
+
+	// A fresh process sees the earlier runs.
+	reopened, err := OpenHistory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	s2 := New(time.Hour)
+	s2.SetHistoryStore(reopened)
+	runs, err := s2.History("backup", 10)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(runs) != 2 || runs[0].Status != StatusSuccess || runs[0].StartedAt.IsZero() {
+		t.Errorf("expected 2 persisted successful runs, got %+v", runs)
+	}
+}
@@ -0,0 +1,123 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// jobState is the persisted runtime state of one job.
+type jobState struct {
+	LastRun time.Time `json:"last_run"`
+	NextRun time.Time `json:"next_run"`
+}
+
+// DefaultStatePath returns ~/.nexus/scheduler_state.json.
+func DefaultStatePath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".nexus", "scheduler_state.json")
+}
+
+// SetStateFile enables persistence of each job's LastRun/NextRun to path
+// (DefaultStatePath if empty) and loads any previously saved state. Call it
+// before Register so restored jobs pick up their saved times; Start then
+// fires one catch-up run for every CatchUpMissed job whose saved NextRun
+// passed while the process was down.
+func (s *Scheduler) SetStateFile(path string) error {
+	if path == "" {
+		path = DefaultStatePath()
+	}
+	saved := make(map[string]jobState)
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &saved); err != nil {
+			return fmt.Errorf("scheduler: corrupt state file %s: %w", path, err)
+		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("scheduler: read state: %w", err)
+	}
+	s.stateMu.Lock()
+	s.statePath = path
+	s.saved = saved
+	s.missed = make(map[string]time.Time)
+	s.stateMu.Unlock()
+	return nil
+}
+
+// restoreState applies saved state to a newly registered job and notes a
+// missed fire time for catch-up. Caller must not hold job.mu.
+func (s *Scheduler) restoreState(job *Job) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	st, ok := s.saved[job.ID]
+	if !ok {
+		return
+	}
+	job.mu.Lock()
+	job.LastRun = st.LastRun
+	job.mu.Unlock()
+	if job.CatchUpMissed && !st.NextRun.IsZero() && st.NextRun.Before(time.Now()) {
+		s.missed[job.ID] = st.NextRun
+	}
+}
+
+// catchUp fires exactly one run for each enabled CatchUpMissed job whose
+// saved NextRun passed while the process was down. Other jobs were already
+// rescheduled forward by Register, so missed intervals never fire in a burst.
+func (s *Scheduler) catchUp() {
+	s.stateMu.Lock()
+	missed := s.missed
+	s.missed = make(map[string]time.Time)
+	s.stateMu.Unlock()
+
+	for id, at := range missed {
+		s.mu.RLock()
+		job, ok := s.jobs[id]
+		s.mu.RUnlock()
+		if !ok || !job.Enabled {
+			continue
+		}
+		log.Info().Str("job", id).Time("missed", at).Msg("catching up missed job run")
+		go s.runJob(job)
+	}
+}
+
+// saveState writes every job's LastRun/NextRun to the state file, if enabled.
+func (s *Scheduler) saveState() {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	if s.statePath == "" {
+		return
+	}
+	s.mu.RLock()
+	for id, job := range s.jobs {
+		job.mu.Lock()
+		s.saved[id] = jobState{LastRun: job.LastRun, NextRun: job.NextRun}
+		job.mu.Unlock()
+	}
+	s.mu.RUnlock()
+
+	data, err := json.MarshalIndent(s.saved, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(s.statePath, data)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("path", s.statePath).Msg("scheduler: failed to persist state")
+	}
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
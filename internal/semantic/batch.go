@@ -0,0 +1,122 @@
+package semantic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultBatchWorkers is the number of concurrent Ollama embedding requests
+// AddBatch makes unless changed with SetBatchWorkers.
+const DefaultBatchWorkers = 4
+
+// BatchDoc is one document to embed and store with AddBatch.
+type BatchDoc struct {
+	Content  string
+	Source   string
+	Metadata map[string]string
+}
+
+// BatchError reports the documents of an AddBatch call that failed, keyed by
+// their index in the input slice. The other documents were stored.
+type BatchError struct {
+	Failed map[int]error
+}
+
+func (e *BatchError) Error() string {
+	idx := make([]int, 0, len(e.Failed))
+	for i := range e.Failed {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	msgs := make([]string, 0, len(idx))
+	for _, i := range idx {
+		msgs = append(msgs, fmt.Sprintf("doc %d: %v", i, e.Failed[i]))
+	}
+	return fmt.Sprintf("semantic: %d of batch failed: %s", len(idx), strings.Join(msgs, "; "))
+}
+
+// SetBatchWorkers sets how many documents AddBatch embeds concurrently.
+func (s *Store) SetBatchWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	s.batchWorkers = n
+}
+
+// AddBatch embeds docs concurrently on a bounded worker pool and inserts the
+// successful ones in a single transaction. The result has one entry per
+// input document, nil where it failed; per-document failures are returned
+// together as a *BatchError. Any other error means nothing was stored.
+func (s *Store) AddBatch(ctx context.Context, docs []BatchDoc) ([]*Document, error) {
+	vecs := make([][]float64, len(docs))
+	hashed := make([]bool, len(docs))
+	failed := make(map[int]error)
+	var failMu sync.Mutex
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(s.batchWorkers, len(docs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				vec, h, err := s.embed(ctx, docs[i].Content)
+				if err != nil {
+					failMu.Lock()
+					failed[i] = err
+					failMu.Unlock()
+					continue
+				}
+				vecs[i], hashed[i] = vec, h
+			}
+		}()
+	}
+	for i := range docs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("semantic: begin batch: %w", err)
+	}
+	defer tx.Rollback()
+	out := make([]*Document, len(docs))
+	cached := make([]vector, len(docs))
+	for i, d := range docs {
+		if vecs[i] == nil {
+			continue
+		}
+		doc, v, err := insertDocument(ctx, tx, d.Content, d.Source, d.Metadata, vecs[i], s.embedderName(hashed[i]))
+		if err != nil {
+			return nil, err
+		}
+		out[i], cached[i] = doc, v
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("semantic: commit batch: %w", err)
+	}
+	for i, doc := range out {
+		if doc != nil {
+			s.cacheVector(doc.ID, cached[i], docs[i].Content)
+		}
+	}
+
+	if len(failed) > 0 {
+		return out, &BatchError{Failed: failed}
+	}
+	return out, nil
+}
@@ -0,0 +1,186 @@
+package semantic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DefaultDedupThreshold is the similarity Dedup uses when no threshold has
+// been set with SetDedupThreshold. Rephrasings score well below it; the same
+// note with whitespace or punctuation changes scores above it.
+const DefaultDedupThreshold = 0.97
+
+// SetDedupThreshold turns on deduplication in Add and AddWithMeta: a new
+// document whose similarity to an existing document from the same source is
+// at least t replaces that document instead of being inserted. t <= 0
+// turns it off (the default). AddBatch does not deduplicate; run Dedup after
+// bulk ingestion instead.
+func (s *Store) SetDedupThreshold(t float64) {
+	s.dedupMu.Lock()
+	defer s.dedupMu.Unlock()
+	s.dedupThreshold = max(t, 0)
+}
+
+// DedupThreshold returns the threshold set with SetDedupThreshold, 0 if
+// deduplication is off.
+func (s *Store) DedupThreshold() float64 {
+	s.dedupMu.Lock()
+	defer s.dedupMu.Unlock()
+	return s.dedupThreshold
+}
+
+// replaceDuplicate overwrites the most similar document from source with the
+// new content if it scores at least threshold, and reports whether it did.
+// The replaced document keeps its ID; its timestamp is refreshed. Caller must
+// hold s.dedupMu.
+func (s *Store) replaceDuplicate(ctx context.Context, content, source string, meta map[string]string, vec []float64, hashed bool, threshold float64) (*Document, bool, error) {
+	candidates, err := s.sourceIDs(ctx, source)
+	if err != nil || len(candidates) == 0 {
+		return nil, false, err
+	}
+	ranked, err := s.rank(ctx, vec, hashed, candidates)
+	if err != nil || len(ranked) == 0 || ranked[0].score < threshold {
+		return nil, false, err
+	}
+	id := ranked[0].id
+
+	if meta == nil {
+		meta = map[string]string{}
+	}
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return nil, false, err
+	}
+	now := time.Now().UTC()
+	buf, v := packVector(vec)
+	v.hashed = hashed
+	if _, err := s.db.ExecContext(ctx,
+		`UPDATE documents SET content = ?, metadata = ?, created_at = ?, embedding = ?, norm = ?, embedder = ? WHERE id = ?`,
+		content, string(metaJSON), now.Unix(), buf, v.norm, s.embedderName(hashed), id,
+	); err != nil {
+		return nil, false, fmt.Errorf("semantic: update duplicate: %w", err)
+	}
+	s.cacheVector(id, v, content)
+	return &Document{ID: id, Content: content, Source: source, Metadata: meta, CreatedAt: now}, true, nil
+}
+
+// sourceIDs returns the IDs of documents from source.
+func (s *Store) sourceIDs(ctx context.Context, source string) (map[int64]bool, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM documents WHERE source = ?`, source)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// Dedup removes near-identical documents already in the store. Within each
+// source, documents are compared newest first and any document at least as
+// similar as the dedup threshold (DefaultDedupThreshold if unset) to a newer
+// one is deleted. Documents are only compared with others embedded in the
+// same space, so fallback embeddings never match Ollama ones.
+func (s *Store) Dedup(ctx context.Context) (removed int, err error) {
+	s.dedupMu.Lock()
+	defer s.dedupMu.Unlock()
+	threshold := s.dedupThreshold
+	if threshold <= 0 {
+		threshold = DefaultDedupThreshold
+	}
+
+	s.mu.Lock()
+	err = s.loadVectors(ctx)
+	s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT id, source FROM documents ORDER BY created_at DESC, id DESC`)
+	if err != nil {
+		return 0, err
+	}
+	type entry struct {
+		id     int64
+		source string
+	}
+	var all []entry
+	for rows.Next() {
+		var e entry
+		if err := rows.Scan(&e.id, &e.source); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		all = append(all, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	kept := make(map[string][]vector)
+	var drop []int64
+	s.mu.RLock()
+	for _, e := range all {
+		v, ok := s.vectors[e.id]
+		if !ok {
+			continue
+		}
+		dup := false
+		for _, k := range kept[e.source] {
+			if k.hashed == v.hashed && vectorSimilarity(v, k) >= threshold {
+				dup = true
+				break
+			}
+		}
+		if dup {
+			drop = append(drop, e.id)
+		} else {
+			kept[e.source] = append(kept[e.source], v)
+		}
+	}
+	s.mu.RUnlock()
+	if len(drop) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("semantic: begin dedup: %w", err)
+	}
+	defer tx.Rollback()
+	for _, id := range drop {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM documents WHERE id = ?`, id); err != nil {
+			return 0, fmt.Errorf("semantic: dedup delete: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("semantic: commit dedup: %w", err)
+	}
+	s.mu.Lock()
+	for _, id := range drop {
+		delete(s.vectors, id)
+		delete(s.hashVectors, id)
+	}
+	s.mu.Unlock()
+	return len(drop), nil
+}
+
+// vectorSimilarity is the cosine similarity of two cached vectors.
+func vectorSimilarity(a, b vector) float64 {
+	if len(a.v) != len(b.v) || a.norm == 0 || b.norm == 0 {
+		return 0
+	}
+	var dot float64
+	for i := range a.v {
+		dot += float64(a.v[i]) * float64(b.v[i])
+	}
+	return dot / (a.norm * b.norm)
+}
@@ -0,0 +1,211 @@
+package semantic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Degraded mode: when the Ollama embedding endpoint is unreachable (or the
+// model is not pulled), the store keeps working with a deterministic local
+// embedding — a hashed bag of words. It only matches shared words, not
+// meaning, but memory stays searchable offline. Documents added meanwhile
+// are tagged and re-embedded with Ollama on the first search after it
+// comes back.
+
+// Mode reports which embedder the store is using.
+type Mode string
+
+const (
+	// ModeOllama means embeddings come from Ollama.
+	ModeOllama Mode = "ollama"
+	// ModeDegraded means Ollama is unavailable and the hashed bag-of-words
+	// fallback is in use.
+	ModeDegraded Mode = "degraded"
+)
+
+// hashEmbedder tags documents stored with the fallback embedding.
+const hashEmbedder = "hash"
+
+// hashDims is the width of fallback vectors.
+const hashDims = 256
+
+// defaultProbeInterval is how long a degraded store goes straight to the
+// fallback before trying Ollama again, so callers don't wait out a
+// connection timeout on every call.
+const defaultProbeInterval = 30 * time.Second
+
+// statusError is a non-200 answer from the embedding endpoint.
+type statusError struct{ code int }
+
+func (e *statusError) Error() string { return fmt.Sprintf("semantic: embed: status %d", e.code) }
+
+// unavailable reports whether err means Ollama cannot embed at all, as
+// opposed to failing one input.
+func unavailable(err error) bool {
+	var ue *url.Error
+	var se *statusError
+	return errors.As(err, &ue) || (errors.As(err, &se) && se.code == http.StatusNotFound)
+}
+
+// Mode returns ModeDegraded while Ollama is unavailable, else ModeOllama.
+func (s *Store) Mode() Mode {
+	if s.degraded.Load() {
+		return ModeDegraded
+	}
+	return ModeOllama
+}
+
+// Probe checks the embedding endpoint now and updates Mode accordingly.
+func (s *Store) Probe(ctx context.Context) error {
+	_, err := s.Embed(ctx, "ping")
+	return err
+}
+
+// noteEmbed updates the mode after a call to the embedding endpoint.
+func (s *Store) noteEmbed(err error) {
+	s.lastProbe.Store(time.Now().UnixNano())
+	switch {
+	case err == nil:
+		if s.degraded.Swap(false) {
+			log.Info().Msg("semantic: Ollama embeddings available again")
+		}
+	case unavailable(err):
+		if !s.degraded.Swap(true) {
+			log.Warn().Err(err).Msg("semantic: Ollama embeddings unavailable, using local fallback")
+		}
+	}
+}
+
+// embed returns an Ollama embedding of text, or the fallback embedding
+// (hashed=true) while Ollama is unavailable. Errors that affect only this
+// input are returned as before.
+func (s *Store) embed(ctx context.Context, text string) (vec []float64, hashed bool, err error) {
+	if s.degraded.Load() && time.Since(time.Unix(0, s.lastProbe.Load())) < s.probeInterval {
+		return hashEmbed(text), true, nil
+	}
+	vec, err = s.checkedEmbed(ctx, text)
+	if err == nil {
+		return vec, false, nil
+	}
+	if ctx.Err() != nil || !unavailable(err) {
+		return nil, false, err
+	}
+	return hashEmbed(text), true, nil
+}
+
+// hashEmbed is the fallback embedding: word counts (1+log tf) hashed into
+// hashDims signed buckets.
+func hashEmbed(text string) []float64 {
+	counts := make(map[string]int)
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		counts[w]++
+	}
+	vec := make([]float64, hashDims)
+	for w, n := range counts {
+		h := fnv.New64a()
+		h.Write([]byte(w))
+		sum := h.Sum64()
+		sign := 1.0
+		if sum>>63 == 1 {
+			sign = -1
+		}
+		vec[sum%hashDims] += sign * (1 + math.Log(float64(n)))
+	}
+	return vec
+}
+
+// loadHashVectors fills the fallback vector cache, embedding every stored
+// document locally. Caller must hold s.mu for writing.
+func (s *Store) loadHashVectors(ctx context.Context) error {
+	if s.hashVectors != nil {
+		return nil
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT id, content FROM documents`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	vectors := make(map[int64]vector)
+	for rows.Next() {
+		var id int64
+		var content string
+		if err := rows.Scan(&id, &content); err != nil {
+			return err
+		}
+		_, v := packVector(hashEmbed(content))
+		v.hashed = true
+		vectors[id] = v
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	s.hashVectors = vectors
+	return nil
+}
+
+// reembedHashed replaces fallback embeddings with Ollama ones. It stops at
+// the first failure, leaving the remaining documents for the next search.
+func (s *Store) reembedHashed(ctx context.Context) (err error) {
+	// Cleared first so a fallback document added meanwhile sets it again.
+	s.hashedPending.Store(false)
+	defer func() {
+		if err != nil {
+			s.hashedPending.Store(true)
+		}
+	}()
+	rows, err := s.db.QueryContext(ctx, `SELECT id, content FROM documents WHERE embedder = ?`, hashEmbedder)
+	if err != nil {
+		return err
+	}
+	type pending struct {
+		id      int64
+		content string
+	}
+	var todo []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.content); err != nil {
+			rows.Close()
+			return err
+		}
+		todo = append(todo, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, p := range todo {
+		vec, err := s.checkedEmbed(ctx, p.content)
+		if err != nil {
+			return fmt.Errorf("semantic: re-embed %d: %w", p.id, err)
+		}
+		buf, v := packVector(vec)
+		if _, err := s.db.ExecContext(ctx,
+			`UPDATE documents SET embedding = ?, norm = ?, embedder = ? WHERE id = ?`,
+			buf, v.norm, s.embedderName(false), p.id,
+		); err != nil {
+			return fmt.Errorf("semantic: re-embed %d: %w", p.id, err)
+		}
+		s.mu.Lock()
+		if s.vectors != nil {
+			s.vectors[p.id] = v
+		}
+		s.mu.Unlock()
+	}
+	if len(todo) > 0 {
+		log.Info().Int("documents", len(todo)).Msg("semantic: re-embedded documents added while degraded")
+	}
+	return nil
+}
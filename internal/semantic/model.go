@@ -0,0 +1,218 @@
+package semantic
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrEmbeddingMismatch is returned when the configured model's embeddings
+// do not match the ones already in the store. Scores across models are
+// meaningless (and 0 across dimensions), so such calls are refused rather
+// than silently breaking search; Reembed migrates the store.
+var ErrEmbeddingMismatch = errors.New("semantic: embedding model mismatch")
+
+// Model returns the embedding model of the stored documents, or the
+// configured model if the store is still empty.
+func (s *Store) Model() string {
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+	if s.storeModel != "" {
+		return s.storeModel
+	}
+	return s.model
+}
+
+// Dimensions returns the dimension of the stored embeddings, 0 if no
+// document has been embedded with Ollama yet.
+func (s *Store) Dimensions() int {
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+	return s.dims
+}
+
+// loadEmbeddingInfo reads the recorded model and dimension. Stores written
+// before they were recorded take them from their first Ollama embedding.
+func (s *Store) loadEmbeddingInfo() error {
+	rows, err := s.db.Query(`SELECT key, value FROM store_meta WHERE key IN ('model', 'dims')`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var k, v string
+		if err := rows.Scan(&k, &v); err != nil {
+			rows.Close()
+			return err
+		}
+		switch k {
+		case "model":
+			s.storeModel = v
+		case "dims":
+			s.dims, _ = strconv.Atoi(v)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if s.dims > 0 {
+		return nil
+	}
+
+	var embedder string
+	var size int
+	err = s.db.QueryRow(`SELECT embedder, length(embedding) FROM documents
+		WHERE embedder != ? AND typeof(embedding) = 'blob' ORDER BY id LIMIT 1`, hashEmbedder).Scan(&embedder, &size)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if embedder == "" {
+		// Documents predating the embedder column came from the configured model.
+		embedder = s.model
+	}
+	if err := saveEmbeddingInfo(s.db, embedder, size/4); err != nil {
+		return err
+	}
+	s.storeModel, s.dims = embedder, size/4
+	return nil
+}
+
+// saveEmbeddingInfo persists model and dims as the store's embedding space.
+func saveEmbeddingInfo(db execer, model string, dims int) error {
+	for k, v := range map[string]string{"model": model, "dims": strconv.Itoa(dims)} {
+		if _, err := db.ExecContext(context.Background(),
+			`INSERT OR REPLACE INTO store_meta (key, value) VALUES (?, ?)`, k, v); err != nil {
+			return fmt.Errorf("semantic: record embedding info: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkedEmbed is Embed for vectors that will be stored or scored: the
+// result must match the store's model and dimension, which are recorded
+// from the first embedding.
+func (s *Store) checkedEmbed(ctx context.Context, text string) ([]float64, error) {
+	s.metaMu.Lock()
+	model := s.model
+	s.metaMu.Unlock()
+	vec, err := s.embedWith(ctx, model, text)
+	if err != nil {
+		return nil, err
+	}
+	if len(vec) == 0 {
+		return nil, fmt.Errorf("semantic: embed: %s returned an empty embedding", model)
+	}
+
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+	if s.dims == 0 {
+		if err := saveEmbeddingInfo(s.db, model, len(vec)); err != nil {
+			return nil, err
+		}
+		s.storeModel, s.dims = model, len(vec)
+		return vec, nil
+	}
+	if model != s.storeModel || len(vec) != s.dims {
+		return nil, fmt.Errorf("%w: the store holds %s embeddings (%d dimensions) but %s returned %d; "+
+			"open the store with model %q, or migrate it with Reembed(ctx, %q)",
+			ErrEmbeddingMismatch, s.storeModel, s.dims, model, len(vec), s.storeModel, model)
+	}
+	return vec, nil
+}
+
+// Reembed migrates every document to newModel (the configured model if
+// empty) and makes it the store's model, so a store can switch embedding
+// models or dimensions. All documents are embedded before anything is
+// written: on error the store is unchanged. It needs Ollama, and must not
+// run concurrently with AddBatch.
+func (s *Store) Reembed(ctx context.Context, newModel string) error {
+	s.dedupMu.Lock()
+	defer s.dedupMu.Unlock()
+	if newModel == "" {
+		s.metaMu.Lock()
+		newModel = s.model
+		s.metaMu.Unlock()
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT id, content FROM documents ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	type pending struct {
+		id      int64
+		content string
+		buf     []byte
+		norm    float64
+	}
+	var docs []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.content); err != nil {
+			rows.Close()
+			return err
+		}
+		docs = append(docs, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	dims := 0
+	for i := range docs {
+		vec, err := s.embedWith(ctx, newModel, docs[i].content)
+		if err != nil {
+			return fmt.Errorf("semantic: reembed %d: %w", docs[i].id, err)
+		}
+		if dims == 0 {
+			dims = len(vec)
+		}
+		if len(vec) == 0 || len(vec) != dims {
+			return fmt.Errorf("semantic: reembed %d: %s returned %d dimensions, expected %d", docs[i].id, newModel, len(vec), dims)
+		}
+		buf, v := packVector(vec)
+		docs[i].buf, docs[i].norm = buf, v.norm
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("semantic: begin reembed: %w", err)
+	}
+	defer tx.Rollback()
+	for _, d := range docs {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE documents SET embedding = ?, norm = ?, embedder = ? WHERE id = ?`,
+			d.buf, d.norm, newModel, d.id,
+		); err != nil {
+			return fmt.Errorf("semantic: reembed %d: %w", d.id, err)
+		}
+	}
+	if dims > 0 {
+		err = saveEmbeddingInfo(tx, newModel, dims)
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM store_meta WHERE key IN ('model', 'dims')`)
+	}
+	if err != nil {
+		return err
+	}
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("semantic: commit reembed: %w", err)
+	}
+	s.model, s.storeModel, s.dims = newModel, newModel, dims
+	if dims == 0 {
+		s.storeModel = ""
+	}
+	s.hashedPending.Store(false)
+
+	s.mu.Lock()
+	s.vectors = nil // reloaded from the new embeddings on the next search
+	s.mu.Unlock()
+	return nil
+}
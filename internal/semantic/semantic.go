@@ -1,6 +1,6 @@
 // Package semantic provides vector-based semantic memory search.
 // Uses Ollama embeddings API (free, local) to embed text into float32 vectors,
-// stored in SQLite. No external vector DB required — pure stdlib + sqlite.
+// stored packed in SQLite and cached in memory for search. No external vector DB required — pure stdlib + sqlite.
 // Replaces Mem.ai ($15/mo) and Notion AI search.
 package semantic
 
@@ -14,26 +14,50 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/rs/zerolog/log"
 )
 
 // Document is a piece of text stored with its embedding.
 type Document struct {
 	ID        int64
 	Content   string
-	Source    string    // e.g. "conversation", "notes", "email", "kb"
+	Source    string            // e.g. "conversation", "notes", "email", "kb"
+	Metadata  map[string]string // arbitrary tags, e.g. project, author, doc-type
 	CreatedAt time.Time
-	Score     float64   // populated on search results
+	Score     float64 // populated on search results
 }
 
-// Store manages the vector store.
+// Store manages the vector store. Parsed vectors are cached in memory after
+// the first search and kept in sync by Add and Delete, so a database file
+// should be written through a single Store.
 type Store struct {
 	db         *sql.DB
 	ollamaURL  string
-	model      string
 	httpClient *http.Client
+
+	metaMu     sync.Mutex
+	model      string // model requested from Ollama
+	storeModel string // model the stored embeddings came from, "" until known
+	dims       int    // dimension of the stored embeddings, 0 until known
+
+	batchWorkers int
+
+	mu          sync.RWMutex
+	vectors     map[int64]vector // nil until first search
+	hashVectors map[int64]vector // fallback vectors, nil until first degraded search
+
+	degraded      atomic.Bool  // Ollama unavailable, see Mode
+	lastProbe     atomic.Int64 // unix nanos of the last embedding call
+	probeInterval time.Duration
+	hashedPending atomic.Bool // documents with fallback embeddings await re-embedding
+
+	dedupMu        sync.Mutex // serialises duplicate checks with inserts
+	dedupThreshold float64    // 0 = no dedup on Add, see SetDedupThreshold
 }
 
 // New opens (or creates) the semantic store at dbPath.
@@ -53,12 +77,23 @@ func New(dbPath, ollamaURL, model string) (*Store, error) {
 	if err := migrate(db); err != nil {
 		return nil, fmt.Errorf("semantic: migrate: %w", err)
 	}
-	return &Store{
-		db:         db,
-		ollamaURL:  strings.TrimRight(ollamaURL, "/"),
-		model:      model,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
-	}, nil
+	s := &Store{
+		db:            db,
+		ollamaURL:     strings.TrimRight(ollamaURL, "/"),
+		model:         model,
+		httpClient:    &http.Client{Timeout: 30 * time.Second},
+		batchWorkers:  DefaultBatchWorkers,
+		probeInterval: defaultProbeInterval,
+	}
+	var pending bool
+	if err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM documents WHERE embedder = ?)`, hashEmbedder).Scan(&pending); err != nil {
+		return nil, fmt.Errorf("semantic: open db: %w", err)
+	}
+	s.hashedPending.Store(pending)
+	if err := s.loadEmbeddingInfo(); err != nil {
+		return nil, fmt.Errorf("semantic: open db: %w", err)
+	}
+	return s, nil
 }
 
 func migrate(db *sql.DB) error {
@@ -68,16 +103,79 @@ func migrate(db *sql.DB) error {
 			content    TEXT    NOT NULL,
 			source     TEXT    NOT NULL DEFAULT '',
 			created_at INTEGER NOT NULL,
-			embedding  TEXT    NOT NULL  -- JSON array of float64
+			embedding  BLOB    NOT NULL,  -- packed little-endian float32
+			metadata   TEXT    NOT NULL DEFAULT '{}',  -- JSON object of string tags
+			norm       REAL    NOT NULL DEFAULT 0  -- L2 norm of embedding
 		);
 		CREATE INDEX IF NOT EXISTS idx_documents_source ON documents(source);
+		CREATE TABLE IF NOT EXISTS store_meta (
+			key   TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		);
 	`)
+	if err != nil {
+		return err
+	}
+	// Stores created before metadata support lack the column.
+	if err := addColumnIfMissing(db, "documents", "metadata", `TEXT NOT NULL DEFAULT '{}'`); err != nil {
+		return err
+	}
+	// Older stores kept embeddings as JSON text without a norm.
+	if err := addColumnIfMissing(db, "documents", "norm", `REAL NOT NULL DEFAULT 0`); err != nil {
+		return err
+	}
+	// Embedder is the model name, or "hash" for the offline fallback.
+	if err := addColumnIfMissing(db, "documents", "embedder", `TEXT NOT NULL DEFAULT ''`); err != nil {
+		return err
+	}
+	return convertLegacyEmbeddings(db)
+}
+
+// addColumnIfMissing adds column to table unless it already exists.
+func addColumnIfMissing(db *sql.DB, table, column, decl string) error {
+	rows, err := db.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			cid         int
+			name, ctype string
+			notNull, pk int
+			dflt        sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &ctype, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	_, err = db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, decl))
 	return err
 }
 
-// Embed fetches an embedding vector from Ollama for the given text.
+// Embed fetches an embedding vector from Ollama for the given text. It never
+// falls back, but its outcome updates Mode.
 func (s *Store) Embed(ctx context.Context, text string) ([]float64, error) {
-	reqBody := map[string]string{"model": s.model, "prompt": text}
+	s.metaMu.Lock()
+	model := s.model
+	s.metaMu.Unlock()
+	return s.embedWith(ctx, model, text)
+}
+
+// embedWith is Embed with an explicit model.
+func (s *Store) embedWith(ctx context.Context, model, text string) (vec []float64, err error) {
+	defer func() {
+		if ctx.Err() == nil {
+			s.noteEmbed(err)
+		}
+	}()
+	reqBody := map[string]string{"model": model, "prompt": text}
 	body, _ := json.Marshal(reqBody)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.ollamaURL+"/api/embeddings", bytes.NewReader(body))
 	if err != nil {
@@ -90,7 +188,7 @@ func (s *Store) Embed(ctx context.Context, text string) ([]float64, error) {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("semantic: embed: status %d", resp.StatusCode)
+		return nil, &statusError{code: resp.StatusCode}
 	}
 	var result struct {
 		Embedding []float64 `json:"embedding"`
@@ -103,79 +201,245 @@ func (s *Store) Embed(ctx context.Context, text string) ([]float64, error) {
 
 // Add embeds and stores a document.
 func (s *Store) Add(ctx context.Context, content, source string) (*Document, error) {
-	vec, err := s.Embed(ctx, content)
+	return s.AddWithMeta(ctx, content, source, nil)
+}
+
+// AddWithMeta embeds and stores a document tagged with arbitrary key-value metadata.
+// While Ollama is unavailable the document is stored with the fallback
+// embedding and re-embedded once Ollama is back. With a dedup threshold set,
+// a near-identical document from the same source is replaced instead.
+func (s *Store) AddWithMeta(ctx context.Context, content, source string, meta map[string]string) (*Document, error) {
+	vec, hashed, err := s.embed(ctx, content)
 	if err != nil {
 		return nil, err
 	}
-	vecJSON, err := json.Marshal(vec)
+	s.dedupMu.Lock()
+	defer s.dedupMu.Unlock()
+	if s.dedupThreshold > 0 {
+		doc, ok, err := s.replaceDuplicate(ctx, content, source, meta, vec, hashed, s.dedupThreshold)
+		if err != nil || ok {
+			return doc, err
+		}
+	}
+	doc, v, err := insertDocument(ctx, s.db, content, source, meta, vec, s.embedderName(hashed))
 	if err != nil {
 		return nil, err
 	}
+	s.cacheVector(doc.ID, v, content)
+	return doc, nil
+}
+
+// embedderName is the embedder column value for a new document.
+func (s *Store) embedderName(hashed bool) string {
+	if hashed {
+		s.hashedPending.Store(true)
+		return hashEmbedder
+	}
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+	return s.model
+}
+
+// execer is satisfied by *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertDocument writes one embedded document and returns it with its cached vector.
+func insertDocument(ctx context.Context, db execer, content, source string, meta map[string]string, vec []float64, embedder string) (*Document, vector, error) {
+	if meta == nil {
+		meta = map[string]string{}
+	}
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return nil, vector{}, err
+	}
 	now := time.Now().UTC()
-	res, err := s.db.ExecContext(ctx,
-		`INSERT INTO documents (content, source, created_at, embedding) VALUES (?, ?, ?, ?)`,
-		content, source, now.Unix(), string(vecJSON),
+	buf, v := packVector(vec)
+	v.hashed = embedder == hashEmbedder
+	res, err := db.ExecContext(ctx,
+		`INSERT INTO documents (content, source, created_at, embedding, metadata, norm, embedder) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		content, source, now.Unix(), buf, string(metaJSON), v.norm, embedder,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("semantic: insert: %w", err)
+		return nil, vector{}, fmt.Errorf("semantic: insert: %w", err)
 	}
 	id, _ := res.LastInsertId()
-	return &Document{ID: id, Content: content, Source: source, CreatedAt: now}, nil
+	return &Document{ID: id, Content: content, Source: source, Metadata: meta, CreatedAt: now}, v, nil
 }
 
 // Search returns the topK most semantically similar documents to query.
 func (s *Store) Search(ctx context.Context, query string, topK int) ([]Document, error) {
-	queryVec, err := s.Embed(ctx, query)
+	return s.SearchFiltered(ctx, query, topK, nil)
+}
+
+// SearchFiltered is Search restricted to documents whose metadata contains
+// every key-value pair in filter. Filtering happens in SQL, before scoring.
+// Scoring runs against the in-memory vector cache; only the topK winning
+// rows are read back from SQLite. While Ollama is unavailable every document
+// is scored with the fallback embedding instead (see Mode).
+func (s *Store) SearchFiltered(ctx context.Context, query string, topK int, filter map[string]string) ([]Document, error) {
+	queryVec, hashed, err := s.embed(ctx, query)
 	if err != nil {
 		return nil, err
 	}
-	rows, err := s.db.QueryContext(ctx, `SELECT id, content, source, created_at, embedding FROM documents`)
+	if !hashed && s.hashedPending.Load() {
+		if err := s.reembedHashed(ctx); err != nil {
+			log.Warn().Err(err).Msg("semantic: re-embedding deferred")
+		}
+	}
+	var candidates map[int64]bool
+	if len(filter) > 0 {
+		if candidates, err = s.filterIDs(ctx, filter); err != nil {
+			return nil, err
+		}
+	}
+
+	results, err := s.rank(ctx, queryVec, hashed, candidates)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	if topK > len(results) {
+		topK = len(results)
+	}
+	if topK <= 0 {
+		return []Document{}, nil
+	}
+	ids := make([]int64, topK)
+	for i := range ids {
+		ids[i] = results[i].id
+	}
+	docs, err := s.loadDocuments(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Document, 0, topK)
+	for _, r := range results[:topK] {
+		if d, ok := docs[r.id]; ok {
+			d.Score = r.score
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
 
-	type scored struct {
-		Doc   Document
-		score float64
+// scored is a document ID with its similarity to a query.
+type scored struct {
+	id    int64
+	score float64
+}
+
+// rank scores vec against the cached vectors of its embedding space,
+// restricted to candidates if non-nil, best first.
+func (s *Store) rank(ctx context.Context, vec []float64, hashed bool, candidates map[int64]bool) ([]scored, error) {
+	s.mu.Lock()
+	var err error
+	if hashed {
+		err = s.loadHashVectors(ctx)
+	} else {
+		err = s.loadVectors(ctx)
 	}
-	var results []scored
-	for rows.Next() {
-		var d Document
-		var createdUnix int64
-		var embJSON string
-		if err := rows.Scan(&d.ID, &d.Content, &d.Source, &createdUnix, &embJSON); err != nil {
-			return nil, err
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	qNorm := norm64(vec)
+	s.mu.RLock()
+	pool := s.vectors
+	if hashed {
+		pool = s.hashVectors
+	}
+	results := make([]scored, 0, len(pool))
+	for id, v := range pool {
+		if candidates != nil && !candidates[id] {
+			continue
 		}
-		d.CreatedAt = time.Unix(createdUnix, 0).UTC()
-		var vec []float64
-		if err := json.Unmarshal([]byte(embJSON), &vec); err != nil {
+		// A fallback vector left by a failed re-embed is in another space.
+		if v.hashed != hashed {
 			continue
 		}
-		score := cosineSimilarity(queryVec, vec)
-		results = append(results, scored{Doc: d, score: score})
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+		results = append(results, scored{id: id, score: score(vec, qNorm, v)})
 	}
+	s.mu.RUnlock()
+
 	sort.Slice(results, func(i, j int) bool {
-		return results[i].score > results[j].score
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return results[i].id < results[j].id
 	})
-	if topK > len(results) {
-		topK = len(results)
+	return results, nil
+}
+
+// filterIDs returns the IDs of documents whose metadata matches filter.
+func (s *Store) filterIDs(ctx context.Context, filter map[string]string) (map[int64]bool, error) {
+	var where []string
+	var args []interface{}
+	for k, v := range filter {
+		where = append(where, `json_extract(metadata, ?) = ?`)
+		args = append(args, metaPath(k), v)
 	}
-	out := make([]Document, topK)
-	for i := 0; i < topK; i++ {
-		out[i] = results[i].Doc
-		out[i].Score = results[i].score
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM documents WHERE `+strings.Join(where, " AND "), args...)
+	if err != nil {
+		return nil, err
 	}
-	return out, nil
+	defer rows.Close()
+	ids := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// loadDocuments reads the given documents (without embeddings) by ID.
+func (s *Store) loadDocuments(ctx context.Context, ids []int64) (map[int64]Document, error) {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	q := `SELECT id, content, source, created_at, metadata FROM documents WHERE id IN (?` +
+		strings.Repeat(",?", len(ids)-1) + `)`
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	docs := make(map[int64]Document, len(ids))
+	for rows.Next() {
+		var d Document
+		var createdUnix int64
+		var metaJSON string
+		if err := rows.Scan(&d.ID, &d.Content, &d.Source, &createdUnix, &metaJSON); err != nil {
+			return nil, err
+		}
+		d.CreatedAt = time.Unix(createdUnix, 0).UTC()
+		_ = json.Unmarshal([]byte(metaJSON), &d.Metadata)
+		docs[d.ID] = d
+	}
+	return docs, rows.Err()
+}
+
+// metaPath builds a JSON path selecting key from the metadata object.
+// The key is quoted so dots and spaces in tag names are matched literally.
+func metaPath(key string) string {
+	return `$."` + strings.ReplaceAll(key, `"`, `\"`) + `"`
 }
 
 // Delete removes a document by ID.
 func (s *Store) Delete(ctx context.Context, id int64) error {
-	_, err := s.db.ExecContext(ctx, `DELETE FROM documents WHERE id = ?`, id)
-	return err
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM documents WHERE id = ?`, id); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.vectors, id)
+	delete(s.hashVectors, id)
+	s.mu.Unlock()
+	return nil
 }
 
 // Count returns the total number of stored documents.
@@ -188,6 +452,12 @@ func (s *Store) Count(ctx context.Context) (int, error) {
 // Close closes the underlying database.
 func (s *Store) Close() error { return s.db.Close() }
 
+// Similarity returns the cosine similarity of two embeddings from Embed,
+// or 0 when their lengths differ.
+func Similarity(a, b []float64) float64 {
+	return cosineSimilarity(a, b)
+}
+
 // cosineSimilarity computes cosine similarity between two equal-length vectors.
 func cosineSimilarity(a, b []float64) float64 {
 	if len(a) != len(b) || len(a) == 0 {
@@ -2,10 +2,14 @@ package semantic
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"math"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 )
 
@@ -114,3 +118,369 @@ func TestStoreDelete(t *testing.T) {
 		t.Errorf("expected 0 after delete, got %d", count)
 	}
 }
+
+func TestStoreSearchFiltered(t *testing.T) {
+	ts := mockEmbedServer(t)
+	defer ts.Close()
+
+	store, err := New(":memory:", ts.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	_, _ = store.AddWithMeta(ctx, "alpha roadmap", "notes", map[string]string{"project": "alpha", "author": "sam"})
+	_, _ = store.AddWithMeta(ctx, "beta roadmap", "notes", map[string]string{"project": "beta"})
+	_, _ = store.Add(ctx, "untagged roadmap", "notes")
+
+	results, err := store.SearchFiltered(ctx, "roadmap", 10, map[string]string{"project": "alpha"})
+	if err != nil {
+		t.Fatalf("SearchFiltered: %v", err)
+	}
+	if len(results) != 1 || results[0].Content != "alpha roadmap" {
+		t.Fatalf("expected only the alpha document, got %+v", results)
+	}
+	if results[0].Metadata["author"] != "sam" {
+		t.Errorf("expected metadata on result, got %v", results[0].Metadata)
+	}
+
+	all, err := store.SearchFiltered(ctx, "roadmap", 10, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Errorf("expected 3 results without filter, got %d", len(all))
+	}
+}
+
+func TestVectorPackRoundTrip(t *testing.T) {
+	vec := []float64{0.5, -1.25, 3, 0}
+	buf, v := packVector(vec)
+	if len(buf) != 16 {
+		t.Fatalf("expected 4 bytes per component, got %d", len(buf))
+	}
+	got, err := decodeVector(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range vec {
+		if float64(got[i]) != vec[i] {
+			t.Errorf("component %d = %v, want %v", i, got[i], vec[i])
+		}
+	}
+	if math.Abs(score(vec, norm64(vec), v)-1) > 1e-6 {
+		t.Errorf("vector should score 1 against itself, got %f", score(vec, norm64(vec), v))
+	}
+}
+
+func TestStoreSearchCacheTracksDelete(t *testing.T) {
+	ts := mockEmbedServer(t)
+	defer ts.Close()
+	store, err := New(":memory:", ts.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	a, _ := store.Add(ctx, "hello", "test")
+	if _, err := store.Search(ctx, "hello", 5); err != nil { // warms the cache
+		t.Fatal(err)
+	}
+	_, _ = store.Add(ctx, "help", "test")
+	if err := store.Delete(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	results, err := store.Search(ctx, "hello", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Content != "help" {
+		t.Errorf("cache out of sync after Add/Delete: %+v", results)
+	}
+}
+
+func TestStoreConvertsLegacyJSONEmbeddings(t *testing.T) {
+	ts := mockEmbedServer(t)
+	defer ts.Close()
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE documents (
+			id INTEGER PRIMARY KEY AUTOINCREMENT, content TEXT NOT NULL,
+			source TEXT NOT NULL DEFAULT '', created_at INTEGER NOT NULL, embedding TEXT NOT NULL);
+		INSERT INTO documents (content, source, created_at, embedding) VALUES ('hello', 'old', 0, '[0.104,0.101,0.108,0.108]');`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := New(path, ts.URL, "")
+	if err != nil {
+		t.Fatalf("open legacy store: %v", err)
+	}
+	defer store.Close()
+	results, err := store.Search(context.Background(), "hello", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Score < 0.99 {
+		t.Errorf("legacy document not searchable after conversion: %+v", results)
+	}
+}
+
+func TestStoreAddBatch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req["prompt"] == "bad" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"embedding": []float64{float64(len(req["prompt"])), 1}})
+	}))
+	defer ts.Close()
+	store, err := New(":memory:", ts.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	store.SetBatchWorkers(2)
+
+	ctx := context.Background()
+	docs, err := store.AddBatch(ctx, []BatchDoc{
+		{Content: "first", Source: "kb"},
+		{Content: "bad", Source: "kb"},
+		{Content: "third", Source: "kb", Metadata: map[string]string{"path": "c.md"}},
+	})
+	var be *BatchError
+	if !errors.As(err, &be) || len(be.Failed) != 1 || be.Failed[1] == nil {
+		t.Fatalf("expected a BatchError for doc 1, got %v", err)
+	}
+	if docs[0] == nil || docs[1] != nil || docs[2] == nil || docs[2].Metadata["path"] != "c.md" {
+		t.Errorf("unexpected batch result: %+v", docs)
+	}
+	if n, _ := store.Count(ctx); n != 2 {
+		t.Errorf("expected 2 stored documents, got %d", n)
+	}
+}
+
+func TestStoreDegradesWithoutOllama(t *testing.T) {
+	var down atomic.Bool
+	down.Store(true)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.Error(w, `model "nomic-embed-text" not found`, http.StatusNotFound)
+			return
+		}
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(map[string]interface{}{"embedding": []float64{float64(len(req["prompt"])), 1}})
+	}))
+	defer ts.Close()
+	store, err := New(":memory:", ts.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	if _, err := store.Add(ctx, "rotate the vault keys every quarter", "notes"); err != nil {
+		t.Fatalf("Add should fall back while Ollama is down: %v", err)
+	}
+	if _, err := store.Add(ctx, "buy milk and eggs", "notes"); err != nil {
+		t.Fatal(err)
+	}
+	if store.Mode() != ModeDegraded {
+		t.Fatalf("expected degraded mode, got %s", store.Mode())
+	}
+	results, err := store.Search(ctx, "vault keys", 1)
+	if err != nil {
+		t.Fatalf("Search should fall back while Ollama is down: %v", err)
+	}
+	if len(results) != 1 || results[0].Content != "rotate the vault keys every quarter" {
+		t.Fatalf("fallback search returned %+v", results)
+	}
+
+	down.Store(false)
+	store.probeInterval = 0
+	if _, err := store.Search(ctx, "vault keys", 1); err != nil {
+		t.Fatal(err)
+	}
+	if store.Mode() != ModeOllama {
+		t.Errorf("expected recovery to ollama mode, got %s", store.Mode())
+	}
+	var pending int
+	store.db.QueryRow(`SELECT COUNT(*) FROM documents WHERE embedder = ?`, hashEmbedder).Scan(&pending)
+	if pending != 0 {
+		t.Errorf("%d fallback documents were not re-embedded", pending)
+	}
+}
+
+func TestStoreProbeUnreachable(t *testing.T) {
+	ts := mockEmbedServer(t)
+	url := ts.URL
+	ts.Close()
+	store, err := New(":memory:", url, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	if err := store.Probe(context.Background()); err == nil {
+		t.Fatal("expected probe of a closed server to fail")
+	}
+	if store.Mode() != ModeDegraded {
+		t.Errorf("expected degraded mode, got %s", store.Mode())
+	}
+}
+
+// bagOfWordsServer embeds text as hashed word counts, so texts with the
+// same words are identical and others are not.
+func bagOfWordsServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(map[string]interface{}{"embedding": hashEmbed(req["prompt"])})
+	}))
+}
+
+func TestStoreAddDedup(t *testing.T) {
+	ts := bagOfWordsServer(t)
+	defer ts.Close()
+	store, err := New(":memory:", ts.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	store.SetDedupThreshold(0.99)
+	ctx := context.Background()
+
+	first, err := store.Add(ctx, "hello world", "conversation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := store.AddWithMeta(ctx, "hello world!", "conversation", map[string]string{"turn": "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("near-duplicate inserted as %d, expected update of %d", second.ID, first.ID)
+	}
+	if _, err := store.Add(ctx, "hello world", "notes"); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := store.Count(ctx); n != 2 {
+		t.Errorf("expected 2 documents (dedup is per source), got %d", n)
+	}
+	results, err := store.Search(ctx, "hello", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Source == "conversation" && (r.Content != "hello world!" || r.Metadata["turn"] != "2") {
+			t.Errorf("duplicate not updated with the newer content: %+v", r)
+		}
+	}
+}
+
+func TestStoreDedupExisting(t *testing.T) {
+	ts := bagOfWordsServer(t)
+	defer ts.Close()
+	store, err := New(":memory:", ts.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	for _, c := range []string{"hello world", "zebra crossing", "Hello, world"} {
+		if _, err := store.Add(ctx, c, "conversation"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.Add(ctx, "hello world", "notes"); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := store.Dedup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Errorf("expected 1 duplicate removed, got %d", removed)
+	}
+	results, err := store.Search(ctx, "hello", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Source == "conversation" && r.Content == "hello world" {
+			t.Error("older duplicate should have been removed, newest kept")
+		}
+	}
+	if n, _ := store.Count(ctx); n != 3 {
+		t.Errorf("expected 3 documents left, got %d", n)
+	}
+}
+
+func TestStoreRejectsMismatchedModelAndReembeds(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		vec := hashEmbed(req["prompt"])
+		if req["model"] == "small" {
+			vec = vec[:8]
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"embedding": vec})
+	}))
+	defer ts.Close()
+	path := filepath.Join(t.TempDir(), "sem.db")
+	ctx := context.Background()
+
+	store, err := New(path, ts.URL, "large")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Add(ctx, "rotate the vault keys", "notes"); err != nil {
+		t.Fatal(err)
+	}
+	if store.Model() != "large" || store.Dimensions() != hashDims {
+		t.Errorf("recorded %s/%d, expected large/%d", store.Model(), store.Dimensions(), hashDims)
+	}
+	store.Close()
+
+	store, err = New(path, ts.URL, "small")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if _, err := store.Add(ctx, "buy milk", "notes"); !errors.Is(err, ErrEmbeddingMismatch) {
+		t.Fatalf("expected ErrEmbeddingMismatch from Add, got %v", err)
+	}
+	if _, err := store.Search(ctx, "vault", 1); !errors.Is(err, ErrEmbeddingMismatch) {
+		t.Fatalf("expected ErrEmbeddingMismatch from Search, got %v", err)
+	}
+
+	if err := store.Reembed(ctx, "small"); err != nil {
+		t.Fatal(err)
+	}
+	if store.Model() != "small" || store.Dimensions() != 8 {
+		t.Errorf("after Reembed recorded %s/%d, expected small/8", store.Model(), store.Dimensions())
+	}
+	if _, err := store.Add(ctx, "buy milk", "notes"); err != nil {
+		t.Fatalf("Add after Reembed: %v", err)
+	}
+	results, err := store.Search(ctx, "rotate the vault keys", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Content != "rotate the vault keys" {
+		t.Errorf("search after Reembed returned %+v", results)
+	}
+}
@@ -0,0 +1,170 @@
+package semantic
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// vector is a cached embedding with its precomputed L2 norm, so scoring a
+// document against a query only needs the dot product.
+type vector struct {
+	v      []float32
+	norm   float64
+	hashed bool // fallback embedding, see hashEmbed
+}
+
+// encodeVector packs vec as little-endian float32, the on-disk embedding format.
+func encodeVector(vec []float64) []byte {
+	buf := make([]byte, 4*len(vec))
+	for i, f := range vec {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(float32(f)))
+	}
+	return buf
+}
+
+// packVector encodes vec for storage and returns the cached form, whose norm
+// is computed from the stored float32 values.
+func packVector(vec []float64) ([]byte, vector) {
+	buf := encodeVector(vec)
+	v, _ := decodeVector(buf)
+	return buf, vector{v: v, norm: norm32(v)}
+}
+
+// decodeVector unpacks an embedding written by encodeVector.
+func decodeVector(buf []byte) ([]float32, error) {
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf("semantic: corrupt embedding (%d bytes)", len(buf))
+	}
+	vec := make([]float32, len(buf)/4)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return vec, nil
+}
+
+func norm64(vec []float64) float64 {
+	var sum float64
+	for _, f := range vec {
+		sum += f * f
+	}
+	return math.Sqrt(sum)
+}
+
+func norm32(vec []float32) float64 {
+	var sum float64
+	for _, f := range vec {
+		sum += float64(f) * float64(f)
+	}
+	return math.Sqrt(sum)
+}
+
+// score is the cosine similarity of q (with norm qNorm) and d.
+func score(q []float64, qNorm float64, d vector) float64 {
+	if len(q) != len(d.v) || qNorm == 0 || d.norm == 0 {
+		return 0
+	}
+	var dot float64
+	for i, f := range d.v {
+		dot += q[i] * float64(f)
+	}
+	return dot / (qNorm * d.norm)
+}
+
+// convertLegacyEmbeddings rewrites embeddings stored as JSON text by older
+// versions into the packed float32 format and fills in their norms.
+func convertLegacyEmbeddings(db *sql.DB) error {
+	rows, err := db.Query(`SELECT id, embedding FROM documents WHERE typeof(embedding) = 'text'`)
+	if err != nil {
+		return err
+	}
+	type legacy struct {
+		id  int64
+		vec []float64
+	}
+	var pending []legacy
+	for rows.Next() {
+		var (
+			id  int64
+			raw string
+			vec []float64
+		)
+		if err := rows.Scan(&id, &raw); err != nil {
+			rows.Close()
+			return err
+		}
+		if err := json.Unmarshal([]byte(raw), &vec); err != nil {
+			continue // unreadable rows are skipped by Search, as before
+		}
+		pending = append(pending, legacy{id, vec})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, l := range pending {
+		buf, v := packVector(l.vec)
+		if _, err := db.Exec(`UPDATE documents SET embedding = ?, norm = ? WHERE id = ?`, buf, v.norm, l.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadVectors fills the in-memory vector cache on first use. Caller must
+// hold s.mu for writing.
+func (s *Store) loadVectors(ctx context.Context) error {
+	if s.vectors != nil {
+		return nil
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT id, embedding, norm, embedder FROM documents WHERE typeof(embedding) = 'blob'`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	vectors := make(map[int64]vector)
+	for rows.Next() {
+		var (
+			id       int64
+			raw      []byte
+			norm     float64
+			embedder string
+		)
+		if err := rows.Scan(&id, &raw, &norm, &embedder); err != nil {
+			return err
+		}
+		v, err := decodeVector(raw)
+		if err != nil {
+			continue
+		}
+		if norm == 0 {
+			norm = norm32(v)
+		}
+		vectors[id] = vector{v: v, norm: norm, hashed: embedder == hashEmbedder}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	s.vectors = vectors
+	return nil
+}
+
+// cacheVector records a newly inserted document's vector, and its fallback
+// vector, in whichever caches are warm.
+func (s *Store) cacheVector(id int64, v vector, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.vectors != nil {
+		s.vectors[id] = v
+	}
+	if s.hashVectors != nil {
+		if !v.hashed {
+			_, v = packVector(hashEmbed(content))
+			v.hashed = true
+		}
+		s.hashVectors[id] = v
+	}
+}
@@ -0,0 +1,312 @@
+package shadow
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Mode selects how Run treats the shadow configuration.
+type Mode int
+
+const (
+	// ModePassive serves every request from the baseline and replays it
+	// against the shadow in the background, with WithShadow set on the
+	// context so the shadow run must not cause side effects.
+	ModePassive Mode = iota
+	// ModeABTest serves each request from exactly one variant, chosen by
+	// hashing the task ID, and records per-variant metrics.
+	ModeABTest
+)
+
+// DefaultABSplit is the share of A/B traffic routed to the shadow variant.
+const DefaultABSplit = 0.5
+
+const (
+	// DefaultMinSamplesForPromotion is how many runs each variant needs
+	// before the engine draws any conclusion from an A/B test.
+	DefaultMinSamplesForPromotion = 30
+	// SignificanceLevel is the p-value below which a difference in success
+	// rate is treated as real rather than noise.
+	SignificanceLevel = 0.05
+)
+
+// Decision is the outcome of an A/B experiment.
+type Decision string
+
+const (
+	DecisionNone     Decision = ""         // still collecting samples
+	DecisionDisabled Decision = "disabled" // shadow significantly worse
+	DecisionPromoted Decision = "promoted" // user approved the shadow
+	DecisionRejected Decision = "rejected" // user declined the shadow
+)
+
+// EvolutionEvent records a decision taken on an experiment.
+type EvolutionEvent struct {
+	Time     time.Time
+	Task     string
+	Decision Decision
+	Reason   string
+}
+
+// VariantStats aggregates the real outcomes of one variant.
+type VariantStats struct {
+	Runs      int
+	Failures  int
+	TotalCost float64
+	Tokens    int
+	Latency   time.Duration // cumulative
+}
+
+// SuccessRate is the fraction of runs that did not fail.
+func (v VariantStats) SuccessRate() float64 {
+	if v.Runs == 0 {
+		return 0
+	}
+	return float64(v.Runs-v.Failures) / float64(v.Runs)
+}
+
+// avgCost is the mean cost of successful runs.
+func (v VariantStats) avgCost() float64 {
+	if n := v.Runs - v.Failures; n > 0 {
+		return v.TotalCost / float64(n)
+	}
+	return 0
+}
+
+// Experiment holds the A/B statistics of one task.
+type Experiment struct {
+	Baseline VariantStats
+	Shadow   VariantStats
+	Decision Decision
+
+	asking bool // a promotion prompt is outstanding
+}
+
+type shadowKey struct{}
+
+// WithShadow marks ctx as a shadow run. Tasks should check IsShadow and skip
+// side effects such as sending messages or writing files.
+func WithShadow(ctx context.Context) context.Context {
+	return context.WithValue(ctx, shadowKey{}, true)
+}
+
+// IsShadow reports whether ctx belongs to a passive shadow run.
+func IsShadow(ctx context.Context) bool {
+	v, _ := ctx.Value(shadowKey{}).(bool)
+	return v
+}
+
+// SetMode switches between ModePassive (the default) and ModeABTest.
+func (e *Engine) SetMode(m Mode) {
+	e.mu.Lock()
+	e.mode = m
+	e.mu.Unlock()
+}
+
+// SetMinSamplesForPromotion sets how many runs each variant needs before an
+// A/B experiment may be disabled or proposed for promotion.
+func (e *Engine) SetMinSamplesForPromotion(n int) {
+	if n < 1 {
+		n = 1
+	}
+	e.mu.Lock()
+	e.minSamples = n
+	e.mu.Unlock()
+}
+
+// Events returns the decisions taken so far, oldest first.
+func (e *Engine) Events() []EvolutionEvent {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]EvolutionEvent(nil), e.events...)
+}
+
+// SetABSplit sets the fraction (0-1) of A/B traffic sent to the shadow.
+func (e *Engine) SetABSplit(fraction float64) {
+	if fraction < 0 {
+		fraction = 0
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	e.mu.Lock()
+	e.abSplit = fraction
+	e.mu.Unlock()
+}
+
+// Run executes one production request for taskName and returns the metrics
+// of the variant that served it. In ModePassive that is always the baseline
+// and the shadow is evaluated asynchronously; in ModeABTest the variant is
+// picked deterministically from taskID, so retries of a task hit the same arm.
+// Once an experiment is decided, all traffic goes to the winning variant.
+func (e *Engine) Run(ctx context.Context, taskID, taskName string, baseline, shadow Task) (*Metrics, error) {
+	e.mu.Lock()
+	mode, split := e.mode, e.abSplit
+	var decision Decision
+	if exp, ok := e.stats[taskName]; ok {
+		decision = exp.Decision
+	}
+	e.mu.Unlock()
+
+	if mode == ModeABTest {
+		useShadow := assignShadow(taskID, split)
+		switch decision {
+		case DecisionPromoted:
+			useShadow = true
+		case DecisionDisabled, DecisionRejected:
+			useShadow = false
+		}
+		task := baseline
+		if useShadow {
+			task = shadow
+		}
+		m, err := runTask(ctx, task)
+		e.record(taskName, useShadow, m, err)
+		if decision == DecisionNone {
+			e.evaluateExperiment(ctx, taskName)
+		}
+		return m, err
+	}
+
+	base, err := runTask(ctx, baseline)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		bg := context.WithoutCancel(ctx)
+		sm, err := runTask(WithShadow(bg), shadow)
+		if err != nil {
+			log.Debug().Err(err).Str("task", taskName).Msg("Shadow run failed")
+			return
+		}
+		e.compare(bg, taskName, base, sm)
+	}()
+	return base, nil
+}
+
+// Stats returns a copy of the A/B statistics recorded for taskName.
+func (e *Engine) Stats(taskName string) Experiment {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if exp, ok := e.stats[taskName]; ok {
+		return *exp
+	}
+	return Experiment{}
+}
+
+func (e *Engine) record(taskName string, shadow bool, m *Metrics, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	exp, ok := e.stats[taskName]
+	if !ok {
+		exp = &Experiment{}
+		e.stats[taskName] = exp
+	}
+	v := &exp.Baseline
+	if shadow {
+		v = &exp.Shadow
+	}
+	v.Runs++
+	if err != nil {
+		v.Failures++
+		return
+	}
+	v.TotalCost += m.Cost
+	v.Tokens += m.Tokens
+	v.Latency += m.Latency
+}
+
+// evaluateExperiment decides an A/B experiment once both variants have
+// MinSamplesForPromotion runs. A shadow whose success rate is significantly
+// lower (two-proportion z-test) is disabled; one that is no worse and at
+// least 10% cheaper is proposed through the HITL gate in the background.
+func (e *Engine) evaluateExperiment(ctx context.Context, taskName string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	exp := e.stats[taskName]
+	if exp == nil || exp.Decision != DecisionNone || exp.asking {
+		return
+	}
+	b, s := exp.Baseline, exp.Shadow
+	if b.Runs < e.minSamples || s.Runs < e.minSamples {
+		return
+	}
+	z, p := twoProportionZ(s.Runs-s.Failures, s.Runs, b.Runs-b.Failures, b.Runs)
+	summary := fmt.Sprintf("shadow success %.1f%% vs baseline %.1f%% over %d/%d runs (z=%.2f, p=%.4f)",
+		s.SuccessRate()*100, b.SuccessRate()*100, s.Runs, b.Runs, z, p)
+
+	if z < 0 && p < SignificanceLevel {
+		e.decide(exp, taskName, DecisionDisabled, summary)
+		return
+	}
+	base, cheaper := b.avgCost(), s.avgCost()
+	if base <= 0 || (base-cheaper)/base < 0.10 {
+		return // keep collecting; no meaningful saving yet
+	}
+	exp.asking = true
+	prompt := fmt.Sprintf(
+		"A/B test on '%s': the shadow config is %.0f%% cheaper ($%.4f vs $%.4f per run) with %s.\n\nPromote it? [Y/N]",
+		taskName, (base-cheaper)/base*100, cheaper, base, summary,
+	)
+	go func() {
+		approved := e.hitl.AskPermission(context.WithoutCancel(ctx), "LOW", prompt)
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		exp.asking = false
+		if approved {
+			e.decide(exp, taskName, DecisionPromoted, summary)
+		} else {
+			e.decide(exp, taskName, DecisionRejected, summary)
+		}
+	}()
+}
+
+// decide records d on exp. Callers hold e.mu.
+func (e *Engine) decide(exp *Experiment, taskName string, d Decision, reason string) {
+	exp.Decision = d
+	e.events = append(e.events, EvolutionEvent{Time: time.Now(), Task: taskName, Decision: d, Reason: reason})
+	log.Info().Str("task", taskName).Str("decision", string(d)).Msg("Shadow A/B experiment concluded: " + reason)
+}
+
+// twoProportionZ compares success proportions x1/n1 and x2/n2 and returns
+// the z statistic and two-sided p-value. Identical degenerate rates (all
+// successes or all failures) yield z=0, p=1.
+func twoProportionZ(x1, n1, x2, n2 int) (z, p float64) {
+	p1, p2 := float64(x1)/float64(n1), float64(x2)/float64(n2)
+	pooled := float64(x1+x2) / float64(n1+n2)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(n1) + 1/float64(n2)))
+	if se == 0 {
+		return 0, 1
+	}
+	z = (p1 - p2) / se
+	return z, math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// assignShadow maps taskID onto [0,1) with FNV-1a and compares it to split.
+func assignShadow(taskID string, split float64) bool {
+	h := fnv.New64a()
+	h.Write([]byte(taskID))
+	return float64(h.Sum64()%10000)/10000 < split
+}
+
+// runTask runs t and fills in the wall-clock latency if the task did not
+// report one itself.
+func runTask(ctx context.Context, t Task) (*Metrics, error) {
+	start := time.Now()
+	m, err := t(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, fmt.Errorf("shadow: task returned no metrics")
+	}
+	if m.Latency == 0 {
+		m.Latency = time.Since(start)
+	}
+	return m, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -30,11 +31,24 @@ type HITLGate interface {
 // their efficiency to production, and prompts the user if it finds an optimization.
 type Engine struct {
 	hitl HITLGate
+
+	mu      sync.Mutex
+	mode    Mode
+	abSplit float64
+	stats   map[string]*Experiment
+	events  []EvolutionEvent
+
+	minSamples int
 }
 
-// New creates a new Shadow Mode evolution engine.
+// New creates a new Shadow Mode evolution engine in ModePassive.
 func New(hitl HITLGate) *Engine {
-	return &Engine{hitl: hitl}
+	return &Engine{
+		hitl:       hitl,
+		abSplit:    DefaultABSplit,
+		stats:      make(map[string]*Experiment),
+		minSamples: DefaultMinSamplesForPromotion,
+	}
 }
 
 // EvaluateAsync runs the shadow task in the background. If it outperforms
@@ -52,24 +66,31 @@ func (e *Engine) Evaluate(ctx context.Context, taskName string, baseline, shadow
 	log.Debug().Str("task", taskName).Msg("Starting baseline vs shadow execution...")
 
 	// 1. Run Baseline (Original Prompt/Model)
-	baseMetrics, err := baseline(ctx)
+	baseMetrics, err := runTask(ctx, baseline)
 	if err != nil {
 		return fmt.Errorf("baseline task failed: %w", err)
 	}
 
-	// 2. Run Shadow (New Prompt/Model)
-	shadowMetrics, err := shadow(ctx)
+	// 2. Run Shadow (New Prompt/Model), flagged so it suppresses side effects
+	shadowMetrics, err := runTask(WithShadow(ctx), shadow)
 	if err != nil {
 		return fmt.Errorf("shadow task failed: %w", err)
 	}
 
+	e.compare(ctx, taskName, baseMetrics, shadowMetrics)
+	return nil
+}
+
+// compare proposes the shadow configuration through the HITL gate if it is
+// significantly cheaper or faster than the baseline without degrading output.
+func (e *Engine) compare(ctx context.Context, taskName string, baseMetrics, shadowMetrics *Metrics) {
 	// Evaluation Criteria
 	// 1. Output quality guardrail: Output must remain structurally similar.
 	// (Heuristic: must be at least 80% of the length of the original. 
 	// In v2, this routes to a local LLM-as-a-judge for semantic verification).
 	if len(shadowMetrics.Output) < int(float64(len(baseMetrics.Output))*0.8) {
 		log.Debug().Msg("Shadow output was too degraded. Discarding experiment.")
-		return nil
+		return
 	}
 
 	// 2. Calculate savings
@@ -92,7 +113,7 @@ func (e *Engine) Evaluate(ctx context.Context, taskName string, baseline, shadow
 	// If no significant improvement, quietly discard the shadow run.
 	if len(reasons) == 0 {
 		log.Debug().Msg("Shadow run did not yield significant improvements.")
-		return nil
+		return
 	}
 
 	// Significant improvement found! Trigger HITL Gate.
@@ -119,8 +140,6 @@ func (e *Engine) Evaluate(ctx context.Context, taskName string, baseline, shadow
 	} else {
 		log.Info().Str("task", taskName).Msg("User rejected Shadow Mode upgrade.")
 	}
-
-	return nil
 }
 
 // generateDiffPreview creates a truncated visual diff for the user to review.
@@ -2,6 +2,7 @@ package shadow
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -81,3 +82,126 @@ func TestShadowEvolution_DiscardDegradedOutput(t *testing.T) {
 		t.Fatal("expected HITL gate NOT to be called because shadow output was degraded")
 	}
 }
+
+func TestRun_ABTestSplitsByTaskID(t *testing.T) {
+	engine := New(&mockGate{})
+	engine.SetMode(ModeABTest)
+
+	variant := func(name string, cost float64) Task {
+		return func(ctx context.Context) (*Metrics, error) {
+			return &Metrics{Cost: cost, Tokens: 10, Output: name}, nil
+		}
+	}
+	served := map[string]string{}
+	for i := 0; i < 200; i++ {
+		id := fmt.Sprintf("task-%d", i)
+		m, err := engine.Run(context.Background(), id, "digest", variant("base", 0.1), variant("shadow", 0.05))
+		if err != nil {
+			t.Fatal(err)
+		}
+		served[id] = m.Output
+	}
+
+	// Assignment is deterministic per task ID.
+	m, _ := engine.Run(context.Background(), "task-7", "digest", variant("base", 0.1), variant("shadow", 0.05))
+	if m.Output != served["task-7"] {
+		t.Errorf("task-7 served by %s, then %s", served["task-7"], m.Output)
+	}
+
+	stats := engine.Stats("digest")
+	if stats.Baseline.Runs+stats.Shadow.Runs != 201 {
+		t.Fatalf("runs = %+v", stats)
+	}
+	if stats.Shadow.Runs < 60 || stats.Baseline.Runs < 60 {
+		t.Errorf("split too uneven: %+v", stats)
+	}
+	if stats.Shadow.Tokens != 10*stats.Shadow.Runs {
+		t.Errorf("shadow tokens = %d", stats.Shadow.Tokens)
+	}
+}
+
+func TestRun_PassiveServesBaselineAndFlagsShadow(t *testing.T) {
+	engine := New(&mockGate{})
+	shadowCtx := make(chan bool, 1)
+	baseline := func(ctx context.Context) (*Metrics, error) {
+		if IsShadow(ctx) {
+			t.Error("baseline ran with shadow context")
+		}
+		return &Metrics{Cost: 0.1, Output: "base"}, nil
+	}
+	shadow := func(ctx context.Context) (*Metrics, error) {
+		shadowCtx <- IsShadow(ctx)
+		return &Metrics{Cost: 0.1, Output: "shadow"}, nil
+	}
+	m, err := engine.Run(context.Background(), "t1", "digest", baseline, shadow)
+	if err != nil || m.Output != "base" {
+		t.Fatalf("Run = %+v, %v", m, err)
+	}
+	select {
+	case flagged := <-shadowCtx:
+		if !flagged {
+			t.Error("shadow run was not marked with WithShadow")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("shadow never ran")
+	}
+}
+
+func TestABTest_SignificanceGate(t *testing.T) {
+	ok := func(ctx context.Context) (*Metrics, error) { return &Metrics{Cost: 0.1, Output: "ok"}, nil }
+	flaky := func(n *int) Task {
+		return func(ctx context.Context) (*Metrics, error) {
+			*n++
+			if *n%2 == 0 {
+				return nil, fmt.Errorf("boom")
+			}
+			return &Metrics{Cost: 0.1, Output: "ok"}, nil
+		}
+	}
+
+	// A 1-in-8 failure rate over a few dozen runs is within noise, even
+	// though the raw success-rate gap exceeds 0.1.
+	engine := New(&mockGate{})
+	engine.SetMode(ModeABTest)
+	engine.SetMinSamplesForPromotion(5)
+	rare := 0
+	rareFail := func(ctx context.Context) (*Metrics, error) {
+		rare++
+		if rare%8 == 0 {
+			return nil, fmt.Errorf("boom")
+		}
+		return &Metrics{Cost: 0.1, Output: "ok"}, nil
+	}
+	for i := 0; i < 30; i++ {
+		_, _ = engine.Run(context.Background(), fmt.Sprintf("t%d", i), "x", ok, rareFail)
+	}
+	if st := engine.Stats("x"); st.Decision != DecisionNone || st.Shadow.Failures == 0 {
+		t.Fatalf("stats = %+v, want failures but no decision", st)
+	}
+
+	// With enough samples the same gap is significant and the shadow is disabled.
+	engine = New(&mockGate{})
+	engine.SetMode(ModeABTest)
+	engine.SetMinSamplesForPromotion(40)
+	calls := 0
+	for i := 0; i < 400 && engine.Stats("x").Decision == DecisionNone; i++ {
+		_, _ = engine.Run(context.Background(), fmt.Sprintf("t%d", i), "x", ok, flaky(&calls))
+	}
+	if d := engine.Stats("x").Decision; d != DecisionDisabled {
+		t.Fatalf("decision = %q, want disabled (stats %+v)", d, engine.Stats("x"))
+	}
+	events := engine.Events()
+	if len(events) != 1 || !strings.Contains(events[0].Reason, "p=") {
+		t.Fatalf("events = %+v", events)
+	}
+}
+
+func TestTwoProportionZ(t *testing.T) {
+	if z, p := twoProportionZ(10, 10, 10, 10); z != 0 || p != 1 {
+		t.Errorf("identical rates: z=%v p=%v", z, p)
+	}
+	z, p := twoProportionZ(20, 40, 38, 40)
+	if z >= 0 || p >= 0.001 {
+		t.Errorf("50%% vs 95%%: z=%v p=%v", z, p)
+	}
+}
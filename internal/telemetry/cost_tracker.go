@@ -10,31 +10,43 @@ Security:
 */
 
 import (
+	"bytes"
 	"crypto/rand"
 	"database/sql"
+	"encoding/csv"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"math"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/Omkar0612/nexus-ai/internal/events"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/rs/zerolog/log"
 )
 
 // ModelPricing holds per-1M token pricing for a model.
 type ModelPricing struct {
-	Provider    string
-	Model       string
-	InputPer1M  float64 // USD per 1M input tokens
-	OutputPer1M float64 // USD per 1M output tokens
-	IsFree      bool
+	Provider    string  `json:"provider"`
+	Model       string  `json:"model"`
+	InputPer1M  float64 `json:"input_per_1m"`  // USD per 1M input tokens
+	OutputPer1M float64 `json:"output_per_1m"` // USD per 1M output tokens
+	IsFree      bool    `json:"is_free"`
 }
 
+// PricingFileEnv names a JSON pricing file merged over PricingTable by New.
+const PricingFileEnv = "NEXUS_PRICING_FILE"
+
+// pricingMu guards PricingTable once LoadPricing may modify it.
+var pricingMu sync.RWMutex
+
 // PricingTable is the built-in provider pricing table (updated Feb 2026).
+// Use LoadPricing rather than writing to it directly.
 var PricingTable = map[string]ModelPricing{
 	// Groq
 	"groq/llama-3.3-70b-versatile": {"groq", "llama-3.3-70b-versatile", 0.59, 0.79, false},
@@ -53,6 +65,49 @@ var PricingTable = map[string]ModelPricing{
 	"ollama/gemma2":   {"ollama", "gemma2", 0, 0, true},
 }
 
+// LoadPricing merges the ModelPricing entries of the JSON array in path over
+// PricingTable, overriding known models and adding new ones:
+//
+//	[{"provider": "groq", "model": "llama-4-scout", "input_per_1m": 0.11, "output_per_1m": 0.34}]
+//
+// Models missing from both tables are still estimated at $1/1M tokens.
+func LoadPricing(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("telemetry: read pricing: %w", err)
+	}
+	var entries []ModelPricing
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("telemetry: parse pricing %s: %w", path, err)
+	}
+	for i, e := range entries {
+		if e.Provider == "" || e.Model == "" {
+			return fmt.Errorf("telemetry: pricing entry %d: provider and model are required", i)
+		}
+		if e.InputPer1M < 0 || e.OutputPer1M < 0 {
+			return fmt.Errorf("telemetry: pricing entry %s/%s: negative price", e.Provider, e.Model)
+		}
+	}
+	pricingMu.Lock()
+	defer pricingMu.Unlock()
+	for _, e := range entries {
+		PricingTable[pricingKey(e.Provider, e.Model)] = e
+	}
+	log.Info().Int("models", len(entries)).Str("path", path).Msg("loaded pricing overrides")
+	return nil
+}
+
+func pricingKey(provider, model string) string {
+	return strings.ToLower(provider) + "/" + strings.ToLower(model)
+}
+
+func lookupPricing(provider, model string) (ModelPricing, bool) {
+	pricingMu.RLock()
+	defer pricingMu.RUnlock()
+	p, ok := PricingTable[pricingKey(provider, model)]
+	return p, ok
+}
+
 // UsageRecord stores a single LLM call's token usage.
 type UsageRecord struct {
 	ID           string
@@ -87,8 +142,20 @@ type CostTracker struct {
 	monthlyLimit float64
 	alertAt      float64 // fraction — alert when this fraction of budget is used
 	onAlert      func(msg string)
+	bus          *events.Bus
+
+	// paused mirrors the budget_pause table so Gate needs no query per call.
+	// It is reloaded at most every pauseRefresh to pick up resets made by
+	// another process.
+	paused       map[string]bool
+	pausedLoaded time.Time
+	pauseRefresh time.Duration
 }
 
+// defaultPauseRefresh bounds how long a `nexus cost reset` run in another
+// process takes to reach a running daemon.
+const defaultPauseRefresh = 5 * time.Second
+
 // randomID returns a cryptographically random hex ID with the given prefix.
 func randomID(prefix string) string {
 	b := make([]byte, 8)
@@ -98,8 +165,14 @@ func randomID(prefix string) string {
 	return prefix + "-" + hex.EncodeToString(b)
 }
 
-// New opens (or creates) the cost tracking database.
+// New opens (or creates) the cost tracking database. If NEXUS_PRICING_FILE
+// is set, that file is merged over the built-in pricing table first.
 func New(dataDir string, dailyLimit, monthlyLimit float64) (*CostTracker, error) {
+	if path := os.Getenv(PricingFileEnv); path != "" {
+		if err := LoadPricing(path); err != nil {
+			return nil, err
+		}
+	}
 	if dataDir == "" {
 		home, _ := os.UserHomeDir()
 		dataDir = filepath.Join(home, ".nexus")
@@ -123,8 +196,15 @@ func New(dataDir string, dailyLimit, monthlyLimit float64) (*CostTracker, error)
 		dailyLimit:   dailyLimit,
 		monthlyLimit: monthlyLimit,
 		alertAt:      0.80,
+		pauseRefresh: defaultPauseRefresh,
+	}
+	if err := ct.migrate(); err != nil {
+		return ct, err
 	}
-	return ct, ct.migrate()
+	if err := ct.loadPaused(); err != nil {
+		return ct, fmt.Errorf("telemetry: load budget pauses: %w", err)
+	}
+	return ct, nil
 }
 
 func (ct *CostTracker) migrate() error {
@@ -142,6 +222,10 @@ func (ct *CostTracker) migrate() error {
 			created_at    DATETIME DEFAULT CURRENT_TIMESTAMP
 		);
 		CREATE INDEX IF NOT EXISTS idx_usage_user_date ON usage(user_id, created_at);
+		CREATE TABLE IF NOT EXISTS budget_pause (
+			user_id   TEXT PRIMARY KEY,
+			paused_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
 	`)
 	return err
 }
@@ -151,6 +235,12 @@ func (ct *CostTracker) SetAlertCallback(fn func(msg string)) {
 	ct.onAlert = fn
 }
 
+// SetEventBus publishes budget warnings (events.KindBudgetWarning) and
+// breaches (events.KindBudgetBreach) to bus.
+func (ct *CostTracker) SetEventBus(bus *events.Bus) {
+	ct.bus = bus
+}
+
 // Record logs a completed LLM call and returns its USD cost.
 func (ct *CostTracker) Record(userID, provider, model, agent, sessionID string, inputTokens, outputTokens int) (float64, error) {
 	cost := ct.calculateCost(provider, model, inputTokens, outputTokens)
@@ -168,10 +258,80 @@ func (ct *CostTracker) Record(userID, provider, model, agent, sessionID string,
 	return cost, nil
 }
 
+// Gate reports whether userID's LLM calls may proceed. It returns false
+// with a reason once a Record call has pushed userID past a budget limit,
+// until ResetBudget clears the pause. Pauses are kept in memory and
+// persisted in costs.db; the in-memory set is reloaded every few seconds,
+// so `nexus cost reset` in another process lifts it for a running daemon.
+// If a reload fails the last known state is used.
+func (ct *CostTracker) Gate(userID string) (allowed bool, reason string) {
+	ct.mu.RLock()
+	stale := time.Since(ct.pausedLoaded) >= ct.pauseRefresh
+	ct.mu.RUnlock()
+	if stale {
+		if err := ct.loadPaused(); err != nil {
+			log.Warn().Err(err).Msg("budget pause reload failed, using last known state")
+		}
+	}
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	if ct.paused[userID] {
+		return false, "budget breached: LLM calls paused, run `nexus cost reset` to resume"
+	}
+	return true, ""
+}
+
+// ResetBudget lifts the auto-pause for userID. Spending is not erased, so
+// the next Record over the limit pauses calls again.
+func (ct *CostTracker) ResetBudget(userID string) error {
+	if _, err := ct.db.Exec(`DELETE FROM budget_pause WHERE user_id=?`, userID); err != nil {
+		return fmt.Errorf("telemetry: reset budget: %w", err)
+	}
+	ct.mu.Lock()
+	delete(ct.paused, userID)
+	ct.mu.Unlock()
+	log.Info().Msg("budget pause reset")
+	return nil
+}
+
+func (ct *CostTracker) setPaused(userID string) {
+	ct.mu.Lock()
+	ct.paused[userID] = true
+	ct.mu.Unlock()
+	if _, err := ct.db.Exec(`INSERT OR IGNORE INTO budget_pause (user_id) VALUES (?)`, userID); err != nil {
+		log.Error().Err(err).Msg("failed to record budget pause")
+	}
+}
+
+// loadPaused replaces the in-memory pause set with the budget_pause table.
+// On error the current set is left untouched and the next Gate retries.
+func (ct *CostTracker) loadPaused() error {
+	rows, err := ct.db.Query(`SELECT user_id FROM budget_pause`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	paused := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		paused[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	ct.mu.Lock()
+	ct.paused = paused
+	ct.pausedLoaded = time.Now()
+	ct.mu.Unlock()
+	return nil
+}
+
 // calculateCost computes the USD cost of a single LLM call.
 func (ct *CostTracker) calculateCost(provider, model string, inputTokens, outputTokens int) float64 {
-	key := strings.ToLower(provider) + "/" + strings.ToLower(model)
-	if pricing, ok := PricingTable[key]; ok {
+	if pricing, ok := lookupPricing(provider, model); ok {
 		if pricing.IsFree {
 			return 0
 		}
@@ -220,20 +380,40 @@ func (ct *CostTracker) GetStatus(userID string) (*BudgetStatus, error) {
 
 func (ct *CostTracker) checkBudget(userID string) {
 	status, err := ct.GetStatus(userID)
-	if err != nil || ct.onAlert == nil {
+	if err != nil {
+		return
+	}
+	if status.BudgetBreached {
+		ct.setPaused(userID)
+	}
+	if ct.onAlert == nil && ct.bus == nil {
 		return
 	}
 	if status.BudgetBreached {
-		msg := fmt.Sprintf("🚨 NEXUS Budget BREACHED\nDaily: $%.4f / $%.2f\nMonthly: $%.4f / $%.2f\n\n⏸ Auto-pausing LLM calls. Run `nexus budget reset` to resume.",
+		msg := fmt.Sprintf("🚨 NEXUS Budget BREACHED\nDaily: $%.4f / $%.2f\nMonthly: $%.4f / $%.2f\n\n⏸ Auto-pausing LLM calls. Run `nexus cost reset` to resume.",
 			status.DailySpent, status.DailyLimit, status.MonthlySpent, status.MonthlyLimit)
 		// Do NOT log userID — PII in log files.
 		log.Error().Msg("budget breached")
-		ct.onAlert(msg)
+		ct.alert(events.KindBudgetBreach, "high", msg, *status)
 	} else if status.NearLimit {
 		msg := fmt.Sprintf("⚠️ NEXUS Budget Warning\nDaily: $%.4f (%.0f%%)\nMonthly: $%.4f (%.0f%%)",
 			status.DailySpent, status.DailyPct, status.MonthlySpent, status.MonthlyPct)
+		ct.alert(events.KindBudgetWarning, "medium", msg, *status)
+	}
+}
+
+// alert delivers a budget message to the callback and the event bus.
+func (ct *CostTracker) alert(kind events.Kind, severity, msg string, status BudgetStatus) {
+	if ct.onAlert != nil {
 		ct.onAlert(msg)
 	}
+	ct.bus.Publish(events.Event{
+		Kind:     kind,
+		Source:   "cost_tracker",
+		Severity: severity,
+		Message:  msg,
+		Payload:  status,
+	})
 }
 
 // DailyReport returns a formatted daily cost report.
@@ -276,10 +456,104 @@ func (ct *CostTracker) DailyReport(userID string) (string, error) {
 	return sb.String(), nil
 }
 
+// sqliteTime formats t the way CURRENT_TIMESTAMP stores created_at (UTC),
+// so range comparisons on the column are correct.
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+// ExportCSV returns every usage record of userID with since <= created_at <
+// until as CSV, oldest first. A zero until means no upper bound.
+func (ct *CostTracker) ExportCSV(userID string, since, until time.Time) ([]byte, error) {
+	if until.IsZero() {
+		until = time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	rows, err := ct.db.Query(
+		`SELECT created_at, provider, model, agent, session_id, input_tokens, output_tokens, cost_usd
+		 FROM usage WHERE user_id=? AND created_at>=? AND created_at<?
+		 ORDER BY created_at, id`,
+		userID, sqliteTime(since), sqliteTime(until),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: export query: %w", err)
+	}
+	defer rows.Close()
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"date", "provider", "model", "agent", "session_id", "input_tokens", "output_tokens", "cost_usd"})
+	for rows.Next() {
+		var r UsageRecord
+		if err := rows.Scan(&r.CreatedAt, &r.Provider, &r.Model, &r.Agent, &r.SessionID, &r.InputTokens, &r.OutputTokens, &r.CostUSD); err != nil {
+			return nil, fmt.Errorf("telemetry: export scan: %w", err)
+		}
+		_ = w.Write([]string{
+			r.CreatedAt.UTC().Format(time.RFC3339), r.Provider, r.Model, r.Agent, r.SessionID,
+			strconv.Itoa(r.InputTokens), strconv.Itoa(r.OutputTokens), strconv.FormatFloat(r.CostUSD, 'f', 6, 64),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// TrendPoint is one bucket of a spend trend. It has the same shape as
+// dashboard.MetricPoint, so it converts directly into a cost series.
+type TrendPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+	Label     string    `json:"label,omitempty"`
+}
+
+// MonthlyTrend returns userID's total spend for each of the last months
+// calendar months (UTC), oldest first, including the current month and
+// months with no usage. Label is the month as "2006-01".
+func (ct *CostTracker) MonthlyTrend(userID string, months int) ([]TrendPoint, error) {
+	if months <= 0 {
+		months = 12
+	}
+	now := time.Now().UTC()
+	first := time.Date(now.Year(), now.Month()-time.Month(months-1), 1, 0, 0, 0, 0, time.UTC)
+	rows, err := ct.db.Query(
+		`SELECT strftime('%Y-%m', created_at), SUM(cost_usd) FROM usage
+		 WHERE user_id=? AND created_at>=? GROUP BY 1`,
+		userID, sqliteTime(first),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: trend query: %w", err)
+	}
+	defer rows.Close()
+	byMonth := make(map[string]float64)
+	for rows.Next() {
+		var month string
+		var cost float64
+		if err := rows.Scan(&month, &cost); err != nil {
+			return nil, err
+		}
+		byMonth[month] = cost
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	points := make([]TrendPoint, 0, months)
+	for i := 0; i < months; i++ {
+		m := first.AddDate(0, i, 0)
+		label := m.Format("2006-01")
+		points = append(points, TrendPoint{
+			Timestamp: m,
+			Value:     math.Round(byMonth[label]*100000) / 100000,
+			Label:     label,
+		})
+	}
+	return points, nil
+}
+
 // SuggestCheaperModel recommends a cheaper alternative to the given model.
 func SuggestCheaperModel(provider, model string) string {
-	key := strings.ToLower(provider) + "/" + strings.ToLower(model)
-	pricing, ok := PricingTable[key]
+	pricing, ok := lookupPricing(provider, model)
 	if !ok || pricing.IsFree {
 		return ""
 	}
@@ -1,7 +1,12 @@
 package telemetry
 
 import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestCalculateCostKnownModel(t *testing.T) {
@@ -82,3 +87,168 @@ func TestSuggestCheaperModel(t *testing.T) {
 		t.Error("expected no suggestion for free model")
 	}
 }
+
+func TestGatePausesAfterBreachUntilReset(t *testing.T) {
+	dir := t.TempDir()
+	ct, err := New(dir, 0.01, 100)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer ct.Close()
+
+	if ok, _ := ct.Gate("user1"); !ok {
+		t.Fatal("gate should be open before any spend")
+	}
+	if _, err := ct.Record("user1", "groq", "llama-3.1-8b-instant", "chat", "s", 1000, 1000); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := ct.Gate("user1"); !ok {
+		t.Fatal("gate should stay open under the daily limit")
+	}
+	// 100k tokens at $0.59/$0.79 per 1M crosses the $0.01 daily limit.
+	if _, err := ct.Record("user1", "groq", "llama-3.3-70b-versatile", "chat", "s", 50000, 50000); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if ok, reason := ct.Gate("user1"); ok || reason == "" {
+			t.Fatalf("gate should block after breach (call %d), got ok=%v reason=%q", i, ok, reason)
+		}
+	}
+	if ok, _ := ct.Gate("user2"); !ok {
+		t.Error("another user's breach should not pause user2")
+	}
+
+	// The pause is shared through the database: a reset from a second
+	// tracker (e.g. `nexus cost reset`) reopens the first one's gate once
+	// its in-memory set is refreshed.
+	other, err := New(dir, 0.01, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	if ok, _ := other.Gate("user1"); ok {
+		t.Error("pause should be visible to another tracker on the same data dir")
+	}
+	if err := other.ResetBudget("user1"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := other.Gate("user1"); !ok {
+		t.Error("gate should reopen at once on the tracker that reset it")
+	}
+	if ok, _ := ct.Gate("user1"); ok {
+		t.Error("the other tracker should keep its cached pause until the refresh interval")
+	}
+	ct.mu.Lock()
+	ct.pausedLoaded = time.Time{}
+	ct.mu.Unlock()
+	if ok, _ := ct.Gate("user1"); !ok {
+		t.Error("gate should reopen after ResetBudget once the pause set is refreshed")
+	}
+}
+
+func TestGateKeepsLastStateWhenReloadFails(t *testing.T) {
+	ct, err := New(t.TempDir(), 0.01, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct.setPaused("user1")
+	ct.db.Close() // every reload now fails
+	ct.mu.Lock()
+	ct.pausedLoaded = time.Time{}
+	ct.mu.Unlock()
+	if ok, _ := ct.Gate("user1"); ok {
+		t.Error("a failed reload should keep user1 paused")
+	}
+	if ok, _ := ct.Gate("user2"); !ok {
+		t.Error("a failed reload should not pause everyone")
+	}
+}
+
+func TestLoadPricingMergesOverBuiltins(t *testing.T) {
+	orig := make(map[string]ModelPricing, len(PricingTable))
+	for k, v := range PricingTable {
+		orig[k] = v
+	}
+	t.Cleanup(func() { PricingTable = orig })
+
+	path := filepath.Join(t.TempDir(), "pricing.json")
+	body := `[
+		{"provider": "groq", "model": "llama-4-scout", "input_per_1m": 2, "output_per_1m": 2},
+		{"provider": "openai", "model": "gpt-4o", "input_per_1m": 1, "output_per_1m": 1}
+	]`
+	if err := os.WriteFile(path, []byte(body), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(PricingFileEnv, path)
+	ct, err := New(t.TempDir(), 0, 0)
+	if err != nil {
+		t.Fatalf("New with pricing file: %v", err)
+	}
+	defer ct.Close()
+
+	if got := ct.calculateCost("groq", "llama-4-scout", 1_000_000, 0); got != 2 {
+		t.Errorf("new model cost = %v, want 2", got)
+	}
+	if got := ct.calculateCost("openai", "gpt-4o", 1_000_000, 0); got != 1 {
+		t.Errorf("overridden model cost = %v, want 1", got)
+	}
+	if got := ct.calculateCost("anthropic", "claude-3-opus", 1_000_000, 0); got != 15 {
+		t.Errorf("untouched built-in cost = %v, want 15", got)
+	}
+	if got := ct.calculateCost("mystery", "model", 500_000, 500_000); got != 1 {
+		t.Errorf("unknown model should estimate at $1/1M, got %v", got)
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"provider": "groq"}]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadPricing(path); err == nil {
+		t.Error("expected error for entry without model")
+	}
+}
+
+func TestExportCSVAndMonthlyTrend(t *testing.T) {
+	ct, err := New(t.TempDir(), 0, 0)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer ct.Close()
+
+	if _, err := ct.Record("user1", "openai", "gpt-4o", "writer", "s1", 1_000_000, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ct.Record("user2", "openai", "gpt-4o", "writer", "s2", 1_000_000, 0); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	lastMonth := time.Date(now.Year(), now.Month()-1, 2, 12, 0, 0, 0, time.UTC)
+	if _, err := ct.db.Exec(
+		`INSERT INTO usage (id,user_id,provider,model,agent,session_id,input_tokens,output_tokens,cost_usd,created_at) VALUES ('old','user1','groq','m','a','s',1,1,0.5,?)`,
+		sqliteTime(lastMonth),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := ct.ExportCSV("user1", time.Now().Add(-time.Hour), time.Time{})
+	if err != nil {
+		t.Fatalf("ExportCSV: %v", err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0][0] != "date" || records[1][1] != "openai" || records[1][7] != "2.500000" {
+		t.Errorf("unexpected CSV: %q", records)
+	}
+
+	trend, err := ct.MonthlyTrend("user1", 3)
+	if err != nil {
+		t.Fatalf("MonthlyTrend: %v", err)
+	}
+	if len(trend) != 3 || trend[0].Value != 0 || trend[1].Value != 0.5 || trend[2].Value != 2.5 {
+		t.Errorf("unexpected trend: %+v", trend)
+	}
+	if trend[2].Label != time.Now().UTC().Format("2006-01") {
+		t.Errorf("last bucket should be the current month, got %s", trend[2].Label)
+	}
+}
@@ -0,0 +1,152 @@
+package tts
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultElevenLabsChars is the ElevenLabs free-tier monthly character quota.
+const DefaultElevenLabsChars = 10_000
+
+// ErrCharBudgetExceeded is returned when a request would push the month's
+// character count past the budget.
+var ErrCharBudgetExceeded = errors.New("tts: monthly character budget exceeded")
+
+// CharBudget persists a running monthly character count per API key in
+// SQLite. Counts are bucketed by UTC calendar month, so the budget resets
+// on the first of each month without any cleanup.
+type CharBudget struct {
+	db      *sql.DB
+	limit   int
+	alertAt float64 // fraction — alert when this fraction of the budget is used
+	onAlert func(msg string)
+	mu      sync.Mutex
+}
+
+// NewCharBudget opens (or creates) tts_usage.db in dataDir (default
+// ~/.nexus) with a monthly limit of limit characters (default
+// DefaultElevenLabsChars).
+func NewCharBudget(dataDir string, limit int) (*CharBudget, error) {
+	if dataDir == "" {
+		home, _ := os.UserHomeDir()
+		dataDir = filepath.Join(home, ".nexus")
+	}
+	if limit <= 0 {
+		limit = DefaultElevenLabsChars
+	}
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		return nil, fmt.Errorf("tts: budget mkdir: %w", err)
+	}
+	dbPath := filepath.Join(dataDir, "tts_usage.db")
+	// Create with 0600 before sql.Open — prevents world-readable window.
+	f, err := os.OpenFile(dbPath, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("tts: create budget db: %w", err)
+	}
+	f.Close()
+	db, err := sql.Open("sqlite3", dbPath+"?_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS tts_chars (
+			key_id TEXT NOT NULL,
+			voice  TEXT NOT NULL DEFAULT '',
+			month  TEXT NOT NULL,
+			chars  INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (key_id, voice, month)
+		);
+	`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("tts: budget migrate: %w", err)
+	}
+	return &CharBudget{db: db, limit: limit, alertAt: 0.80}, nil
+}
+
+// SetAlertCallback sets a function called when usage crosses 80% of the
+// budget or a request is refused. It has the same signature as
+// telemetry.CostTracker.SetAlertCallback, so both can share one notifier.
+func (b *CharBudget) SetAlertCallback(fn func(msg string)) {
+	b.onAlert = fn
+}
+
+// Limit returns the monthly character limit.
+func (b *CharBudget) Limit() int { return b.limit }
+
+// budgetKeyID identifies an API key without storing the key itself.
+func budgetKeyID(apiKey string) string {
+	h := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(h[:8])
+}
+
+func currentMonth() string {
+	return time.Now().UTC().Format("2006-01")
+}
+
+// Used returns the characters consumed by keyID this month across all voices.
+func (b *CharBudget) Used(keyID string) int {
+	var used int
+	b.db.QueryRow(`SELECT COALESCE(SUM(chars),0) FROM tts_chars WHERE key_id=? AND month=?`,
+		keyID, currentMonth()).Scan(&used)
+	return used
+}
+
+// Remaining returns the characters keyID may still use this month.
+func (b *CharBudget) Remaining(keyID string) int {
+	if r := b.limit - b.Used(keyID); r > 0 {
+		return r
+	}
+	return 0
+}
+
+// Check returns ErrCharBudgetExceeded if n more characters would exceed
+// keyID's budget for this month.
+func (b *CharBudget) Check(keyID string, n int) error {
+	used := b.Used(keyID)
+	if used+n <= b.limit {
+		return nil
+	}
+	b.alert(fmt.Sprintf("🚨 NEXUS TTS character budget exhausted\nUsed: %d / %d this month, request needs %d.",
+		used, b.limit, n))
+	return fmt.Errorf("%w: %d of %d used this month, request needs %d", ErrCharBudgetExceeded, used, b.limit, n)
+}
+
+// Add records n characters synthesized with voice under keyID.
+func (b *CharBudget) Add(keyID, voice string, n int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	before := b.Used(keyID)
+	if _, err := b.db.Exec(
+		`INSERT INTO tts_chars (key_id, voice, month, chars) VALUES (?,?,?,?)
+		 ON CONFLICT(key_id, voice, month) DO UPDATE SET chars = chars + excluded.chars`,
+		keyID, voice, currentMonth(), n,
+	); err != nil {
+		return fmt.Errorf("tts: record usage: %w", err)
+	}
+	threshold := int(float64(b.limit) * b.alertAt)
+	if before < threshold && before+n >= threshold {
+		b.alert(fmt.Sprintf("⚠️ NEXUS TTS Budget Warning\nCharacters: %d / %d this month (%.0f%%)",
+			before+n, b.limit, float64(before+n)/float64(b.limit)*100))
+	}
+	return nil
+}
+
+func (b *CharBudget) alert(msg string) {
+	log.Warn().Msg(msg)
+	if b.onAlert != nil {
+		b.onAlert(msg)
+	}
+}
+
+// Close shuts down the budget database.
+func (b *CharBudget) Close() error { return b.db.Close() }
@@ -0,0 +1,135 @@
+package tts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache is a content-addressed store of synthesized audio on disk.
+// Entries are keyed by a hash of backend, voice, speed, pitch and text, and the least
+// recently used files are evicted once the total size exceeds maxBytes.
+type Cache struct {
+	dir      string
+	maxBytes int64
+	mu       sync.Mutex
+}
+
+// NewCache opens a cache in dir (default ~/.nexus/tts-cache) capped at
+// maxBytes (default 100 MB).
+func NewCache(dir string, maxBytes int64) (*Cache, error) {
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".nexus", "tts-cache")
+	}
+	if maxBytes <= 0 {
+		maxBytes = 100 << 20
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("tts: cache mkdir: %w", err)
+	}
+	return &Cache{dir: dir, maxBytes: maxBytes}, nil
+}
+
+// cacheKey hashes everything that affects the synthesized audio.
+func cacheKey(backend Backend, voice string, req Request) string {
+	h := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%.3f\x00%.3f\x00%t\x00%s",
+		backend, voice, req.Speed, req.Pitch, req.SSML, req.Text)))
+	return hex.EncodeToString(h[:])
+}
+
+func (c *Cache) path(key, ext string) string {
+	return filepath.Join(c.dir, key+"."+ext)
+}
+
+// Get returns the cached file for key and marks it recently used.
+func (c *Cache) Get(key, ext string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p := c.path(key, ext)
+	if _, err := os.Stat(p); err != nil {
+		return "", false
+	}
+	now := time.Now()
+	_ = os.Chtimes(p, now, now)
+	return p, true
+}
+
+// Put copies the audio at src into the cache under key and evicts old entries.
+func (c *Cache) Put(key, ext, src string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	dst := c.path(key, ext)
+	if err := copyFile(src, dst); err != nil {
+		return "", fmt.Errorf("tts: cache put: %w", err)
+	}
+	c.evict()
+	return dst, nil
+}
+
+// evict removes least-recently-used entries until the cache fits maxBytes.
+// Caller must hold c.mu.
+func (c *Cache) evict() {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	type file struct {
+		path string
+		size int64
+		used time.Time
+	}
+	var files []file
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() || strings.HasSuffix(e.Name(), ".tmp") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, file{filepath.Join(c.dir, e.Name()), info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].used.Before(files[j].used) })
+	for _, f := range files {
+		if total <= c.maxBytes {
+			break
+		}
+		if os.Remove(f.path) == nil {
+			total -= f.size
+		}
+	}
+}
+
+// copyFile copies src to dst via a temp file so readers never see a partial file.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
@@ -0,0 +1,244 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Player plays audio as it is read from audio and returns once playback ends.
+type Player func(ctx context.Context, audio io.Reader) error
+
+// WithPlayer sets the player used by SpeakStream. The default pipes audio
+// into the first of mpv or ffplay found on PATH.
+func WithPlayer(p Player) Option {
+	return func(a *Agent) { a.player = p }
+}
+
+// CommandPlayer returns a Player that pipes audio into the stdin of the
+// given command, e.g. CommandPlayer("mpv", "--no-video", "-").
+func CommandPlayer(name string, args ...string) Player {
+	return func(ctx context.Context, audio io.Reader) error {
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Stdin = audio
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("tts: player %s: %w — output: %s", name, err, out)
+		}
+		return nil
+	}
+}
+
+// defaultPlayer picks a stdin-capable player from PATH.
+func defaultPlayer() (Player, error) {
+	if _, err := exec.LookPath("mpv"); err == nil {
+		return CommandPlayer("mpv", "--no-video", "--really-quiet", "-"), nil
+	}
+	if _, err := exec.LookPath("ffplay"); err == nil {
+		return CommandPlayer("ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet", "-"), nil
+	}
+	return nil, errors.New("tts: no streaming audio player found (install mpv or ffplay)")
+}
+
+// SpeakStream synthesises text and plays it while synthesis is still
+// running, so long text starts speaking almost immediately.
+//
+// ElevenLabs audio comes from the streaming endpoint and is piped to the
+// player as it arrives; if req.OutputPath is set the stream is also saved
+// there. Coqui text is synthesised sentence by sentence, each sentence
+// playing while the next is generated; use Speak for a single WAV file.
+// System TTS already speaks directly and is used as is. Streaming bypasses
+// the audio cache.
+func (a *Agent) SpeakStream(ctx context.Context, req Request) (*Result, error) {
+	if req.Text == "" {
+		return nil, fmt.Errorf("tts: text must not be empty")
+	}
+	if err := req.normalise(); err != nil {
+		return nil, err
+	}
+	if a.backend == BackendSystem {
+		return a.speakSystem(req)
+	}
+	play := a.player
+	if play == nil {
+		p, err := defaultPlayer()
+		if err != nil {
+			return nil, err
+		}
+		play = p
+	}
+	switch a.backend {
+	case BackendCoqui:
+		return a.streamCoqui(ctx, req, play)
+	case BackendElevenLabs:
+		res, err := a.streamElevenLabs(ctx, req, play)
+		if errors.Is(err, ErrCharBudgetExceeded) && a.budgetFallback {
+			log.Warn().Err(err).Msg("tts: falling back to system TTS")
+			return a.speakSystem(req)
+		}
+		return res, err
+	default:
+		return nil, fmt.Errorf("tts: unsupported backend: %s", a.backend)
+	}
+}
+
+// --- ElevenLabs streaming ---
+
+func (a *Agent) streamElevenLabs(ctx context.Context, req Request, play Player) (*Result, error) {
+	start := time.Now()
+	keyID := budgetKeyID(a.apiKey)
+	n := utf8.RuneCountInString(req.Text)
+	if a.budget != nil {
+		if err := a.budget.Check(keyID, n); err != nil {
+			return nil, err
+		}
+	}
+	body, err := json.Marshal(a.elevenLabsBody(req))
+	if err != nil {
+		return nil, err
+	}
+	voiceID := a.elevenLabsVoice(req)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s/v1/text-to-speech/%s/stream", a.elevenLabsURL, voiceID),
+		bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("xi-api-key", a.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "audio/mpeg")
+	// The shared client's timeout covers the whole body, which a long
+	// stream can outlive; ctx bounds the request instead.
+	client := *a.client
+	client.Timeout = 0
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("tts[elevenlabs]: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("tts[elevenlabs]: status %d: %s", resp.StatusCode, raw)
+	}
+
+	var audio io.Reader = resp.Body
+	if req.OutputPath != "" {
+		f, err := os.Create(req.OutputPath)
+		if err != nil {
+			return nil, fmt.Errorf("tts[elevenlabs]: write: %w", err)
+		}
+		defer f.Close()
+		audio = io.TeeReader(resp.Body, f)
+	}
+	if err := play(ctx, audio); err != nil {
+		return nil, err
+	}
+	// Drain whatever the player did not consume so the saved file is whole.
+	if _, err := io.Copy(io.Discard, audio); err != nil {
+		return nil, fmt.Errorf("tts[elevenlabs]: read: %w", err)
+	}
+	if a.budget != nil {
+		if err := a.budget.Add(keyID, a.elevenLabsVoice(req), n); err != nil {
+			log.Warn().Err(err).Msg("tts: failed to record character usage")
+		}
+	}
+	return &Result{Path: req.OutputPath, Backend: BackendElevenLabs, Latency: time.Since(start)}, nil
+}
+
+// --- Coqui sentence streaming ---
+
+var sentenceEndRe = regexp.MustCompile(`[.!?…]+["')\]]*\s+`)
+
+// splitSentences breaks text into sentences, keeping terminal punctuation.
+func splitSentences(text string) []string {
+	var out []string
+	last := 0
+	for _, loc := range sentenceEndRe.FindAllStringIndex(text, -1) {
+		if s := strings.TrimSpace(text[last:loc[1]]); s != "" {
+			out = append(out, s)
+		}
+		last = loc[1]
+	}
+	if s := strings.TrimSpace(text[last:]); s != "" {
+		out = append(out, s)
+	}
+	return out
+}
+
+type coquiChunk struct {
+	path string
+	err  error
+}
+
+func (a *Agent) streamCoqui(ctx context.Context, req Request, play Player) (*Result, error) {
+	start := time.Now()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Synthesis runs one sentence ahead of playback.
+	chunks := make(chan coquiChunk, 1)
+	go func() {
+		defer close(chunks)
+		for _, sentence := range splitSentences(req.plainText()) {
+			part := req
+			part.Text, part.SSML, part.OutputPath = sentence, false, tempAudio("wav")
+			res, err := a.speakCoqui(ctx, part)
+			var c coquiChunk
+			if err != nil {
+				c.err = err
+			} else {
+				c.path = res.Path
+			}
+			select {
+			case chunks <- c:
+			case <-ctx.Done():
+				os.Remove(c.path)
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var firstErr error
+	for c := range chunks {
+		if firstErr == nil {
+			if c.err != nil {
+				firstErr = c.err
+			} else {
+				firstErr = playFile(ctx, play, c.path)
+			}
+			if firstErr != nil {
+				cancel()
+			}
+		}
+		if c.path != "" {
+			os.Remove(c.path)
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return &Result{Backend: BackendCoqui, Latency: time.Since(start)}, nil
+}
+
+func playFile(ctx context.Context, play Player, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("tts: open chunk: %w", err)
+	}
+	defer f.Close()
+	return play(ctx, f)
+}
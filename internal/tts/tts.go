@@ -10,15 +10,24 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
 )
 
 // Backend selects the TTS provider.
@@ -31,11 +40,59 @@ const (
 )
 
 // Request is a speech synthesis request.
+//
+// Speed and Pitch are multipliers in [MinRate, MaxRate]; zero means 1.0
+// (normal). Backends that cannot honour a setting use normal instead:
+// ElevenLabs clamps Speed to 0.7–1.2 and ignores Pitch, Coqui ignores Pitch.
+// When SSML is set, Text is SSML markup; backends without SSML support
+// speak it with the tags stripped.
 type Request struct {
 	Text       string
 	Voice      string
 	OutputPath string
 	Speed      float64
+	Pitch      float64
+	SSML       bool
+}
+
+// Accepted range for Request.Speed and Request.Pitch.
+const (
+	MinRate = 0.5
+	MaxRate = 2.0
+)
+
+// ElevenLabs voice_settings.speed range.
+const (
+	elevenLabsMinSpeed = 0.7
+	elevenLabsMaxSpeed = 1.2
+)
+
+// normalise defaults zero Speed/Pitch to 1.0 and rejects out-of-range values.
+func (r *Request) normalise() error {
+	if r.Speed == 0 {
+		r.Speed = 1.0
+	}
+	if r.Pitch == 0 {
+		r.Pitch = 1.0
+	}
+	if r.Speed < MinRate || r.Speed > MaxRate {
+		return fmt.Errorf("tts: speed %.2f out of range [%.1f, %.1f]", r.Speed, MinRate, MaxRate)
+	}
+	if r.Pitch < MinRate || r.Pitch > MaxRate {
+		return fmt.Errorf("tts: pitch %.2f out of range [%.1f, %.1f]", r.Pitch, MinRate, MaxRate)
+	}
+	return nil
+}
+
+var ssmlTagRe = regexp.MustCompile(`<[^>]*>`)
+
+// plainText returns the request text with SSML tags stripped and entities
+// decoded, for backends that would otherwise read the markup aloud.
+func (r Request) plainText() string {
+	if !r.SSML {
+		return r.Text
+	}
+	return strings.TrimSpace(html.UnescapeString(ssmlTagRe.ReplaceAllString(r.Text, "")))
 }
 
 // Result holds synthesis output.
@@ -43,6 +100,7 @@ type Result struct {
 	Path    string
 	Backend Backend
 	Latency time.Duration
+	Cached  bool // served from the audio cache without re-synthesizing
 }
 
 // Agent is the TTS agent.
@@ -52,6 +110,12 @@ type Agent struct {
 	apiKey   string
 	voiceID  string
 	client   *http.Client
+	cache    *Cache
+
+	elevenLabsURL  string
+	budget         *CharBudget
+	budgetFallback bool
+	player         Player
 }
 
 // Option configures the TTS agent.
@@ -69,12 +133,27 @@ func WithSystem() Option {
 	return func(a *Agent) { a.backend = BackendSystem }
 }
 
+// WithCache reuses previously synthesized audio for identical requests.
+// System TTS speaks directly and is never cached.
+func WithCache(c *Cache) Option {
+	return func(a *Agent) { a.cache = c }
+}
+
+// WithCharBudget meters ElevenLabs requests against b. When fallback is
+// true, requests that would exceed the budget are spoken with system TTS
+// instead of failing with ErrCharBudgetExceeded.
+func WithCharBudget(b *CharBudget, fallback bool) Option {
+	return func(a *Agent) { a.budget = b; a.budgetFallback = fallback }
+}
+
 // New creates a TTS agent. Defaults to system TTS.
 func New(opts ...Option) *Agent {
 	a := &Agent{
 		backend:  BackendSystem,
 		coquiURL: "http://localhost:5002",
 		client:   &http.Client{Timeout: 60 * time.Second},
+
+		elevenLabsURL: "https://api.elevenlabs.io",
 	}
 	for _, o := range opts {
 		o(a)
@@ -87,14 +166,19 @@ func (a *Agent) Speak(ctx context.Context, req Request) (*Result, error) {
 	if req.Text == "" {
 		return nil, fmt.Errorf("tts: text must not be empty")
 	}
-	if req.Speed == 0 {
-		req.Speed = 1.0
+	if err := req.normalise(); err != nil {
+		return nil, err
 	}
 	switch a.backend {
 	case BackendCoqui:
-		return a.speakCoqui(ctx, req)
+		return a.cached(req, "wav", func() (*Result, error) { return a.speakCoqui(ctx, req) })
 	case BackendElevenLabs:
-		return a.speakElevenLabs(ctx, req)
+		res, err := a.cached(req, "mp3", func() (*Result, error) { return a.speakElevenLabsMetered(ctx, req) })
+		if errors.Is(err, ErrCharBudgetExceeded) && a.budgetFallback {
+			log.Warn().Err(err).Msg("tts: falling back to system TTS")
+			return a.speakSystem(req)
+		}
+		return res, err
 	case BackendSystem:
 		return a.speakSystem(req)
 	default:
@@ -102,15 +186,49 @@ func (a *Agent) Speak(ctx context.Context, req Request) (*Result, error) {
 	}
 }
 
+// cached serves req from the audio cache when possible, otherwise runs
+// synth and stores its output. Cache failures never fail the request.
+func (a *Agent) cached(req Request, ext string, synth func() (*Result, error)) (*Result, error) {
+	if a.cache == nil {
+		return synth()
+	}
+	start := time.Now()
+	voice := req.Voice
+	if voice == "" {
+		voice = a.voiceID
+	}
+	key := cacheKey(a.backend, voice, req)
+	if p, ok := a.cache.Get(key, ext); ok {
+		if req.OutputPath != "" {
+			if err := copyFile(p, req.OutputPath); err != nil {
+				return nil, fmt.Errorf("tts: copy cached audio: %w", err)
+			}
+			p = req.OutputPath
+		}
+		return &Result{Path: p, Backend: a.backend, Latency: time.Since(start), Cached: true}, nil
+	}
+	res, err := synth()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := a.cache.Put(key, ext, res.Path); err != nil {
+		log.Warn().Err(err).Msg("tts: failed to cache synthesized audio")
+	}
+	return res, nil
+}
+
 // --- Coqui TTS ---
 
 func (a *Agent) speakCoqui(ctx context.Context, req Request) (*Result, error) {
 	start := time.Now()
 	params := url.Values{}
-	params.Set("text", req.Text)
+	params.Set("text", req.plainText())
 	if req.Voice != "" {
 		params.Set("speaker_id", req.Voice)
 	}
+	if req.Speed != 1.0 {
+		params.Set("speed", strconv.FormatFloat(req.Speed, 'f', 2, 64))
+	}
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet,
 		a.coquiURL+"/api/tts?"+params.Encode(), nil)
 	if err != nil {
@@ -141,34 +259,80 @@ func (a *Agent) speakCoqui(ctx context.Context, req Request) (*Result, error) {
 
 // --- ElevenLabs ---
 
+// RemainingChars returns the ElevenLabs characters left in this month's
+// budget, or -1 if no budget is configured.
+func (a *Agent) RemainingChars() int {
+	if a.budget == nil {
+		return -1
+	}
+	return a.budget.Remaining(budgetKeyID(a.apiKey))
+}
+
+// speakElevenLabsMetered checks the character budget before synthesis and
+// records usage after it. Cache hits never reach it, so they are free.
+func (a *Agent) speakElevenLabsMetered(ctx context.Context, req Request) (*Result, error) {
+	if a.budget == nil {
+		return a.speakElevenLabs(ctx, req)
+	}
+	keyID := budgetKeyID(a.apiKey)
+	n := utf8.RuneCountInString(req.Text)
+	if err := a.budget.Check(keyID, n); err != nil {
+		return nil, err
+	}
+	res, err := a.speakElevenLabs(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.budget.Add(keyID, a.elevenLabsVoice(req), n); err != nil {
+		log.Warn().Err(err).Msg("tts: failed to record character usage")
+	}
+	return res, nil
+}
+
 type elevenLabsRequest struct {
 	Text          string                 `json:"text"`
 	ModelID       string                 `json:"model_id"`
 	VoiceSettings map[string]interface{} `json:"voice_settings"`
 }
 
+// elevenLabsBody builds the synthesis request body shared by Speak and
+// SpeakStream.
+func (a *Agent) elevenLabsBody(req Request) elevenLabsRequest {
+	settings := map[string]interface{}{
+		"stability":        0.5,
+		"similarity_boost": 0.75,
+	}
+	if req.Speed != 1.0 {
+		settings["speed"] = math.Min(math.Max(req.Speed, elevenLabsMinSpeed), elevenLabsMaxSpeed)
+	}
+	// ElevenLabs reads SSML break/phoneme tags inline, so Text is sent as is.
+	return elevenLabsRequest{
+		Text:          req.Text,
+		ModelID:       "eleven_monolingual_v1",
+		VoiceSettings: settings,
+	}
+}
+
+// elevenLabsVoice resolves the voice ID for req.
+func (a *Agent) elevenLabsVoice(req Request) string {
+	if req.Voice != "" {
+		return req.Voice
+	}
+	if a.voiceID != "" {
+		return a.voiceID
+	}
+	return "21m00Tcm4TlvDq8ikWAM" // default: Rachel
+}
+
 func (a *Agent) speakElevenLabs(ctx context.Context, req Request) (*Result, error) {
 	start := time.Now()
-	body, err := json.Marshal(elevenLabsRequest{
-		Text:    req.Text,
-		ModelID: "eleven_monolingual_v1",
-		VoiceSettings: map[string]interface{}{
-			"stability":        0.5,
-			"similarity_boost": 0.75,
-		},
-	})
+	body, err := json.Marshal(a.elevenLabsBody(req))
 	if err != nil {
 		return nil, err
 	}
-	voiceID := a.voiceID
-	if req.Voice != "" {
-		voiceID = req.Voice
-	}
-	if voiceID == "" {
-		voiceID = "21m00Tcm4TlvDq8ikWAM" // default: Rachel
-	}
+	voiceID := a.elevenLabsVoice(req)
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost,
-		fmt.Sprintf("https://api.elevenlabs.io/v1/text-to-speech/%s", voiceID),
+		fmt.Sprintf("%s/v1/text-to-speech/%s", a.elevenLabsURL, voiceID),
 		bytes.NewReader(body))
 	if err != nil {
 		return nil, err
@@ -210,23 +374,30 @@ func (a *Agent) speakSystem(req Request) (*Result, error) {
 	switch runtime.GOOS {
 	case "darwin":
 		// 'say' accepts the text as a direct argument — no shell injection risk.
-		args := []string{req.Text}
+		args := []string{"-r", strconv.Itoa(int(systemWPM * req.Speed))}
 		if req.Voice != "" {
-			args = []string{"-v", req.Voice, req.Text}
+			args = append(args, "-v", req.Voice)
 		}
-		cmd = exec.Command("say", args...)
+		cmd = exec.Command("say", append(args, req.plainText())...)
 	case "windows":
 		// Use -EncodedCommand to avoid single-quote injection.
 		// The script is base64-encoded so arbitrary text cannot escape the string.
 		script := fmt.Sprintf(
 			`Add-Type -AssemblyName System.Speech; `+
-				`$s = New-Object System.Speech.Synthesis.SpeechSynthesizer; `+
+				`$s = New-Object System.Speech.Synthesis.SpeechSynthesizer; $s.Rate = %d; `+
 				`$s.Speak([System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String('%s')))`,
-			base64.StdEncoding.EncodeToString([]byte(req.Text)))
+			windowsRate(req.Speed), base64.StdEncoding.EncodeToString([]byte(req.plainText())))
 		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
 	default: // Linux + others
 		// 'espeak' accepts text as a direct argument — no shell injection risk.
-		cmd = exec.Command("espeak", req.Text)
+		args := []string{
+			"-s", strconv.Itoa(int(systemWPM * req.Speed)),
+			"-p", strconv.Itoa(min(int(50*req.Pitch), 99)),
+		}
+		if req.SSML {
+			args = append(args, "-m")
+		}
+		cmd = exec.Command("espeak", append(args, req.Text)...)
 	}
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return nil, fmt.Errorf("tts[system]: %w — output: %s", err, out)
@@ -234,6 +405,14 @@ func (a *Agent) speakSystem(req Request) (*Result, error) {
 	return &Result{Backend: BackendSystem, Latency: time.Since(start)}, nil
 }
 
+// systemWPM is the normal speaking rate of espeak and say, in words per minute.
+const systemWPM = 175
+
+// windowsRate maps a speed multiplier onto SpeechSynthesizer.Rate (-10..10).
+func windowsRate(speed float64) int {
+	return max(-10, min(10, int(math.Round((speed-1)*10))))
+}
+
 func tempAudio(ext string) string {
 	return filepath.Join(os.TempDir(),
 		fmt.Sprintf("nexus-tts-%d.%s", time.Now().UnixNano(), ext))
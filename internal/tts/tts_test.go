@@ -1,10 +1,19 @@
 package tts
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestCoquiTTS(t *testing.T) {
@@ -69,3 +78,203 @@ func TestCoquiTextEncoding(t *testing.T) {
 		t.Errorf("text not decoded correctly: %q", receivedText)
 	}
 }
+
+func TestSpeakCache(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "audio/wav")
+		w.Write([]byte{0x52, 0x49, 0x46, 0x46}) //nolint:errcheck
+	}))
+	defer srv.Close()
+
+	cache, err := NewCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("NewCache: %v", err)
+	}
+	a := New(WithCoqui(srv.URL), WithCache(cache))
+	req := Request{Text: "Good morning, here is your digest."}
+
+	first, err := a.Speak(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Speak: %v", err)
+	}
+	second, err := a.Speak(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Speak (cached): %v", err)
+	}
+	if hits != 1 {
+		t.Errorf("expected 1 synthesis, got %d", hits)
+	}
+	if first.Cached || !second.Cached {
+		t.Errorf("expected only second result cached, got %v/%v", first.Cached, second.Cached)
+	}
+
+	if _, err := a.Speak(context.Background(), Request{Text: req.Text, Speed: 1.5}); err != nil {
+		t.Fatal(err)
+	}
+	if hits != 2 {
+		t.Errorf("different speed must miss the cache, got %d syntheses", hits)
+	}
+}
+
+func TestCacheEvictsLRU(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewCache(dir, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(t.TempDir(), "a.wav")
+	if err := os.WriteFile(src, []byte("12345"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	oldPath, _ := c.Put("old", "wav", src)
+	past := time.Now().Add(-time.Hour)
+	_ = os.Chtimes(oldPath, past, past)
+	_, _ = c.Put("new", "wav", src)
+
+	if _, ok := c.Get("old", "wav"); ok {
+		t.Error("expected least-recently-used entry to be evicted")
+	}
+	if _, ok := c.Get("new", "wav"); !ok {
+		t.Error("expected newest entry to remain")
+	}
+}
+
+func TestElevenLabsCharBudget(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Write([]byte{0xff, 0xfb}) //nolint:errcheck
+	}))
+	defer srv.Close()
+
+	budget, err := NewCharBudget(t.TempDir(), 20)
+	if err != nil {
+		t.Fatalf("NewCharBudget: %v", err)
+	}
+	defer budget.Close()
+	var alerts []string
+	budget.SetAlertCallback(func(msg string) { alerts = append(alerts, msg) })
+
+	a := New(WithElevenLabs("key", "voice"), WithCharBudget(budget, false))
+	a.elevenLabsURL = srv.URL
+	if got := a.RemainingChars(); got != 20 {
+		t.Fatalf("expected 20 chars remaining, got %d", got)
+	}
+	dir := t.TempDir()
+	if _, err := a.Speak(context.Background(), Request{Text: "Sixteen chars!!!", OutputPath: dir + "/a.mp3"}); err != nil {
+		t.Fatalf("Speak: %v", err)
+	}
+	if got := a.RemainingChars(); got != 4 {
+		t.Errorf("expected 4 chars remaining, got %d", got)
+	}
+	if len(alerts) != 1 {
+		t.Errorf("expected one 80%% warning, got %d", len(alerts))
+	}
+
+	_, err = a.Speak(context.Background(), Request{Text: "Too long now", OutputPath: dir + "/b.mp3"})
+	if !errors.Is(err, ErrCharBudgetExceeded) {
+		t.Fatalf("expected ErrCharBudgetExceeded, got %v", err)
+	}
+	if hits != 1 {
+		t.Errorf("over-budget request must not reach the API, got %d calls", hits)
+	}
+}
+
+func TestSpeedAndSSMLThreading(t *testing.T) {
+	var coquiQuery url.Values
+	coqui := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		coquiQuery = r.URL.Query()
+		w.Write([]byte{0x00}) //nolint:errcheck
+	}))
+	defer coqui.Close()
+	var elevenBody elevenLabsRequest
+	eleven := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&elevenBody) //nolint:errcheck
+		w.Write([]byte{0xff})                       //nolint:errcheck
+	}))
+	defer eleven.Close()
+
+	dir := t.TempDir()
+	ssml := `<speak>Fish &amp; chips<break time="1s"/></speak>`
+	c := New(WithCoqui(coqui.URL))
+	if _, err := c.Speak(context.Background(), Request{Text: ssml, SSML: true, Speed: 1.5, OutputPath: dir + "/c.wav"}); err != nil {
+		t.Fatalf("coqui: %v", err)
+	}
+	if got := coquiQuery.Get("speed"); got != "1.50" {
+		t.Errorf("coqui speed param = %q, want 1.50", got)
+	}
+	if got := coquiQuery.Get("text"); got != "Fish & chips" {
+		t.Errorf("coqui should get SSML stripped, got %q", got)
+	}
+
+	e := New(WithElevenLabs("key", "voice"))
+	e.elevenLabsURL = eleven.URL
+	if _, err := e.Speak(context.Background(), Request{Text: ssml, SSML: true, Speed: 1.5, OutputPath: dir + "/e.mp3"}); err != nil {
+		t.Fatalf("elevenlabs: %v", err)
+	}
+	if got := elevenBody.VoiceSettings["speed"]; got != 1.2 {
+		t.Errorf("elevenlabs speed should clamp to 1.2, got %v", got)
+	}
+	if elevenBody.Text != ssml {
+		t.Errorf("elevenlabs should receive SSML as is, got %q", elevenBody.Text)
+	}
+
+	if _, err := c.Speak(context.Background(), Request{Text: "hi", Pitch: 3}); err == nil {
+		t.Error("expected out-of-range pitch to be rejected")
+	}
+}
+
+func TestSpeakStreamElevenLabs(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Write([]byte("mp3-frames")) //nolint:errcheck
+	}))
+	defer srv.Close()
+
+	var played bytes.Buffer
+	a := New(WithElevenLabs("key", "voice"), WithPlayer(func(_ context.Context, audio io.Reader) error {
+		_, err := io.Copy(&played, audio)
+		return err
+	}))
+	a.elevenLabsURL = srv.URL
+	out := filepath.Join(t.TempDir(), "stream.mp3")
+	res, err := a.SpeakStream(context.Background(), Request{Text: "A long chapter.", OutputPath: out})
+	if err != nil {
+		t.Fatalf("SpeakStream: %v", err)
+	}
+	if path != "/v1/text-to-speech/voice/stream" {
+		t.Errorf("expected streaming endpoint, got %s", path)
+	}
+	if played.String() != "mp3-frames" {
+		t.Errorf("player got %q", played.String())
+	}
+	if data, _ := os.ReadFile(out); string(data) != "mp3-frames" || res.Path != out {
+		t.Errorf("expected stream saved to %s, got %q", out, data)
+	}
+}
+
+func TestSpeakStreamCoquiSentences(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Query().Get("text"))) //nolint:errcheck
+	}))
+	defer srv.Close()
+
+	var played []string
+	a := New(WithCoqui(srv.URL), WithPlayer(func(_ context.Context, audio io.Reader) error {
+		b, err := io.ReadAll(audio)
+		played = append(played, string(b))
+		return err
+	}))
+	if _, err := a.SpeakStream(context.Background(), Request{Text: "First one. Second one! Third?"}); err != nil {
+		t.Fatalf("SpeakStream: %v", err)
+	}
+	want := []string{"First one.", "Second one!", "Third?"}
+	if strings.Join(played, "|") != strings.Join(want, "|") {
+		t.Errorf("played %q, want %q", played, want)
+	}
+}
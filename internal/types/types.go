@@ -42,7 +42,9 @@ type LLMConfig struct {
 	BaseURL    string `toml:"base_url"   mapstructure:"base_url"`
 	MaxTokens  int    `toml:"max_tokens" mapstructure:"max_tokens"`
 	TimeoutSec int    `toml:"timeout_sec" mapstructure:"timeout_sec"`
-	Fallback   string `toml:"fallback"   mapstructure:"fallback"`
+	Fallback   string `toml:"fallback"   mapstructure:"fallback"` // single fallback provider name, e.g. "ollama"
+	// Fallbacks is an ordered fallback chain tried after the primary provider.
+	Fallbacks []LLMConfig `toml:"fallbacks" mapstructure:"fallbacks"`
 }
 
 // MemoryConfig holds memory storage settings
@@ -2,9 +2,11 @@
 //
 // Security properties:
 //   - AES-256-GCM: authenticated encryption, detects tampering
-//   - PBKDF2-HMAC-SHA256 (100k iterations, random 16-byte salt): GPU-resistant KDF
+//   - Argon2id (64 MiB, 3 passes, 4 lanes, random 16-byte salt): memory-hard KDF
+//     for new vaults; PBKDF2-HMAC-SHA256 (100k iterations) vaults stay readable
 //   - File permissions: 0600 (owner read/write only)
 //   - Secret values never appear in logs or LLM prompts (RedactPrompt)
+//   - Common credential shapes are redacted even if never stored (RedactPatterns)
 //   - Constant-time name comparison prevents timing side-channels
 //   - Crypto/rand IDs (not time-based) prevent sequential enumeration
 package vault
@@ -18,14 +20,19 @@ import (
 	"database/sql"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/pbkdf2"
 )
 
@@ -36,12 +43,34 @@ const (
 	pbkdf2Iterations = 100_000
 	pbkdf2KeyLen     = 32 // AES-256
 	saltLen          = 16
+
+	// Argon2id parameters follow the second recommended option of RFC 9106
+	// for memory-constrained environments: 64 MiB of memory, 3 passes and 4
+	// lanes, ~0.1-0.3s per derivation. Each guess costs an attacker 64 MiB,
+	// which caps GPU parallelism far below PBKDF2's.
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024 // KiB
+	argon2Threads = 4
+)
+
+// KDF names the key-derivation function a vault was created with. It is
+// recorded in the kv table next to the salt.
+type KDF string
+
+const (
+	KDFArgon2id KDF = "argon2id"      // default for new vaults
+	KDFPBKDF2   KDF = "pbkdf2-sha256" // vaults created before Argon2id support
 )
 
 // Vault manages encrypted secrets storage.
 type Vault struct {
-	db  *sql.DB
+	db *sql.DB
+
+	// mu guards key and kdf. Rotate holds it exclusively for the whole
+	// re-encryption so no secret is written under the old key meanwhile.
+	mu  sync.RWMutex
 	key []byte
+	kdf KDF
 }
 
 // Secret represents a stored secret (value is NEVER included in listings).
@@ -51,12 +80,26 @@ type Secret struct {
 	Category    string
 	PrivacyZone string
 	CreatedAt   time.Time
+	ExpiresAt   time.Time // zero if the secret never expires
 }
 
+// ErrSecretExpired is returned by Get for a secret past its ExpiresAt. The
+// value can still be read with GetAllowExpired while it is being rotated.
+var ErrSecretExpired = errors.New("vault: secret expired")
+
 // Open initialises the encrypted vault at path using passphrase.
 // If path is empty, defaults to ~/.nexus/vault.db.
-// The vault file is created with 0600 permissions.
+// The vault file is created with 0600 permissions. New vaults use Argon2id.
 func Open(path, passphrase string) (*Vault, error) {
+	return OpenWithKDF(path, passphrase, KDFArgon2id)
+}
+
+// OpenWithKDF is like Open but selects the KDF used if the vault is created.
+// An existing vault always reopens with the KDF it was created with.
+func OpenWithKDF(path, passphrase string, kdf KDF) (*Vault, error) {
+	if kdf != KDFArgon2id && kdf != KDFPBKDF2 {
+		return nil, fmt.Errorf("vault: unknown kdf %q", kdf)
+	}
 	if path == "" {
 		home, _ := os.UserHomeDir()
 		path = filepath.Join(home, ".nexus", "vault.db")
@@ -76,46 +119,63 @@ func Open(path, passphrase string) (*Vault, error) {
 		return nil, fmt.Errorf("vault: open db: %w", err)
 	}
 
-	key, err := resolveKey(db, passphrase)
+	key, kdf, err := resolveKey(db, passphrase, kdf)
 	if err != nil {
 		db.Close()
 		return nil, fmt.Errorf("vault: key derivation: %w", err)
 	}
 
-	v := &Vault{db: db, key: key}
+	v := &Vault{db: db, key: key, kdf: kdf}
 	return v, v.migrate()
 }
 
-// resolveKey derives the vault key using PBKDF2.
-// On first open it generates a new random salt and stores it.
-// On subsequent opens it loads the existing salt.
-func resolveKey(db *sql.DB, passphrase string) ([]byte, error) {
+// KDF reports the key-derivation function protecting the vault.
+func (v *Vault) KDF() KDF {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.kdf
+}
+
+// resolveKey derives the vault key with the vault's KDF.
+// On first open it generates a new random salt and stores it with newKDF.
+// On subsequent opens it loads the existing salt and recorded KDF; vaults
+// without a recorded KDF predate Argon2id and use PBKDF2.
+func resolveKey(db *sql.DB, passphrase string, newKDF KDF) ([]byte, KDF, error) {
 	// Ensure kv table exists for salt storage.
 	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS kv (key TEXT PRIMARY KEY, value TEXT NOT NULL)`)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	var saltHex string
+	kdf := KDFPBKDF2
 	err = db.QueryRow(`SELECT value FROM kv WHERE key = 'salt'`).Scan(&saltHex)
 	if err == sql.ErrNoRows {
-		// First open: generate and persist a new random salt.
+		// First open: generate and persist a new random salt and the KDF.
 		salt := make([]byte, saltLen)
 		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
-			return nil, fmt.Errorf("generate salt: %w", err)
+			return nil, "", fmt.Errorf("generate salt: %w", err)
 		}
-		saltHex = hex.EncodeToString(salt)
-		_, err = db.Exec(`INSERT INTO kv (key, value) VALUES ('salt', ?)`, saltHex)
+		saltHex, kdf = hex.EncodeToString(salt), newKDF
+		_, err = db.Exec(`INSERT INTO kv (key, value) VALUES ('salt', ?), ('kdf', ?)`, saltHex, string(kdf))
 		if err != nil {
-			return nil, fmt.Errorf("persist salt: %w", err)
+			return nil, "", fmt.Errorf("persist salt: %w", err)
 		}
 	} else if err != nil {
-		return nil, err
+		return nil, "", err
+	} else {
+		var stored string
+		switch err := db.QueryRow(`SELECT value FROM kv WHERE key = 'kdf'`).Scan(&stored); {
+		case err == nil:
+			kdf = KDF(stored)
+		case err != sql.ErrNoRows:
+			return nil, "", err
+		}
 	}
 
 	salt, err := hex.DecodeString(saltHex)
 	if err != nil {
-		return nil, fmt.Errorf("decode salt: %w", err)
+		return nil, "", fmt.Errorf("decode salt: %w", err)
 	}
 
 	if passphrase == "" {
@@ -123,8 +183,97 @@ func resolveKey(db *sql.DB, passphrase string) ([]byte, error) {
 		passphrase = "nexus-default-vault-key-change-me"
 	}
 
-	key := pbkdf2.Key([]byte(passphrase), salt, pbkdf2Iterations, pbkdf2KeyLen, sha256.New)
-	return key, nil
+	key, err := deriveKey(kdf, passphrase, salt)
+	return key, kdf, err
+}
+
+func deriveKey(kdf KDF, passphrase string, salt []byte) ([]byte, error) {
+	switch kdf {
+	case KDFArgon2id:
+		return argon2.IDKey([]byte(passphrase), salt, argon2Time, argon2Memory, argon2Threads, pbkdf2KeyLen), nil
+	case KDFPBKDF2:
+		return pbkdf2.Key([]byte(passphrase), salt, pbkdf2Iterations, pbkdf2KeyLen, sha256.New), nil
+	}
+	return nil, fmt.Errorf("unknown kdf %q", kdf)
+}
+
+// Rotate re-keys the vault under newPassphrase. Every secret is decrypted with
+// the current key, re-encrypted with a key derived from newPassphrase and a
+// fresh salt, and written back together with the new salt in one transaction,
+// so a failure part-way leaves the vault readable with the old passphrase.
+// The new key always uses Argon2id, upgrading PBKDF2 vaults. The old key is
+// zeroised afterwards.
+func (v *Vault) Rotate(newPassphrase string) error {
+	if newPassphrase == "" {
+		return fmt.Errorf("vault: rotate: new passphrase must not be empty")
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	rows, err := v.db.Query(`SELECT id, encrypted FROM secrets`)
+	if err != nil {
+		return fmt.Errorf("vault: rotate: %w", err)
+	}
+	type row struct{ id, plain string }
+	var all []row
+	for rows.Next() {
+		var id, enc string
+		if err := rows.Scan(&id, &enc); err != nil {
+			rows.Close()
+			return fmt.Errorf("vault: rotate: %w", err)
+		}
+		plain, err := decryptWith(v.key, enc)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("vault: rotate: secret %s: %w", id, err)
+		}
+		all = append(all, row{id, plain})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("vault: rotate: %w", err)
+	}
+
+	salt := make([]byte, saltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return fmt.Errorf("vault: rotate: generate salt: %w", err)
+	}
+	newKey, err := deriveKey(KDFArgon2id, newPassphrase, salt)
+	if err != nil {
+		return fmt.Errorf("vault: rotate: %w", err)
+	}
+
+	tx, err := v.db.Begin()
+	if err != nil {
+		zeroise(newKey)
+		return fmt.Errorf("vault: rotate: %w", err)
+	}
+	commit := func() error {
+		for _, r := range all {
+			enc, err := encryptWith(newKey, r.plain)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec(`UPDATE secrets SET encrypted = ? WHERE id = ?`, enc, r.id); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(`UPDATE kv SET value = ? WHERE key = 'salt'`, hex.EncodeToString(salt)); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO kv (key, value) VALUES ('kdf', ?)`, string(KDFArgon2id)); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+	if err := commit(); err != nil {
+		_ = tx.Rollback()
+		zeroise(newKey)
+		return fmt.Errorf("vault: rotate: %w", err)
+	}
+
+	zeroise(v.key)
+	v.key, v.kdf = newKey, KDFArgon2id
+	return nil
 }
 
 func (v *Vault) migrate() error {
@@ -138,36 +287,71 @@ func (v *Vault) migrate() error {
 			created_at   DATETIME DEFAULT CURRENT_TIMESTAMP
 		);
 	`)
+	if err != nil {
+		return err
+	}
+	// Vaults created before secret expiry existed lack expires_at.
+	var n int
+	if err := v.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('secrets') WHERE name = 'expires_at'`).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		_, err = v.db.Exec(`ALTER TABLE secrets ADD COLUMN expires_at DATETIME`)
+	}
 	return err
 }
 
-// Store saves an encrypted secret.
+// Store saves an encrypted secret that never expires.
 // Uses a crypto/rand ID (not time-based) to prevent sequential enumeration.
 func (v *Vault) Store(name, value, category, privacyZone string) error {
+	return v.StoreWithTTL(name, value, category, privacyZone, 0)
+}
+
+// StoreWithTTL saves an encrypted secret that expires ttl from now, e.g. an
+// API key with a known validity period. A ttl <= 0 means no expiry.
+func (v *Vault) StoreWithTTL(name, value, category, privacyZone string, ttl time.Duration) error {
 	if name == "" {
 		return fmt.Errorf("vault: name must not be empty")
 	}
+	v.mu.RLock()
+	defer v.mu.RUnlock()
 	enc, err := v.encrypt(value)
 	if err != nil {
 		return fmt.Errorf("vault: encrypt: %w", err)
 	}
+	var expires sql.NullTime
+	if ttl > 0 {
+		expires = sql.NullTime{Time: time.Now().Add(ttl).UTC(), Valid: true}
+	}
 	id := randomID()
 	_, err = v.db.Exec(
-		`INSERT OR REPLACE INTO secrets (id, name, encrypted, category, privacy_zone) VALUES (?, ?, ?, ?, ?)`,
-		id, name, enc, category, privacyZone,
+		`INSERT OR REPLACE INTO secrets (id, name, encrypted, category, privacy_zone, expires_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		id, name, enc, category, privacyZone, expires,
 	)
 	return err
 }
 
-// Get decrypts and returns a secret by name.
+// Get decrypts and returns a secret by name. A secret past its expiry yields
+// ErrSecretExpired; use GetAllowExpired to read it anyway.
 // Uses constant-time name comparison to prevent timing side-channels.
 func (v *Vault) Get(name string) (string, error) {
+	return v.get(name, false)
+}
+
+// GetAllowExpired is like Get but also returns expired secrets.
+func (v *Vault) GetAllowExpired(name string) (string, error) {
+	return v.get(name, true)
+}
+
+func (v *Vault) get(name string, allowExpired bool) (string, error) {
 	if name == "" {
 		return "", fmt.Errorf("vault: name must not be empty")
 	}
+	v.mu.RLock()
+	defer v.mu.RUnlock()
 	// Fetch all names + encrypted blobs; do constant-time name match.
 	// For a local vault the row count is always small, so this is safe.
-	rows, err := v.db.Query(`SELECT name, encrypted FROM secrets`)
+	rows, err := v.db.Query(`SELECT name, encrypted, expires_at FROM secrets`)
 	if err != nil {
 		return "", err
 	}
@@ -176,10 +360,14 @@ func (v *Vault) Get(name string) (string, error) {
 	namBytes := []byte(name)
 	for rows.Next() {
 		var rowName, enc string
-		if err := rows.Scan(&rowName, &enc); err != nil {
+		var expires sql.NullTime
+		if err := rows.Scan(&rowName, &enc, &expires); err != nil {
 			return "", err
 		}
 		if subtle.ConstantTimeCompare([]byte(rowName), namBytes) == 1 {
+			if !allowExpired && expires.Valid && !time.Now().Before(expires.Time) {
+				return "", fmt.Errorf("%w: %q expired %s", ErrSecretExpired, name, expires.Time.Format(time.RFC3339))
+			}
 			return v.decrypt(enc)
 		}
 	}
@@ -192,7 +380,7 @@ func (v *Vault) Get(name string) (string, error) {
 // List returns all secret metadata (never values) for a privacy zone.
 // Pass empty string to list all zones.
 func (v *Vault) List(privacyZone string) ([]Secret, error) {
-	query := `SELECT id, name, category, privacy_zone, created_at FROM secrets`
+	query := `SELECT id, name, category, privacy_zone, created_at, expires_at FROM secrets`
 	var args []interface{}
 	if privacyZone != "" {
 		query += " WHERE privacy_zone = ?"
@@ -206,24 +394,50 @@ func (v *Vault) List(privacyZone string) ([]Secret, error) {
 	var secrets []Secret
 	for rows.Next() {
 		var s Secret
-		if err := rows.Scan(&s.ID, &s.Name, &s.Category, &s.PrivacyZone, &s.CreatedAt); err != nil {
+		var expires sql.NullTime
+		if err := rows.Scan(&s.ID, &s.Name, &s.Category, &s.PrivacyZone, &s.CreatedAt, &expires); err != nil {
 			return nil, err
 		}
+		if expires.Valid {
+			s.ExpiresAt = expires.Time
+		}
 		secrets = append(secrets, s)
 	}
 	return secrets, rows.Err()
 }
 
+// ListExpiring returns metadata of secrets that expire within the given
+// window, including already-expired ones, soonest first. It feeds rotation
+// reminders such as "GROQ_API_KEY expires in 3 days".
+func (v *Vault) ListExpiring(within time.Duration) ([]Secret, error) {
+	all, err := v.List("")
+	if err != nil {
+		return nil, err
+	}
+	cutoff := time.Now().Add(within)
+	var expiring []Secret
+	for _, s := range all {
+		if !s.ExpiresAt.IsZero() && s.ExpiresAt.Before(cutoff) {
+			expiring = append(expiring, s)
+		}
+	}
+	sort.Slice(expiring, func(i, j int) bool { return expiring[i].ExpiresAt.Before(expiring[j].ExpiresAt) })
+	return expiring, nil
+}
+
 // RedactPrompt replaces any vault secret values found in prompt with [REDACTED:<name>].
 // Secrets shorter than 8 chars are not redacted (too short = likely false positives).
+// A second pass (RedactPatterns) catches well-known credential shapes that were
+// never stored in the vault at all.
 func (v *Vault) RedactPrompt(prompt string) string {
 	secrets, err := v.List("")
 	if err != nil {
-		return prompt
+		return RedactPatterns(prompt)
 	}
 	redacted := prompt
 	for _, s := range secrets {
-		val, err := v.Get(s.Name)
+		// Expired secrets are still live credentials until rotated.
+		val, err := v.GetAllowExpired(s.Name)
 		if err != nil || len(val) < 8 {
 			continue
 		}
@@ -234,7 +448,34 @@ func (v *Vault) RedactPrompt(prompt string) string {
 		// Zero the decrypted value from memory as soon as we're done with it.
 		zeroise([]byte(val))
 	}
-	return redacted
+	return RedactPatterns(redacted)
+}
+
+// secretPattern is a named regular expression matching a well-known credential shape.
+type secretPattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// secretPatterns are applied in order; PEM blocks come first so their base64
+// body is not partially matched by a shorter pattern.
+var secretPatterns = []secretPattern{
+	{"private_key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{"aws_access_key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"github_token", regexp.MustCompile(`\b(?:github_pat_[0-9A-Za-z_]{22,}|gh[pousr]_[0-9A-Za-z]{36,})\b`)},
+	{"openai_key", regexp.MustCompile(`\bsk-(?:proj-|ant-)?[0-9A-Za-z_\-]{20,}`)},
+	{"jwt", regexp.MustCompile(`\beyJ[0-9A-Za-z_\-]{8,}\.eyJ[0-9A-Za-z_\-]{8,}\.[0-9A-Za-z_\-]{8,}`)},
+	{"bearer_token", regexp.MustCompile(`(?i)\bbearer\s+[0-9A-Za-z._~+/\-]{16,}=*`)},
+}
+
+// RedactPatterns replaces well-known credential shapes (AWS access keys, OpenAI
+// sk- keys, GitHub tokens, JWTs, bearer tokens, PEM private keys) with
+// [REDACTED:<pattern>]. It needs no vault and is safe to call on any text.
+func RedactPatterns(text string) string {
+	for _, p := range secretPatterns {
+		text = p.re.ReplaceAllString(text, "[REDACTED:"+p.name+"]")
+	}
+	return text
 }
 
 // Delete removes a secret by name.
@@ -246,14 +487,25 @@ func (v *Vault) Delete(name string) error {
 // Close closes the underlying database.
 func (v *Vault) Close() error {
 	// Zero the in-memory key before closing.
+	v.mu.Lock()
 	zeroise(v.key)
+	v.mu.Unlock()
 	return v.db.Close()
 }
 
 // --- encryption ---
 
+// encrypt and decrypt use the current key; callers hold v.mu.
 func (v *Vault) encrypt(plaintext string) (string, error) {
-	block, err := aes.NewCipher(v.key)
+	return encryptWith(v.key, plaintext)
+}
+
+func (v *Vault) decrypt(encoded string) (string, error) {
+	return decryptWith(v.key, encoded)
+}
+
+func encryptWith(key []byte, plaintext string) (string, error) {
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return "", err
 	}
@@ -269,12 +521,12 @@ func (v *Vault) encrypt(plaintext string) (string, error) {
 	return base64.StdEncoding.EncodeToString(ct), nil
 }
 
-func (v *Vault) decrypt(encoded string) (string, error) {
+func decryptWith(key []byte, encoded string) (string, error) {
 	data, err := base64.StdEncoding.DecodeString(encoded)
 	if err != nil {
 		return "", fmt.Errorf("vault: base64: %w", err)
 	}
-	block, err := aes.NewCipher(v.key)
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return "", err
 	}
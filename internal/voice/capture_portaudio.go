@@ -0,0 +1,143 @@
+//go:build portaudio
+
+package voice
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gordonklaus/portaudio"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	captureFrames   = 1024  // samples per read
+	speechThreshold = 500.0 // RMS of int16 samples that counts as speech
+	maxUtterance    = 30 * time.Second
+)
+
+// startCapture opens the default microphone and segments it into utterances
+// on silence. Each utterance is written to a temporary WAV, transcribed and
+// passed to the transcript handler, gated on the wake word in wake-word
+// mode (see dispatch). Push-to-talk currently segments the same way as
+// continuous mode.
+func (v *VoiceInterface) startCapture() (func(), error) {
+	if err := portaudio.Initialize(); err != nil {
+		return nil, fmt.Errorf("voice: portaudio: %w", err)
+	}
+	rate := v.cfg.SampleRate
+	if rate <= 0 {
+		rate = 16000
+	}
+	buf := make([]int16, captureFrames)
+	stream, err := portaudio.OpenDefaultStream(1, 0, float64(rate), len(buf), buf)
+	if err != nil {
+		portaudio.Terminate()
+		return nil, fmt.Errorf("voice: open microphone: %w", err)
+	}
+	if err := stream.Start(); err != nil {
+		stream.Close()
+		portaudio.Terminate()
+		return nil, fmt.Errorf("voice: start microphone: %w", err)
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		silenceLimit := time.Duration(v.cfg.SilenceMs) * time.Millisecond
+		frameDur := time.Duration(len(buf)) * time.Second / time.Duration(rate)
+		var utterance []int16
+		var silence time.Duration
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if err := stream.Read(); err != nil {
+				log.Warn().Err(err).Msg("VoiceInterface: microphone read failed")
+				return
+			}
+			speaking := rms(buf) >= speechThreshold
+			if len(utterance) == 0 && !speaking {
+				continue
+			}
+			utterance = append(utterance, buf...)
+			if speaking {
+				silence = 0
+			} else {
+				silence += frameDur
+			}
+			length := time.Duration(len(utterance)) * time.Second / time.Duration(rate)
+			if silence >= silenceLimit || length >= maxUtterance {
+				v.deliver(utterance, rate)
+				utterance, silence = nil, 0
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+		stream.Stop()
+		stream.Close()
+		portaudio.Terminate()
+	}, nil
+}
+
+// deliver transcribes one captured utterance and hands it to the handler.
+func (v *VoiceInterface) deliver(samples []int16, rate int) {
+	path := filepath.Join(os.TempDir(), fmt.Sprintf("nexus-voice-%d.wav", time.Now().UnixNano()))
+	defer os.Remove(path)
+	if err := writeWAV(path, samples, rate); err != nil {
+		log.Warn().Err(err).Msg("VoiceInterface: write utterance")
+		return
+	}
+	evt, err := v.Transcribe(path)
+	if err != nil {
+		log.Warn().Err(err).Msg("VoiceInterface: transcription failed")
+		return
+	}
+	if evt.Text == "" {
+		return
+	}
+	v.dispatch(evt)
+}
+
+func rms(samples []int16) float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+// writeWAV writes 16-bit mono PCM samples as a RIFF/WAVE file.
+func writeWAV(path string, samples []int16, rate int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	dataLen := uint32(len(samples) * 2)
+	header := []interface{}{
+		[4]byte{'R', 'I', 'F', 'F'}, 36 + dataLen, [4]byte{'W', 'A', 'V', 'E'},
+		[4]byte{'f', 'm', 't', ' '}, uint32(16), uint16(1), uint16(1),
+		uint32(rate), uint32(rate * 2), uint16(2), uint16(16),
+		[4]byte{'d', 'a', 't', 'a'}, dataLen,
+	}
+	for _, field := range header {
+		if err := binary.Write(f, binary.LittleEndian, field); err != nil {
+			return err
+		}
+	}
+	if err := binary.Write(f, binary.LittleEndian, samples); err != nil {
+		return err
+	}
+	return f.Close()
+}
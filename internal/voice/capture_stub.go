@@ -0,0 +1,11 @@
+//go:build !portaudio
+
+package voice
+
+import "fmt"
+
+// startCapture is unavailable without PortAudio; Transcribe still works on
+// recorded WAV files.
+func (v *VoiceInterface) startCapture() (func(), error) {
+	return nil, fmt.Errorf("voice: %s mode needs microphone capture — rebuild with -tags portaudio, or use simulated mode", v.cfg.Mode)
+}
@@ -15,13 +15,19 @@ NEXUS VoiceInterface:
   6. Push-to-talk mode (hold key = record)
   7. Works fully offline — no cloud STT/TTS APIs
 
-Build tag: requires portaudio and whisper.cpp bindings.
-For environments without audio hardware, runs in text-simulation mode.
+Microphone capture needs PortAudio and is built only with -tags portaudio.
+Transcription shells out to whisper.cpp and works on any WAV file, so
+environments without audio hardware can still Transcribe recordings or run
+in text-simulation mode.
 */
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -64,8 +70,11 @@ type VoiceConfig struct {
 	WakeWord     string
 	SampleRate   int
 	Language     string
-	WhisperModel string // tiny/base/small/medium
+	WhisperModel string // tiny/base/small/medium, or a path to a ggml .bin model
+	WhisperBin   string // whisper.cpp CLI binary (default: whisper-cli)
+	WhisperDir   string // directory holding ggml-<model>.bin (default: ~/.nexus/models/whisper)
 	SilenceMs    int    // ms of silence to end utterance
+	FollowUpMs   int    // wake-word mode: ms after a wake word during which commands need no wake word
 }
 
 // DefaultConfig returns sensible defaults
@@ -77,17 +86,21 @@ func DefaultConfig() VoiceConfig {
 		SampleRate:   16000,
 		Language:     "en",
 		WhisperModel: "base",
+		WhisperBin:   "whisper-cli",
 		SilenceMs:    800,
+		FollowUpMs:   8000,
 	}
 }
 
 // VoiceInterface manages voice input/output for NEXUS
 type VoiceInterface struct {
-	cfg       VoiceConfig
-	mu        sync.Mutex
-	listening bool
-	onText    func(TranscriptEvent)
-	simBuffer []string // for simulated mode
+	cfg         VoiceConfig
+	mu          sync.Mutex
+	listening   bool
+	onText      func(TranscriptEvent)
+	simBuffer   []string // for simulated mode
+	stopCapture func()   // set while microphone capture runs
+	lastWake    time.Time
 }
 
 // New creates a VoiceInterface
@@ -113,7 +126,12 @@ func (v *VoiceInterface) Start() error {
 		log.Info().Msg("VoiceInterface: running in simulated mode (no mic)")
 		return nil
 	default:
-		// In production: initialise PortAudio stream here
+		stop, err := v.startCapture()
+		if err != nil {
+			v.listening = false
+			return err
+		}
+		v.stopCapture = stop
 		log.Info().Str("mode", string(v.cfg.Mode)).Msg("VoiceInterface: starting")
 		return nil
 	}
@@ -123,7 +141,12 @@ func (v *VoiceInterface) Start() error {
 func (v *VoiceInterface) Stop() {
 	v.mu.Lock()
 	v.listening = false
+	stop := v.stopCapture
+	v.stopCapture = nil
 	v.mu.Unlock()
+	if stop != nil {
+		stop()
+	}
 	log.Info().Msg("VoiceInterface: stopped")
 }
 
@@ -132,6 +155,35 @@ func (v *VoiceInterface) SimulateInput(text string) {
 	if v.onText == nil {
 		return
 	}
+	v.dispatch(v.newEvent(text, 0.95, 1500))
+}
+
+// dispatch forwards evt to the transcript handler. In wake-word mode,
+// utterances without the wake word are dropped unless they arrive within
+// FollowUpMs of the last one that had it.
+func (v *VoiceInterface) dispatch(evt TranscriptEvent) {
+	if v.onText == nil {
+		return
+	}
+	if v.cfg.Mode == ModeWakeWord {
+		v.mu.Lock()
+		if evt.WakeWordDetected {
+			v.lastWake = evt.Timestamp
+		} else if v.lastWake.IsZero() || evt.Timestamp.Sub(v.lastWake) > time.Duration(v.cfg.FollowUpMs)*time.Millisecond {
+			v.mu.Unlock()
+			log.Debug().Str("text", truncate(evt.Text, 80)).Msg("VoiceInterface: no wake word, ignoring")
+			return
+		} else {
+			// A follow-up extends the window, so a conversation can continue.
+			v.lastWake = evt.Timestamp
+		}
+		v.mu.Unlock()
+	}
+	v.onText(evt)
+}
+
+// newEvent builds a TranscriptEvent, detecting and stripping the wake word.
+func (v *VoiceInterface) newEvent(text string, confidence float64, durationMs int64) TranscriptEvent {
 	wakeDetected := containsWakeWord(text, v.cfg.WakeWord)
 	clean := text
 	if wakeDetected {
@@ -139,13 +191,111 @@ func (v *VoiceInterface) SimulateInput(text string) {
 			strings.ToLower(text), v.cfg.WakeWord, "", 1,
 		)))
 	}
-	v.onText(TranscriptEvent{
+	return TranscriptEvent{
 		Text:             clean,
-		Confidence:       0.95,
-		DurationMs:       1500,
+		Confidence:       confidence,
+		DurationMs:       durationMs,
 		Timestamp:        time.Now(),
 		WakeWordDetected: wakeDetected,
-	})
+	}
+}
+
+// Transcribe runs whisper.cpp over a 16 kHz mono WAV file using the
+// configured model and language. Confidence is the mean token probability
+// and DurationMs the end offset of the last segment.
+func (v *VoiceInterface) Transcribe(wavPath string) (TranscriptEvent, error) {
+	if _, err := os.Stat(wavPath); err != nil {
+		return TranscriptEvent{}, fmt.Errorf("voice: transcribe: %w", err)
+	}
+	bin := v.cfg.WhisperBin
+	if bin == "" {
+		bin = "whisper-cli"
+	}
+	outDir, err := os.MkdirTemp("", "nexus-whisper-*")
+	if err != nil {
+		return TranscriptEvent{}, fmt.Errorf("voice: transcribe: %w", err)
+	}
+	defer os.RemoveAll(outDir)
+	prefix := filepath.Join(outDir, "transcript")
+
+	args := []string{"-m", v.whisperModelPath(), "-f", wavPath, "-ojf", "-of", prefix, "-np", "-nt"}
+	if v.cfg.Language != "" {
+		args = append(args, "-l", v.cfg.Language)
+	}
+	if out, err := exec.Command(bin, args...).CombinedOutput(); err != nil {
+		return TranscriptEvent{}, fmt.Errorf("voice: whisper: %w — output: %s", err, truncate(string(out), 200))
+	}
+	data, err := os.ReadFile(prefix + ".json")
+	if err != nil {
+		return TranscriptEvent{}, fmt.Errorf("voice: whisper output: %w", err)
+	}
+	text, confidence, durationMs, err := parseWhisperJSON(data)
+	if err != nil {
+		return TranscriptEvent{}, err
+	}
+	return v.newEvent(text, confidence, durationMs), nil
+}
+
+// whisperModelPath resolves WhisperModel to a ggml model file.
+func (v *VoiceInterface) whisperModelPath() string {
+	model := v.cfg.WhisperModel
+	if model == "" {
+		model = "base"
+	}
+	if strings.HasSuffix(model, ".bin") {
+		return model
+	}
+	dir := v.cfg.WhisperDir
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".nexus", "models", "whisper")
+	}
+	return filepath.Join(dir, "ggml-"+model+".bin")
+}
+
+// whisperOutput is the subset of whisper.cpp's --output-json-full we use.
+type whisperOutput struct {
+	Transcription []struct {
+		Offsets struct {
+			From int64 `json:"from"`
+			To   int64 `json:"to"`
+		} `json:"offsets"`
+		Text   string `json:"text"`
+		Tokens []struct {
+			Text string  `json:"text"`
+			P    float64 `json:"p"`
+		} `json:"tokens"`
+	} `json:"transcription"`
+}
+
+// whisperMarkerRe matches non-speech markers such as [BLANK_AUDIO] or (music).
+var whisperMarkerRe = regexp.MustCompile(`\[[A-Z_ ]+\]|\([^)]*\)`)
+
+func parseWhisperJSON(data []byte) (text string, confidence float64, durationMs int64, err error) {
+	var out whisperOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", 0, 0, fmt.Errorf("voice: parse whisper output: %w", err)
+	}
+	var parts []string
+	var pSum float64
+	var pN int
+	for _, seg := range out.Transcription {
+		if t := strings.TrimSpace(whisperMarkerRe.ReplaceAllString(seg.Text, "")); t != "" {
+			parts = append(parts, t)
+		}
+		for _, tok := range seg.Tokens {
+			if strings.HasPrefix(tok.Text, "[_") { // special tokens: [_BEG_], [_TT_150] …
+				continue
+			}
+			pSum += tok.P
+			pN++
+		}
+		durationMs = seg.Offsets.To
+	}
+	if pN > 0 {
+		confidence = pSum / float64(pN)
+	}
+	return strings.Join(parts, " "), confidence, durationMs, nil
 }
 
 // Speak sends text to the configured TTS engine
@@ -1,7 +1,12 @@
 package voice
 
 import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestVoiceStartStop(t *testing.T) {
@@ -71,3 +76,105 @@ func TestVoiceStatus(t *testing.T) {
 		t.Error("expected non-empty status")
 	}
 }
+
+// fakeWhisper writes a script that mimics whisper-cli -ojf -of <prefix>,
+// recording its arguments next to the output.
+func fakeWhisper(t *testing.T, transcript string) (bin, argsFile string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake whisper binary is a shell script")
+	}
+	dir := t.TempDir()
+	argsFile = filepath.Join(dir, "args")
+	bin = filepath.Join(dir, "whisper-cli")
+	script := `#!/bin/sh
+echo "$@" > ` + argsFile + `
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-of" ]; then prefix="$2"; fi
+  shift
+done
+cat > "$prefix.json" <<'JSON'
+` + transcript + `
+JSON
+`
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return bin, argsFile
+}
+
+func TestVoiceTranscribe(t *testing.T) {
+	bin, argsFile := fakeWhisper(t, `{"transcription": [
+		{"offsets": {"from": 0, "to": 1200}, "text": " Hey NEXUS, run", "tokens": [{"text": "[_BEG_]", "p": 0.1}, {"text": " Hey", "p": 0.9}]},
+		{"offsets": {"from": 1200, "to": 2400}, "text": " the drift scan. [BLANK_AUDIO]", "tokens": [{"text": " drift", "p": 0.7}]}
+	]}`)
+	wav := filepath.Join(t.TempDir(), "utterance.wav")
+	if err := os.WriteFile(wav, []byte("RIFF"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.WhisperBin = bin
+	cfg.WhisperDir = "/models"
+	cfg.Language = "en"
+	v := New(cfg)
+
+	evt, err := v.Transcribe(wav)
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if !evt.WakeWordDetected || evt.Text != ", run the drift scan." {
+		t.Errorf("unexpected event: %+v", evt)
+	}
+	if evt.DurationMs != 2400 {
+		t.Errorf("expected duration 2400ms, got %d", evt.DurationMs)
+	}
+	if evt.Confidence < 0.79 || evt.Confidence > 0.81 {
+		t.Errorf("expected mean token confidence 0.8, got %f", evt.Confidence)
+	}
+	args, _ := os.ReadFile(argsFile)
+	if !strings.Contains(string(args), "-m /models/ggml-base.bin") || !strings.Contains(string(args), "-l en") {
+		t.Errorf("unexpected whisper args: %s", args)
+	}
+}
+
+func TestVoiceTranscribeMissingFile(t *testing.T) {
+	v := New(DefaultConfig())
+	if _, err := v.Transcribe(filepath.Join(t.TempDir(), "missing.wav")); err == nil {
+		t.Error("expected error for missing WAV")
+	}
+}
+
+func TestVoiceWakeWordGating(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Mode = ModeWakeWord
+	cfg.FollowUpMs = 50
+	v := New(cfg)
+
+	var got []string
+	v.SetTranscriptHandler(func(evt TranscriptEvent) { got = append(got, evt.Text) })
+
+	v.SimulateInput("what is the weather")
+	if len(got) != 0 {
+		t.Fatalf("utterance without wake word must be dropped, got %q", got)
+	}
+	v.SimulateInput("hey nexus open my notes")
+	v.SimulateInput("and the calendar")
+	if len(got) != 2 || got[0] != "open my notes" || got[1] != "and the calendar" {
+		t.Fatalf("expected command and follow-up, got %q", got)
+	}
+	time.Sleep(100 * time.Millisecond)
+	v.SimulateInput("this is background chatter")
+	if len(got) != 2 {
+		t.Errorf("utterance after the follow-up window must be dropped, got %q", got)
+	}
+}
+
+func TestVoiceUngatedModes(t *testing.T) {
+	v := New(DefaultConfig()) // simulated mode forwards everything
+	var got []string
+	v.SetTranscriptHandler(func(evt TranscriptEvent) { got = append(got, evt.Text) })
+	v.SimulateInput("what is the weather")
+	if len(got) != 1 {
+		t.Errorf("expected ungated transcript, got %q", got)
+	}
+}
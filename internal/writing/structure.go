@@ -0,0 +1,149 @@
+package writing
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Structure-aware rewriting: fenced code blocks, YAML/TOML front-matter and
+// (for rewrites) Markdown tables are lifted out of the text before it is sent
+// to the LLM and replaced with opaque placeholders, then put back verbatim.
+// Models routinely translate code comments, reflow tables and "fix"
+// front-matter keys; a placeholder gives them nothing to mangle.
+
+// placeholderFmt is the token that stands in for a protected block. It is
+// plain ASCII so every tokenizer keeps it intact.
+const placeholderFmt = "@@KEEP%d@@"
+
+// structurePrompt is appended to the instructions whenever placeholders are
+// present in the text.
+const structurePrompt = "Lines of the form @@KEEPn@@ are placeholders for content that must not change. " +
+	"Copy each placeholder exactly once, on its own line, in the same position."
+
+var tableDelimRe = regexp.MustCompile(`^\|?\s*:?-{3,}:?\s*(\|\s*:?-{3,}:?\s*)*\|?$`)
+
+// protected is text with its structural blocks replaced by placeholders.
+type protected struct {
+	text   string
+	blocks []string
+}
+
+// protectStructure replaces front-matter and fenced code blocks in text with
+// placeholders. When tables is true, Markdown tables are protected as well.
+func protectStructure(text string, tables bool) protected {
+	lines := strings.Split(text, "\n")
+	var p protected
+	var out []string
+	keep := func(block []string) {
+		out = append(out, fmt.Sprintf(placeholderFmt, len(p.blocks)))
+		p.blocks = append(p.blocks, strings.Join(block, "\n"))
+	}
+
+	i := 0
+	if end := frontMatterEnd(lines); end > 0 {
+		keep(lines[:end+1])
+		i = end + 1
+	}
+	for i < len(lines) {
+		if fence, ok := openingFence(lines[i]); ok {
+			end := closingFence(lines, i+1, fence)
+			keep(lines[i : end+1])
+			i = end + 1
+			continue
+		}
+		if tables && isTableStart(lines, i) {
+			end := i + 2
+			for end < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[end]), "|") {
+				end++
+			}
+			keep(lines[i:end])
+			i = end
+			continue
+		}
+		out = append(out, lines[i])
+		i++
+	}
+	p.text = strings.Join(out, "\n")
+	return p
+}
+
+// restore puts the protected blocks back into s. It fails if the model
+// dropped or duplicated a placeholder, since silently losing a code sample is
+// worse than surfacing the error.
+func (p protected) restore(s string) (string, error) {
+	for i, block := range p.blocks {
+		ph := fmt.Sprintf(placeholderFmt, i)
+		switch n := strings.Count(s, ph); n {
+		case 1:
+			s = strings.Replace(s, ph, block, 1)
+		case 0:
+			return "", fmt.Errorf("writing: model dropped protected block %d", i)
+		default:
+			return "", fmt.Errorf("writing: model repeated protected block %d (%d times)", i, n)
+		}
+	}
+	return s, nil
+}
+
+// frontMatterEnd returns the index of the closing delimiter of a leading
+// "---" (YAML) or "+++" (TOML) front-matter block, or -1 if there is none.
+func frontMatterEnd(lines []string) int {
+	if len(lines) == 0 {
+		return -1
+	}
+	delim := strings.TrimSpace(lines[0])
+	if delim != "---" && delim != "+++" {
+		return -1
+	}
+	for i := 1; i < len(lines); i++ {
+		l := strings.TrimSpace(lines[i])
+		if l == delim || (delim == "---" && l == "...") {
+			return i
+		}
+	}
+	return -1
+}
+
+// openingFence reports whether line opens a fenced code block and returns
+// the fence run (e.g. "```" or "~~~~").
+func openingFence(line string) (string, bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || len(trimmed) < 3 {
+		return "", false
+	}
+	c := trimmed[0]
+	if c != '`' && c != '~' {
+		return "", false
+	}
+	n := 0
+	for n < len(trimmed) && trimmed[n] == c {
+		n++
+	}
+	if n < 3 {
+		return "", false
+	}
+	return trimmed[:n], true
+}
+
+// closingFence returns the index of the line closing fence, or the last line
+// if the block is never closed (CommonMark runs it to end of document).
+func closingFence(lines []string, from int, fence string) int {
+	for i := from; i < len(lines); i++ {
+		l := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(l, fence) && strings.Trim(l, fence[:1]) == "" {
+			return i
+		}
+	}
+	return len(lines) - 1
+}
+
+// isTableStart reports whether lines[i] is a Markdown table header followed
+// by a delimiter row.
+func isTableStart(lines []string, i int) bool {
+	if i+1 >= len(lines) {
+		return false
+	}
+	return strings.HasPrefix(strings.TrimSpace(lines[i]), "|") &&
+		tableDelimRe.MatchString(strings.TrimSpace(lines[i+1]))
+}
@@ -1,5 +1,5 @@
 // Package writing provides the NEXUS v1.7 AI Writing Studio.
-// Operations: Draft, Rewrite, Summarise, Proofread, Expand, Translate.
+// Operations: Outline, Draft, Rewrite, Summarise, Proofread, Expand, Translate.
 // Backed by the shared LLM router — zero additional cost.
 package writing
 
@@ -32,10 +32,26 @@ func New(r *router.Router) *Agent {
 	return &Agent{r: r}
 }
 
+// Sampling temperatures: precise edits stay near-deterministic, generative
+// tasks get more room, creative writing the most.
+const (
+	tempPrecise    = 0.2
+	tempGenerative = 0.7
+	tempCreative   = 0.9
+)
+
+// styleTemperature picks the sampling temperature for generative tasks.
+func styleTemperature(style Style) float64 {
+	if style == StyleCreative {
+		return tempCreative
+	}
+	return tempGenerative
+}
+
 // completeText is an internal helper that maps a single prompt to the router's
 // (ctx, systemPrompt, userMsg) signature.
-func (a *Agent) completeText(ctx context.Context, system, user string) (string, error) {
-	res, err := a.r.Complete(ctx, system, user)
+func (a *Agent) completeText(ctx context.Context, system, user string, temperature float64) (string, error) {
+	res, err := a.r.CompleteWithOptions(ctx, system, user, router.CompletionOptions{Temperature: router.Float(temperature)})
 	if err != nil {
 		return "", fmt.Errorf("writing: llm: %w", err)
 	}
@@ -49,17 +65,63 @@ func (a *Agent) Draft(ctx context.Context, topic string, style Style, words int)
 		"Write a %s-style piece about: %s\nTarget length: ~%d words.",
 		style, topic, words,
 	)
-	return a.completeText(ctx, system, user)
+	return a.completeText(ctx, system, user, styleTemperature(style))
 }
 
-// Rewrite rewrites existing text in the given style.
+// DefaultOutlineSections is the number of top-level sections Outline asks for.
+const DefaultOutlineSections = 5
+
+// maxOutlineDepth bounds how many bullet levels an outline may nest.
+const maxOutlineDepth = 4
+
+// Outline turns a topic into a nested bullet outline with
+// DefaultOutlineSections top-level sections, nested depth levels deep
+// (default 2: sections and sub-points). The result is suitable input for
+// Expand.
+func (a *Agent) Outline(ctx context.Context, topic string, depth int) (string, error) {
+	return a.OutlineSections(ctx, topic, depth, DefaultOutlineSections)
+}
+
+// OutlineSections is Outline with an explicit number of top-level sections.
+func (a *Agent) OutlineSections(ctx context.Context, topic string, depth, sections int) (string, error) {
+	if sections <= 0 {
+		sections = DefaultOutlineSections
+	}
+	if depth <= 0 {
+		depth = 2
+	}
+	if depth > maxOutlineDepth {
+		depth = maxOutlineDepth
+	}
+	nesting := "Do not add sub-points."
+	if depth > 1 {
+		nesting = fmt.Sprintf("Nest sub-points exactly %d levels deep, indenting each level by two more spaces, with 2-4 points under each bullet.", depth)
+	}
+	system := "You are an expert writer and editor. Output only the outline as Markdown bullets with no preamble."
+	user := fmt.Sprintf(
+		"Create a structured outline about: %s\nUse exactly %d top-level sections as \"- \" bullets. %s",
+		topic, sections, nesting,
+	)
+	return a.completeText(ctx, system, user, tempGenerative)
+}
+
+// Rewrite rewrites existing text in the given style. Front-matter, fenced
+// code blocks and Markdown tables are passed through unchanged.
 func (a *Agent) Rewrite(ctx context.Context, text string, style Style) (string, error) {
+	p := protectStructure(text, true)
 	system := "You are an expert editor. Output only the rewritten text with no preamble."
+	if len(p.blocks) > 0 {
+		system += " " + structurePrompt
+	}
 	user := fmt.Sprintf(
 		"Rewrite the following text in a %s style. Keep the meaning, improve clarity and tone.\n\n%s",
-		style, text,
+		style, p.text,
 	)
-	return a.completeText(ctx, system, user)
+	out, err := a.completeText(ctx, system, user, styleTemperature(style))
+	if err != nil {
+		return "", err
+	}
+	return p.restore(out)
 }
 
 // Summarise condenses text to a target word count.
@@ -69,7 +131,7 @@ func (a *Agent) Summarise(ctx context.Context, text string, targetWords int) (st
 		"Summarise the following text in ~%d words. Be concise and capture key points.\n\n%s",
 		targetWords, text,
 	)
-	return a.completeText(ctx, system, user)
+	return a.completeText(ctx, system, user, tempPrecise)
 }
 
 // Proofread returns corrected text and a list of issues found.
@@ -79,7 +141,7 @@ func (a *Agent) Proofread(ctx context.Context, text string) (corrected string, i
 		"Proofread the following text. Respond with:\nCORRECTED: <full corrected text>\nISSUE: <one issue per line, prefix each with ISSUE:>\n\n%s",
 		text,
 	)
-	raw, err := a.completeText(ctx, system, user)
+	raw, err := a.completeText(ctx, system, user, tempPrecise)
 	if err != nil {
 		return "", nil, err
 	}
@@ -104,12 +166,24 @@ func (a *Agent) Expand(ctx context.Context, outline string, style Style) (string
 		"Expand the following outline into full %s prose. Each bullet becomes a paragraph.\n\n%s",
 		style, outline,
 	)
-	return a.completeText(ctx, system, user)
+	return a.completeText(ctx, system, user, styleTemperature(style))
 }
 
-// Translate translates text to the target language.
+// Translate translates text to the target language. Front-matter and fenced
+// code blocks are passed through unchanged; Markdown tables keep their layout
+// and only the cell text is translated.
 func (a *Agent) Translate(ctx context.Context, text, targetLang string) (string, error) {
+	p := protectStructure(text, false)
 	system := fmt.Sprintf("You are a professional translator. Translate to %s. Output only the translation.", targetLang)
-	user := text
-	return a.completeText(ctx, system, user)
+	if strings.Contains(p.text, "|") {
+		system += " Keep Markdown table rows and | separators as they are; translate only the cell text."
+	}
+	if len(p.blocks) > 0 {
+		system += " " + structurePrompt
+	}
+	out, err := a.completeText(ctx, system, p.text, tempPrecise)
+	if err != nil {
+		return "", err
+	}
+	return p.restore(out)
 }
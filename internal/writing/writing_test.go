@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/Omkar0612/nexus-ai/internal/router"
@@ -100,3 +101,91 @@ func TestTranslate(t *testing.T) {
 		t.Error("expected translation")
 	}
 }
+
+func TestOutline(t *testing.T) {
+	srv := mockLLMServer("- Intro\n  - Why now\n- Architecture\n  - Router")
+	defer srv.Close()
+	a := newTestAgent(srv.URL)
+	out, err := a.Outline(context.Background(), "Local-first AI", 2)
+	if err != nil {
+		t.Fatalf("Outline: %v", err)
+	}
+	if !strings.HasPrefix(out, "- ") {
+		t.Errorf("expected bullet outline, got %q", out)
+	}
+}
+
+func TestOutlineDepthAndSections(t *testing.T) {
+	var prompt string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct{ Content string } `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		prompt = body.Messages[len(body.Messages)-1].Content
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"content": "- A"}}},
+		})
+	}))
+	defer srv.Close()
+	a := newTestAgent(srv.URL)
+
+	if _, err := a.OutlineSections(context.Background(), "Roadmap", 3, 7); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(prompt, "exactly 7 top-level") || !strings.Contains(prompt, "3 levels deep") {
+		t.Errorf("depth and sections not in prompt: %q", prompt)
+	}
+	if _, err := a.Outline(context.Background(), "Roadmap", 1); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(prompt, "exactly 5 top-level") || !strings.Contains(prompt, "Do not add sub-points") {
+		t.Errorf("depth 1 outline should be flat with default sections: %q", prompt)
+	}
+}
+
+func TestProtectStructure(t *testing.T) {
+	doc := "---\ntitle: Demo\n---\nIntro text.\n\n```go\n// say hello\nfmt.Println(\"hi\")\n```\n\n| Name | Value |\n| --- | --- |\n| a | 1 |\n\nOutro."
+	p := protectStructure(doc, true)
+	if len(p.blocks) != 3 {
+		t.Fatalf("expected 3 protected blocks, got %d: %q", len(p.blocks), p.blocks)
+	}
+	if strings.Contains(p.text, "say hello") || strings.Contains(p.text, "title:") || strings.Contains(p.text, "| a |") {
+		t.Errorf("protected content leaked into prompt text: %q", p.text)
+	}
+	back, err := p.restore(p.text)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if back != doc {
+		t.Errorf("round trip mismatch:\n got %q\nwant %q", back, doc)
+	}
+
+	if p := protectStructure(doc, false); len(p.blocks) != 2 {
+		t.Errorf("expected tables left in place when tables=false, got %d blocks", len(p.blocks))
+	}
+}
+
+func TestTranslatePreservesCodeBlocks(t *testing.T) {
+	srv := mockLLMServer("Hola.\n\n@@KEEP0@@\n\nAdiós.")
+	defer srv.Close()
+	a := newTestAgent(srv.URL)
+	code := "```python\n# greet the user\nprint('hello')\n```"
+	out, err := a.Translate(context.Background(), "Hello.\n\n"+code+"\n\nGoodbye.", "Spanish")
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	if !strings.Contains(out, code) {
+		t.Errorf("code block not restored verbatim: %q", out)
+	}
+}
+
+func TestRewriteDroppedPlaceholder(t *testing.T) {
+	srv := mockLLMServer("Rewritten without the code.")
+	defer srv.Close()
+	a := newTestAgent(srv.URL)
+	if _, err := a.Rewrite(context.Background(), "Text.\n\n```\nx := 1\n```", StyleCasual); err == nil {
+		t.Error("expected error when the model drops a protected block")
+	}
+}